@@ -18,15 +18,22 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/rs/zerolog"
 	"promptline/internal/chat"
-	"promptline/internal/config"
 )
 
+// batchResult is the JSON form of a batch reply printed with -json.
+type batchResult struct {
+	Response  string           `json:"response"`
+	ToolCalls []chat.ToolTrace `json:"tool_calls"`
+}
+
 func runBatchMode(logger zerolog.Logger) {
 	if err := runBatch(logger); err != nil {
 		logger.Error().Err(err).Msg("Batch mode failed")
@@ -38,18 +45,24 @@ func runBatchMode(logger zerolog.Logger) {
 func runBatch(logger zerolog.Logger) error {
 	logger.Debug().Msg("Running in batch mode")
 
-	// Load configuration
-	cfg, err := config.LoadConfig("config.json")
+	// Load configuration and create chat session
+	session, err := newProviderSession(*provider)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
-
-	// Create chat session
-	session := chat.NewSession(cfg)
 	defer session.Close()
-	session.ToolApprover = newToolApprover()
+	// With an approval hook the session asks it instead of the terminal.
+	if session.Config.ApprovalHook == "" {
+		session.ToolApprover = newToolApprover(session.Config.ToolApprovalTimeout(), session.ToolRegistry, session.Config.ApprovalPreview, nil)
+	}
 	session.Logger = &logger
 	session.DryRun = *dryRun
+	session.ToolsDisabled = *noTools
+
+	// Fail fast on a bad key or endpoint, before consuming stdin.
+	if err := runPreflight(io.Discard, session); err != nil {
+		return fmt.Errorf("preflight check failed: %w", err)
+	}
 
 	// Read input from stdin
 	scanner := bufio.NewScanner(os.Stdin)
@@ -73,7 +86,13 @@ func runBatch(logger zerolog.Logger) error {
 			Msg("AI response received")
 
 		// Output response
-		fmt.Println(response)
+		if *jsonOut {
+			if err := writeBatchJSON(os.Stdout, response, session.LastTurnTrace()); err != nil {
+				return fmt.Errorf("failed to write response: %w", err)
+			}
+		} else {
+			fmt.Println(response)
+		}
 
 	}
 
@@ -83,3 +102,11 @@ func runBatch(logger zerolog.Logger) error {
 
 	return nil
 }
+
+// writeBatchJSON prints a reply and the tool calls made for it as one JSON line.
+func writeBatchJSON(w io.Writer, response string, trace []chat.ToolTrace) error {
+	if trace == nil {
+		trace = []chat.ToolTrace{}
+	}
+	return json.NewEncoder(w).Encode(batchResult{Response: response, ToolCalls: trace})
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"unicode/utf8"
+)
+
+// clipboardFallbackFile is read when no system clipboard is reachable, e.g. on
+// headless machines or over SSH.
+const clipboardFallbackFile = ".promptline_clipboard.txt"
+
+// clipboardCommand is an external program that prints the clipboard contents.
+type clipboardCommand struct {
+	name string
+	args []string
+}
+
+// clipboardReaders lists the programs tried in order for the current platform.
+var clipboardReaders = func() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{{name: "pbpaste"}}
+	case "windows":
+		return []clipboardCommand{{name: "powershell", args: []string{"-NoProfile", "-Command", "Get-Clipboard -Raw"}}}
+	default:
+		return []clipboardCommand{
+			{name: "wl-paste", args: []string{"--no-newline"}},
+			{name: "xclip", args: []string{"-selection", "clipboard", "-o"}},
+			{name: "xsel", args: []string{"--clipboard", "--output"}},
+		}
+	}
+}()
+
+// readClipboard returns the system clipboard contents and where they came from.
+// It falls back to ./.promptline_clipboard.txt when no clipboard program works.
+func readClipboard() (string, string, error) {
+	for _, reader := range clipboardReaders {
+		if _, err := exec.LookPath(reader.name); err != nil {
+			continue
+		}
+		var stdout bytes.Buffer
+		cmd := exec.Command(reader.name, reader.args...)
+		cmd.Stdout = &stdout
+		if err := cmd.Run(); err != nil {
+			continue
+		}
+		return stdout.String(), reader.name, nil
+	}
+
+	data, err := os.ReadFile(clipboardFallbackFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "", fmt.Errorf("no clipboard available and %s not found", clipboardFallbackFile)
+		}
+		return "", "", fmt.Errorf("read %s: %w", clipboardFallbackFile, err)
+	}
+	return string(data), clipboardFallbackFile, nil
+}
+
+// truncatePaste cuts text to at most limit bytes without splitting a UTF-8
+// sequence, reporting whether anything was dropped.
+func truncatePaste(text string, limit int) (string, bool) {
+	if limit <= 0 || len(text) <= limit {
+		return text, false
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut], true
+}
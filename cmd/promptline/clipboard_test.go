@@ -0,0 +1,67 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func useClipboardFallback(t *testing.T, content string) {
+	t.Helper()
+	saved := clipboardReaders
+	clipboardReaders = nil
+	t.Cleanup(func() { clipboardReaders = saved })
+	t.Chdir(t.TempDir())
+	if content != "" {
+		if err := os.WriteFile(clipboardFallbackFile, []byte(content), 0o600); err != nil {
+			t.Fatalf("write fallback file: %v", err)
+		}
+	}
+}
+
+func TestReadClipboardFallback(t *testing.T) {
+	useClipboardFallback(t, "")
+	if _, _, err := readClipboard(); err == nil || !strings.Contains(err.Error(), clipboardFallbackFile) {
+		t.Fatalf("expected missing fallback error, got %v", err)
+	}
+
+	if err := os.WriteFile(clipboardFallbackFile, []byte("log line\n"), 0o600); err != nil {
+		t.Fatalf("write fallback file: %v", err)
+	}
+	content, source, err := readClipboard()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "log line\n" || source != clipboardFallbackFile {
+		t.Errorf("unexpected clipboard %q from %q", content, source)
+	}
+}
+
+func TestTruncatePaste(t *testing.T) {
+	if got, cut := truncatePaste("short", 10); got != "short" || cut {
+		t.Errorf("short text should be kept, got %q %v", got, cut)
+	}
+	if got, cut := truncatePaste("unlimited", 0); got != "unlimited" || cut {
+		t.Errorf("zero limit should keep text, got %q %v", got, cut)
+	}
+	// "é" is two bytes; cutting inside it must drop the whole rune.
+	if got, cut := truncatePaste("abé", 3); got != "ab" || !cut {
+		t.Errorf("expected rune-safe truncation, got %q %v", got, cut)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"promptline/internal/tools"
+)
+
+// codeBlockAsk reads the answers to the save prompts. The console sets it to
+// its readline-based ask, so answers are not split between readline and a
+// second reader of stdin; while it is nil no blocks are offered.
+var codeBlockAsk func(prompt string) (string, bool)
+
+// codeBlock is a fenced code block found in an assistant reply.
+type codeBlock struct {
+	Lang    string
+	Path    string
+	Content string
+}
+
+// extractCodeBlocks returns the fenced code blocks of a reply in order. A file
+// name is taken from a "path=..." or "file:..." entry in the info string, as in
+// ```go path=cmd/main.go. Unterminated blocks are ignored.
+func extractCodeBlocks(reply string) []codeBlock {
+	var blocks []codeBlock
+	var current *codeBlock
+	var fence string
+	var body strings.Builder
+	for _, line := range strings.Split(reply, "\n") {
+		trimmed := strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if current == nil {
+			marker := fenceMarker(trimmed)
+			if marker == "" {
+				continue
+			}
+			lang, path := parseFenceInfo(trimmed[len(marker):])
+			current = &codeBlock{Lang: lang, Path: path}
+			fence = marker
+			body.Reset()
+			continue
+		}
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			current.Content = body.String()
+			blocks = append(blocks, *current)
+			current = nil
+			continue
+		}
+		body.WriteString(strings.TrimSuffix(line, "\r"))
+		body.WriteString("\n")
+	}
+	return blocks
+}
+
+// fenceMarker returns the run of backticks or tildes opening a fence, or ""
+// when line does not open one.
+func fenceMarker(line string) string {
+	if !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "~~~") {
+		return ""
+	}
+	end := 0
+	for end < len(line) && line[end] == line[0] {
+		end++
+	}
+	return line[:end]
+}
+
+// parseFenceInfo splits a fence info string into the language and file name.
+func parseFenceInfo(info string) (lang, path string) {
+	for i, field := range strings.Fields(info) {
+		switch {
+		case strings.HasPrefix(field, "path="):
+			path = strings.Trim(strings.TrimPrefix(field, "path="), `"'`)
+		case strings.HasPrefix(field, "file="):
+			path = strings.Trim(strings.TrimPrefix(field, "file="), `"'`)
+		case strings.HasPrefix(field, "file:"):
+			path = strings.TrimPrefix(field, "file:")
+		case i == 0:
+			lang = field
+		}
+	}
+	return lang, path
+}
+
+// offerReplyCodeBlocks offers to save the code blocks of a finished reply when
+// save_code_blocks is on and the console can ask about them.
+func offerReplyCodeBlocks(registry *tools.Registry, reply string) {
+	blocks := extractCodeBlocks(reply)
+	if len(blocks) == 0 || codeBlockAsk == nil || registry == nil {
+		return
+	}
+	offerCodeBlocks(codeBlockAsk, os.Stdout, registry, blocks)
+}
+
+// offerCodeBlocks asks, block by block, whether to write each one to disk;
+// ask returns false when the answer was interrupted, which stops the offer.
+// Blocks with a file name default to it; the others ask for one, and an empty
+// answer skips the block. Writes go through the create_file path checks in the
+// registry working directory, and existing files are only replaced after a
+// second confirmation.
+func offerCodeBlocks(ask func(prompt string) (string, bool), output io.Writer, registry *tools.Registry, blocks []codeBlock) {
+	for i, block := range blocks {
+		label := fmt.Sprintf("code block %d of %d (%s)", i+1, len(blocks), describeCodeBlock(block))
+		path := block.Path
+		if path != "" {
+			answer, ok := ask(fmt.Sprintf("Save %s to %s? (yes/No/other path): ", label, path))
+			if !ok {
+				return
+			}
+			answer = strings.TrimSpace(answer)
+			switch strings.ToLower(answer) {
+			case "y", "yes":
+			case "", "n", "no":
+				continue
+			default:
+				path = answer
+			}
+		} else {
+			answer, ok := ask(fmt.Sprintf("Save %s as (empty to skip): ", label))
+			if !ok {
+				return
+			}
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				continue
+			}
+			path = answer
+		}
+
+		result, err := registry.WriteTextFile(path, block.Content, false)
+		if errors.Is(err, tools.ErrFileExists) {
+			answer, ok := ask(fmt.Sprintf("%s exists. Overwrite? (yes/No): ", path))
+			if !ok {
+				return
+			}
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				continue
+			}
+			result, err = registry.WriteTextFile(path, block.Content, true)
+		}
+		if err != nil {
+			fmt.Fprintf(output, "✗ %s: %v\n", path, err)
+			continue
+		}
+		fmt.Fprintf(output, "✓ %s\n", result)
+	}
+}
+
+func describeCodeBlock(block codeBlock) string {
+	lines := strings.Count(block.Content, "\n")
+	unit := "lines"
+	if lines == 1 {
+		unit = "line"
+	}
+	if block.Lang == "" {
+		return fmt.Sprintf("%d %s", lines, unit)
+	}
+	return fmt.Sprintf("%s, %d %s", block.Lang, lines, unit)
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"promptline/internal/tools"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	reply := "Two files:\n\n```go path=cmd/main.go\npackage main\n```\n\nand\n\n~~~~ file:notes.txt\nuse ``` inside\n~~~~\n\n```\nno name\n```\n\n```sh\nunterminated\n"
+	blocks := extractCodeBlocks(reply)
+	if len(blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %+v", blocks)
+	}
+	want := []codeBlock{
+		{Lang: "go", Path: "cmd/main.go", Content: "package main\n"},
+		{Path: "notes.txt", Content: "use ``` inside\n"},
+		{Content: "no name\n"},
+	}
+	for i, block := range blocks {
+		if block != want[i] {
+			t.Fatalf("block %d: got %+v, want %+v", i, block, want[i])
+		}
+	}
+}
+
+func TestOfferCodeBlocks(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+	previousRoot, err := tools.WorkRoot()
+	if err != nil {
+		t.Fatalf("failed to read work root: %v", err)
+	}
+	if err := tools.ConfigureWorkRoot(""); err != nil {
+		t.Fatalf("failed to configure work root: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = tools.ConfigureWorkRoot(previousRoot)
+	})
+	if err := os.Mkdir("work", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("work", "existing.txt"), []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	registry := tools.NewRegistry()
+	if _, err := registry.ChangeWorkingDirectory("work"); err != nil {
+		t.Fatalf("failed to change directory: %v", err)
+	}
+
+	blocks := []codeBlock{
+		{Lang: "go", Path: "src/main.go", Content: "package main\n"},
+		{Content: "unnamed\n"},
+		{Path: "skipped.txt", Content: "skip\n"},
+		{Path: "existing.txt", Content: "new\n"},
+		{Path: "other.txt", Content: "renamed\n"},
+		{Path: "../../outside.txt", Content: "escape\n"},
+	}
+	replies := []string{"yes", "named.txt", "", "y", "yes", "renamed.txt", "y"}
+	var out strings.Builder
+	ask := func(prompt string) (string, bool) {
+		out.WriteString(prompt)
+		if len(replies) == 0 {
+			return "", false
+		}
+		reply := replies[0]
+		replies = replies[1:]
+		return reply, true
+	}
+	offerCodeBlocks(ask, &out, registry, blocks)
+
+	for path, want := range map[string]string{
+		"src/main.go":  "package main\n",
+		"named.txt":    "unnamed\n",
+		"existing.txt": "new\n",
+		"renamed.txt":  "renamed\n",
+	} {
+		data, err := os.ReadFile(filepath.Join("work", path))
+		if err != nil || string(data) != want {
+			t.Fatalf("%s: got %q (%v), want %q\noutput:\n%s", path, data, err, want, out.String())
+		}
+	}
+	for _, path := range []string{"work/skipped.txt", "work/other.txt", "src/main.go", "named.txt", "../outside.txt"} {
+		if _, err := os.Stat(path); err == nil {
+			t.Fatalf("%s should not have been written", path)
+		}
+	}
+	if !strings.Contains(out.String(), "existing.txt exists. Overwrite?") ||
+		!strings.Contains(out.String(), "✗ ../../outside.txt") {
+		t.Fatalf("unexpected output:\n%s", out.String())
+	}
+}
@@ -17,8 +17,13 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 
@@ -31,32 +36,96 @@ import (
 type Command struct {
 	Name        string
 	Description string
+	Usage       string // arguments shown after the name, if any
+	Details     string // longer explanation for /help <command>
 }
 
 // getAvailableCommands returns the list of all slash commands
 func getAvailableCommands() []Command {
 	return []Command{
-		{Name: "help", Description: "Show available commands"},
-		{Name: "clear", Description: "Clear conversation history"},
-		{Name: "history", Description: "Display conversation history"},
-		{Name: "debug", Description: "Toggle debug mode"},
+		{Name: "help", Description: "Show available commands", Usage: "[command]",
+			Details: "Without arguments lists every command. With a command name shows its usage and details."},
+		{Name: "clear", Description: "Clear conversation history",
+			Details: "Drops all messages except the system prompt. The history file is not modified."},
+		{Name: "history", Description: "Display conversation history, or search earlier input", Usage: "[text]",
+			Details: "With text, lists the most recent prompts containing it (ignoring case) and loads the one you pick into the input line for editing. Ctrl+R searches the same history incrementally."},
+		{Name: "debug", Description: "Turn debug mode on or off", Usage: "[on|off]",
+			Details: "Without an argument it toggles. While debug mode is on, log lines (requests, tool calls, timings) are shown in the chat and, when promptline was started with -d, also written to the debug log file."},
+		{Name: "debug-request", Description: "Show the JSON of the next request sent to the provider", Usage: "[file|off]",
+			Details: "Prints the exact chat completion request body (messages, tools and parameters) of the next prompt before it is sent, or writes it to file. The response is shown as usual. The API key is never part of the dump. off cancels a pending dump."},
 		{Name: "permissions", Description: "Show and adjust tool permissions"},
+		{Name: "cd", Description: "Change the tools working directory", Usage: "<dir>",
+			Details: "Tools resolve relative paths against this directory. It cannot leave the directory promptline was started in."},
+		{Name: "checkpoint", Description: "Save a named snapshot of the conversation", Usage: "<name>",
+			Details: "Saving under an existing name replaces that checkpoint. Use /restore to go back to it."},
+		{Name: "restore", Description: "Roll the conversation back to a checkpoint", Usage: "<name>"},
+		{Name: "checkpoints", Description: "List conversation checkpoints"},
+		{Name: "pin", Description: "Keep a turn when the history is trimmed", Usage: "[turn]",
+			Details: "A turn is one of your messages with everything answered after it, tool calls included; turns count from 1 at the oldest message in the conversation. Without a number pins the latest turn. Pinned turns are skipped when history_max_messages or the context window trims old messages. /pin list shows the pinned turns."},
+		{Name: "unpin", Description: "Let a pinned turn be trimmed again", Usage: "[turn]",
+			Details: "Without a number removes every pin."},
+		{Name: "retry", Description: "Regenerate the last response", Usage: "[temperature]",
+			Details: "Drops the last answer, including its tool calls, and asks again. The optional temperature (0-2) applies to this request only."},
+		{Name: "edit", Description: "Edit and resend your last message",
+			Details: "Loads your last message into the prompt. Sending the edited text replaces that turn: the old message and everything answered after it are dropped before the new version is sent. Clearing the line or pressing Ctrl+C keeps the conversation as it was."},
+		{Name: "context", Description: "Show how much of the context window is in use",
+			Details: "Estimates the tokens of the conversation (about four characters per token) against context_window and warns above 80%."},
+		{Name: "attach", Description: "Attach a text file to the next message", Usage: "[path]",
+			Details: "The file is read with the same path and size checks as read_file and sent inside a ```file:<path> block before your next message. Attach several files by repeating the command. Without a path lists pending attachments. Files that would overflow context_window are truncated."},
+		{Name: "paste", Description: "Send the clipboard contents", Usage: "[prompt]",
+			Details: "Reads the system clipboard, or ./.promptline_clipboard.txt when none is available, and sends it after the optional prompt. Pastes above paste_max_bytes are truncated."},
+		{Name: "stream", Description: "Turn response streaming on or off", Usage: "[on|off]",
+			Details: "With streaming off, replies are shown once complete. Without an argument shows the current mode."},
+		{Name: "tools", Description: "Turn tool use on or off", Usage: "[on|off]",
+			Details: "With tools off, requests carry no tool definitions, so the model answers in plain text. Unlike denying tools in config, the model is not told about them at all. Start with -no-tools to begin with tools off. Without an argument shows the current mode."},
+		{Name: "prefix", Description: "Turn the user message prefix on or off", Usage: "[on|off]",
+			Details: "user_message_prefix from config.json is sent in front of every user message, but never shown or saved to history. off sends messages as typed for the rest of the session. Without an argument shows the prefix and whether it is sent."},
+		{Name: "snippet", Description: "Save and reuse prompt templates", Usage: "[list|save <name> [text]|use <name>|delete <name>]",
+			Details: "Snippets live in ./.promptline_snippets.json. save without text stores the last message you sent. Typing ::name anywhere in a message replaces it with the snippet before sending; {{key}} placeholders are filled from ::name(key=value, other=value). /snippet use <name> sends a snippet on its own."},
+		{Name: "models", Description: "List the models offered by the provider", Usage: "[filter]",
+			Details: "Queries the provider's model list and prints the IDs containing filter (case-insensitive). Switch to one with /model. Providers without a model listing endpoint are reported as such."},
+		{Name: "model", Description: "Show or switch the model", Usage: "[name]",
+			Details: "Without a name shows the model in use and its context window. With a name, the following requests use that model for the rest of the session; config.json is not changed. The context window follows the model (model_context_windows, then context_window), so /context and history trimming adjust right away."},
+		{Name: "import", Description: "Continue a conversation exported from another app", Usage: "<file> [chatgpt|openai]",
+			Details: "Replaces the current conversation with the user and assistant messages of an export; the system prompt stays. Reads ChatGPT's conversations.json (the most recently updated chat when it holds several) or a JSON list of chat completion messages; the format is detected unless given. System prompts and tool traffic are skipped."},
+		{Name: "cleanup", Description: "Delete the temporary files created by mktemp",
+			Details: "Empties the .tmp directory of the working directory, where the mktemp tool creates files. Nothing outside .tmp is touched. Files mktemp created are also removed when promptline exits unless clean_temp_on_close is false."},
 		{Name: "quit", Description: "Exit the application"},
 		{Name: "exit", Description: "Exit the application"},
 	}
 }
 
+// findCommand looks up a command by name, with or without the leading slash.
+func findCommand(name string) (Command, bool) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "/"))
+	for _, cmd := range getAvailableCommands() {
+		if cmd.Name == name {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// usageLine returns the command with its arguments, e.g. "/retry [temperature]".
+func (c Command) usageLine() string {
+	if c.Usage == "" {
+		return "/" + c.Name
+	}
+	return "/" + c.Name + " " + c.Usage
+}
+
 // handleCommand processes slash commands, returns true if should quit
-func handleCommand(input string, session *chat.Session, logger zerolog.Logger, debugMode *bool) bool {
-	cmdName := strings.TrimPrefix(input, "/")
-	cmdName = strings.ToLower(strings.TrimSpace(cmdName))
+func handleCommand(input string, session *chat.Session, logger zerolog.Logger, debugMode *bool, canceler *operationCanceler) bool {
+	cmdName, cmdArgs, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(input, "/")), " ")
+	cmdName = strings.ToLower(cmdName)
+	cmdArgs = strings.TrimSpace(cmdArgs)
 
 	logger.Debug().Str("command", cmdName).Msg("Executing command")
 
 	// Execute command based on name
 	switch cmdName {
 	case "help":
-		showHelp()
+		showHelp(os.Stdout, cmdArgs)
 		return false
 
 	case "clear":
@@ -69,18 +138,115 @@ func handleCommand(input string, session *chat.Session, logger zerolog.Logger, d
 		return false
 
 	case "debug":
-		*debugMode = !*debugMode
-		if *debugMode {
-			fmt.Println("✓ Debug mode enabled")
-		} else {
-			fmt.Println("✓ Debug mode disabled")
-		}
+		setDebugMode(os.Stdout, debugMode, cmdArgs)
+		return false
+
+	case "debug-request":
+		armRequestDump(os.Stdout, session, cmdArgs)
 		return false
 
 	case "permissions":
 		showPermissions(session)
 		return false
 
+	case "cd":
+		changeDirectory(session.ToolRegistry, cmdArgs)
+		return false
+
+	case "checkpoint":
+		if err := session.Checkpoint(cmdArgs); err != nil {
+			fmt.Printf("✗ %v (usage: /checkpoint <name>)\n", err)
+			return false
+		}
+		fmt.Printf("✓ Checkpoint %q saved\n", cmdArgs)
+		return false
+
+	case "restore":
+		if err := session.RestoreCheckpoint(cmdArgs); err != nil {
+			fmt.Printf("✗ %v (type /checkpoints to list them)\n", err)
+			return false
+		}
+		fmt.Printf("✓ Restored checkpoint %q\n", cmdArgs)
+		return false
+
+	case "checkpoints":
+		showCheckpoints(session)
+		return false
+
+	case "retry":
+		temperature, err := parseRetryTemperature(cmdArgs)
+		if err != nil {
+			fmt.Printf("✗ %v (usage: /retry [temperature])\n", err)
+			return false
+		}
+		retryLastResponse(session, temperature, logger, canceler)
+		return false
+
+	case "edit":
+		// The interactive loop loads the message into the prompt itself; this
+		// only runs where there is no line editor to hand it to.
+		fmt.Println("✗ /edit needs the interactive prompt")
+		return false
+
+	case "context":
+		showContextUsage(session)
+		return false
+
+	case "attach":
+		attachFile(session, cmdArgs)
+		return false
+
+	case "paste":
+		pasteClipboard(session, cmdArgs, logger, canceler)
+		return false
+
+	case "stream":
+		setStreaming(session, cmdArgs)
+		return false
+
+	case "tools":
+		setToolsEnabled(os.Stdout, session, cmdArgs)
+		return false
+
+	case "prefix":
+		setUserPrefix(os.Stdout, session, cmdArgs)
+		return false
+
+	case "snippet":
+		message, err := runSnippetCommand(session, cmdArgs)
+		if err != nil {
+			fmt.Printf("✗ %v (usage: /snippet [list|save <name> [text]|use <name>|delete <name>])\n", err)
+			return false
+		}
+		if message != "" {
+			handleConversation(message, session, logger, canceler)
+		}
+		return false
+
+	case "models":
+		listModels(os.Stdout, session, cmdArgs, canceler)
+		return false
+
+	case "model":
+		switchModel(os.Stdout, session, cmdArgs)
+		return false
+
+	case "pin":
+		pinTurn(os.Stdout, session, cmdArgs)
+		return false
+
+	case "unpin":
+		unpinTurn(os.Stdout, session, cmdArgs)
+		return false
+
+	case "import":
+		importConversation(os.Stdout, session, cmdArgs)
+		return false
+
+	case "cleanup":
+		cleanupTempFiles(os.Stdout, session.ToolRegistry)
+		return false
+
 	case "quit", "exit":
 		return true
 
@@ -90,20 +256,355 @@ func handleCommand(input string, session *chat.Session, logger zerolog.Logger, d
 	}
 }
 
-func showHelp() {
-	fmt.Println("\nAvailable Commands:")
-	seen := make(map[string]bool)
-	for _, cmd := range getAvailableCommands() {
-		if seen[cmd.Name] {
-			continue
+// importConversation loads an exported chat given as "<file> [format]".
+func importConversation(w io.Writer, session *chat.Session, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		fmt.Fprintln(w, "✗ usage: /import <file> [chatgpt|openai]")
+		return
+	}
+	format := chat.ImportFormatAuto
+	if len(fields) == 2 {
+		parsed, err := chat.ParseImportFormat(fields[1])
+		if err != nil {
+			fmt.Fprintf(w, "✗ %v\n", err)
+			return
 		}
-		seen[cmd.Name] = true
-		fmt.Printf("  /%-12s - %s\n", cmd.Name, cmd.Description)
+		format = parsed
 	}
-	fmt.Println("\nKeyboard Shortcuts:")
-	fmt.Println("  Ctrl+↑/↓     - Navigate command history")
-	fmt.Println("  Tab          - Auto-complete commands")
-	fmt.Println()
+	result, err := session.ImportConversation(fields[0], format)
+	if err != nil {
+		fmt.Fprintf(w, "✗ Import failed: %v\n", err)
+		return
+	}
+	from := fields[0]
+	if result.Title != "" {
+		from = fmt.Sprintf("%q from %s", result.Title, fields[0])
+	}
+	fmt.Fprintf(w, "✓ Imported %d messages of %s", result.Messages, from)
+	if result.Skipped > 0 {
+		fmt.Fprintf(w, " (skipped %d system, tool or non-text messages)", result.Skipped)
+	}
+	fmt.Fprintln(w)
+}
+
+// startEdit returns the last user message for /edit to load into the prompt.
+func startEdit(w io.Writer, session *chat.Session) (string, bool) {
+	text, ok := session.LastUserMessage()
+	if !ok {
+		fmt.Fprintf(w, "✗ %v\n", chat.ErrNothingToEdit)
+		return "", false
+	}
+	fmt.Fprintln(w, "Editing your last message; Enter resends it in place of the old turn, an empty line or Ctrl+C keeps it.")
+	return text, true
+}
+
+// parseRetryTemperature reads the optional /retry temperature override.
+func parseRetryTemperature(arg string) (*float32, error) {
+	if arg == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(arg, 32)
+	if err != nil || value < 0 || value > 2 {
+		return nil, fmt.Errorf("invalid temperature %q, expected a number between 0 and 2", arg)
+	}
+	temperature := float32(value)
+	return &temperature, nil
+}
+
+// attachFile queues a file for the next message, or lists the queued files.
+func attachFile(session *chat.Session, path string) {
+	if path == "" {
+		pending := session.PendingAttachments()
+		if len(pending) == 0 {
+			fmt.Println("No pending attachments (usage: /attach <path>)")
+			return
+		}
+		fmt.Println("Pending attachments:")
+		for _, attachment := range pending {
+			fmt.Printf("  %s (%d bytes)\n", attachment.Name, len(attachment.Content))
+		}
+		return
+	}
+
+	content, err := tools.ReadTextFile(path)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return
+	}
+	attachment, err := session.Attach(path, content)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return
+	}
+	if attachment.Truncated {
+		fmt.Printf("⚠ %s truncated to %d of %d bytes to fit the context window\n", path, len(attachment.Content), len(content))
+	}
+	fmt.Printf("✓ Attached %s, it will be sent with your next message (%d pending)\n", path, len(session.PendingAttachments()))
+}
+
+// listModels prints the provider's model IDs matching filter. Ctrl+C cancels
+// a slow request like it does a response.
+func listModels(w io.Writer, session *chat.Session, filter string, canceler *operationCanceler) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if canceler != nil {
+		canceler.Set(cancel)
+	}
+	defer func() {
+		cancel()
+		if canceler != nil {
+			canceler.Clear()
+		}
+	}()
+
+	models, err := session.ListModels(ctx, filter)
+	if errors.Is(err, chat.ErrModelsUnsupported) {
+		fmt.Fprintln(w, "⚠ This provider doesn't offer a model list, check its documentation for model names")
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(w, "✗ Failed to list models: %v\n", err)
+		return
+	}
+	if len(models) == 0 {
+		if filter != "" {
+			fmt.Fprintf(w, "No models matching %q\n", filter)
+		} else {
+			fmt.Fprintln(w, "The provider returned no models")
+		}
+		return
+	}
+	fmt.Fprintf(w, "\nModels (%d):\n", len(models))
+	for _, model := range models {
+		marker := " "
+		if model == session.Config.Model {
+			marker = "*"
+		}
+		fmt.Fprintf(w, " %s %s\n", marker, model)
+	}
+	fmt.Fprintln(w)
+}
+
+// pinTurn pins the numbered turn, or the latest one, or lists the pins.
+func pinTurn(w io.Writer, session *chat.Session, arg string) {
+	if strings.EqualFold(arg, "list") {
+		turns := session.PinnedTurns()
+		if len(turns) == 0 {
+			fmt.Fprintln(w, "No pinned turns (usage: /pin [turn])")
+			return
+		}
+		numbers := make([]string, len(turns))
+		for i, turn := range turns {
+			numbers[i] = strconv.Itoa(turn)
+		}
+		fmt.Fprintf(w, "Pinned turns: %s\n", strings.Join(numbers, ", "))
+		return
+	}
+	n, err := parseTurnNumber(arg)
+	if err != nil {
+		fmt.Fprintf(w, "✗ %v (usage: /pin [turn|list])\n", err)
+		return
+	}
+	turn, text, err := session.PinTurn(n)
+	if err != nil {
+		fmt.Fprintf(w, "✗ %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "✓ Pinned turn %d: %s\n", turn, truncateRunes(strings.Join(strings.Fields(text), " "), 60))
+}
+
+// unpinTurn removes the pin of the numbered turn, or every pin.
+func unpinTurn(w io.Writer, session *chat.Session, arg string) {
+	n, err := parseTurnNumber(arg)
+	if err != nil {
+		fmt.Fprintf(w, "✗ %v (usage: /unpin [turn])\n", err)
+		return
+	}
+	removed, err := session.UnpinTurn(n)
+	if err != nil {
+		fmt.Fprintf(w, "✗ %v\n", err)
+		return
+	}
+	if n == 0 {
+		fmt.Fprintf(w, "✓ Removed %d pins\n", removed)
+		return
+	}
+	fmt.Fprintf(w, "✓ Unpinned turn %d\n", n)
+}
+
+// parseTurnNumber reads the optional turn argument of /pin and /unpin; an
+// empty argument gives 0.
+func parseTurnNumber(arg string) (int, error) {
+	if arg == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid turn %q", arg)
+	}
+	return n, nil
+}
+
+// switchModel shows the active model, or switches to the named one.
+func switchModel(w io.Writer, session *chat.Session, name string) {
+	if name != "" {
+		if err := session.SetModel(name); err != nil {
+			fmt.Fprintf(w, "✗ %v\n", err)
+			return
+		}
+		fmt.Fprint(w, "✓ Switched to ")
+	} else {
+		fmt.Fprint(w, "Model in use: ")
+	}
+	if window := session.ContextWindow(); window > 0 {
+		fmt.Fprintf(w, "%s (context window %d tokens)\n", session.Config.Model, window)
+	} else {
+		fmt.Fprintf(w, "%s\n", session.Config.Model)
+	}
+}
+
+// contextWarnFraction is the context window share above which /context warns.
+const contextWarnFraction = 0.8
+
+func showContextUsage(session *chat.Session) {
+	usage := session.ContextUsage()
+	if usage.Window <= 0 {
+		fmt.Printf("Context: %d messages, ~%d tokens (set context_window to see the limit)\n", usage.Messages, usage.Tokens)
+		return
+	}
+	fraction := usage.Fraction()
+	fmt.Printf("Context: %d messages, ~%d of %d tokens (%.1f%%)\n", usage.Messages, usage.Tokens, usage.Window, fraction*100)
+	if fraction >= contextWarnFraction {
+		fmt.Printf("⚠ Context is %.0f%% full, consider /clear or starting over with a summary\n", fraction*100)
+	}
+}
+
+// pasteClipboard sends the clipboard contents as a message, after the optional
+// prompt. Pastes above paste_max_bytes are truncated with a warning.
+func pasteClipboard(session *chat.Session, prompt string, logger zerolog.Logger, canceler *operationCanceler) {
+	content, source, err := readClipboard()
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return
+	}
+	if strings.TrimSpace(content) == "" {
+		fmt.Printf("✗ Clipboard is empty (%s)\n", source)
+		return
+	}
+	content, truncated := truncatePaste(content, session.Config.PasteMaxBytes)
+	if truncated {
+		fmt.Printf("⚠ Paste truncated to %d bytes (paste_max_bytes)\n", len(content))
+	}
+	fmt.Printf("✓ Pasted %d bytes from %s\n", len(content), source)
+
+	message := content
+	if prompt != "" {
+		message = prompt + "\n\n" + content
+	}
+	handleConversation(message, session, logger, canceler)
+}
+
+// setStreaming switches response streaming for the session, or reports the
+// current mode when no argument is given.
+func setStreaming(session *chat.Session, arg string) {
+	var enabled bool
+	switch strings.ToLower(arg) {
+	case "":
+		if session.Config.StreamingEnabled() {
+			fmt.Println("Streaming is on")
+		} else {
+			fmt.Println("Streaming is off")
+		}
+		return
+	case "on":
+		enabled = true
+	case "off":
+		enabled = false
+	default:
+		fmt.Printf("✗ Invalid argument %q (usage: /stream on|off)\n", arg)
+		return
+	}
+	session.Config.Streaming = &enabled
+	if enabled {
+		fmt.Println("✓ Streaming enabled")
+	} else {
+		fmt.Println("✓ Streaming disabled, replies are shown when complete")
+	}
+}
+
+// setToolsEnabled handles /tools [on|off].
+func setToolsEnabled(w io.Writer, session *chat.Session, arg string) {
+	switch strings.ToLower(arg) {
+	case "":
+		if session.ToolsDisabled {
+			fmt.Fprintln(w, "Tools are off")
+		} else {
+			fmt.Fprintln(w, "Tools are on")
+		}
+	case "on":
+		session.ToolsDisabled = false
+		fmt.Fprintln(w, "✓ Tools enabled")
+	case "off":
+		session.ToolsDisabled = true
+		fmt.Fprintln(w, "✓ Tools disabled, requests are sent without tool definitions")
+	default:
+		fmt.Fprintf(w, "✗ Invalid argument %q (usage: /tools on|off)\n", arg)
+	}
+}
+
+// setUserPrefix turns sending user_message_prefix on or off, or shows it.
+func setUserPrefix(w io.Writer, session *chat.Session, arg string) {
+	prefix := session.Config.UserMessagePrefix
+	if prefix == "" {
+		fmt.Fprintln(w, "No user_message_prefix is configured")
+		return
+	}
+	switch strings.ToLower(arg) {
+	case "":
+		state := "on"
+		if session.NoUserPrefix {
+			state = "off"
+		}
+		fmt.Fprintf(w, "User message prefix is %s: %q\n", state, prefix)
+	case "on":
+		session.NoUserPrefix = false
+		fmt.Fprintln(w, "✓ User message prefix enabled")
+	case "off":
+		session.NoUserPrefix = true
+		fmt.Fprintln(w, "✓ User message prefix disabled, messages are sent as typed")
+	default:
+		fmt.Fprintf(w, "✗ Invalid argument %q (usage: /prefix on|off)\n", arg)
+	}
+}
+
+// showHelp lists all commands alphabetically, or the details of one command.
+func showHelp(w io.Writer, topic string) {
+	if topic != "" {
+		cmd, ok := findCommand(topic)
+		if !ok {
+			fmt.Fprintf(w, "✗ Unknown command: %s (type /help for available commands)\n", topic)
+			return
+		}
+		fmt.Fprintf(w, "\nUsage: %s\n", cmd.usageLine())
+		fmt.Fprintf(w, "  %s\n", cmd.Description)
+		if cmd.Details != "" {
+			fmt.Fprintf(w, "  %s\n", cmd.Details)
+		}
+		fmt.Fprintln(w)
+		return
+	}
+
+	commands := getAvailableCommands()
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	fmt.Fprintln(w, "\nAvailable Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-22s - %s\n", cmd.usageLine(), cmd.Description)
+	}
+	fmt.Fprintln(w, "\nType /help <command> for details.")
+	fmt.Fprintln(w, "\nKeyboard Shortcuts:")
+	fmt.Fprintln(w, "  Ctrl+↑/↓     - Navigate command history")
+	fmt.Fprintln(w, "  Tab          - Auto-complete commands")
+	fmt.Fprintln(w)
 }
 
 func showHistory(session *chat.Session) {
@@ -129,6 +630,73 @@ func showHistory(session *chat.Session) {
 	fmt.Println()
 }
 
+func changeDirectory(registry *tools.Registry, path string) {
+	dir, err := registry.ChangeWorkingDirectory(path)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return
+	}
+	fmt.Printf("✓ Working directory: %s\n", dir)
+}
+
+// cleanupTempFiles empties the .tmp directory used by mktemp.
+func cleanupTempFiles(w io.Writer, registry *tools.Registry) {
+	removed, err := registry.CleanTempDir()
+	if err != nil {
+		fmt.Fprintf(w, "✗ %v\n", err)
+		return
+	}
+	fmt.Fprintf(w, "✓ Removed %d entries from .tmp\n", removed)
+}
+
+// requestDumpFile writes a dumped request to a file, replacing its contents.
+type requestDumpFile string
+
+func (f requestDumpFile) Write(p []byte) (int, error) {
+	if err := os.WriteFile(string(f), p, 0o600); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// armRequestDump makes the session dump its next request to w, or to the
+// file named by target.
+func armRequestDump(w io.Writer, session *chat.Session, target string) {
+	switch target {
+	case "":
+		session.DumpNextRequest(w)
+		fmt.Fprintln(w, "✓ The next request will be printed before it is sent")
+	case "off":
+		if !session.RequestDumpPending() {
+			fmt.Fprintln(w, "No request dump pending")
+			return
+		}
+		session.DumpNextRequest(nil)
+		fmt.Fprintln(w, "✓ Request dump cancelled")
+	default:
+		session.DumpNextRequest(requestDumpFile(target))
+		fmt.Fprintf(w, "✓ The next request will be written to %s\n", target)
+	}
+}
+
+func showCheckpoints(session *chat.Session) {
+	checkpoints := session.Checkpoints()
+	if len(checkpoints) == 0 {
+		fmt.Println("No checkpoints (create one with /checkpoint <name>)")
+		return
+	}
+
+	fmt.Println("\nCheckpoints:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "Name\tMessages\tCreated")
+	fmt.Fprintln(w, "────\t────────\t───────")
+	for _, cp := range checkpoints {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", cp.Name, cp.Messages, cp.Created.Format("15:04:05"))
+	}
+	w.Flush()
+	fmt.Println()
+}
+
 func showPermissions(session *chat.Session) {
 	fmt.Println("\nTool Permissions:")
 
@@ -17,11 +17,19 @@
 package main
 
 import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rs/zerolog"
+	"github.com/sashabaranov/go-openai"
 	"promptline/internal/chat"
 	"promptline/internal/config"
+	"promptline/internal/tools"
 )
 
 func TestGetAvailableCommands(t *testing.T) {
@@ -58,7 +66,7 @@ func TestHandleCommandHelp(t *testing.T) {
 	debugMode := false
 
 	// Help command should not quit
-	shouldQuit := handleCommand("/help", session, logger, &debugMode)
+	shouldQuit := handleCommand("/help", session, logger, &debugMode, nil)
 
 	if shouldQuit {
 		t.Error("Help command should not trigger quit")
@@ -77,7 +85,7 @@ func TestHandleCommandClear(t *testing.T) {
 	logger := zerolog.Nop()
 	debugMode := false
 
-	shouldQuit := handleCommand("/clear", session, logger, &debugMode)
+	shouldQuit := handleCommand("/clear", session, logger, &debugMode, nil)
 
 	if shouldQuit {
 		t.Error("Clear command should not trigger quit")
@@ -101,14 +109,14 @@ func TestHandleCommandQuit(t *testing.T) {
 	debugMode := false
 
 	// Quit command should return true
-	shouldQuit := handleCommand("/quit", session, logger, &debugMode)
+	shouldQuit := handleCommand("/quit", session, logger, &debugMode, nil)
 
 	if !shouldQuit {
 		t.Error("Quit command should trigger quit")
 	}
 
 	// Exit should also trigger quit
-	shouldQuit = handleCommand("/exit", session, logger, &debugMode)
+	shouldQuit = handleCommand("/exit", session, logger, &debugMode, nil)
 
 	if !shouldQuit {
 		t.Error("Exit command should trigger quit")
@@ -126,7 +134,7 @@ func TestHandleCommandDebug(t *testing.T) {
 	debugMode := false
 
 	// Toggle debug on
-	shouldQuit := handleCommand("/debug", session, logger, &debugMode)
+	shouldQuit := handleCommand("/debug", session, logger, &debugMode, nil)
 
 	if shouldQuit {
 		t.Error("Debug command should not trigger quit")
@@ -137,13 +145,55 @@ func TestHandleCommandDebug(t *testing.T) {
 	}
 
 	// Toggle debug off
-	handleCommand("/debug", session, logger, &debugMode)
+	handleCommand("/debug", session, logger, &debugMode, nil)
 
 	if debugMode {
 		t.Error("Debug mode should be disabled")
 	}
 }
 
+func TestDebugModeShowsLogLines(t *testing.T) {
+	previous := zerolog.GlobalLevel()
+	t.Cleanup(func() {
+		zerolog.SetGlobalLevel(previous)
+		debugOutput.show(nil)
+	})
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	logger := zerolog.New(debugOutput)
+	debugMode := false
+
+	var out bytes.Buffer
+	logger.Debug().Msg("before toggle")
+	setDebugMode(&out, &debugMode, "on")
+	if !debugMode || zerolog.GlobalLevel() != zerolog.DebugLevel {
+		t.Fatalf("expected /debug on to enable debug logging, got mode %v level %v", debugMode, zerolog.GlobalLevel())
+	}
+	logger.Debug().Str("tool_name", "ls").Msg("Tool permission evaluated")
+	if !strings.Contains(out.String(), "Tool permission evaluated") || !strings.Contains(out.String(), "ls") {
+		t.Fatalf("expected the debug line in the chat, got %q", out.String())
+	}
+	if strings.Contains(out.String(), "before toggle") {
+		t.Fatalf("expected lines logged before /debug on to be dropped, got %q", out.String())
+	}
+
+	out.Reset()
+	setDebugMode(&out, &debugMode, "off")
+	logger.Debug().Msg("after toggle")
+	logger.Info().Msg("info after toggle")
+	if debugMode || zerolog.GlobalLevel() != zerolog.InfoLevel {
+		t.Fatalf("expected /debug off to restore the info level, got mode %v level %v", debugMode, zerolog.GlobalLevel())
+	}
+	if strings.Contains(out.String(), "after toggle") {
+		t.Fatalf("expected no log lines once debug mode is off, got %q", out.String())
+	}
+
+	out.Reset()
+	setDebugMode(&out, &debugMode, "maybe")
+	if debugMode || !strings.Contains(out.String(), "usage: /debug [on|off]") {
+		t.Fatalf("expected an unknown argument to be rejected, got %q", out.String())
+	}
+}
+
 func TestHandleCommandUnknown(t *testing.T) {
 	cfg := &config.Config{
 		APIKey: "test-key",
@@ -155,7 +205,7 @@ func TestHandleCommandUnknown(t *testing.T) {
 	debugMode := false
 
 	// Unknown command should not quit
-	shouldQuit := handleCommand("/nonexistent", session, logger, &debugMode)
+	shouldQuit := handleCommand("/nonexistent", session, logger, &debugMode, nil)
 
 	if shouldQuit {
 		t.Error("Unknown command should not trigger quit")
@@ -173,7 +223,7 @@ func TestHandleCommandCaseInsensitive(t *testing.T) {
 	debugMode := false
 
 	// Should handle uppercase
-	shouldQuit := handleCommand("/QUIT", session, logger, &debugMode)
+	shouldQuit := handleCommand("/QUIT", session, logger, &debugMode, nil)
 
 	if !shouldQuit {
 		t.Error("QUIT (uppercase) should trigger quit")
@@ -249,8 +299,32 @@ func TestCommandStructure(t *testing.T) {
 }
 
 func TestShowHelp(t *testing.T) {
-	// Should not panic
-	showHelp()
+	var out bytes.Buffer
+	showHelp(&out, "")
+	text := out.String()
+	for _, cmd := range getAvailableCommands() {
+		if !strings.Contains(text, cmd.usageLine()) {
+			t.Errorf("expected %s in help output", cmd.usageLine())
+		}
+	}
+	if strings.Index(text, "/checkpoint <name>") > strings.Index(text, "/retry [temperature]") {
+		t.Error("expected commands sorted alphabetically")
+	}
+}
+
+func TestShowHelpForCommand(t *testing.T) {
+	var out bytes.Buffer
+	showHelp(&out, "/retry")
+	text := out.String()
+	if !strings.Contains(text, "Usage: /retry [temperature]") || !strings.Contains(text, "applies to this request only") {
+		t.Errorf("expected retry usage and details, got %q", text)
+	}
+
+	out.Reset()
+	showHelp(&out, "nope")
+	if !strings.Contains(out.String(), "Unknown command: nope") {
+		t.Errorf("expected unknown command message, got %q", out.String())
+	}
 }
 
 func TestHandleCommandTrimming(t *testing.T) {
@@ -264,7 +338,7 @@ func TestHandleCommandTrimming(t *testing.T) {
 	debugMode := false
 
 	// Should handle commands with whitespace - note the slash should not have spaces before it
-	shouldQuit := handleCommand("/quit  ", session, logger, &debugMode)
+	shouldQuit := handleCommand("/quit  ", session, logger, &debugMode, nil)
 
 	if !shouldQuit {
 		t.Error("Quit with trailing whitespace should trigger quit")
@@ -283,7 +357,7 @@ func TestHandleHistoryCommand(t *testing.T) {
 	logger := zerolog.Nop()
 	debugMode := false
 
-	shouldQuit := handleCommand("/history", session, logger, &debugMode)
+	shouldQuit := handleCommand("/history", session, logger, &debugMode, nil)
 
 	if shouldQuit {
 		t.Error("History command should not trigger quit")
@@ -300,9 +374,430 @@ func TestHandlePermissionsCommand(t *testing.T) {
 	logger := zerolog.Nop()
 	debugMode := false
 
-	shouldQuit := handleCommand("/permissions", session, logger, &debugMode)
+	shouldQuit := handleCommand("/permissions", session, logger, &debugMode, nil)
 
 	if shouldQuit {
 		t.Error("Permissions command should not trigger quit")
 	}
 }
+
+func TestHandleCdCommand(t *testing.T) {
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "gpt-4o-mini",
+	}
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "Sub"), 0o755); err != nil {
+		t.Fatalf("failed to create subdir: %v", err)
+	}
+	previousRoot, err := tools.WorkRoot()
+	if err != nil {
+		t.Fatalf("failed to read work root: %v", err)
+	}
+	t.Chdir(root)
+	if err := tools.ConfigureWorkRoot(""); err != nil {
+		t.Fatalf("failed to configure work root: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = tools.ConfigureWorkRoot(previousRoot)
+	})
+
+	session := chat.NewSession(cfg)
+	logger := zerolog.Nop()
+	debugMode := false
+
+	if handleCommand("/cd Sub", session, logger, &debugMode, nil) {
+		t.Error("cd command should not trigger quit")
+	}
+	wd, err := session.ToolRegistry.WorkingDirectory()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	if filepath.Base(wd) != "Sub" {
+		t.Errorf("expected case-preserving cd into Sub, got %q", wd)
+	}
+	if cwd, _ := os.Getwd(); filepath.Base(cwd) == "Sub" {
+		t.Errorf("expected /cd to leave the process working directory alone, got %q", cwd)
+	}
+}
+
+func TestHandleCheckpointCommands(t *testing.T) {
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "gpt-4o-mini",
+	}
+	session := chat.NewSession(cfg)
+	logger := zerolog.Nop()
+	debugMode := false
+
+	session.AddMessage("user", "first")
+	if handleCommand("/checkpoint First-Try", session, logger, &debugMode, nil) {
+		t.Fatal("checkpoint command should not trigger quit")
+	}
+	session.AddMessage("user", "second")
+	handleCommand("/checkpoints", session, logger, &debugMode, nil)
+	handleCommand("/restore First-Try", session, logger, &debugMode, nil)
+
+	history := session.GetHistory()
+	if len(history) != 1 || history[0].Content != "first" {
+		t.Fatalf("expected restore to roll back to the checkpoint, got %+v", history)
+	}
+	handleCommand("/restore missing", session, logger, &debugMode, nil)
+	if len(session.GetHistory()) != 1 {
+		t.Error("restoring an unknown checkpoint should leave history untouched")
+	}
+}
+
+func TestHandleRetryCommand(t *testing.T) {
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "gpt-4o-mini",
+	}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModeUpper))
+	logger := zerolog.Nop()
+	debugMode := false
+
+	session.AddMessage("user", "hello")
+	session.AddAssistantMessage("a poor answer", nil)
+
+	handleCommand("/retry hot", session, logger, &debugMode, nil)
+	if history := session.GetHistory(); history[len(history)-1].Content != "a poor answer" {
+		t.Fatalf("invalid temperature should leave history untouched, got %+v", history)
+	}
+
+	if handleCommand("/retry 0.5", session, logger, &debugMode, nil) {
+		t.Fatal("retry command should not trigger quit")
+	}
+	history := session.GetHistory()
+	if len(history) != 2 || history[1].Content != "HELLO" {
+		t.Fatalf("expected regenerated answer, got %+v", history)
+	}
+}
+
+func TestHandleStreamCommand(t *testing.T) {
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "gpt-4o-mini",
+	}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModeUpper))
+	logger := zerolog.Nop()
+	debugMode := false
+
+	if !cfg.StreamingEnabled() {
+		t.Fatal("streaming should be on by default")
+	}
+	handleCommand("/stream maybe", session, logger, &debugMode, nil)
+	if !cfg.StreamingEnabled() {
+		t.Fatal("invalid argument should not change the streaming mode")
+	}
+
+	if handleCommand("/stream off", session, logger, &debugMode, nil) {
+		t.Fatal("stream command should not trigger quit")
+	}
+	if cfg.StreamingEnabled() {
+		t.Fatal("expected streaming to be disabled")
+	}
+
+	handleConversation("hello", session, logger, nil)
+	history := session.GetHistory()
+	if len(history) != 2 || history[1].Content != "HELLO" {
+		t.Fatalf("expected complete answer without streaming, got %+v", history)
+	}
+
+	handleCommand("/stream on", session, logger, &debugMode, nil)
+	if !cfg.StreamingEnabled() {
+		t.Fatal("expected streaming to be enabled")
+	}
+}
+
+func TestHandlePasteCommand(t *testing.T) {
+	useClipboardFallback(t, "line one\nline two\n")
+	cfg := &config.Config{
+		APIKey:        "test-key",
+		Model:         "gpt-4o-mini",
+		PasteMaxBytes: 8,
+	}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	logger := zerolog.Nop()
+	debugMode := false
+
+	if handleCommand("/paste explain", session, logger, &debugMode, nil) {
+		t.Fatal("paste command should not trigger quit")
+	}
+	history := session.GetHistory()
+	if len(history) != 2 || history[0].Content != "explain\n\nline one" {
+		t.Fatalf("expected truncated paste after the prompt, got %+v", history)
+	}
+}
+
+func TestHandleContextCommand(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:        "test-key",
+		Model:         "gpt-4o-mini",
+		ContextWindow: 100,
+	}
+	session := chat.NewSession(cfg)
+	logger := zerolog.Nop()
+	debugMode := false
+
+	session.AddMessage("user", strings.Repeat("x", 400))
+	if handleCommand("/context", session, logger, &debugMode, nil) {
+		t.Error("context command should not trigger quit")
+	}
+	cfg.ContextWindow = 0
+	handleCommand("/context", session, logger, &debugMode, nil)
+}
+
+func TestHandleAttachCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("notes.txt", []byte("remember the milk\n"), 0o644); err != nil {
+		t.Fatalf("write notes: %v", err)
+	}
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "gpt-4o-mini",
+	}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	logger := zerolog.Nop()
+	debugMode := false
+
+	handleCommand("/attach missing.txt", session, logger, &debugMode, nil)
+	if len(session.PendingAttachments()) != 0 {
+		t.Fatal("missing file should not be attached")
+	}
+	if handleCommand("/attach notes.txt", session, logger, &debugMode, nil) {
+		t.Fatal("attach command should not trigger quit")
+	}
+	handleCommand("/attach", session, logger, &debugMode, nil)
+
+	handleConversation("summarize", session, logger, nil)
+	history := session.GetHistory()
+	if !strings.HasPrefix(history[0].Content, "```file:notes.txt\nremember the milk\n```") {
+		t.Fatalf("expected attachment in the user message, got %q", history[0].Content)
+	}
+}
+
+func TestListModelsCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o"},{"id":"gpt-4o-mini"},{"id":"whisper-1"}]}`))
+	}))
+	defer server.Close()
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}
+	session := chat.NewSessionWithClient(cfg, openai.NewClientWithConfig(clientConfig))
+
+	var out bytes.Buffer
+	listModels(&out, session, "GPT", nil)
+	text := out.String()
+	if !strings.Contains(text, "Models (2)") || !strings.Contains(text, "* gpt-4o-mini") || strings.Contains(text, "whisper") {
+		t.Fatalf("unexpected model list: %q", text)
+	}
+
+	out.Reset()
+	listModels(&out, session, "claude", nil)
+	if !strings.Contains(out.String(), `No models matching "claude"`) {
+		t.Fatalf("expected no-match message, got %q", out.String())
+	}
+
+	out.Reset()
+	echo := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	listModels(&out, echo, "", nil)
+	if !strings.Contains(out.String(), "doesn't offer a model list") {
+		t.Fatalf("expected unsupported message, got %q", out.String())
+	}
+}
+
+func TestSetToolsEnabled(t *testing.T) {
+	session := chat.NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, chat.NewEchoClient(chat.EchoModePlain))
+
+	var out bytes.Buffer
+	setToolsEnabled(&out, session, "off")
+	if !session.ToolsDisabled || !strings.Contains(out.String(), "Tools disabled") {
+		t.Fatalf("expected tools to be disabled, got %q", out.String())
+	}
+	out.Reset()
+	setToolsEnabled(&out, session, "")
+	if !strings.Contains(out.String(), "Tools are off") {
+		t.Fatalf("expected current mode, got %q", out.String())
+	}
+	out.Reset()
+	setToolsEnabled(&out, session, "maybe")
+	if !session.ToolsDisabled || !strings.Contains(out.String(), "usage: /tools on|off") {
+		t.Fatalf("expected invalid argument to leave tools off, got %q", out.String())
+	}
+	setToolsEnabled(&out, session, "ON")
+	if session.ToolsDisabled {
+		t.Fatal("expected tools to be enabled again")
+	}
+}
+
+func TestSetUserPrefix(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", UserMessagePrefix: "Team rules"}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+
+	var out bytes.Buffer
+	setUserPrefix(&out, session, "off")
+	if !session.NoUserPrefix {
+		t.Fatalf("expected the prefix to be disabled, got %q", out.String())
+	}
+	out.Reset()
+	setUserPrefix(&out, session, "")
+	if !strings.Contains(out.String(), `is off: "Team rules"`) {
+		t.Fatalf("expected the current state, got %q", out.String())
+	}
+	setUserPrefix(&out, session, "on")
+	if session.NoUserPrefix {
+		t.Fatal("expected the prefix to be enabled again")
+	}
+
+	cfg.UserMessagePrefix = ""
+	out.Reset()
+	setUserPrefix(&out, session, "off")
+	if !strings.Contains(out.String(), "No user_message_prefix") {
+		t.Fatalf("expected a note that no prefix is configured, got %q", out.String())
+	}
+}
+
+func TestArmRequestDump(t *testing.T) {
+	session := chat.NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, chat.NewEchoClient(chat.EchoModePlain))
+	path := filepath.Join(t.TempDir(), "request.json")
+
+	var out bytes.Buffer
+	armRequestDump(&out, session, path)
+	if !session.RequestDumpPending() || !strings.Contains(out.String(), path) {
+		t.Fatalf("expected a pending dump to %s, got %q", path, out.String())
+	}
+	if _, err := session.GetResponse("hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), `"content": "hello"`) {
+		t.Fatalf("expected the request in %s, got %q (%v)", path, data, err)
+	}
+
+	out.Reset()
+	armRequestDump(&out, session, "")
+	armRequestDump(&out, session, "off")
+	if session.RequestDumpPending() || !strings.Contains(out.String(), "Request dump cancelled") {
+		t.Fatalf("expected the dump to be cancelled, got %q", out.String())
+	}
+}
+
+func TestStartEdit(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+
+	var out bytes.Buffer
+	if _, ok := startEdit(&out, session); ok || !strings.Contains(out.String(), "no message to edit") {
+		t.Fatalf("expected nothing to edit, got %q", out.String())
+	}
+
+	session.AddMessage(openai.ChatMessageRoleUser, "wrong question")
+	session.AddAssistantMessage("wrong question", nil)
+	draft, ok := startEdit(&out, session)
+	if !ok || draft != "wrong question" {
+		t.Fatalf("expected the last message as draft, got %q (%v)", draft, ok)
+	}
+
+	resendEditedMessage(session, "right question", zerolog.Nop(), nil)
+	history := session.GetHistory()
+	if len(history) != 2 || history[0].Content != "right question" || history[1].Content != "right question" {
+		t.Fatalf("expected the turn to be replaced, got %+v", history)
+	}
+}
+
+func TestSwitchModel(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:              "test-key",
+		Model:               "gpt-4o-mini",
+		ContextWindow:       4096,
+		ModelContextWindows: map[string]int{"gpt-4o": 128000},
+	}
+	session := chat.NewSession(cfg)
+
+	var out bytes.Buffer
+	switchModel(&out, session, "")
+	if got := out.String(); got != "Model in use: gpt-4o-mini (context window 128000 tokens)\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+
+	out.Reset()
+	switchModel(&out, session, "local-model")
+	if got := out.String(); got != "✓ Switched to local-model (context window 4096 tokens)\n" {
+		t.Fatalf("unexpected output: %q", got)
+	}
+	if cfg.Model != "local-model" {
+		t.Fatalf("expected the model to change, got %q", cfg.Model)
+	}
+}
+
+func TestPinCommands(t *testing.T) {
+	session := chat.NewSession(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"})
+	session.AddMessage("user", "keep   this\nrule")
+	session.AddMessage("assistant", "ok")
+	session.AddMessage("user", "later")
+
+	var out bytes.Buffer
+	pinTurn(&out, session, "1")
+	pinTurn(&out, session, "")
+	pinTurn(&out, session, "list")
+	pinTurn(&out, session, "x")
+	want := "✓ Pinned turn 1: keep this rule\n" +
+		"✓ Pinned turn 2: later\n" +
+		"Pinned turns: 1, 2\n" +
+		"✗ invalid turn \"x\" (usage: /pin [turn|list])\n"
+	if got := out.String(); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	out.Reset()
+	unpinTurn(&out, session, "2")
+	unpinTurn(&out, session, "2")
+	unpinTurn(&out, session, "")
+	want = "✓ Unpinned turn 2\n✗ turn 2 is not pinned\n✓ Removed 1 pins\n"
+	if got := out.String(); got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+}
+
+func TestRunPreflight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"invalid key"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o-mini"}]}`))
+	}))
+	defer server.Close()
+
+	newSession := func(key string, preflight *bool) *chat.Session {
+		cfg := &config.Config{APIKey: key, APIURL: server.URL, Model: "gpt-4o-mini", PreflightCheck: preflight}
+		return chat.NewSession(cfg)
+	}
+
+	var out bytes.Buffer
+	if err := runPreflight(&out, newSession("good-key", nil)); err != nil {
+		t.Fatalf("expected preflight success, got %v", err)
+	}
+	if want := "✓ Connected to gpt-4o-mini at " + server.URL; !strings.Contains(out.String(), want) {
+		t.Fatalf("expected %q, got %q", want, out.String())
+	}
+
+	out.Reset()
+	if err := runPreflight(&out, newSession("bad-key", nil)); err == nil {
+		t.Fatal("expected preflight failure for a bad key")
+	}
+	if !strings.Contains(out.String(), "✗ Preflight check failed: authentication failed") {
+		t.Fatalf("unexpected failure output: %q", out.String())
+	}
+
+	out.Reset()
+	disabled := false
+	if err := runPreflight(&out, newSession("bad-key", &disabled)); err != nil || out.Len() != 0 {
+		t.Fatalf("expected disabled preflight to do nothing, got %v / %q", err, out.String())
+	}
+}
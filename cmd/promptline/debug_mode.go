@@ -0,0 +1,105 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// debugOutput is where the logger writes. /debug switches debug mode while
+// promptline runs, so whether a line is kept, and where it goes, is decided
+// on every write rather than once at startup.
+var debugOutput = &debugWriter{}
+
+// debugWriter drops log lines while debug mode is off. While it is on, lines
+// go to the debug log file opened by -d and, after /debug on, to the chat.
+type debugWriter struct {
+	mu      sync.Mutex
+	enabled bool
+	file    io.Writer
+	chat    io.Writer
+}
+
+func (d *debugWriter) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.enabled {
+		return len(p), nil
+	}
+	if d.file != nil {
+		if _, err := d.file.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	if d.chat != nil {
+		// A broken terminal must not fail the log file write.
+		_, _ = d.chat.Write(p)
+	}
+	return len(p), nil
+}
+
+// setFile sends log lines to the debug log file and enables logging.
+func (d *debugWriter) setFile(file io.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.file = file
+	d.enabled = true
+}
+
+// show turns logging on and prints the lines to w in readable form, or turns
+// it off when w is nil.
+func (d *debugWriter) show(w io.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.enabled = w != nil
+	d.chat = nil
+	if w != nil {
+		d.chat = zerolog.ConsoleWriter{Out: w, TimeFormat: "15:04:05"}
+	}
+}
+
+// setDebugMode handles /debug [on|off]: without an argument it toggles. The
+// global log level follows the mode, and while it is on the log lines are
+// shown in the chat as well as written to the debug log file.
+func setDebugMode(w io.Writer, debugMode *bool, arg string) {
+	on := !*debugMode
+	switch strings.ToLower(arg) {
+	case "":
+	case "on":
+		on = true
+	case "off":
+		on = false
+	default:
+		fmt.Fprintf(w, "✗ Unknown argument %q (usage: /debug [on|off])\n", arg)
+		return
+	}
+	*debugMode = on
+	if on {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+		debugOutput.show(w)
+		fmt.Fprintln(w, "✓ Debug mode enabled; log lines are shown here")
+		return
+	}
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	debugOutput.show(nil)
+	fmt.Fprintln(w, "✓ Debug mode disabled")
+}
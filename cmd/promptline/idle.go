@@ -0,0 +1,101 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"promptline/internal/chat"
+)
+
+// clearScreen clears the terminal and its scrollback.
+const clearScreen = "\033[H\033[2J\033[3J"
+
+// idleWatch runs onIdle once the prompt has waited timeout without a key
+// press. It only counts while armed, so time spent streaming a reply or
+// running tools is never idle. A zero timeout disables it.
+type idleWatch struct {
+	timeout time.Duration
+	onIdle  func()
+
+	mu    sync.Mutex
+	timer *time.Timer
+	armed bool
+}
+
+func newIdleWatch(timeout time.Duration, onIdle func()) *idleWatch {
+	return &idleWatch{timeout: timeout, onIdle: onIdle}
+}
+
+// arm starts counting; call it before waiting for input.
+func (w *idleWatch) arm() {
+	if w.timeout <= 0 {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.armed = true
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.timeout, w.fire)
+		return
+	}
+	w.timer.Reset(w.timeout)
+}
+
+// disarm stops counting; call it once input arrived and on shutdown.
+func (w *idleWatch) disarm() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.armed = false
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// touch restarts the count after a key press.
+func (w *idleWatch) touch() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.armed && w.timer != nil {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+func (w *idleWatch) fire() {
+	w.mu.Lock()
+	armed := w.armed
+	w.armed = false
+	w.mu.Unlock()
+	// A disarm that raced with the timer wins.
+	if armed {
+		w.onIdle()
+	}
+}
+
+// saveIdleHistory writes the conversation to history_file before an idle quit.
+func saveIdleHistory(session *chat.Session, logger zerolog.Logger) {
+	if session.Config.HistoryFile == "" {
+		return
+	}
+	if err := session.SaveConversationHistory(session.Config.HistoryFile); err != nil {
+		fmt.Printf("✗ Failed to save history: %v\n", err)
+		logger.Error().Err(err).Msg("Failed to save history on idle quit")
+	}
+}
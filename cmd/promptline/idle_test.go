@@ -0,0 +1,65 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdleWatch(t *testing.T) {
+	var fired atomic.Int32
+	w := newIdleWatch(50*time.Millisecond, func() { fired.Add(1) })
+	defer w.disarm()
+
+	// Not armed: nothing happens, as while a reply is streaming.
+	time.Sleep(80 * time.Millisecond)
+	if fired.Load() != 0 {
+		t.Fatal("expected no idle action while disarmed")
+	}
+
+	// Key presses keep postponing it.
+	w.arm()
+	for i := 0; i < 4; i++ {
+		time.Sleep(25 * time.Millisecond)
+		w.touch()
+	}
+	if fired.Load() != 0 {
+		t.Fatal("expected key presses to reset the idle timer")
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if fired.Load() != 1 {
+		t.Fatalf("expected the idle action once, got %d", fired.Load())
+	}
+
+	w.arm()
+	w.disarm()
+	time.Sleep(80 * time.Millisecond)
+	if fired.Load() != 1 {
+		t.Fatal("expected disarm to cancel a pending idle action")
+	}
+}
+
+func TestIdleWatchDisabled(t *testing.T) {
+	w := newIdleWatch(0, func() { t.Error("idle action must not run without a timeout") })
+	w.arm()
+	w.touch()
+	time.Sleep(20 * time.Millisecond)
+	w.disarm()
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// inputHistoryLimit matches the number of entries readline keeps by default.
+const inputHistoryLimit = 500
+
+// maxHistoryMatches caps the entries /history <text> offers to pick from.
+const maxHistoryMatches = 10
+
+// inputHistory is a copy of the prompt history for /history searches;
+// readline keeps its own but does not expose it.
+type inputHistory struct {
+	entries []string
+}
+
+// loadInputHistory reads the entries readline saved to path, one per line.
+// A missing or unreadable file gives an empty history.
+func loadInputHistory(path string) *inputHistory {
+	h := &inputHistory{}
+	if path == "" {
+		return h
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		h.add(scanner.Text())
+	}
+	return h
+}
+
+// add records a submitted line, skipping blank lines and repeats of the
+// previous entry, and drops the oldest entries beyond inputHistoryLimit.
+func (h *inputHistory) add(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == line) {
+		return
+	}
+	h.entries = append(h.entries, line)
+	if over := len(h.entries) - inputHistoryLimit; over > 0 {
+		h.entries = append([]string(nil), h.entries[over:]...)
+	}
+}
+
+// search returns up to limit distinct entries containing query, ignoring
+// case, newest first. Earlier /history searches are not offered, as they
+// would always match their own text.
+func (h *inputHistory) search(query string, limit int) []string {
+	query = strings.ToLower(strings.TrimSpace(query))
+	seen := make(map[string]bool)
+	var matches []string
+	for i := len(h.entries) - 1; i >= 0 && len(matches) < limit; i-- {
+		entry := h.entries[i]
+		if seen[entry] || isHistorySearch(entry) || !strings.Contains(strings.ToLower(entry), query) {
+			continue
+		}
+		seen[entry] = true
+		matches = append(matches, entry)
+	}
+	return matches
+}
+
+// isHistorySearch reports whether line is a /history <text> command.
+func isHistorySearch(line string) bool {
+	name, query, _ := strings.Cut(strings.TrimPrefix(line, "/"), " ")
+	return strings.HasPrefix(line, "/") && strings.EqualFold(name, "history") && strings.TrimSpace(query) != ""
+}
+
+// recallInput searches the input history for query and returns the entry to
+// load into the prompt. With several matches it lists them and asks, through
+// ask, which one to use; an empty or interrupted answer cancels.
+func recallInput(w io.Writer, history *inputHistory, query string, ask func(prompt string) (string, bool)) (string, bool) {
+	matches := history.search(query, maxHistoryMatches)
+	switch len(matches) {
+	case 0:
+		fmt.Fprintf(w, "No earlier input matches %q\n", query)
+		return "", false
+	case 1:
+		fmt.Fprintln(w, "Loaded into the prompt; Enter sends it, an empty line or Ctrl+C drops it.")
+		return matches[0], true
+	}
+
+	for i, entry := range matches {
+		fmt.Fprintf(w, "  %2d. %s\n", i+1, entry)
+	}
+	prompt := fmt.Sprintf("Pick 1-%d (Enter cancels): ", len(matches))
+	for {
+		answer, ok := ask(prompt)
+		answer = strings.TrimSpace(answer)
+		if !ok || answer == "" {
+			fmt.Fprintln(w, "Nothing recalled")
+			return "", false
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(matches) {
+			fmt.Fprintln(w, "Loaded into the prompt; Enter sends it, an empty line or Ctrl+C drops it.")
+			return matches[n-1], true
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInputHistorySearch(t *testing.T) {
+	h := &inputHistory{}
+	for _, line := range []string{"explain main.go", "  ", "/model gpt-4o", "Explain the tests", "/history explain", "list files", "explain main.go", "explain main.go"} {
+		h.add(line)
+	}
+	if len(h.entries) != 6 {
+		t.Fatalf("expected blank lines and repeats to be skipped, got %q", h.entries)
+	}
+
+	got := h.search("EXPLAIN", maxHistoryMatches)
+	if want := []string{"explain main.go", "Explain the tests"}; !slices.Equal(got, want) {
+		t.Fatalf("expected newest-first unique matches without searches, got %q", got)
+	}
+	if got := h.search("explain", 1); len(got) != 1 || got[0] != "explain main.go" {
+		t.Fatalf("expected the limit to keep the newest match, got %q", got)
+	}
+	if got := h.search("nothing like this", maxHistoryMatches); len(got) != 0 {
+		t.Fatalf("expected no matches, got %q", got)
+	}
+}
+
+func TestInputHistoryLoadAndLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	var lines []string
+	for i := 0; i < inputHistoryLimit+20; i++ {
+		lines = append(lines, fmt.Sprintf("prompt %d", i))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	h := loadInputHistory(path)
+	if len(h.entries) != inputHistoryLimit || h.entries[0] != "prompt 20" {
+		t.Fatalf("expected the newest %d entries, got %d starting at %q", inputHistoryLimit, len(h.entries), h.entries[0])
+	}
+	if got := loadInputHistory(filepath.Join(t.TempDir(), "missing")); len(got.entries) != 0 {
+		t.Fatalf("expected an empty history for a missing file, got %q", got.entries)
+	}
+}
+
+func TestRecallInput(t *testing.T) {
+	h := &inputHistory{}
+	for _, line := range []string{"fix the parser", "fix the lexer", "run the tests"} {
+		h.add(line)
+	}
+	noAsk := func(string) (string, bool) {
+		t.Fatal("expected no question")
+		return "", false
+	}
+
+	var out bytes.Buffer
+	if got, ok := recallInput(&out, h, "tests", noAsk); !ok || got != "run the tests" {
+		t.Fatalf("expected a single match to load directly, got %q %v", got, ok)
+	}
+	if _, ok := recallInput(&out, h, "deploy", noAsk); ok || !strings.Contains(out.String(), `No earlier input matches "deploy"`) {
+		t.Fatalf("expected no match to be reported, got %q", out.String())
+	}
+
+	out.Reset()
+	answers := []string{"7", "2"}
+	ask := func(prompt string) (string, bool) {
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, true
+	}
+	got, ok := recallInput(&out, h, "fix", ask)
+	if !ok || got != "fix the parser" {
+		t.Fatalf("expected the second listed entry after an invalid pick, got %q %v", got, ok)
+	}
+	if !strings.Contains(out.String(), " 1. fix the lexer") || !strings.Contains(out.String(), " 2. fix the parser") {
+		t.Fatalf("expected the matches to be listed newest first, got %q", out.String())
+	}
+
+	cancel := func(string) (string, bool) { return "", true }
+	if _, ok := recallInput(&out, h, "fix", cancel); ok {
+		t.Fatal("expected an empty answer to cancel")
+	}
+}
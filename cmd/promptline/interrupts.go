@@ -26,23 +26,35 @@ import (
 type operationCanceler struct {
 	mu     sync.Mutex
 	cancel context.CancelFunc
+	// done is closed when the current operation is cancelled, so prompts
+	// running on behalf of it (tool approval) can give up too.
+	done chan struct{}
 }
 
 func (c *operationCanceler) Set(cancel context.CancelFunc) {
 	c.mu.Lock()
 	c.cancel = cancel
+	c.done = make(chan struct{})
 	c.mu.Unlock()
 }
 
 func (c *operationCanceler) Clear() {
 	c.mu.Lock()
 	c.cancel = nil
+	c.done = nil
 	c.mu.Unlock()
 }
 
 func (c *operationCanceler) Cancel() bool {
 	c.mu.Lock()
 	cancel := c.cancel
+	if c.done != nil {
+		select {
+		case <-c.done:
+		default:
+			close(c.done)
+		}
+	}
 	c.mu.Unlock()
 	if cancel == nil {
 		return false
@@ -51,6 +63,17 @@ func (c *operationCanceler) Cancel() bool {
 	return true
 }
 
+// Done returns a channel closed when the current operation is cancelled. It is
+// nil, and blocks forever, when no operation is running.
+func (c *operationCanceler) Done() <-chan struct{} {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.done
+}
+
 func filterInterruptRune(r rune) (rune, bool) {
 	if r == readline.CharBell {
 		return 0, false
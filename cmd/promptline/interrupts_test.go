@@ -29,8 +29,13 @@ func TestOperationCanceler(t *testing.T) {
 		t.Fatal("expected no cancel when unset")
 	}
 
+	if canceler.Done() != nil {
+		t.Fatal("expected no done channel when unset")
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	canceler.Set(cancel)
+	done := canceler.Done()
 	if !canceler.Cancel() {
 		t.Fatal("expected cancel to return true")
 	}
@@ -39,6 +44,14 @@ func TestOperationCanceler(t *testing.T) {
 	default:
 		t.Fatal("expected context to be canceled")
 	}
+	select {
+	case <-done:
+	default:
+		t.Fatal("expected done channel to be closed")
+	}
+	if !canceler.Cancel() {
+		t.Fatal("expected repeated cancel to be safe")
+	}
 
 	canceler.Clear()
 	if canceler.Cancel() {
@@ -0,0 +1,65 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/chzyer/readline"
+	"promptline/internal/config"
+)
+
+// readlineKeys is the control character readline acts on for each action.
+var readlineKeys = map[string]rune{
+	config.KeyActionSend:        readline.CharEnter,
+	config.KeyActionCancel:      readline.CharInterrupt,
+	config.KeyActionQuit:        readline.CharDelete,
+	config.KeyActionHistoryPrev: readline.CharPrev,
+	config.KeyActionHistoryNext: readline.CharNext,
+	config.KeyActionSearch:      readline.CharBckSearch,
+}
+
+// keyTranslator rewrites configured keys into the ones readline acts on. A
+// built-in key whose action moved to another key is dropped, unless another
+// action is bound to it. A zero value means the key is dropped.
+type keyTranslator map[rune]rune
+
+func newKeyTranslator(bindings map[string]rune) keyTranslator {
+	translator := keyTranslator{}
+	for action, key := range bindings {
+		if native := readlineKeys[action]; key != native {
+			translator[native] = 0
+		}
+	}
+	for action, key := range bindings {
+		if native := readlineKeys[action]; key != native {
+			translator[key] = native
+		} else {
+			delete(translator, key)
+		}
+	}
+	return translator
+}
+
+// filter is used as the readline input filter.
+func (t keyTranslator) filter(r rune) (rune, bool) {
+	if mapped, ok := t[r]; ok {
+		if mapped == 0 {
+			return 0, false
+		}
+		r = mapped
+	}
+	return filterInterruptRune(r)
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/chzyer/readline"
+	"promptline/internal/config"
+)
+
+func TestKeyTranslator(t *testing.T) {
+	cfg := &config.Config{Keybindings: map[string]string{
+		config.KeyActionHistoryPrev: "ctrl+k",
+		config.KeyActionHistoryNext: "ctrl+p",
+	}}
+	bindings, err := cfg.KeyBindings()
+	if err != nil {
+		t.Fatalf("KeyBindings failed: %v", err)
+	}
+	keys := newKeyTranslator(bindings)
+
+	tests := []struct {
+		in   rune
+		want rune
+		ok   bool
+	}{
+		{in: 'a', want: 'a', ok: true},
+		{in: readline.CharEnter, want: readline.CharEnter, ok: true},
+		{in: 'k' - 'a' + 1, want: readline.CharPrev, ok: true},
+		{in: readline.CharPrev, want: readline.CharNext, ok: true},
+		{in: readline.CharNext, ok: false},
+		{in: readline.CharBell, ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := keys.filter(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("filter(%d) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestKeyTranslatorDefaultsPassThrough(t *testing.T) {
+	bindings, err := (&config.Config{}).KeyBindings()
+	if err != nil {
+		t.Fatalf("KeyBindings failed: %v", err)
+	}
+	if keys := newKeyTranslator(bindings); len(keys) != 0 {
+		t.Fatalf("expected no translations for the default bindings, got %v", keys)
+	}
+}
@@ -24,13 +24,18 @@ import (
 	"path/filepath"
 
 	"github.com/rs/zerolog"
+	"promptline/internal/tools"
 )
 
 var (
 	debugMode = flag.Bool("d", false, "Enable debug mode")
 	logFile   = flag.String("log-file", "", "Log file path (logs disabled by default)")
 	dryRun    = flag.Bool("dry-run", false, "Validate tool calls without executing them")
+	noTools   = flag.Bool("no-tools", false, "Send requests without tools so the model cannot call them")
+	jsonOut   = flag.Bool("json", false, "In batch mode, print each reply as JSON with the tool calls it made")
 	version   = flag.Bool("version", false, "Display version information and exit")
+	resume    = flag.Bool("resume", false, "Continue the conversation saved in history_file")
+	provider  = flag.String("provider", "", "Chat provider: empty for the configured API, \"echo\" (or echo:upper, echo:reverse) for an offline demo client")
 )
 
 // Version is set at build time via ldflags. Defaults to "dev".
@@ -58,6 +63,12 @@ func main() {
 	}
 	logger.Info().Str("version", Version).Msg("Promptline starting")
 
+	// Tools may cd into subdirectories but never above the startup directory.
+	if err := tools.ConfigureWorkRoot(""); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Check if we're running in batch mode (with "-" argument)
 	args := flag.Args()
 	if len(args) > 0 && args[0] == "-" {
@@ -77,7 +88,6 @@ func initLogger(debug bool, logFilePath string) (zerolog.Logger, io.Closer, erro
 	}
 
 	// Configure output
-	var output io.Writer
 	var closer io.Closer
 	if debug {
 		if logFilePath == "" {
@@ -98,12 +108,10 @@ func initLogger(debug bool, logFilePath string) (zerolog.Logger, io.Closer, erro
 			file = tmp
 		}
 		closer = file
-		output = file
-	} else {
-		// Logging is disabled when debug mode is off
-		output = io.Discard
+		debugOutput.setFile(file)
 	}
 
-	// Create logger with timestamp
-	return zerolog.New(output).With().Timestamp().Logger(), closer, nil
+	// Create logger with timestamp. Nothing is written while debug mode is
+	// off; /debug can turn it on later.
+	return zerolog.New(debugOutput).With().Timestamp().Logger(), closer, nil
 }
@@ -0,0 +1,51 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"promptline/internal/chat"
+)
+
+// preflightTimeout bounds the startup connectivity check.
+const preflightTimeout = 10 * time.Second
+
+// runPreflight verifies the provider connection and API key when the config
+// asks for it, reporting the outcome on w. Sessions that cannot be checked,
+// such as the echo provider, are skipped silently.
+func runPreflight(w io.Writer, session *chat.Session) error {
+	if !session.Config.PreflightEnabled() {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+	err := session.Preflight(ctx)
+	if errors.Is(err, chat.ErrModelsUnsupported) {
+		return nil
+	}
+	if err != nil {
+		fmt.Fprintf(w, "✗ Preflight check failed: %v\n", err)
+		return err
+	}
+	fmt.Fprintf(w, "✓ Connected to %s at %s\n", session.Config.Model, session.BaseURL)
+	return nil
+}
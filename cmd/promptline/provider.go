@@ -0,0 +1,73 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"promptline/internal/chat"
+	"promptline/internal/config"
+)
+
+const echoProvider = "echo"
+
+// newProviderSession loads config.json and creates a session for the selected provider.
+// An empty name uses the configured OpenAI-compatible API; "echo", "echo:upper" and
+// "echo:reverse" use the offline echo client. When sandbox_dir is configured the
+// session starts inside its own sandbox directory.
+func newProviderSession(name string) (*chat.Session, error) {
+	session, err := newProviderSessionFor(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := session.OpenSandbox(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func newProviderSessionFor(name string) (*chat.Session, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		cfg, err := config.LoadConfig("config.json")
+		if err != nil {
+			return nil, err
+		}
+		return chat.NewSession(cfg), nil
+	}
+
+	mode, isEcho := strings.CutPrefix(name, echoProvider)
+	if !isEcho || (mode != "" && !strings.HasPrefix(mode, ":")) {
+		return nil, fmt.Errorf("unknown provider %q (use echo or leave empty)", name)
+	}
+
+	echoMode, err := chat.ParseEchoMode(strings.TrimPrefix(mode, ":"))
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := config.LoadConfig("config.json")
+	// The echo client never contacts a provider, so no API key is needed; the
+	// rest of config.json still applies.
+	if err != nil && !errors.Is(err, config.ErrMissingAPIKey) {
+		return nil, err
+	}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(echoMode))
+	session.BaseURL = chat.EchoBaseURL
+	return session, nil
+}
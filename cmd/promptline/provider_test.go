@@ -0,0 +1,139 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/chat"
+)
+
+func newEchoTestSession(t *testing.T, name string) *chat.Session {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("DASHSCOPE_API_KEY", "")
+
+	session, err := newProviderSession(name)
+	if err != nil {
+		t.Fatalf("newProviderSession(%q) failed: %v", name, err)
+	}
+	return session
+}
+
+func TestNewProviderSessionEchoWithoutAPIKey(t *testing.T) {
+	session := newEchoTestSession(t, "echo:upper")
+	if _, ok := session.Client.(*chat.EchoClient); !ok {
+		t.Fatalf("expected echo client, got %T", session.Client)
+	}
+	if session.BaseURL != chat.EchoBaseURL {
+		t.Errorf("expected base URL %q, got %q", chat.EchoBaseURL, session.BaseURL)
+	}
+}
+
+func TestNewProviderSessionEchoKeepsConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("DASHSCOPE_API_KEY", "")
+	if err := os.WriteFile("config.json", []byte(`{"history_file": "echo-history.jsonl", "idle_timeout_minutes": 5}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := newProviderSession("echo")
+	if err != nil {
+		t.Fatalf("newProviderSession failed: %v", err)
+	}
+	if session.Config.HistoryFile != "echo-history.jsonl" || session.Config.IdleTimeoutMinutes != 5 {
+		t.Fatalf("expected config.json to apply without an API key, got history %q and idle %d",
+			session.Config.HistoryFile, session.Config.IdleTimeoutMinutes)
+	}
+	if _, err := newProviderSession(""); err == nil {
+		t.Fatal("expected the default provider to still require an API key")
+	}
+}
+
+func TestNewProviderSessionRejectsUnknownProvider(t *testing.T) {
+	for _, name := range []string{"anthropic", "echoes", "echo:shout"} {
+		if _, err := newProviderSession(name); err == nil {
+			t.Errorf("expected error for provider %q", name)
+		}
+	}
+}
+
+func TestStreamConversationWithEchoProvider(t *testing.T) {
+	session := newEchoTestSession(t, "echo")
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) {
+		return true, nil
+	}
+
+	streamConversation(session, "tool: get_current_datetime", true, zerolog.Nop(), nil)
+
+	history := session.GetHistory()
+	var sawToolResult bool
+	for _, msg := range history {
+		if msg.Role == openai.ChatMessageRoleTool {
+			sawToolResult = true
+		}
+	}
+	if !sawToolResult {
+		t.Fatal("expected tool result in history")
+	}
+	last := history[len(history)-1]
+	if last.Role != openai.ChatMessageRoleAssistant || !strings.HasPrefix(last.Content, "Tool result:") {
+		t.Errorf("expected final assistant reply summarizing the tool result, got %+v", last)
+	}
+}
+
+func TestWriteBatchJSONIncludesToolTrace(t *testing.T) {
+	session := newEchoTestSession(t, "echo")
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) {
+		return true, nil
+	}
+
+	response, err := session.GetResponse("tool: get_current_datetime")
+	if err != nil {
+		t.Fatalf("GetResponse failed: %v", err)
+	}
+	var out strings.Builder
+	if err := writeBatchJSON(&out, response, session.LastTurnTrace()); err != nil {
+		t.Fatalf("writeBatchJSON failed: %v", err)
+	}
+
+	var result batchResult
+	if err := json.Unmarshal([]byte(out.String()), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	if result.Response != response {
+		t.Errorf("expected response %q, got %q", response, result.Response)
+	}
+	if len(result.ToolCalls) != 1 || result.ToolCalls[0].Name != "get_current_datetime" || !result.ToolCalls[0].Success {
+		t.Errorf("unexpected tool trace %+v", result.ToolCalls)
+	}
+
+	out.Reset()
+	if err := writeBatchJSON(&out, "plain", nil); err != nil {
+		t.Fatalf("writeBatchJSON failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `"tool_calls":[]`) {
+		t.Errorf("expected an empty tool_calls list, got %q", out.String())
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"promptline/internal/chat"
+)
+
+const quitChoicePrompt = "[s]ave & quit, [q]uit without saving, [c]ancel: "
+
+// confirmExit is confirmQuit for a prompt that ended with Ctrl+D or an idle
+// quit. An idle quit has closed the prompt and nobody is there to answer, so
+// it quits without asking and its messages are saved by saveIdleHistory.
+func confirmExit(w io.Writer, session *chat.Session, logger zerolog.Logger, idleQuit bool, ask func(prompt string) (string, bool)) bool {
+	if idleQuit {
+		return true
+	}
+	return confirmQuit(w, session, logger, ask)
+}
+
+// confirmQuit reports whether promptline should quit. When the conversation
+// has messages not yet written to history_file it asks, through ask, whether
+// to save them first; ask returns false when the answer was interrupted,
+// which cancels. Nothing is asked with confirm_unsaved_quit set to false.
+func confirmQuit(w io.Writer, session *chat.Session, logger zerolog.Logger, ask func(prompt string) (string, bool)) bool {
+	cfg := session.Config
+	if cfg == nil || cfg.HistoryFile == "" || !cfg.ConfirmUnsavedQuitEnabled() {
+		return true
+	}
+	unsaved := session.UnsavedMessageCount()
+	if unsaved == 0 {
+		return true
+	}
+
+	noun := "messages"
+	if unsaved == 1 {
+		noun = "message"
+	}
+	fmt.Fprintf(w, "⚠ %d %s not saved to %s\n", unsaved, noun, cfg.HistoryFile)
+	for {
+		answer, ok := ask(quitChoicePrompt)
+		if !ok {
+			fmt.Fprintln(w, "Quit cancelled")
+			return false
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "s", "save":
+			if err := session.SaveConversationHistory(cfg.HistoryFile); err != nil {
+				fmt.Fprintf(w, "✗ Failed to save history: %v\n", err)
+				logger.Error().Err(err).Msg("Failed to save history on quit")
+				return false
+			}
+			fmt.Fprintf(w, "✓ Saved to %s\n", cfg.HistoryFile)
+			return true
+		case "q", "quit":
+			return true
+		case "", "c", "cancel":
+			fmt.Fprintln(w, "Quit cancelled")
+			return false
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/chat"
+	"promptline/internal/config"
+)
+
+func TestConfirmQuit(t *testing.T) {
+	newSession := func(t *testing.T) *chat.Session {
+		session := chat.NewSession(&config.Config{
+			APIKey:      "test-key",
+			Model:       "gpt-4o-mini",
+			HistoryFile: filepath.Join(t.TempDir(), "history.jsonl"),
+		})
+		session.AddMessage(openai.ChatMessageRoleUser, "Hello")
+		session.AddMessage(openai.ChatMessageRoleAssistant, "Hi there!")
+		return session
+	}
+	answers := func(replies ...string) func(string) (string, bool) {
+		return func(string) (string, bool) {
+			if len(replies) == 0 {
+				return "", false
+			}
+			reply := replies[0]
+			replies = replies[1:]
+			return reply, true
+		}
+	}
+
+	t.Run("save and quit", func(t *testing.T) {
+		session := newSession(t)
+		var out bytes.Buffer
+		if !confirmQuit(&out, session, zerolog.Nop(), answers("maybe", "s")) {
+			t.Fatal("expected save to quit")
+		}
+		if !strings.Contains(out.String(), "2 messages not saved") {
+			t.Fatalf("expected an unsaved warning, got %q", out.String())
+		}
+		if got := session.UnsavedMessageCount(); got != 0 {
+			t.Fatalf("expected the conversation to be saved, %d left", got)
+		}
+		data, err := os.ReadFile(session.Config.HistoryFile)
+		if err != nil || !strings.Contains(string(data), "Hi there!") {
+			t.Fatalf("expected the history file to hold the reply, got %q (%v)", data, err)
+		}
+	})
+
+	t.Run("quit without saving", func(t *testing.T) {
+		session := newSession(t)
+		if !confirmQuit(&bytes.Buffer{}, session, zerolog.Nop(), answers("q")) {
+			t.Fatal("expected quit")
+		}
+		if _, err := os.Stat(session.Config.HistoryFile); !os.IsNotExist(err) {
+			t.Fatalf("expected no history file, got %v", err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		session := newSession(t)
+		if confirmQuit(&bytes.Buffer{}, session, zerolog.Nop(), answers("c")) {
+			t.Fatal("expected cancel to keep the session")
+		}
+		if confirmQuit(&bytes.Buffer{}, session, zerolog.Nop(), answers()) {
+			t.Fatal("expected an interrupted answer to cancel")
+		}
+	})
+
+	t.Run("idle quit", func(t *testing.T) {
+		never := func(string) (string, bool) {
+			t.Fatal("did not expect a question after an idle quit")
+			return "", false
+		}
+		session := newSession(t)
+		if !confirmExit(&bytes.Buffer{}, session, zerolog.Nop(), true, never) {
+			t.Fatal("expected an idle quit to quit")
+		}
+		saveIdleHistory(session, zerolog.Nop())
+		if got := session.UnsavedMessageCount(); got != 0 {
+			t.Fatalf("expected the idle quit to save the conversation, %d left", got)
+		}
+		data, err := os.ReadFile(session.Config.HistoryFile)
+		if err != nil || !strings.Contains(string(data), "Hi there!") {
+			t.Fatalf("expected the history file to hold the reply, got %q (%v)", data, err)
+		}
+
+		if confirmExit(&bytes.Buffer{}, newSession(t), zerolog.Nop(), false, answers("c")) {
+			t.Fatal("expected Ctrl+D to still ask about unsaved messages")
+		}
+	})
+
+	t.Run("nothing to ask", func(t *testing.T) {
+		never := func(string) (string, bool) {
+			t.Fatal("did not expect a question")
+			return "", false
+		}
+		session := newSession(t)
+		if err := session.SaveConversationHistory(session.Config.HistoryFile); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+		if !confirmQuit(&bytes.Buffer{}, session, zerolog.Nop(), never) {
+			t.Fatal("expected a saved conversation to quit")
+		}
+
+		session = newSession(t)
+		disabled := false
+		session.Config.ConfirmUnsavedQuit = &disabled
+		if !confirmQuit(&bytes.Buffer{}, session, zerolog.Nop(), never) {
+			t.Fatal("expected confirm_unsaved_quit false to quit")
+		}
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/chat"
+)
+
+const (
+	// resumeRecapMessages is how many of the last user and assistant
+	// messages the recap after a resume shows.
+	resumeRecapMessages = 4
+	resumeRecapWidth    = 100
+)
+
+// resumeConversation loads history_file into a session that has no messages
+// yet and returns how many were loaded. A missing or empty file loads nothing
+// and is not an error.
+func resumeConversation(session *chat.Session) (int, error) {
+	cfg := session.Config
+	if cfg == nil || cfg.HistoryFile == "" || len(session.GetHistory()) > 0 {
+		return 0, nil
+	}
+	if err := session.LoadConversationHistory(cfg.HistoryFile, 0); err != nil {
+		return 0, err
+	}
+	return len(session.GetHistory()), nil
+}
+
+// printResumeRecap shows the last few turns of a resumed conversation, one
+// line each; /history shows them in full.
+func printResumeRecap(w io.Writer, session *chat.Session, loaded int) {
+	var recap []openai.ChatCompletionMessage
+	for _, msg := range session.GetHistory() {
+		if (msg.Role == openai.ChatMessageRoleUser || msg.Role == openai.ChatMessageRoleAssistant) && strings.TrimSpace(msg.Content) != "" {
+			recap = append(recap, msg)
+		}
+	}
+	if len(recap) > resumeRecapMessages {
+		recap = recap[len(recap)-resumeRecapMessages:]
+	}
+
+	noun := "messages"
+	if loaded == 1 {
+		noun = "message"
+	}
+	fmt.Fprintf(w, "Resumed %d %s from %s\n", loaded, noun, session.Config.HistoryFile)
+	for _, msg := range recap {
+		marker := "❯"
+		if msg.Role == openai.ChatMessageRoleAssistant {
+			marker = "⟫"
+		}
+		fmt.Fprintf(w, "  %s %s\n", marker, truncateRunes(strings.Join(strings.Fields(msg.Content), " "), resumeRecapWidth))
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"promptline/internal/chat"
+	"promptline/internal/config"
+)
+
+func TestResumeConversation(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", HistoryFile: historyFile}
+
+	fresh := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	if n, err := resumeConversation(fresh); n != 0 || err != nil {
+		t.Fatalf("expected a missing history file to load nothing, got %d, %v", n, err)
+	}
+	if err := os.WriteFile(historyFile, nil, 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if n, err := resumeConversation(fresh); n != 0 || err != nil {
+		t.Fatalf("expected an empty history file to load nothing, got %d, %v", n, err)
+	}
+
+	earlier := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	for _, text := range []string{"first question", "second question", "third question"} {
+		earlier.AddMessage("user", text)
+		earlier.AddMessage("assistant", "answer to the "+text+"\nwith a second line")
+	}
+	if err := earlier.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	n, err := resumeConversation(session)
+	if err != nil || n != 6 {
+		t.Fatalf("expected 6 messages resumed, got %d, %v", n, err)
+	}
+	if len(session.Messages) != 7 || session.Messages[1].Content != "first question" {
+		t.Fatalf("expected the history after the system prompt, got %d messages", len(session.Messages))
+	}
+	if session.UnsavedMessageCount() != 0 {
+		t.Fatal("expected resumed messages to count as saved")
+	}
+
+	var out bytes.Buffer
+	printResumeRecap(&out, session, n)
+	recap := out.String()
+	if !strings.Contains(recap, "Resumed 6 messages from "+historyFile) {
+		t.Fatalf("expected a resume line, got %q", recap)
+	}
+	if strings.Contains(recap, "first question") || !strings.Contains(recap, "❯ third question") ||
+		!strings.Contains(recap, "⟫ answer to the third question with a second line") {
+		t.Fatalf("expected the last turns condensed to one line each, got %q", recap)
+	}
+
+	if n, err := resumeConversation(session); n != 0 || err != nil || len(session.Messages) != 7 {
+		t.Fatalf("expected a second resume to leave the conversation alone, got %d, %v", n, err)
+	}
+}
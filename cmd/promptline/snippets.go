@@ -0,0 +1,246 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"promptline/internal/chat"
+)
+
+// snippetsFile stores named prompt templates in the working directory.
+const snippetsFile = ".promptline_snippets.json"
+
+// snippetPreviewBytes caps the text shown per snippet by /snippet list.
+const snippetPreviewBytes = 60
+
+var (
+	// snippetNamePattern limits names to what a ::name reference can match.
+	snippetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	// snippetRefPattern matches ::name or ::name(key=value, ...) at the start
+	// of the input or after whitespace, so C++ scopes like std::vector are kept.
+	snippetRefPattern = regexp.MustCompile(`(^|\s)::([A-Za-z0-9_-]+)(?:\(([^)]*)\))?`)
+	// snippetPlaceholderPattern matches {{arg}} placeholders in a snippet.
+	snippetPlaceholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_-]+)\s*\}\}`)
+)
+
+// loadSnippets reads the snippet file; a missing file is an empty set.
+func loadSnippets(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets: %w", err)
+	}
+	snippets := map[string]string{}
+	if err := json.Unmarshal(data, &snippets); err != nil {
+		return nil, fmt.Errorf("invalid snippets file %s: %w", path, err)
+	}
+	return snippets, nil
+}
+
+func saveSnippets(path string, snippets map[string]string) error {
+	data, err := json.MarshalIndent(snippets, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write snippets: %w", err)
+	}
+	return nil
+}
+
+// expandSnippets replaces every ::name reference with the stored snippet,
+// filling {{arg}} placeholders from ::name(arg=value). References to unknown
+// snippets are left as typed. A placeholder without a value is an error, so
+// a half-filled template is never sent.
+func expandSnippets(input string, snippets map[string]string) (string, error) {
+	var expandErr error
+	expanded := snippetRefPattern.ReplaceAllStringFunc(input, func(match string) string {
+		parts := snippetRefPattern.FindStringSubmatch(match)
+		prefix, name, rawArgs := parts[1], parts[2], parts[3]
+		text, ok := snippets[name]
+		if !ok || expandErr != nil {
+			return match
+		}
+		args, err := parseSnippetArgs(rawArgs)
+		if err != nil {
+			expandErr = fmt.Errorf("snippet %q: %w", name, err)
+			return match
+		}
+		var missing []string
+		text = snippetPlaceholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+			key := snippetPlaceholderPattern.FindStringSubmatch(placeholder)[1]
+			value, ok := args[key]
+			if !ok {
+				missing = append(missing, key)
+				return placeholder
+			}
+			return value
+		})
+		if len(missing) > 0 {
+			expandErr = fmt.Errorf("snippet %q needs a value for %s (use ::%s(%s=...))", name, strings.Join(missing, ", "), name, missing[0])
+			return match
+		}
+		return prefix + text
+	})
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return expanded, nil
+}
+
+// parseSnippetArgs parses "key=value, key2=value2".
+func parseSnippetArgs(raw string) (map[string]string, error) {
+	args := map[string]string{}
+	if strings.TrimSpace(raw) == "" {
+		return args, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid argument %q, expected key=value", strings.TrimSpace(pair))
+		}
+		args[key] = strings.TrimSpace(value)
+	}
+	return args, nil
+}
+
+// expandSnippetRefs expands ::name references in a message using the snippet
+// file. It only touches the disk when the input looks like it has a reference.
+func expandSnippetRefs(input string) (string, error) {
+	if !strings.Contains(input, "::") {
+		return input, nil
+	}
+	snippets, err := loadSnippets(snippetsFile)
+	if err != nil {
+		return "", err
+	}
+	return expandSnippets(input, snippets)
+}
+
+// runSnippetCommand handles /snippet save|use|list|delete. It returns the
+// message to send, if any.
+func runSnippetCommand(session *chat.Session, args string) (string, error) {
+	action, rest, _ := strings.Cut(strings.TrimSpace(args), " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(action) {
+	case "", "list":
+		return "", listSnippets()
+	case "save":
+		name, text, _ := strings.Cut(rest, " ")
+		return "", saveSnippet(session, name, strings.TrimSpace(text))
+	case "use":
+		if rest == "" {
+			return "", fmt.Errorf("missing snippet name")
+		}
+		snippets, err := loadSnippets(snippetsFile)
+		if err != nil {
+			return "", err
+		}
+		name, _, _ := strings.Cut(rest, "(")
+		if _, ok := snippets[name]; !ok {
+			return "", fmt.Errorf("unknown snippet %q", name)
+		}
+		return expandSnippets("::"+rest, snippets)
+	case "delete":
+		snippets, err := loadSnippets(snippetsFile)
+		if err != nil {
+			return "", err
+		}
+		if _, ok := snippets[rest]; !ok {
+			return "", fmt.Errorf("unknown snippet %q", rest)
+		}
+		delete(snippets, rest)
+		if err := saveSnippets(snippetsFile, snippets); err != nil {
+			return "", err
+		}
+		fmt.Printf("✓ Snippet %q deleted\n", rest)
+		return "", nil
+	default:
+		return "", fmt.Errorf("unknown action %q", action)
+	}
+}
+
+// saveSnippet stores text under name, or the last message sent when text is empty.
+func saveSnippet(session *chat.Session, name, text string) error {
+	if !snippetNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid snippet name %q (letters, digits, - and _ only)", name)
+	}
+	if text == "" {
+		text = lastUserMessage(session)
+		if text == "" {
+			return fmt.Errorf("nothing to save, give the snippet text or send a message first")
+		}
+	}
+	snippets, err := loadSnippets(snippetsFile)
+	if err != nil {
+		return err
+	}
+	snippets[name] = text
+	if err := saveSnippets(snippetsFile, snippets); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Snippet %q saved, type ::%s in a message to use it\n", name, name)
+	return nil
+}
+
+func listSnippets() error {
+	snippets, err := loadSnippets(snippetsFile)
+	if err != nil {
+		return err
+	}
+	if len(snippets) == 0 {
+		fmt.Println("No snippets (save one with /snippet save <name> <text>)")
+		return nil
+	}
+	names := make([]string, 0, len(snippets))
+	for name := range snippets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("\nSnippets:")
+	for _, name := range names {
+		preview, cut := truncatePaste(strings.ReplaceAll(snippets[name], "\n", " "), snippetPreviewBytes)
+		if cut {
+			preview += "…"
+		}
+		fmt.Printf("  ::%s  %s\n", name, preview)
+	}
+	fmt.Println()
+	return nil
+}
+
+func lastUserMessage(session *chat.Session) string {
+	history := session.GetHistory()
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == openai.ChatMessageRoleUser {
+			return history[i].Content
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"promptline/internal/chat"
+	"promptline/internal/config"
+)
+
+func TestExpandSnippets(t *testing.T) {
+	snippets := map[string]string{
+		"review": "Review {{file}} for {{focus}} issues.",
+		"terse":  "Answer in one sentence.",
+	}
+
+	got, err := expandSnippets("::terse What is Go?", snippets)
+	if err != nil || got != "Answer in one sentence. What is Go?" {
+		t.Fatalf("unexpected expansion %q (%v)", got, err)
+	}
+
+	got, err = expandSnippets("Please ::review(file=main.go, focus=concurrency) thanks", snippets)
+	if err != nil || got != "Please Review main.go for concurrency issues. thanks" {
+		t.Fatalf("unexpected expansion with args %q (%v)", got, err)
+	}
+
+	got, err = expandSnippets("std::terse and ::unknown stay", snippets)
+	if err != nil || got != "std::terse and ::unknown stay" {
+		t.Fatalf("expected scoped names and unknown snippets untouched, got %q (%v)", got, err)
+	}
+
+	if _, err := expandSnippets("::review(file=main.go)", snippets); err == nil || !strings.Contains(err.Error(), "focus") {
+		t.Fatalf("expected missing placeholder error, got %v", err)
+	}
+	if _, err := expandSnippets("::review(file)", snippets); err == nil || !strings.Contains(err.Error(), "key=value") {
+		t.Fatalf("expected invalid argument error, got %v", err)
+	}
+}
+
+func TestHandleSnippetCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModeUpper))
+	logger := zerolog.Nop()
+	debugMode := false
+
+	handleCommand("/snippet save greet Say hello to {{name}}", session, logger, &debugMode, nil)
+	snippets, err := loadSnippets(snippetsFile)
+	if err != nil || snippets["greet"] != "Say hello to {{name}}" {
+		t.Fatalf("expected saved snippet, got %v (%v)", snippets, err)
+	}
+
+	handleCommand("/snippet use greet(name=Ada)", session, logger, &debugMode, nil)
+	history := session.GetHistory()
+	if len(history) != 2 || history[0].Content != "Say hello to Ada" || history[1].Content != "SAY HELLO TO ADA" {
+		t.Fatalf("expected snippet to be sent, got %+v", history)
+	}
+
+	handleCommand("/snippet save again", session, logger, &debugMode, nil)
+	if expanded, err := expandSnippetRefs("::again"); err != nil || expanded != "Say hello to Ada" {
+		t.Fatalf("expected last message saved as snippet, got %q (%v)", expanded, err)
+	}
+
+	handleCommand("/snippet delete greet", session, logger, &debugMode, nil)
+	snippets, _ = loadSnippets(snippetsFile)
+	if _, ok := snippets["greet"]; ok {
+		t.Fatal("expected snippet to be deleted")
+	}
+
+	handleCommand("/snippet save bad!name text", session, logger, &debugMode, nil)
+	snippets, _ = loadSnippets(snippetsFile)
+	if len(snippets) != 1 {
+		t.Fatalf("expected invalid name to be rejected, got %v", snippets)
+	}
+}
@@ -0,0 +1,77 @@
+//go:build !windows
+
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// stopPollInterval is how long a read waits for input before checking whether
+// it was stopped.
+const stopPollInterval = 50 * time.Millisecond
+
+// errReadStopped is returned by a stoppableReader read after Close.
+var errReadStopped = errors.New("read stopped")
+
+// stoppableReader reads a file only once poll reports input, so a prompt that
+// gave up can stop its pending read with Close without closing the file.
+type stoppableReader struct {
+	file *os.File
+	stop chan struct{}
+	once sync.Once
+}
+
+// newStoppableReader wraps file, typically os.Stdin, for a prompt that may
+// time out or be cancelled.
+func newStoppableReader(file *os.File) io.ReadCloser {
+	return &stoppableReader{file: file, stop: make(chan struct{})}
+}
+
+func (r *stoppableReader) Read(p []byte) (int, error) {
+	fds := []unix.PollFd{{Fd: int32(r.file.Fd()), Events: unix.POLLIN}}
+	for {
+		select {
+		case <-r.stop:
+			return 0, errReadStopped
+		default:
+		}
+		n, err := unix.Poll(fds, int(stopPollInterval/time.Millisecond))
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n > 0 {
+			return r.file.Read(p)
+		}
+	}
+}
+
+// Close stops the pending read and leaves the file open.
+func (r *stoppableReader) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	return nil
+}
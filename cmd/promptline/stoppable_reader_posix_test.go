@@ -0,0 +1,63 @@
+//go:build !windows
+
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStoppableReaderLeavesInputForTheNextReader(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	stoppable := newStoppableReader(r)
+	done := make(chan error, 1)
+	go func() {
+		_, err := stoppable.Read(make([]byte, 16))
+		done <- err
+	}()
+	time.Sleep(2 * stopPollInterval)
+	if err := stoppable.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, errReadStopped) {
+			t.Fatalf("expected errReadStopped, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected Close to stop the pending read")
+	}
+
+	if _, err := w.Write([]byte("yes\n")); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil || line != "yes\n" {
+		t.Fatalf("expected the next reader to get the line, got %q (%v)", line, err)
+	}
+}
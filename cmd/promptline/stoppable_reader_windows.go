@@ -0,0 +1,31 @@
+//go:build windows
+
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// newStoppableReader wraps file for a prompt that may time out or be
+// cancelled. Windows consoles cannot be polled like POSIX terminals, so Close
+// leaves a pending read in place.
+func newStoppableReader(file *os.File) io.ReadCloser {
+	return io.NopCloser(file)
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"promptline/internal/chat"
+)
+
+// streamRenderer coalesces streamed reply chunks so the terminal is written
+// at most once per interval instead of once per chunk, which can be hundreds
+// of times a second. A chunk holding a newline is written at once, and a
+// timer writes what is left when the stream pauses. Callers must Flush before
+// printing anything else and when the stream ends.
+type streamRenderer struct {
+	mu       sync.Mutex
+	out      io.Writer
+	interval time.Duration
+	buf      []byte
+	last     time.Time
+	timer    *time.Timer
+	writes   int
+}
+
+// newStreamRenderer returns a renderer writing to out; an interval of zero
+// or less writes every chunk as it arrives.
+func newStreamRenderer(out io.Writer, interval time.Duration) *streamRenderer {
+	return &streamRenderer{out: out, interval: interval}
+}
+
+// Write queues a chunk, writing it out now when the interval has passed since
+// the last write or the chunk ends a line.
+func (r *streamRenderer) Write(chunk string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf = append(r.buf, chunk...)
+	since := time.Since(r.last)
+	if r.interval <= 0 || since >= r.interval || strings.Contains(chunk, "\n") {
+		r.flushLocked()
+		return
+	}
+	if r.timer == nil {
+		r.timer = time.AfterFunc(r.interval-since, r.Flush)
+	}
+}
+
+// Flush writes any queued text.
+func (r *streamRenderer) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+}
+
+func (r *streamRenderer) flushLocked() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+	if len(r.buf) == 0 {
+		return
+	}
+	_, _ = r.out.Write(r.buf)
+	r.buf = r.buf[:0]
+	r.last = time.Now()
+	r.writes++
+}
+
+// renderFlushInterval returns the configured render_flush_interval_ms.
+func renderFlushInterval(session *chat.Session) time.Duration {
+	if session.Config == nil {
+		return 0
+	}
+	return session.Config.RenderFlushPeriod()
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingWriter records what was written and how many writes it took.
+type countingWriter struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	return w.buf.Write(p)
+}
+
+func (w *countingWriter) snapshot() (string, int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String(), w.writes
+}
+
+func TestStreamRendererCoalescesChunks(t *testing.T) {
+	out := &countingWriter{}
+	r := newStreamRenderer(out, time.Hour)
+
+	// The first chunk is written at once, later ones wait for the interval.
+	for _, chunk := range []string{"Hel", "lo", ", ", "wor", "ld"} {
+		r.Write(chunk)
+	}
+	if text, writes := out.snapshot(); text != "Hel" || writes != 1 {
+		t.Fatalf("expected only the first chunk to be written, got %q in %d writes", text, writes)
+	}
+
+	// A chunk ending a line is a natural boundary.
+	r.Write("!\n")
+	if text, writes := out.snapshot(); text != "Hello, world!\n" || writes != 2 {
+		t.Fatalf("expected a newline to flush, got %q in %d writes", text, writes)
+	}
+
+	// The end of the stream always flushes.
+	r.Write("Bye")
+	r.Flush()
+	r.Flush()
+	if text, writes := out.snapshot(); text != "Hello, world!\nBye" || writes != 3 {
+		t.Fatalf("expected the final flush to write the rest once, got %q in %d writes", text, writes)
+	}
+}
+
+func TestStreamRendererFlushesAfterInterval(t *testing.T) {
+	out := &countingWriter{}
+	r := newStreamRenderer(out, 20*time.Millisecond)
+	r.Write("a")
+	r.Write("b")
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if text, _ := out.snapshot(); text == "ab" {
+			break
+		}
+		if time.Now().After(deadline) {
+			text, _ := out.snapshot()
+			t.Fatalf("expected the timer to flush a paused stream, got %q", text)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// BenchmarkStreamRender compares terminal writes for a reply streamed in
+// small chunks, written per chunk and with the default 50ms interval.
+func BenchmarkStreamRender(b *testing.B) {
+	chunks := make([]string, 0, 400)
+	for i := 0; i < 400; i++ {
+		chunk := "tok "
+		if i%80 == 79 {
+			chunk = "end\n"
+		}
+		chunks = append(chunks, chunk)
+	}
+	for _, bench := range []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"per-chunk", 0},
+		{"coalesced", 50 * time.Millisecond},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			writes := 0
+			for i := 0; i < b.N; i++ {
+				out := &countingWriter{}
+				r := newStreamRenderer(out, bench.interval)
+				for _, chunk := range chunks {
+					r.Write(chunk)
+				}
+				r.Flush()
+				_, n := out.snapshot()
+				writes += n
+			}
+			b.ReportMetric(float64(writes)/float64(b.N), "draws/op")
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -39,8 +40,36 @@ func handleConversation(input string, session *chat.Session, logger zerolog.Logg
 	streamConversation(session, input, true, sessionLogger, canceler)
 }
 
-// streamConversation handles streaming with tool execution
+// streamConversation requests a response, streamed or not, and handles tool execution
 func streamConversation(session *chat.Session, input string, includeUserMessage bool, logger zerolog.Logger, canceler *operationCanceler) {
+	streamResponse(session, func(ctx context.Context, events chan<- chat.StreamEvent) {
+		session.RespondWithContext(ctx, input, includeUserMessage, events)
+	}, includeUserMessage, logger, canceler)
+}
+
+// retryLastResponse regenerates the last answer and renders it like a new one.
+func retryLastResponse(session *chat.Session, temperature *float32, logger zerolog.Logger, canceler *operationCanceler) {
+	streamResponse(session, func(ctx context.Context, events chan<- chat.StreamEvent) {
+		session.RegenerateLastResponse(ctx, temperature, events)
+	}, true, logger, canceler)
+}
+
+// resendEditedMessage replaces the last user turn with text and answers it.
+func resendEditedMessage(session *chat.Session, text string, logger zerolog.Logger, canceler *operationCanceler) {
+	if err := session.ReplaceLastUserTurn(text); err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return
+	}
+	logConversation(logger.With().Str("session_id", session.SessionID).Logger(), openai.ChatMessageRoleUser, text)
+	fmt.Println("↺ Replaced your last message")
+	streamResponse(session, func(ctx context.Context, events chan<- chat.StreamEvent) {
+		session.RespondWithContext(ctx, "", false, events)
+	}, true, logger, canceler)
+}
+
+// streamResponse renders the events produced by start, then runs any requested
+// tools and continues the conversation with their results.
+func streamResponse(session *chat.Session, start func(ctx context.Context, events chan<- chat.StreamEvent), showPrefix bool, logger zerolog.Logger, canceler *operationCanceler) {
 	sessionLogger := logger.With().Str("session_id", session.SessionID).Logger()
 	// Create streaming events channel
 	events := make(chan chat.StreamEvent, 10)
@@ -56,25 +85,45 @@ func streamConversation(session *chat.Session, input string, includeUserMessage
 	}()
 
 	// Start streaming in goroutine
-	go session.StreamResponseWithContext(ctx, input, includeUserMessage, events)
+	go start(ctx, events)
 
-	// Display assistant prefix with special character (only for new conversations)
-	if includeUserMessage {
+	// Display the assistant prefix once per turn: rounds that continue after
+	// tool calls render under the prefix of the round that made them.
+	if showPrefix {
 		fmt.Print("⟫ ")
 	}
 
-	start := time.Now()
+	startTime := time.Now()
 	var responseBuilder strings.Builder
 	var toolCallsToExecute []*chat.StreamEvent
+	progressShown := false
+	renderer := newStreamRenderer(os.Stdout, renderFlushInterval(session))
+	defer renderer.Flush()
 
 	// Process streaming events
 	for event := range events {
+		if event.Type != chat.StreamEventContent {
+			renderer.Flush()
+		}
+		if progressShown && event.Type != chat.StreamEventToolProgress {
+			fmt.Print(clearLine)
+			progressShown = false
+		}
 		switch event.Type {
 		case chat.StreamEventContent:
-			// Print content chunk directly
-			fmt.Print(event.Content)
+			// Coalesce chunks so the terminal is not redrawn for every token
+			renderer.Write(event.Content)
 			responseBuilder.WriteString(event.Content)
 
+		case chat.StreamEventToolProgress:
+			// Keep the indicator on its own line below any streamed text
+			if !progressShown && responseBuilder.Len() > 0 && !strings.HasSuffix(responseBuilder.String(), "\n") {
+				fmt.Println()
+				responseBuilder.WriteString("\n")
+			}
+			fmt.Print(clearLine + formatToolProgress(event.ToolName, event.ArgBytes))
+			progressShown = true
+
 		case chat.StreamEventToolCall:
 			// Collect tool calls for execution after stream completes
 			if event.ToolCall != nil {
@@ -94,11 +143,15 @@ func streamConversation(session *chat.Session, input string, includeUserMessage
 		}
 	}
 
-	duration := time.Since(start)
+	renderer.Flush()
+	if progressShown {
+		fmt.Print(clearLine)
+	}
+	duration := time.Since(startTime)
 
 	// Log the response
 	sessionLogger.Info().
-		Str("model_response", responseBuilder.String()).
+		Str("model_response", tools.RedactSecrets(responseBuilder.String())).
 		Dur("duration_ms", duration).
 		Int("tool_calls", len(toolCallsToExecute)).
 		Msg("AI response received")
@@ -109,16 +162,28 @@ func streamConversation(session *chat.Session, input string, includeUserMessage
 		fmt.Println() // newline before tool execution
 
 		anyHandled := false
-		for _, event := range toolCallsToExecute {
+		for i, event := range toolCallsToExecute {
+			if ctx.Err() != nil {
+				skipToolCalls(session, toolCallsToExecute[i:])
+				break
+			}
 			if executeToolCall(session, event.ToolCall, sessionLogger) {
 				anyHandled = true
 			}
 		}
 
-		// Continue conversation with tool results if any tool call was handled
+		// Ctrl+C at an approval prompt denies the tool and ends the turn.
+		if ctx.Err() != nil {
+			fmt.Println("⟫ cancelled")
+			fmt.Println()
+			sessionLogger.Debug().Msg("Turn cancelled during tool execution")
+			return
+		}
+
+		// Continue conversation with tool results if any tool call was handled.
+		// The answer stays in the same assistant block, so the prefix is not repeated.
 		if anyHandled {
 			fmt.Println()
-			fmt.Print("⟫ ")
 			streamConversation(session, "", false, sessionLogger, canceler)
 		} else {
 			fmt.Println()
@@ -128,6 +193,9 @@ func streamConversation(session *chat.Session, input string, includeUserMessage
 		// No tool calls, conversation complete
 		fmt.Println() // newline after response
 		fmt.Println()
+		if session.Config != nil && session.Config.SaveCodeBlocks {
+			offerReplyCodeBlocks(session.ToolRegistry, responseBuilder.String())
+		}
 	}
 }
 
@@ -165,7 +233,7 @@ func executeToolCall(session *chat.Session, toolCall *openai.ToolCall, logger ze
 	logger.Debug().
 		Str("tool_name", toolName).
 		Str("tool_call_id", toolCallID).
-		Str("tool_args", toolArgs).
+		Str("tool_args", tools.RedactSecrets(toolArgs)).
 		Msg("Executing tool")
 
 	// Execute the tool with approval handling
@@ -204,12 +272,45 @@ func executeToolCall(session *chat.Session, toolCall *openai.ToolCall, logger ze
 	return true
 }
 
+// skipToolCalls records a result for tool calls left unrun by a cancelled turn,
+// since every tool call must be answered before the conversation can go on.
+func skipToolCalls(session *chat.Session, events []*chat.StreamEvent) {
+	for _, event := range events {
+		session.AddToolResultMessage(*event.ToolCall, &tools.ToolResult{
+			Function: toolCallName(*event.ToolCall),
+			Error:    fmt.Errorf("%w: turn cancelled", tools.ErrToolDeniedByUser),
+		})
+	}
+}
+
+// clearLine returns the cursor to column zero and erases the line.
+const clearLine = "\r\033[K"
+
+// formatToolProgress renders the live indicator for a tool call whose arguments are still streaming.
+func formatToolProgress(name string, argBytes int) string {
+	if name == "" {
+		name = "tool"
+	}
+	return fmt.Sprintf("🔧 Tool building: %s (%s args…)", name, formatByteCount(argBytes))
+}
+
+func formatByteCount(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	}
+}
+
 func logToolCall(logger zerolog.Logger, name, args, callID string) {
 	logger.Info().
 		Str("role", "tool_call").
 		Str("tool", name).
 		Str("tool_call_id", callID).
-		Str("args", args).
+		Str("args", tools.RedactSecrets(args)).
 		Msg("conversation")
 }
 
@@ -219,7 +320,7 @@ func logConversation(logger zerolog.Logger, role, content string) {
 	}
 	logger.Info().
 		Str("role", role).
-		Str("content", content).
+		Str("content", tools.RedactSecrets(content)).
 		Msg("conversation")
 }
 
@@ -17,6 +17,7 @@
 package main
 
 import (
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -345,3 +346,61 @@ func containsRecursive(s, substr string) bool {
 	}
 	return containsRecursive(s[1:], substr)
 }
+
+func TestFormatToolProgress(t *testing.T) {
+	tests := []struct {
+		name     string
+		argBytes int
+		want     string
+	}{
+		{"create_file", 512, "🔧 Tool building: create_file (512 B args…)"},
+		{"edit_file", 1229, "🔧 Tool building: edit_file (1.2 KB args…)"},
+		{"", 3 * 1024 * 1024, "🔧 Tool building: tool (3.0 MB args…)"},
+	}
+	for _, tt := range tests {
+		if got := formatToolProgress(tt.name, tt.argBytes); got != tt.want {
+			t.Errorf("formatToolProgress(%q, %d) = %q, want %q", tt.name, tt.argBytes, got, tt.want)
+		}
+	}
+}
+
+// captureStdout returns what fn prints to standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		done <- string(data)
+	}()
+	fn()
+	writer.Close()
+	return <-done
+}
+
+func TestConversationPrintsPrefixOncePerTurn(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) { return true, nil }
+
+	output := captureStdout(t, func() {
+		handleConversation(`tool: get_current_datetime {}`, session, zerolog.Nop(), nil)
+	})
+
+	if count := strings.Count(output, "⟫"); count != 1 {
+		t.Fatalf("expected one assistant prefix per turn, got %d in %q", count, output)
+	}
+	prefix := strings.Index(output, "⟫ ")
+	call := strings.Index(output, "🔧 [get_current_datetime]")
+	answer := strings.Index(output, "Tool result:")
+	if prefix != 0 || call < prefix || answer < call {
+		t.Fatalf("expected prefix, tool call and answer in order, got %q", output)
+	}
+}
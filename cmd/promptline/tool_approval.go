@@ -18,16 +18,22 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/sashabaranov/go-openai"
 	"golang.org/x/term"
 	"promptline/internal/chat"
+	"promptline/internal/config"
+	"promptline/internal/tools"
 )
 
 type approvalDecision int
@@ -41,8 +47,18 @@ const (
 
 type toolPromptFunc func(call openai.ToolCall) (approvalDecision, error)
 
-func newToolApprover() chat.ToolApprovalFunc {
-	return newToolApproverWithPrompt(promptToolApproval)
+// newToolApprover prompts on the terminal; a positive timeout denies the tool
+// when no answer arrives in time, and cancelling the running operation through
+// canceler (Ctrl+C) denies it at once. Tools in registry that provide a
+// confirmation summary have it shown above the prompt.
+func newToolApprover(timeout time.Duration, registry *tools.Registry, preview config.ApprovalPreview, canceler *operationCanceler) chat.ToolApprovalFunc {
+	return newToolApproverWithPrompt(func(call openai.ToolCall) (approvalDecision, error) {
+		summary := ""
+		if registry != nil {
+			summary = registry.ConfirmSummary(call.Function.Name, call.Function.Arguments)
+		}
+		return promptToolApproval(call, summary, preview, timeout, canceler.Done())
+	})
 }
 
 func newToolApproverWithPrompt(prompt toolPromptFunc) chat.ToolApprovalFunc {
@@ -71,44 +87,183 @@ func newToolApproverWithPrompt(prompt toolPromptFunc) chat.ToolApprovalFunc {
 	}
 }
 
-func promptToolApproval(call openai.ToolCall) (approvalDecision, error) {
-	input := os.Stdin
+func promptToolApproval(call openai.ToolCall, summary string, preview config.ApprovalPreview, timeout time.Duration, cancelled <-chan struct{}) (approvalDecision, error) {
+	input := io.Reader(os.Stdin)
 	output := io.Writer(os.Stdout)
-	if !term.IsTerminal(int(os.Stdin.Fd())) {
+	// A timed or cancellable prompt reads from its own /dev/tty handle so closing
+	// it unblocks the pending read without touching stdin. Without /dev/tty it
+	// reads stdin through a stoppable reader, so a read left after the prompt
+	// gave up cannot take the next line typed.
+	var closer io.Closer
+	stdinIsTerminal := term.IsTerminal(int(os.Stdin.Fd()))
+	if timeout > 0 || cancelled != nil || !stdinIsTerminal {
 		if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
 			input = tty
 			output = tty
+			closer = tty
 			defer tty.Close()
-		} else {
+		} else if !stdinIsTerminal {
 			return approvalNo, fmt.Errorf("no TTY available for tool approval")
+		} else {
+			stdin := newStoppableReader(os.Stdin)
+			input = stdin
+			closer = stdin
 		}
 	}
-	reader := bufio.NewReader(input)
+	if summary != "" {
+		fmt.Fprintln(output, summary)
+	}
+	return readApprovalDecision(input, closer, output, approvalPrompt(call, summary, preview), timeout, cancelled)
+}
+
+// approvalPrompt builds the question line. When a confirmation summary is shown
+// above it, the raw arguments are left out since the summary covers them.
+func approvalPrompt(call openai.ToolCall, summary string, preview config.ApprovalPreview) string {
 	name := toolCallName(call)
 	rawArgs := strings.TrimSpace(call.Function.Arguments)
-	argsDisplay := ""
-	if rawArgs != "" && rawArgs != "{}" && rawArgs != "null" {
-		argsDisplay = fmt.Sprintf(" with args %s", rawArgs)
-		if argsMap, ok := parseArgsJSON(rawArgs); ok {
-			delete(argsMap, "content")
-			if redacted, err := json.Marshal(argsMap); err == nil {
-				argsDisplay = fmt.Sprintf(" with args %s", string(redacted))
-			}
+	if summary != "" || rawArgs == "" || rawArgs == "{}" || rawArgs == "null" {
+		return fmt.Sprintf("Allow tool %s? (Yes/no/always): ", name)
+	}
+	args := formatArgsPreview(rawArgs, preview)
+	if strings.Contains(args, "\n") {
+		return fmt.Sprintf("Args for %s:\n%s\nAllow tool %s? (Yes/no/always): ", name, args, name)
+	}
+	return fmt.Sprintf("Allow tool %s with args %s? (Yes/no/always): ", name, args)
+}
+
+const (
+	// argPreviewMinField is the shortest a string argument is cut to.
+	argPreviewMinField = 16
+	// argPreviewMaxItems caps array elements shown once truncation kicks in.
+	argPreviewMaxItems = 5
+)
+
+// formatArgsPreview renders tool arguments for an approval prompt. File
+// content is left out. Arguments over preview.MaxChars are shortened field by
+// field, halving the room for each string until the JSON fits, so the preview
+// keeps its shape; unparseable arguments are cut as plain text.
+func formatArgsPreview(rawArgs string, preview config.ApprovalPreview) string {
+	argsMap, ok := parseArgsJSON(rawArgs)
+	if !ok {
+		return truncateRunes(rawArgs, preview.MaxChars)
+	}
+	delete(argsMap, "content")
+	text := marshalArgsPreview(argsMap, preview.Pretty)
+	if preview.MaxChars <= 0 || utf8.RuneCountInString(text) <= preview.MaxChars {
+		return text
+	}
+	for limit := preview.MaxChars / 2; limit >= argPreviewMinField; limit /= 2 {
+		text = marshalArgsPreview(truncateArgValue(argsMap, limit), preview.Pretty)
+		if utf8.RuneCountInString(text) <= preview.MaxChars {
+			return text
 		}
 	}
+	return truncateRunes(text, preview.MaxChars)
+}
 
-	for {
-		fmt.Fprintf(output, "Allow tool %s%s? (Yes/no/always): ", name, argsDisplay)
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			return approvalNo, err
+func marshalArgsPreview(args interface{}, pretty bool) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(args); err != nil {
+		return fmt.Sprint(args)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// truncateArgValue shortens every string in value to limit runes and long
+// arrays to argPreviewMaxItems elements.
+func truncateArgValue(value interface{}, limit int) interface{} {
+	switch v := value.(type) {
+	case string:
+		return truncateRunes(v, limit)
+	case []interface{}:
+		items := v
+		if len(items) > argPreviewMaxItems {
+			items = items[:argPreviewMaxItems]
+		}
+		out := make([]interface{}, 0, len(items)+1)
+		for _, item := range items {
+			out = append(out, truncateArgValue(item, limit))
 		}
-		decision := parseApprovalInput(line)
-		switch decision {
-		case approvalYes, approvalNo, approvalAlways:
-			return decision, nil
-		default:
-			fmt.Fprintln(output, "Please enter yes, no, or always.")
+		if len(v) > len(items) {
+			out = append(out, fmt.Sprintf("… %d more", len(v)-len(items)))
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = truncateArgValue(item, limit)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+// truncateRunes cuts s to at most limit runes, ending with "…" when shortened.
+// A non-positive limit keeps s whole.
+func truncateRunes(s string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:limit-1]) + "…"
+}
+
+// readApprovalDecision asks until it gets a valid answer. With a positive timeout
+// it denies once the deadline passes, and it denies with context.Canceled when
+// cancelled is closed. Either way the notice is written to output first, and
+// then the pending read is stopped by closing closer, which must be a handle
+// the caller opened for the prompt, such as /dev/tty or a stoppable reader of
+// stdin; with a nil closer the read is abandoned and input stays open.
+func readApprovalDecision(input io.Reader, closer io.Closer, output io.Writer, prompt string, timeout time.Duration, cancelled <-chan struct{}) (approvalDecision, error) {
+	type readResult struct {
+		line string
+		err  error
+	}
+	reader := bufio.NewReader(input)
+	lines := make(chan readResult, 1)
+	readLine := func() {
+		line, err := reader.ReadString('\n')
+		lines <- readResult{line: line, err: err}
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		deadline = time.After(timeout)
+	}
+	for {
+		fmt.Fprint(output, prompt)
+		go readLine()
+		select {
+		case result := <-lines:
+			if result.err != nil {
+				return approvalNo, result.err
+			}
+			decision := parseApprovalInput(result.line)
+			switch decision {
+			case approvalYes, approvalNo, approvalAlways:
+				return decision, nil
+			default:
+				fmt.Fprintln(output, "Please enter yes, no, or always.")
+			}
+		case <-deadline:
+			// output may be the handle closer closes, so the notice goes first.
+			fmt.Fprintf(output, "\nNo answer within %s, denying.\n", timeout)
+			if closer != nil {
+				_ = closer.Close()
+			}
+			return approvalNo, tools.ErrToolApprovalTimeout
+		case <-cancelled:
+			fmt.Fprintln(output, "Approval cancelled, denying.")
+			if closer != nil {
+				_ = closer.Close()
+			}
+			return approvalNo, context.Canceled
 		}
 	}
 }
@@ -17,9 +17,20 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+	"promptline/internal/tools"
 )
 
 func TestParseApprovalInput(t *testing.T) {
@@ -104,3 +115,218 @@ func TestToolApproverAlwaysPersists(t *testing.T) {
 		t.Fatalf("expected prompt count 2, got %d", prompts)
 	}
 }
+
+func TestReadApprovalDecisionRepromptsOnInvalidInput(t *testing.T) {
+	var output bytes.Buffer
+	decision, err := readApprovalDecision(strings.NewReader("maybe\nalways\n"), nil, &output, "Allow? ", 0, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decision != approvalAlways {
+		t.Fatalf("expected always, got %v", decision)
+	}
+	if strings.Count(output.String(), "Allow? ") != 2 {
+		t.Errorf("expected two prompts, got %q", output.String())
+	}
+}
+
+func TestReadApprovalDecisionTimesOut(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	var output bytes.Buffer
+	decision, err := readApprovalDecision(reader, reader, &output, "Allow? ", 20*time.Millisecond, nil)
+	if !errors.Is(err, tools.ErrToolApprovalTimeout) {
+		t.Fatalf("expected approval timeout error, got %v", err)
+	}
+	if decision != approvalNo {
+		t.Fatalf("expected timeout to deny, got %v", decision)
+	}
+	if !strings.Contains(output.String(), "denying") {
+		t.Errorf("expected timeout notice, got %q", output.String())
+	}
+	if _, err := writer.Write([]byte("yes\n")); err == nil {
+		t.Error("expected input to be closed after timeout")
+	}
+}
+
+func TestReadApprovalDecisionTimeoutKeepsUnownedInput(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	var output bytes.Buffer
+	if _, err := readApprovalDecision(reader, nil, &output, "Allow? ", 20*time.Millisecond, nil); !errors.Is(err, tools.ErrToolApprovalTimeout) {
+		t.Fatalf("expected approval timeout error, got %v", err)
+	}
+	written := make(chan error, 1)
+	go func() {
+		_, err := writer.Write([]byte("yes\n"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Errorf("expected input to stay open without a closer, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the abandoned read to consume the input")
+	}
+}
+
+func TestReadApprovalDecisionCancelled(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	canceler := &operationCanceler{}
+	canceler.Set(func() {})
+	defer canceler.Clear()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		canceler.Cancel()
+	}()
+
+	var output bytes.Buffer
+	decision, err := readApprovalDecision(reader, reader, &output, "Allow? ", time.Minute, canceler.Done())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	if decision != approvalNo {
+		t.Fatalf("expected cancellation to deny, got %v", decision)
+	}
+	if !strings.Contains(output.String(), "Approval cancelled") {
+		t.Errorf("expected cancellation notice, got %q", output.String())
+	}
+	if _, err := writer.Write([]byte("yes\n")); err == nil {
+		t.Error("expected input to be closed after cancellation")
+	}
+}
+
+func TestReadApprovalDecisionCancelKeepsUnownedInput(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	cancelled := make(chan struct{})
+	close(cancelled)
+	var output bytes.Buffer
+	if _, err := readApprovalDecision(reader, nil, &output, "Allow? ", 0, cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation error, got %v", err)
+	}
+	written := make(chan error, 1)
+	go func() {
+		_, err := writer.Write([]byte("yes\n"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Errorf("expected input to stay open without a closer, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected the abandoned read to consume the input")
+	}
+}
+
+// ttyStub stands in for the /dev/tty handle: one handle for input and
+// output that rejects writes once closed.
+type ttyStub struct {
+	*io.PipeReader
+	mu     sync.Mutex
+	out    bytes.Buffer
+	closed bool
+}
+
+func (s *ttyStub) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, os.ErrClosed
+	}
+	return s.out.Write(p)
+}
+
+func (s *ttyStub) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	return s.PipeReader.Close()
+}
+
+func TestReadApprovalDecisionNoticeReachesTTY(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		timeout time.Duration
+		cancel  bool
+		notice  string
+	}{
+		{name: "timeout", timeout: 20 * time.Millisecond, notice: "No answer within 20ms, denying."},
+		{name: "cancel", cancel: true, notice: "Approval cancelled, denying."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reader, writer := io.Pipe()
+			defer writer.Close()
+			tty := &ttyStub{PipeReader: reader}
+			var cancelled chan struct{}
+			if tc.cancel {
+				cancelled = make(chan struct{})
+				close(cancelled)
+			}
+			if _, err := readApprovalDecision(tty, tty, tty, "Allow? ", tc.timeout, cancelled); err == nil {
+				t.Fatal("expected the prompt to deny")
+			}
+			if !strings.Contains(tty.out.String(), tc.notice) {
+				t.Fatalf("expected %q on the terminal, got %q", tc.notice, tty.out.String())
+			}
+		})
+	}
+}
+
+func TestApprovalPromptWithSummary(t *testing.T) {
+	call := openai.ToolCall{Function: openai.FunctionCall{Name: "rm", Arguments: `{"path":"a.txt"}`}}
+
+	plain := approvalPrompt(call, "", config.ApprovalPreview{MaxChars: 400})
+	if plain != `Allow tool rm with args {"path":"a.txt"}? (Yes/no/always): ` {
+		t.Fatalf("unexpected plain prompt: %q", plain)
+	}
+	withSummary := approvalPrompt(call, "rm will delete 1 file from 1 target(s):\n  /tmp/a.txt", config.ApprovalPreview{})
+	if withSummary != "Allow tool rm? (Yes/no/always): " {
+		t.Fatalf("expected summary to replace args, got %q", withSummary)
+	}
+}
+
+func TestFormatArgsPreview(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	raw := `{"path":"a.txt","content":"secret","pattern":"` + long + `","items":[1,2,3,4,5,6,7]}`
+
+	full := formatArgsPreview(raw, config.ApprovalPreview{})
+	if strings.Contains(full, "secret") || !strings.Contains(full, long) {
+		t.Fatalf("expected content dropped and no limit, got %q", full)
+	}
+
+	short := formatArgsPreview(raw, config.ApprovalPreview{MaxChars: 120})
+	if len([]rune(short)) > 120 {
+		t.Fatalf("expected at most 120 chars, got %d: %q", len([]rune(short)), short)
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(short), &parsed); err != nil {
+		t.Fatalf("expected truncated preview to stay valid JSON, got %q: %v", short, err)
+	}
+	if parsed["path"] != "a.txt" || !strings.HasSuffix(parsed["pattern"].(string), "…") {
+		t.Fatalf("expected per-field truncation, got %v", parsed)
+	}
+	if items := parsed["items"].([]interface{}); len(items) != argPreviewMaxItems+1 || items[argPreviewMaxItems] != "… 2 more" {
+		t.Fatalf("expected long array to be shortened, got %v", items)
+	}
+
+	pretty := formatArgsPreview(`{"path":"a.txt","mode":"755"}`, config.ApprovalPreview{MaxChars: 400, Pretty: true})
+	if pretty != "{\n  \"mode\": \"755\",\n  \"path\": \"a.txt\"\n}" {
+		t.Fatalf("unexpected pretty preview: %q", pretty)
+	}
+	prompt := approvalPrompt(openai.ToolCall{Function: openai.FunctionCall{Name: "chmod", Arguments: `{"path":"a.txt"}`}}, "", config.ApprovalPreview{Pretty: true})
+	if prompt != "Args for chmod:\n{\n  \"path\": \"a.txt\"\n}\nAllow tool chmod? (Yes/no/always): " {
+		t.Fatalf("unexpected pretty prompt: %q", prompt)
+	}
+
+	if raw := formatArgsPreview("not json "+long, config.ApprovalPreview{MaxChars: 50}); len([]rune(raw)) != 50 || !strings.HasSuffix(raw, "…") {
+		t.Fatalf("expected raw fallback cut to 50 chars, got %q", raw)
+	}
+}
@@ -17,10 +17,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
+	"sync/atomic"
 
 	"github.com/chzyer/readline"
 	"github.com/rs/zerolog"
@@ -28,23 +31,42 @@ import (
 	"promptline/internal/config"
 )
 
+const (
+	defaultBanner    = "Promptline by Dyne.org"
+	emptySessionHint = "Try /help for commands, /paste to send the clipboard, /quit to exit"
+)
+
 func runTUIMode(logger zerolog.Logger) {
 	logger.Debug().Msg("Running in streaming console mode")
 
-	// Load configuration
-	cfg, err := config.LoadConfig("config.json")
+	// Load configuration and create chat session
+	session, err := newProviderSession(*provider)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("Failed to load config")
+		// Logs are off by default, so say what went wrong on the terminal too.
+		logger.Error().Err(err).Msg("Failed to load config")
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
-
-	// Create chat session
-	session := chat.NewSession(cfg)
+	cfg := session.Config
 	defer session.Close()
-	session.ToolApprover = newToolApprover()
+	canceler := &operationCanceler{}
+	session.ToolApprover = newToolApprover(session.Config.ToolApprovalTimeout(), session.ToolRegistry, session.Config.ApprovalPreview, canceler)
 	session.Logger = &logger
 	session.DryRun = *dryRun
+	session.ToolsDisabled = *noTools
+	if cfg.EventSocket != "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sock, err := chat.ListenEventSocket(ctx, cfg.EventSocket)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Event socket disabled")
+			fmt.Printf("✗ %v\n", err)
+		} else {
+			session.StreamSink = sock
+			defer sock.Close()
+		}
+	}
 
-	canceler := &operationCanceler{}
 	interrupts := make(chan os.Signal, 1)
 	signal.Notify(interrupts, os.Interrupt)
 	defer signal.Stop(interrupts)
@@ -56,36 +78,115 @@ func runTUIMode(logger zerolog.Logger) {
 		}
 	}()
 
+	bindings, err := cfg.KeyBindings()
+	if err != nil {
+		logger.Warn().Err(err).Msg("Invalid keybindings, using defaults")
+		bindings, _ = (&config.Config{}).KeyBindings()
+	}
+	keys := newKeyTranslator(bindings)
+
+	// With idle_timeout_minutes set, a prompt left waiting either quits, like
+	// Ctrl+D, or hides the conversation until a key is pressed.
+	var rl *readline.Instance
+	var idleQuit, locked atomic.Bool
+	idle := newIdleWatch(cfg.IdleTimeout(), func() {
+		if cfg.IdleActionMode() == config.IdleActionLock {
+			locked.Store(true)
+			rl.Clean()
+			fmt.Printf("%s🔒 Locked after %s without input, press any key to resume\n", clearScreen, cfg.IdleTimeout())
+			return
+		}
+		idleQuit.Store(true)
+		_ = rl.Close()
+	})
+	defer idle.disarm()
+	filterInput := func(r rune) (rune, bool) {
+		if locked.CompareAndSwap(true, false) {
+			// The key that unlocks is not typed into the prompt.
+			fmt.Print(clearScreen)
+			fmt.Println("Unlocked; /history shows the conversation")
+			idle.arm()
+			return 0, false
+		}
+		idle.touch()
+		return keys.filter(r)
+	}
+
 	// Initialize readline with dynamic command completion and Ctrl-R handler
-	rl, err := readline.NewEx(&readline.Config{
-		Prompt:          "❯ ",
-		HistoryFile:     cfg.CommandHistoryFile,
-		AutoComplete:    getCommandCompleter(),
-		InterruptPrompt: "\n",
-		EOFPrompt:       "",
-		FuncFilterInputRune: func(r rune) (rune, bool) {
-			return filterInterruptRune(r)
-		},
+	rl, err = readline.NewEx(&readline.Config{
+		Prompt:              "❯ ",
+		HistoryFile:         cfg.CommandHistoryFile,
+		AutoComplete:        getCommandCompleter(),
+		InterruptPrompt:     "\n",
+		EOFPrompt:           "",
+		FuncFilterInputRune: filterInput,
 	})
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to initialize readline")
 	}
 	defer rl.Close()
 
-	// Display header
-	fmt.Println("Promptline by Dyne.org")
-	fmt.Printf("Connected to: %s\n", session.BaseURL)
-	fmt.Printf("Model in use: %s\n", session.Config.Model)
-	// fmt.Println("Type /help for commands, /quit to exit")
-	// fmt.Println("Press Ctrl+R to search conversation history")
-	fmt.Println()
+	resumed := 0
+	if *resume || cfg.ResumeOnStartup {
+		if resumed, err = resumeConversation(session); err != nil {
+			logger.Warn().Err(err).Msg("Failed to resume conversation")
+			fmt.Printf("✗ Could not resume the conversation: %v\n", err)
+		}
+	}
+	printHeader(os.Stdout, session)
+	if resumed > 0 {
+		printResumeRecap(os.Stdout, session, resumed)
+	}
+	if err := runPreflight(os.Stdout, session); err != nil {
+		logger.Warn().Err(err).Msg("Preflight check failed")
+		fmt.Println("  Requests will likely fail; fix config.json or set preflight_check to false to skip this check.")
+		fmt.Println()
+	}
+
+	// ask reads an answer at the prompt without adding it to the input
+	// history; Ctrl+C or Ctrl+D reports no answer.
+	ask := func(prompt string) (string, bool) {
+		rl.SetPrompt(prompt)
+		rl.HistoryDisable()
+		defer func() {
+			rl.HistoryEnable()
+			rl.SetPrompt("❯ ")
+		}()
+		answer, err := rl.Readline()
+		return answer, err == nil
+	}
+	codeBlockAsk = ask
+	defer func() { codeBlockAsk = nil }()
+	// confirm asks at the prompt what to do with unsaved messages before
+	// /quit or Ctrl+D; Ctrl+C or Ctrl+D at the question cancels.
+	confirm := func() bool {
+		return confirmQuit(os.Stdout, session, logger, ask)
+	}
+	inputs := loadInputHistory(cfg.CommandHistoryFile)
 
-	// Track debug mode for commands
-	debugMode := false
+	// editing is set while the prompt holds the message loaded by /edit.
+	editing := false
+	editDraft := ""
+	// recalled is an earlier input picked with /history <text>.
+	recalled := ""
 
 	// Main event loop
 	for {
-		line, err := rl.Readline()
+		var line string
+		var err error
+		idle.arm()
+		switch {
+		case editing:
+			line, err = rl.ReadlineWithDefault(editDraft)
+		case recalled != "":
+			line, err = rl.ReadlineWithDefault(recalled)
+			recalled = ""
+		default:
+			line, err = rl.Readline()
+		}
+		idle.disarm()
+		wasEditing := editing
+		editing = false
 		if err != nil {
 			action := classifyReadlineError(line, err)
 			switch action {
@@ -94,6 +195,9 @@ func runTUIMode(logger zerolog.Logger) {
 				continue
 			case readlineExit:
 				fmt.Println()
+				if !confirmExit(os.Stdout, session, logger, idleQuit.Load(), ask) {
+					continue
+				}
 				goto done
 			default:
 				logger.Debug().Err(err).Msg("Readline interrupted")
@@ -104,29 +208,83 @@ func runTUIMode(logger zerolog.Logger) {
 		line = sanitizeInputLine(line)
 		line = strings.TrimSpace(line)
 		if line == "" {
+			if wasEditing {
+				fmt.Println("Edit cancelled")
+			}
 			continue
 		}
 
 		logger.Info().Str("user_input", line).Msg("User input received")
+		inputs.add(line)
+
+		// /edit hands the last message to the line editor, so it is handled
+		// here rather than in handleCommand.
+		if name, _, _ := strings.Cut(strings.TrimPrefix(line, "/"), " "); strings.HasPrefix(line, "/") && strings.EqualFold(name, "edit") {
+			editDraft, editing = startEdit(os.Stdout, session)
+			continue
+		}
+		// /history <text> also fills the line editor.
+		if isHistorySearch(line) {
+			_, query, _ := strings.Cut(line, " ")
+			recalled, _ = recallInput(os.Stdout, inputs, strings.TrimSpace(query), ask)
+			continue
+		}
 
 		// Handle slash commands
 		if strings.HasPrefix(line, "/") {
-			if handleCommand(line, session, logger, &debugMode) {
+			if handleCommand(line, session, logger, debugMode, canceler) {
 				// /quit was called
+				if !confirm() {
+					continue
+				}
 				break
 			}
 			continue
 		}
 
+		// Expand ::name snippet references client-side
+		line, err = expandSnippetRefs(line)
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			continue
+		}
+
 		// Handle conversation
+		if wasEditing {
+			resendEditedMessage(session, line, logger, canceler)
+			continue
+		}
 		handleConversation(line, session, logger, canceler)
 
 	}
 
 done:
+	if idleQuit.Load() {
+		fmt.Printf("Quit after %s without input\n", cfg.IdleTimeout())
+		saveIdleHistory(session, logger)
+	}
 	logger.Info().Msg("Session ended")
 }
 
+// printHeader shows the banner and connection details. A conversation that has
+// not started yet also gets the configured startup tip and a short hint.
+func printHeader(w io.Writer, session *chat.Session) {
+	banner := session.Config.Banner
+	if strings.TrimSpace(banner) == "" {
+		banner = defaultBanner
+	}
+	fmt.Fprintln(w, strings.TrimRight(banner, "\n"))
+	fmt.Fprintf(w, "Connected to: %s\n", session.BaseURL)
+	fmt.Fprintf(w, "Model in use: %s\n", session.Config.Model)
+	if len(session.GetHistory()) == 0 {
+		if tip := strings.TrimSpace(session.Config.StartupTip); tip != "" {
+			fmt.Fprintf(w, "Tip: %s\n", tip)
+		}
+		fmt.Fprintln(w, emptySessionHint)
+	}
+	fmt.Fprintln(w)
+}
+
 // getCommandCompleter builds a readline completer from available commands
 func getCommandCompleter() *readline.PrefixCompleter {
 	commands := getAvailableCommands()
@@ -0,0 +1,69 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"promptline/internal/chat"
+	"promptline/internal/config"
+)
+
+func TestPrintHeaderDefault(t *testing.T) {
+	session := chat.NewSessionWithClient(&config.Config{Model: "gpt-4o-mini"}, chat.NewEchoClient(chat.EchoModePlain))
+	var out bytes.Buffer
+	printHeader(&out, session)
+
+	text := out.String()
+	if !strings.HasPrefix(text, defaultBanner+"\n") {
+		t.Errorf("expected default banner, got %q", text)
+	}
+	if !strings.Contains(text, emptySessionHint) {
+		t.Errorf("expected hint for an empty session, got %q", text)
+	}
+	if strings.Contains(text, "Tip:") {
+		t.Errorf("expected no tip without configuration, got %q", text)
+	}
+}
+
+func TestPrintHeaderConfigured(t *testing.T) {
+	cfg := &config.Config{
+		Model:      "gpt-4o-mini",
+		Banner:     "My Assistant\nsecond line\n",
+		StartupTip: "use /context to check the window",
+	}
+	session := chat.NewSessionWithClient(cfg, chat.NewEchoClient(chat.EchoModePlain))
+	var out bytes.Buffer
+	printHeader(&out, session)
+
+	text := out.String()
+	if !strings.HasPrefix(text, "My Assistant\nsecond line\nConnected to:") {
+		t.Errorf("expected custom banner, got %q", text)
+	}
+	if !strings.Contains(text, "Tip: use /context to check the window\n") {
+		t.Errorf("expected startup tip, got %q", text)
+	}
+
+	session.AddMessage("user", "hello")
+	out.Reset()
+	printHeader(&out, session)
+	if strings.Contains(out.String(), "Tip:") || strings.Contains(out.String(), emptySessionHint) {
+		t.Errorf("expected no tip or hint once the conversation started, got %q", out.String())
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ErrDeniedByHook is returned when the approval hook exits with a non-zero status.
+var ErrDeniedByHook = errors.New("denied by approval hook")
+
+// approvalHookTimeout bounds how long a hook may take to decide.
+const approvalHookTimeout = 30 * time.Second
+
+// approvalHookRequest is the JSON document written to the hook's stdin.
+type approvalHookRequest struct {
+	Tool      string `json:"tool"`
+	Arguments any    `json:"arguments"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// approvalHook returns the configured approval_hook command, if any.
+func (s *Session) approvalHook() string {
+	if s.Config == nil {
+		return ""
+	}
+	return strings.TrimSpace(s.Config.ApprovalHook)
+}
+
+// runApprovalHook asks the approval hook about a tool call. The command is
+// split on whitespace and run without a shell; exit status 0 approves and any
+// other status denies, with the first line of its output as the reason.
+func (s *Session) runApprovalHook(hook string, call openai.ToolCall) (bool, error) {
+	fields := strings.Fields(hook)
+	if len(fields) == 0 {
+		return false, fmt.Errorf("approval hook is empty")
+	}
+	var args any = call.Function.Arguments
+	if json.Valid([]byte(call.Function.Arguments)) {
+		args = json.RawMessage(call.Function.Arguments)
+	}
+	input, err := json.Marshal(approvalHookRequest{
+		Tool:      call.Function.Name,
+		Arguments: args,
+		SessionID: s.SessionID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode approval request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), approvalHookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, fields[0], fields[1:]...)
+	cmd.Stdin = bytes.NewReader(input)
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return false, fmt.Errorf("approval hook timed out after %s", approvalHookTimeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		reason := firstLine(string(output))
+		if reason == "" {
+			reason = fmt.Sprintf("exit status %d", exitErr.ExitCode())
+		}
+		return false, fmt.Errorf("%w: %s", ErrDeniedByHook, reason)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to run approval hook: %w", err)
+	}
+	return true, nil
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Attachment is a file queued to be sent with the next user message.
+type Attachment struct {
+	Name      string
+	Content   string
+	Truncated bool // cut to fit the context window
+}
+
+// Attach queues a file for the next user message. When the configured context
+// window is known, content that would not fit next to the conversation and the
+// attachments already queued is truncated; Truncated reports it. An attachment
+// that does not fit at all is rejected with ErrContextFull.
+func (s *Session) Attach(name, content string) (Attachment, error) {
+	attachment := Attachment{Name: name, Content: content}
+	if budget, limited := s.attachmentBudget(); limited {
+		tokens := s.estimateTokens(formatAttachment(attachment))
+		if tokens > budget {
+			runes := []rune(content)
+			keep := len(runes) * budget / tokens
+			if keep <= 0 {
+				return Attachment{}, fmt.Errorf("%w: no room left for %s", ErrContextFull, name)
+			}
+			attachment.Content = string(runes[:keep])
+			attachment.Truncated = true
+		}
+	}
+
+	s.mu.Lock()
+	s.attachments = append(s.attachments, attachment)
+	s.mu.Unlock()
+	return attachment, nil
+}
+
+// PendingAttachments returns the attachments queued for the next user message.
+func (s *Session) PendingAttachments() []Attachment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Attachment(nil), s.attachments...)
+}
+
+// addUserMessage records a user message, prefixed by any pending attachments,
+// and starts a new tool trace.
+func (s *Session) addUserMessage(prompt string) {
+	s.injectReminder()
+
+	s.mu.Lock()
+	attachments := s.attachments
+	s.attachments = nil
+	s.turnTrace = nil
+	s.mu.Unlock()
+
+	if len(attachments) == 0 {
+		s.AddMessage(openai.ChatMessageRoleUser, prompt)
+		return
+	}
+	parts := make([]string, 0, len(attachments)+1)
+	for _, attachment := range attachments {
+		parts = append(parts, formatAttachment(attachment))
+	}
+	parts = append(parts, prompt)
+	s.AddMessage(openai.ChatMessageRoleUser, strings.Join(parts, "\n\n"))
+}
+
+// attachmentBudget returns how many tokens are left for new attachments, and
+// false when no context window is configured.
+func (s *Session) attachmentBudget() (int, bool) {
+	usage := s.ContextUsage()
+	if usage.Window <= 0 {
+		return 0, false
+	}
+	used := usage.Tokens
+	for _, attachment := range s.PendingAttachments() {
+		used += s.estimateTokens(formatAttachment(attachment))
+	}
+	return max(usage.Window-used, 0), true
+}
+
+func (s *Session) estimateTokens(content string) int {
+	estimator := s.TokenEstimator
+	if estimator == nil {
+		estimator = CharTokenEstimator{}
+	}
+	return estimator.EstimateTokens([]openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: content}})
+}
+
+func formatAttachment(attachment Attachment) string {
+	content := strings.TrimRight(attachment.Content, "\n")
+	return fmt.Sprintf("```file:%s\n%s\n```", attachment.Name, content)
+}
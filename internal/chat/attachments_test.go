@@ -0,0 +1,91 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+)
+
+func TestAttachmentsPrefixNextMessage(t *testing.T) {
+	session := newEchoSession(EchoModePlain)
+	if _, err := session.Attach("a.txt", "alpha\n"); err != nil {
+		t.Fatalf("attach a.txt: %v", err)
+	}
+	if _, err := session.Attach("b.txt", "beta"); err != nil {
+		t.Fatalf("attach b.txt: %v", err)
+	}
+	if got := len(session.PendingAttachments()); got != 2 {
+		t.Fatalf("expected 2 pending attachments, got %d", got)
+	}
+
+	collectStream(t, session, "compare them", true)
+
+	history := session.GetHistory()
+	want := "```file:a.txt\nalpha\n```\n\n```file:b.txt\nbeta\n```\n\ncompare them"
+	if history[0].Content != want {
+		t.Errorf("unexpected user message %q", history[0].Content)
+	}
+	if len(session.PendingAttachments()) != 0 {
+		t.Error("expected attachments to be consumed by the message")
+	}
+
+	collectStream(t, session, "again", true)
+	if history := session.GetHistory(); history[2].Content != "again" {
+		t.Errorf("expected plain follow-up message, got %q", history[2].Content)
+	}
+}
+
+func TestAttachTruncatesToContextWindow(t *testing.T) {
+	session := NewSessionWithClient(&config.Config{Model: "m"}, NewEchoClient(EchoModePlain))
+	session.TokenEstimator = runeEstimator{}
+	session.ClearHistory() // leaves only the system prompt
+	used := session.ContextUsage().Tokens
+	session.Config.ContextWindow = used + 100
+
+	attachment, err := session.Attach("big.txt", strings.Repeat("x", 400))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !attachment.Truncated || len(attachment.Content) >= 100 {
+		t.Fatalf("expected truncated attachment, got %d bytes (truncated=%v)", len(attachment.Content), attachment.Truncated)
+	}
+
+	if _, err := session.Attach("more.txt", strings.Repeat("y", 400)); !errors.Is(err, ErrContextFull) {
+		t.Fatalf("expected ErrContextFull once the window is used up, got %v", err)
+	}
+
+	session.ClearHistory()
+	if len(session.PendingAttachments()) != 0 {
+		t.Error("expected /clear to drop pending attachments")
+	}
+}
+
+// runeEstimator counts one token per rune, which keeps the budget math exact.
+type runeEstimator struct{}
+
+func (runeEstimator) EstimateTokens(messages []openai.ChatCompletionMessage) int {
+	total := 0
+	for _, msg := range messages {
+		total += len([]rune(msg.Content))
+	}
+	return total
+}
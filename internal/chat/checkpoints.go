@@ -0,0 +1,108 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// checkpoint is an in-memory snapshot of the conversation.
+type checkpoint struct {
+	messages   []openai.ChatCompletionMessage
+	savedCount int
+	pins       map[int]bool
+	saveCount  uint64
+	created    time.Time
+}
+
+// CheckpointInfo describes a named checkpoint.
+type CheckpointInfo struct {
+	Name     string
+	Messages int
+	Created  time.Time
+}
+
+// Checkpoint snapshots the current messages under name, replacing any checkpoint
+// with the same name. Checkpoints last for the lifetime of the session.
+func (s *Session) Checkpoint(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("checkpoint name is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checkpoints == nil {
+		s.checkpoints = make(map[string]checkpoint)
+	}
+	s.checkpoints[name] = checkpoint{
+		messages:   append([]openai.ChatCompletionMessage(nil), s.Messages...),
+		savedCount: s.lastSavedMsgCount,
+		pins:       maps.Clone(s.pinnedTurns),
+		saveCount:  s.saveCount,
+		created:    time.Now(),
+	}
+	return nil
+}
+
+// RestoreCheckpoint rolls the conversation back to the named checkpoint.
+func (s *Session) RestoreCheckpoint(name string) error {
+	name = strings.TrimSpace(name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cp, ok := s.checkpoints[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrCheckpointNotFound, name)
+	}
+	s.Messages = append([]openai.ChatCompletionMessage(nil), cp.messages...)
+	s.pinnedTurns = maps.Clone(cp.pins)
+
+	// The history file is append-only. Messages saved when the checkpoint was taken
+	// stay saved; any save since then also wrote the rest of the checkpoint's messages.
+	s.lastSavedMsgCount = cp.savedCount
+	if s.saveCount > cp.saveCount {
+		s.lastSavedMsgCount = len(s.Messages) - 1
+	}
+	s.trimHistoryLocked()
+	return nil
+}
+
+// Checkpoints lists the session's checkpoints, oldest first.
+func (s *Session) Checkpoints() []CheckpointInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]CheckpointInfo, 0, len(s.checkpoints))
+	for name, cp := range s.checkpoints {
+		infos = append(infos, CheckpointInfo{
+			Name:     name,
+			Messages: len(cp.messages) - 1,
+			Created:  cp.created,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].Created.Equal(infos[j].Created) {
+			return infos[i].Created.Before(infos[j].Created)
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
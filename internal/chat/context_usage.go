@@ -0,0 +1,139 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// tokensPerMessage approximates the framing each message adds (role, separators).
+const tokensPerMessage = 4
+
+// TokenEstimator estimates how many tokens a list of messages takes up in the
+// model's context window.
+type TokenEstimator interface {
+	EstimateTokens(messages []openai.ChatCompletionMessage) int
+}
+
+// CharTokenEstimator approximates one token per four characters, which is close
+// enough for English text and code to judge how full the context is.
+type CharTokenEstimator struct{}
+
+// EstimateTokens implements TokenEstimator.
+func (CharTokenEstimator) EstimateTokens(messages []openai.ChatCompletionMessage) int {
+	total := 0
+	for _, msg := range messages {
+		chars := utf8.RuneCountInString(msg.Content)
+		for _, part := range msg.MultiContent {
+			chars += utf8.RuneCountInString(part.Text)
+		}
+		for _, call := range msg.ToolCalls {
+			chars += utf8.RuneCountInString(call.Function.Name) + utf8.RuneCountInString(call.Function.Arguments)
+		}
+		total += (chars+3)/4 + tokensPerMessage
+	}
+	return total
+}
+
+// ContextUsage describes how much of the context window the conversation uses.
+type ContextUsage struct {
+	Messages int
+	Tokens   int // estimated
+	Window   int // context window of the active model, 0 when unknown
+}
+
+// Fraction returns the share of the context window in use, or 0 when the
+// window is unknown.
+func (u ContextUsage) Fraction() float64 {
+	if u.Window <= 0 {
+		return 0
+	}
+	return float64(u.Tokens) / float64(u.Window)
+}
+
+// ContextUsage estimates the size of the messages sent with the next request.
+func (s *Session) ContextUsage() ContextUsage {
+	messages := s.MessagesSnapshot()
+	estimator := s.TokenEstimator
+	if estimator == nil {
+		estimator = CharTokenEstimator{}
+	}
+	return ContextUsage{
+		Messages: len(messages),
+		Tokens:   estimator.EstimateTokens(messages),
+		Window:   s.ContextWindow(),
+	}
+}
+
+// ContextWindow returns the context window of the active model in tokens, or
+// 0 when it is unknown.
+func (s *Session) ContextWindow() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.contextWindowLocked()
+}
+
+func (s *Session) contextWindowLocked() int {
+	if s.Config == nil {
+		return 0
+	}
+	return s.Config.ModelContextWindow(s.Config.Model)
+}
+
+// SetModel switches the model used for the following requests. The context
+// window follows the model, so the history is trimmed again right away.
+func (s *Session) SetModel(model string) error {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return fmt.Errorf("model name is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Config.Model = model
+	s.trimHistoryLocked()
+	return nil
+}
+
+// tokenOverflowLocked returns how many of the oldest non-system messages to
+// drop so the conversation fits the context window of the active model,
+// leaving room for Config.MaxTokens of reply. It only applies when a
+// TokenEstimator is set, and it never drops the newest message or leaves tool
+// results without the call that requested them.
+func (s *Session) tokenOverflowLocked() int {
+	budget := s.contextWindowLocked()
+	if s.TokenEstimator == nil || budget <= 0 {
+		return 0
+	}
+	if s.Config.MaxTokens != nil && *s.Config.MaxTokens > 0 && *s.Config.MaxTokens < budget {
+		budget -= *s.Config.MaxTokens
+	}
+	total := s.TokenEstimator.EstimateTokens(s.Messages)
+	last := len(s.Messages) - 1
+	drop := 0
+	for total > budget && 1+drop < last {
+		total -= s.TokenEstimator.EstimateTokens(s.Messages[1+drop : 2+drop])
+		drop++
+	}
+	for drop > 0 && 1+drop < last && s.Messages[1+drop].Role == openai.ChatMessageRoleTool {
+		drop++
+	}
+	return drop
+}
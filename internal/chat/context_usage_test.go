@@ -0,0 +1,170 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+	"promptline/internal/tools"
+)
+
+type fixedEstimator int
+
+func (f fixedEstimator) EstimateTokens(messages []openai.ChatCompletionMessage) int {
+	return int(f) * len(messages)
+}
+
+func TestCharTokenEstimator(t *testing.T) {
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: strings.Repeat("a", 40)},
+		{Role: openai.ChatMessageRoleAssistant, ToolCalls: []openai.ToolCall{{
+			Function: openai.FunctionCall{Name: "cat", Arguments: `{"path":"a"}`},
+		}}},
+	}
+	// 40 chars -> 10 tokens, "cat" + 12 chars of args -> 4 tokens, plus framing.
+	want := 10 + 4 + 2*tokensPerMessage
+	if got := (CharTokenEstimator{}).EstimateTokens(messages); got != want {
+		t.Errorf("expected %d tokens, got %d", want, got)
+	}
+}
+
+func TestSessionContextUsage(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", ContextWindow: 1000}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+	session.AddMessage(openai.ChatMessageRoleUser, "hello")
+
+	usage := session.ContextUsage()
+	if usage.Messages != 2 {
+		t.Errorf("expected system and user messages, got %d", usage.Messages)
+	}
+	if usage.Tokens <= 0 || usage.Window != 1000 {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	session.TokenEstimator = fixedEstimator(250)
+	usage = session.ContextUsage()
+	if usage.Tokens != 500 || usage.Fraction() != 0.5 {
+		t.Errorf("expected custom estimator to be used, got %+v (%.2f)", usage, usage.Fraction())
+	}
+
+	if (ContextUsage{Tokens: 10}).Fraction() != 0 {
+		t.Error("expected zero fraction without a context window")
+	}
+}
+
+func TestTrimHistoryToTokenBudget(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+	session.TokenEstimator = CharTokenEstimator{}
+	// Leave room for about 60 tokens of conversation next to the system prompt.
+	cfg.ContextWindow = session.TokenEstimator.EstimateTokens(session.Messages[:1]) + 60
+
+	session.AddMessage(openai.ChatMessageRoleUser, "short question")
+	session.AddMessage(openai.ChatMessageRoleAssistant, strings.Repeat("long answer ", 40))
+	session.AddMessage(openai.ChatMessageRoleUser, "another short question")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "short answer")
+
+	history := session.GetHistory()
+	if len(history) != 2 || history[0].Content != "another short question" {
+		t.Fatalf("expected the large answer and everything before it to be trimmed, got %d messages", len(history))
+	}
+	if session.Messages[0].Role != openai.ChatMessageRoleSystem {
+		t.Fatal("expected the system prompt to be kept")
+	}
+	if usage := session.ContextUsage(); usage.Tokens > cfg.ContextWindow {
+		t.Fatalf("expected conversation within budget, got %d tokens", usage.Tokens)
+	}
+
+	// A single message over budget is kept; there is nothing older to drop.
+	session.AddMessage(openai.ChatMessageRoleUser, strings.Repeat("x", 1000))
+	history = session.GetHistory()
+	if len(history) != 1 || len(history[0].Content) != 1000 {
+		t.Fatalf("expected only the oversized newest message, got %d messages", len(history))
+	}
+}
+
+func TestSetModelAdjustsTrimming(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "big-model", ContextWindow: 100000}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+	session.TokenEstimator = fixedEstimator(10)
+	system := session.TokenEstimator.EstimateTokens(session.Messages[:1])
+	cfg.ModelContextWindows = map[string]int{"small": system + 20}
+
+	for i := 0; i < 4; i++ {
+		session.AddMessage(openai.ChatMessageRoleUser, fmt.Sprintf("message %d", i))
+	}
+	if got := len(session.GetHistory()); got != 4 {
+		t.Fatalf("expected the large window to keep all messages, got %d", got)
+	}
+	if window := session.ContextWindow(); window != 100000 {
+		t.Fatalf("expected context_window for an unlisted model, got %d", window)
+	}
+
+	if err := session.SetModel("small-v2"); err != nil {
+		t.Fatalf("SetModel failed: %v", err)
+	}
+	if window := session.ContextWindow(); window != system+20 {
+		t.Fatalf("expected the small model window, got %d", window)
+	}
+	history := session.GetHistory()
+	if len(history) != 2 || history[0].Content != "message 2" {
+		t.Fatalf("expected switching to the small model to trim to 2 messages, got %d", len(history))
+	}
+	if usage := session.ContextUsage(); usage.Window != system+20 {
+		t.Fatalf("expected /context to follow the model, got %+v", usage)
+	}
+
+	if err := session.SetModel(" "); err == nil {
+		t.Fatal("expected an empty model name to be rejected")
+	}
+	if cfg.Model != "small-v2" {
+		t.Fatalf("expected the model to stay, got %q", cfg.Model)
+	}
+}
+
+func TestTrimHistoryToTokenBudgetKeepsToolPairs(t *testing.T) {
+	maxTokens := 50
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", ContextWindow: 90, MaxTokens: &maxTokens}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+	session.TokenEstimator = fixedEstimator(10)
+
+	session.AddMessage(openai.ChatMessageRoleUser, "list files")
+	call := openai.ToolCall{ID: "call-1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "ls", Arguments: "{}"}}
+	session.AddAssistantMessage("", []openai.ToolCall{call})
+	session.AddToolResultMessage(call, &tools.ToolResult{Function: "ls", Result: "a.txt"})
+	session.AddMessage(openai.ChatMessageRoleAssistant, "one file")
+	session.AddMessage(openai.ChatMessageRoleUser, "thanks")
+
+	// Messages are 10 tokens each against a 40 token budget. The last message
+	// pushes out the tool call, and its result goes with it.
+	history := session.GetHistory()
+	if len(history) != 2 || history[0].Content != "one file" {
+		t.Fatalf("expected trimming past the tool result, got %+v", history)
+	}
+
+	session.TokenEstimator = nil
+	for i := 0; i < 10; i++ {
+		session.AddMessage(openai.ChatMessageRoleUser, "more")
+	}
+	if got := len(session.GetHistory()); got != 12 {
+		t.Fatalf("expected no token trimming without an estimator, got %d messages", got)
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// EchoMode selects how the echo client transforms the user's input.
+type EchoMode string
+
+const (
+	EchoModePlain   EchoMode = "echo"
+	EchoModeUpper   EchoMode = "upper"
+	EchoModeReverse EchoMode = "reverse"
+)
+
+// EchoToolTrigger is the prefix that makes the echo client answer with a tool call.
+// "tool: ls" requests the ls tool with default arguments, "tool: cat {"path":"a.txt"}"
+// passes explicit JSON arguments.
+const EchoToolTrigger = "tool:"
+
+// EchoBaseURL is reported as the endpoint of sessions backed by the echo client.
+const EchoBaseURL = "echo://local"
+
+const (
+	echoDefaultTool     = "ls"
+	echoDefaultToolArgs = `{"path":"."}`
+	echoChunkSize       = 8
+)
+
+// EchoClient is an offline ChatClient that answers with a transformed copy of the
+// last user message. It never touches the network, which makes it useful for UI
+// development, demos and CI. Streaming goes through a real openai stream backed by
+// an in-process transport, so the session code paths are the same as in production.
+type EchoClient struct {
+	// Mode selects the transformation applied to the input.
+	Mode EchoMode
+	// Template wraps the transformed input; "{input}" is replaced with it.
+	// An empty template returns the transformed input as-is.
+	Template string
+	// ChunkDelay is the pause between streamed chunks.
+	ChunkDelay time.Duration
+
+	stream    *openai.Client
+	callCount uint64
+}
+
+// NewEchoClient creates an echo client using the given mode.
+func NewEchoClient(mode EchoMode) *EchoClient {
+	if mode == "" {
+		mode = EchoModePlain
+	}
+	c := &EchoClient{Mode: mode}
+	clientConfig := openai.DefaultConfig("echo")
+	clientConfig.BaseURL = EchoBaseURL
+	clientConfig.HTTPClient = &http.Client{Transport: echoTransport{client: c}}
+	c.stream = openai.NewClientWithConfig(clientConfig)
+	return c
+}
+
+// ParseEchoMode maps a mode name to an EchoMode.
+func ParseEchoMode(name string) (EchoMode, error) {
+	switch EchoMode(strings.ToLower(strings.TrimSpace(name))) {
+	case "", EchoModePlain:
+		return EchoModePlain, nil
+	case EchoModeUpper:
+		return EchoModeUpper, nil
+	case EchoModeReverse:
+		return EchoModeReverse, nil
+	default:
+		return "", fmt.Errorf("unknown echo mode %q (use echo, upper or reverse)", name)
+	}
+}
+
+// CreateChatCompletion implements ChatClient.
+func (c *EchoClient) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	content, toolCall := c.reply(req)
+	message := openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleAssistant,
+		Content: content,
+	}
+	finish := openai.FinishReasonStop
+	if toolCall != nil {
+		message.ToolCalls = []openai.ToolCall{*toolCall}
+		finish = openai.FinishReasonToolCalls
+	}
+	return openai.ChatCompletionResponse{
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []openai.ChatCompletionChoice{{
+			Message:      message,
+			FinishReason: finish,
+		}},
+	}, nil
+}
+
+// CreateChatCompletionStream implements ChatClient.
+func (c *EchoClient) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	return c.stream.CreateChatCompletionStream(ctx, req)
+}
+
+// reply builds the answer for a request: either text or a single tool call.
+func (c *EchoClient) reply(req openai.ChatCompletionRequest) (string, *openai.ToolCall) {
+	if len(req.Messages) == 0 {
+		return c.render(""), nil
+	}
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role == openai.ChatMessageRoleTool {
+		return fmt.Sprintf("Tool result:\n%s", last.Content), nil
+	}
+
+	input := lastUserContent(req.Messages)
+	trimmed := strings.TrimSpace(input)
+	if strings.HasPrefix(strings.ToLower(trimmed), EchoToolTrigger) {
+		return "", c.toolCall(strings.TrimSpace(trimmed[len(EchoToolTrigger):]))
+	}
+	return c.render(input), nil
+}
+
+func (c *EchoClient) render(input string) string {
+	switch c.Mode {
+	case EchoModeUpper:
+		input = strings.ToUpper(input)
+	case EchoModeReverse:
+		runes := []rune(input)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		input = string(runes)
+	}
+	if c.Template == "" {
+		return input
+	}
+	return strings.ReplaceAll(c.Template, "{input}", input)
+}
+
+// toolCall parses "<name> [json args]" into a demo tool call.
+func (c *EchoClient) toolCall(spec string) *openai.ToolCall {
+	name, args := echoDefaultTool, echoDefaultToolArgs
+	if spec != "" {
+		parts := strings.SplitN(spec, " ", 2)
+		name = parts[0]
+		args = "{}"
+		if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+			args = strings.TrimSpace(parts[1])
+		}
+	}
+	id := atomic.AddUint64(&c.callCount, 1)
+	return &openai.ToolCall{
+		ID:   fmt.Sprintf("echo_call_%d", id),
+		Type: openai.ToolTypeFunction,
+		Function: openai.FunctionCall{
+			Name:      name,
+			Arguments: args,
+		},
+	}
+}
+
+func lastUserContent(messages []openai.ChatCompletionMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == openai.ChatMessageRoleUser {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// streamChunks splits a reply into the chunks a provider would stream.
+func (c *EchoClient) streamChunks(model string, content string, toolCall *openai.ToolCall) []openai.ChatCompletionStreamResponse {
+	chunk := func(delta openai.ChatCompletionStreamChoiceDelta, finish openai.FinishReason) openai.ChatCompletionStreamResponse {
+		return openai.ChatCompletionStreamResponse{
+			Object:  "chat.completion.chunk",
+			Created: time.Now().Unix(),
+			Model:   model,
+			Choices: []openai.ChatCompletionStreamChoice{{Delta: delta, FinishReason: finish}},
+		}
+	}
+
+	chunks := []openai.ChatCompletionStreamResponse{
+		chunk(openai.ChatCompletionStreamChoiceDelta{Role: openai.ChatMessageRoleAssistant}, ""),
+	}
+	if toolCall != nil {
+		index := 0
+		// Split the arguments so clients exercise fragment accumulation.
+		args := toolCall.Function.Arguments
+		half := len(args) / 2
+		chunks = append(chunks,
+			chunk(openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{{
+				Index:    &index,
+				ID:       toolCall.ID,
+				Type:     toolCall.Type,
+				Function: openai.FunctionCall{Name: toolCall.Function.Name, Arguments: args[:half]},
+			}}}, ""),
+			chunk(openai.ChatCompletionStreamChoiceDelta{ToolCalls: []openai.ToolCall{{
+				Index:    &index,
+				Function: openai.FunctionCall{Arguments: args[half:]},
+			}}}, ""),
+			chunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonToolCalls),
+		)
+		return chunks
+	}
+
+	runes := []rune(content)
+	for start := 0; start < len(runes); start += echoChunkSize {
+		end := min(start+echoChunkSize, len(runes))
+		chunks = append(chunks, chunk(openai.ChatCompletionStreamChoiceDelta{Content: string(runes[start:end])}, ""))
+	}
+	return append(chunks, chunk(openai.ChatCompletionStreamChoiceDelta{}, openai.FinishReasonStop))
+}
+
+// echoTransport serves chat completion streams from the echo client as SSE.
+type echoTransport struct {
+	client *EchoClient
+}
+
+func (t echoTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req openai.ChatCompletionRequest
+	if r.Body != nil {
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return nil, fmt.Errorf("echo client: decode request: %w", err)
+		}
+	}
+	content, toolCall := t.client.reply(req)
+	chunks := t.client.streamChunks(req.Model, content, toolCall)
+
+	reader, writer := io.Pipe()
+	go func() {
+		ctx := r.Context()
+		for i, chunk := range chunks {
+			if i > 0 && t.client.ChunkDelay > 0 {
+				select {
+				case <-ctx.Done():
+					writer.CloseWithError(ctx.Err())
+					return
+				case <-time.After(t.client.ChunkDelay):
+				}
+			}
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			var buf bytes.Buffer
+			buf.WriteString("data: ")
+			buf.Write(data)
+			buf.WriteString("\n\n")
+			if _, err := writer.Write(buf.Bytes()); err != nil {
+				return
+			}
+		}
+		_, _ = io.WriteString(writer, "data: [DONE]\n\n")
+		writer.Close()
+	}()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+		Body:       reader,
+		Request:    r,
+	}, nil
+}
+
+// Verify that EchoClient implements ChatClient at compile time.
+var _ ChatClient = (*EchoClient)(nil)
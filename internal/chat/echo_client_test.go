@@ -0,0 +1,199 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+)
+
+func newEchoSession(mode EchoMode) *Session {
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}
+	return NewSessionWithClient(cfg, NewEchoClient(mode))
+}
+
+func collectStream(t *testing.T, session *Session, prompt string, includeUserMessage bool) (string, []openai.ToolCall) {
+	t.Helper()
+	events := make(chan StreamEvent, 10)
+	go session.StreamResponseWithContext(context.Background(), prompt, includeUserMessage, events)
+
+	var content strings.Builder
+	var calls []openai.ToolCall
+	for event := range events {
+		switch event.Type {
+		case StreamEventContent:
+			content.WriteString(event.Content)
+		case StreamEventToolCall:
+			calls = append(calls, *event.ToolCall)
+		case StreamEventError:
+			t.Fatalf("unexpected stream error: %v", event.Err)
+		}
+	}
+	return content.String(), calls
+}
+
+func TestEchoClientModes(t *testing.T) {
+	tests := []struct {
+		mode     EchoMode
+		template string
+		want     string
+	}{
+		{EchoModePlain, "", "Hello, world"},
+		{EchoModeUpper, "", "HELLO, WORLD"},
+		{EchoModeReverse, "", "dlrow ,olleH"},
+		{EchoModePlain, "you said: {input}", "you said: Hello, world"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode)+tt.template, func(t *testing.T) {
+			session := newEchoSession(tt.mode)
+			session.Client.(*EchoClient).Template = tt.template
+
+			got, err := session.GetResponse("Hello, world")
+			if err != nil {
+				t.Fatalf("GetResponse failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEchoClientStreamsContent(t *testing.T) {
+	session := newEchoSession(EchoModeUpper)
+
+	content, calls := collectStream(t, session, "stream this reply in several chunks", true)
+	if content != "STREAM THIS REPLY IN SEVERAL CHUNKS" {
+		t.Errorf("unexpected streamed content %q", content)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected no tool calls, got %d", len(calls))
+	}
+
+	history := session.GetHistory()
+	last := history[len(history)-1]
+	if last.Role != openai.ChatMessageRoleAssistant || last.Content != content {
+		t.Errorf("expected assistant message with streamed content, got %+v", last)
+	}
+}
+
+func TestEchoClientStreamsToolCall(t *testing.T) {
+	session := newEchoSession(EchoModePlain)
+
+	_, calls := collectStream(t, session, `tool: cat {"path":"notes.txt"}`, true)
+	if len(calls) != 1 {
+		t.Fatalf("expected one tool call, got %d", len(calls))
+	}
+	if calls[0].Function.Name != "cat" {
+		t.Errorf("expected cat tool call, got %q", calls[0].Function.Name)
+	}
+	if calls[0].Function.Arguments != `{"path":"notes.txt"}` {
+		t.Errorf("expected reassembled arguments, got %q", calls[0].Function.Arguments)
+	}
+	if calls[0].ID == "" {
+		t.Error("expected tool call ID")
+	}
+}
+
+func TestEchoClientDefaultToolCall(t *testing.T) {
+	call := NewEchoClient("").toolCall("")
+	if call.Function.Name != echoDefaultTool || call.Function.Arguments != echoDefaultToolArgs {
+		t.Errorf("unexpected default tool call %+v", call.Function)
+	}
+}
+
+func TestEchoClientToolApprovalFlow(t *testing.T) {
+	session := newEchoSession(EchoModePlain)
+	var approved []string
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) {
+		approved = append(approved, call.Function.Name)
+		return true, nil
+	}
+
+	got, err := session.GetResponse("tool: get_current_datetime")
+	if err != nil {
+		t.Fatalf("GetResponse failed: %v", err)
+	}
+	if len(approved) != 1 || approved[0] != "get_current_datetime" {
+		t.Fatalf("expected approval request for get_current_datetime, got %v", approved)
+	}
+	if !strings.HasPrefix(got, "Tool result:\n") {
+		t.Errorf("expected tool result summary, got %q", got)
+	}
+}
+
+func TestParseEchoMode(t *testing.T) {
+	for name, want := range map[string]EchoMode{"": EchoModePlain, "echo": EchoModePlain, "Upper": EchoModeUpper, "reverse": EchoModeReverse} {
+		got, err := ParseEchoMode(name)
+		if err != nil || got != want {
+			t.Errorf("ParseEchoMode(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseEchoMode("shout"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestRespondWithoutStreaming(t *testing.T) {
+	session := newEchoSession(EchoModeUpper)
+	streaming := false
+	session.Config.Streaming = &streaming
+
+	respond := func(prompt string) ([]StreamEvent, []openai.ToolCall) {
+		events := make(chan StreamEvent, 10)
+		go session.RespondWithContext(context.Background(), prompt, true, events)
+		var contents []StreamEvent
+		var calls []openai.ToolCall
+		for event := range events {
+			switch event.Type {
+			case StreamEventContent:
+				contents = append(contents, event)
+			case StreamEventToolCall:
+				calls = append(calls, *event.ToolCall)
+			case StreamEventError:
+				t.Fatalf("unexpected error: %v", event.Err)
+			}
+		}
+		return contents, calls
+	}
+
+	contents, calls := respond("answer in one piece please")
+	if len(contents) != 1 || contents[0].Content != "ANSWER IN ONE PIECE PLEASE" {
+		t.Fatalf("expected a single content event, got %+v", contents)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no tool calls, got %d", len(calls))
+	}
+
+	contents, calls = respond(`tool: cat {"path":"notes.txt"}`)
+	if len(contents) != 0 {
+		t.Errorf("expected no content for a tool call, got %+v", contents)
+	}
+	if len(calls) != 1 || calls[0].Function.Name != "cat" || calls[0].Function.Arguments != `{"path":"notes.txt"}` {
+		t.Fatalf("expected cat tool call, got %+v", calls)
+	}
+	history := session.GetHistory()
+	last := history[len(history)-1]
+	if last.Role != openai.ChatMessageRoleAssistant || len(last.ToolCalls) != 1 {
+		t.Errorf("expected assistant message with the tool call, got %+v", last)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// isEmptyReply reports whether an assistant reply has neither text nor tool calls.
+func isEmptyReply(reply *openai.ChatCompletionMessage) bool {
+	return reply != nil && strings.TrimSpace(reply.Content) == "" && len(reply.MultiContent) == 0 && len(reply.ToolCalls) == 0
+}
+
+// retryEmptyReply decides whether an empty reply is requested again. When it
+// is, the empty assistant message is dropped from the history so the retry
+// answers the same conversation. After Config.EmptyReplyRetryCount attempts
+// the empty reply is kept and the caller reports ErrEmptyResponse.
+func (s *Session) retryEmptyReply(requestID string, attempt int) bool {
+	retries := s.Config.EmptyReplyRetryCount()
+	if logger := s.sessionLogger(); logger != nil {
+		logger.Debug().
+			Str("request_id", requestID).
+			Int("attempt", attempt+1).
+			Int("retries", retries).
+			Msg("Model returned an empty response")
+	}
+	if attempt >= retries {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last := len(s.Messages) - 1
+	if last > 0 && s.Messages[last].Role == openai.ChatMessageRoleAssistant && isEmptyReply(&s.Messages[last]) {
+		s.Messages = s.Messages[:last]
+		s.prunePinsLocked()
+		// lastSavedMsgCount counts messages after the system prompt. An empty
+		// reply that was already saved stays in the append-only history file.
+		if s.lastSavedMsgCount > last-1 {
+			s.lastSavedMsgCount = last - 1
+		}
+	}
+	return true
+}
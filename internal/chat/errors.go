@@ -17,11 +17,45 @@
 package chat
 
 import (
+	"errors"
 	"fmt"
 
 	apperrors "promptline/internal/errors"
 )
 
+// ErrCheckpointNotFound is returned when restoring a checkpoint name that was never created.
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// ErrNothingToRegenerate is returned when there is no assistant response to retry.
+var ErrNothingToRegenerate = errors.New("no response to regenerate")
+
+// ErrNothingToEdit is returned when there is no user message to edit.
+var ErrNothingToEdit = errors.New("no message to edit")
+
+// ErrNoSuchTurn is returned when pinning a turn the conversation does not have.
+var ErrNoSuchTurn = errors.New("no such turn")
+
+// ErrEmptyMessage is returned when an edited message has no text.
+var ErrEmptyMessage = errors.New("message is empty")
+
+// ErrStreamStalled is returned when a streamed reply sends no data for longer
+// than the configured stall timeout.
+var ErrStreamStalled = errors.New("stream stalled")
+
+// ErrContextFull is returned when an attachment does not fit in the context window.
+var ErrContextFull = errors.New("context window is full")
+
+// ErrEmptyResponse is returned when the model answers with neither text nor
+// tool calls, even after the configured retries.
+var ErrEmptyResponse = errors.New("model returned an empty response")
+
+// ErrModelsUnsupported is returned when the provider has no model listing endpoint.
+var ErrModelsUnsupported = errors.New("provider does not support listing models")
+
+// ErrHistoryLocked is returned when another process holds the history file lock
+// for longer than historyLockTimeout.
+var ErrHistoryLocked = errors.New("history file is locked by another process")
+
 // NewStreamError wraps a streaming operation error with a code and message.
 func NewStreamError(operation string, err error) *apperrors.Error {
 	return apperrors.Wrap(apperrors.CodeStream, fmt.Sprintf("streaming error during %s", operation), err)
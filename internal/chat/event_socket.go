@@ -0,0 +1,265 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// socketClientBuffer is how many lines a socket client may fall behind
+	// before it is disconnected; a client that misses content would render
+	// a wrong reply.
+	socketClientBuffer = 256
+	// socketWriteTimeout bounds a single write to a client.
+	socketWriteTimeout = 5 * time.Second
+)
+
+// StreamSink receives a copy of every StreamEvent of a reply, in order.
+// Publish must not block for long, it runs on the reply's goroutine.
+type StreamSink interface {
+	Publish(event StreamEvent)
+}
+
+// tapStreamEvents returns a channel that forwards to events and copies each
+// event to s.StreamSink. Closing it publishes a "done" event and closes events
+// once everything was forwarded.
+func (s *Session) tapStreamEvents(events chan<- StreamEvent) chan<- StreamEvent {
+	sink := s.StreamSink
+	if sink == nil {
+		return events
+	}
+	tapped := make(chan StreamEvent)
+	go func() {
+		defer close(events)
+		for event := range tapped {
+			sink.Publish(event)
+			events <- event
+		}
+		sink.Publish(StreamEvent{Type: streamEventDone})
+	}()
+	return tapped
+}
+
+// streamEventDone marks the end of a reply for sinks; it is never sent to
+// callers.
+const streamEventDone StreamEventType = -1
+
+// socketEvent is the JSON line written to event socket clients.
+type socketEvent struct {
+	Type     string           `json:"type"`
+	Content  string           `json:"content,omitempty"`
+	ToolCall *openai.ToolCall `json:"tool_call,omitempty"`
+	Tool     string           `json:"tool,omitempty"`
+	ArgBytes int              `json:"arg_bytes,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+func newSocketEvent(event StreamEvent) socketEvent {
+	switch event.Type {
+	case StreamEventContent:
+		return socketEvent{Type: "content", Content: event.Content}
+	case StreamEventToolCall:
+		return socketEvent{Type: "tool_call", ToolCall: event.ToolCall}
+	case StreamEventToolProgress:
+		return socketEvent{Type: "tool_progress", Tool: event.ToolName, ArgBytes: event.ArgBytes}
+	case StreamEventError:
+		msg := "unknown error"
+		if event.Err != nil {
+			msg = event.Err.Error()
+		}
+		return socketEvent{Type: "error", Error: msg}
+	default:
+		return socketEvent{Type: "done"}
+	}
+}
+
+// EventSocket serves the stream events of a session as newline-delimited JSON
+// to every client connected to a Unix domain socket, so another program can
+// render the conversation. It implements StreamSink.
+type EventSocket struct {
+	path     string
+	listener net.Listener
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	clients map[*socketClient]struct{}
+	closed  bool
+}
+
+type socketClient struct {
+	conn  net.Conn
+	lines chan []byte
+}
+
+// ListenEventSocket listens on the Unix socket at path until ctx is done or
+// Close is called. A stale socket left at path by an earlier run is replaced;
+// a socket another process still listens on, or any other file, is an error.
+// The socket is only accessible to the current user.
+func ListenEventSocket(ctx context.Context, path string) (*EventSocket, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("event socket %s: file exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("event socket %s: already in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("event socket %s: %w", path, err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("event socket %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("event socket %s: %w", path, err)
+	}
+
+	es := &EventSocket{
+		path:     path,
+		listener: listener,
+		done:     make(chan struct{}),
+		clients:  make(map[*socketClient]struct{}),
+	}
+	es.wg.Add(1)
+	go es.acceptLoop()
+	go func() {
+		select {
+		case <-ctx.Done():
+			es.Close()
+		case <-es.done:
+		}
+	}()
+	return es, nil
+}
+
+// Path returns the socket path.
+func (es *EventSocket) Path() string {
+	return es.path
+}
+
+func (es *EventSocket) acceptLoop() {
+	defer es.wg.Done()
+	for {
+		conn, err := es.listener.Accept()
+		if err != nil {
+			return
+		}
+		client := &socketClient{conn: conn, lines: make(chan []byte, socketClientBuffer)}
+		es.mu.Lock()
+		if es.closed {
+			es.mu.Unlock()
+			conn.Close()
+			return
+		}
+		es.clients[client] = struct{}{}
+		es.mu.Unlock()
+
+		es.wg.Add(1)
+		go es.writeLoop(client)
+	}
+}
+
+func (es *EventSocket) writeLoop(client *socketClient) {
+	defer es.wg.Done()
+	defer client.conn.Close()
+	for line := range client.lines {
+		client.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+		if _, err := client.conn.Write(line); err != nil {
+			es.dropClient(client)
+			// Drain so dropClient's close ends the loop.
+			for range client.lines {
+			}
+			return
+		}
+	}
+}
+
+// dropClient disconnects a client; it is safe to call more than once.
+func (es *EventSocket) dropClient(client *socketClient) {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if _, ok := es.clients[client]; ok {
+		delete(es.clients, client)
+		close(client.lines)
+	}
+}
+
+// Publish sends event to every connected client without blocking. A client
+// too far behind is disconnected.
+func (es *EventSocket) Publish(event StreamEvent) {
+	line, err := json.Marshal(newSocketEvent(event))
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	for client := range es.clients {
+		select {
+		case client.lines <- line:
+		default:
+			delete(es.clients, client)
+			close(client.lines)
+		}
+	}
+}
+
+// clientCount returns how many clients are connected.
+func (es *EventSocket) clientCount() int {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	return len(es.clients)
+}
+
+// Close stops listening, disconnects every client after its queued events
+// were written, and removes the socket file.
+func (es *EventSocket) Close() error {
+	es.mu.Lock()
+	if es.closed {
+		es.mu.Unlock()
+		return nil
+	}
+	es.closed = true
+	close(es.done)
+	err := es.listener.Close()
+	for client := range es.clients {
+		delete(es.clients, client)
+		close(client.lines)
+	}
+	es.mu.Unlock()
+
+	es.wg.Wait()
+	if removeErr := os.Remove(es.path); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		err = errors.Join(err, removeErr)
+	}
+	return err
+}
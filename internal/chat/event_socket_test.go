@@ -0,0 +1,139 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readSocketTurn reads the events of one reply, up to and including "done".
+func readSocketTurn(t *testing.T, conn net.Conn) []socketEvent {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	scanner := bufio.NewScanner(conn)
+	var events []socketEvent
+	for scanner.Scan() {
+		var event socketEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+		if event.Type == "done" {
+			return events
+		}
+	}
+	t.Fatalf("stream ended before done: %v (got %+v)", scanner.Err(), events)
+	return nil
+}
+
+func TestEventSocketStreamsATurn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	es, err := ListenEventSocket(ctx, path)
+	if err != nil {
+		t.Fatalf("ListenEventSocket failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected a private socket file, got %v (%v)", info, err)
+	}
+
+	var clients []net.Conn
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("dial failed: %v", err)
+		}
+		defer conn.Close()
+		clients = append(clients, conn)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for es.clientCount() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("clients not registered, have %d", es.clientCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	session := newEchoSession(EchoModePlain)
+	session.StreamSink = es
+	prompt := "Hello from a front-end over the socket"
+	reply, _ := collectStream(t, session, prompt, true)
+	if reply != prompt {
+		t.Fatalf("expected the caller to get the reply unchanged, got %q", reply)
+	}
+
+	for i, conn := range clients {
+		events := readSocketTurn(t, conn)
+		var content strings.Builder
+		for _, event := range events[:len(events)-1] {
+			if event.Type != "content" {
+				t.Fatalf("client %d: unexpected event %+v", i, event)
+			}
+			content.WriteString(event.Content)
+		}
+		if len(events) < 3 || content.String() != prompt {
+			t.Fatalf("client %d: expected the reply in several chunks, got %d events with %q", i, len(events), content.String())
+		}
+	}
+
+	_, calls := collectStream(t, session, EchoToolTrigger+" ls", true)
+	if len(calls) != 1 {
+		t.Fatalf("expected one tool call, got %d", len(calls))
+	}
+	events := readSocketTurn(t, clients[0])
+	if len(events) != 2 || events[0].Type != "tool_call" || events[0].ToolCall == nil || events[0].ToolCall.Function.Name != "ls" {
+		t.Fatalf("expected a tool_call event for ls, got %+v", events)
+	}
+
+	cancel()
+	if err := es.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the socket file to be removed, got %v", err)
+	}
+}
+
+func TestListenEventSocketPathChecks(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "not-a-socket")
+	if err := os.WriteFile(file, []byte("data"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if _, err := ListenEventSocket(context.Background(), file); err == nil {
+		t.Fatal("expected an error for a regular file")
+	}
+
+	path := filepath.Join(dir, "events.sock")
+	first, err := ListenEventSocket(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ListenEventSocket failed: %v", err)
+	}
+	if _, err := ListenEventSocket(context.Background(), path); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Fatalf("expected a socket in use to be refused, got %v", err)
+	}
+	first.Close()
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+)
+
+// ClientFactory builds a chat client for a provider endpoint.
+type ClientFactory func(provider config.ProviderConfig) ChatClient
+
+// newProviderClient builds an OpenAI client for provider that sends its
+// requests through httpClient, so fallbacks share the proxy and TLS settings.
+func newProviderClient(provider config.ProviderConfig, httpClient *http.Client) ChatClient {
+	clientConfig := openai.DefaultConfig(provider.APIKey)
+	if provider.APIURL != "" {
+		clientConfig.BaseURL = provider.APIURL
+	}
+	clientConfig.HTTPClient = httpClient
+	return openai.NewClientWithConfig(clientConfig)
+}
+
+// isFailoverError reports whether err means the endpoint is unreachable or
+// failing on its side, as opposed to rejecting the request itself.
+func isFailoverError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode >= http.StatusInternalServerError
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// fallbackProvider fills the empty fields of the fallback at index i from the
+// primary config.
+func (s *Session) fallbackProvider(i int) config.ProviderConfig {
+	provider := s.Config.Fallbacks[i]
+	if provider.APIKey == "" {
+		provider.APIKey = s.Config.APIKey
+	}
+	if provider.Model == "" {
+		provider.Model = s.Config.Model
+	}
+	return provider
+}
+
+// fallbackClient returns the cached client for the fallback at index i,
+// building it on first use.
+func (s *Session) fallbackClient(i int) ChatClient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if client, ok := s.fallbackClients[i]; ok {
+		return client
+	}
+	factory := s.ClientFactory
+	if factory == nil {
+		factory = func(provider config.ProviderConfig) ChatClient {
+			return newProviderClient(provider, newHTTPClient(s.Config))
+		}
+	}
+	client := factory(s.fallbackProvider(i))
+	if s.fallbackClients == nil {
+		s.fallbackClients = make(map[int]ChatClient)
+	}
+	s.fallbackClients[i] = client
+	return client
+}
+
+// withFailover runs call against the primary client and, on connection or
+// server errors, against each configured fallback in order. Every request
+// starts from the primary, so a recovered primary is picked up on the next turn.
+func withFailover[T any](s *Session, ctx context.Context, requestID, operation string, req openai.ChatCompletionRequest, call func(ChatClient, openai.ChatCompletionRequest) (T, error)) (T, error) {
+	result, err := call(s.Client, req)
+	for i := range s.Config.Fallbacks {
+		if !isFailoverError(ctx, err) {
+			break
+		}
+		provider := s.fallbackProvider(i)
+		s.logFailover(requestID, operation, provider, err)
+		fallbackReq := req
+		fallbackReq.Model = provider.Model
+		result, err = call(s.fallbackClient(i), fallbackReq)
+	}
+	return result, err
+}
+
+func (s *Session) logFailover(requestID, operation string, provider config.ProviderConfig, err error) {
+	logger := s.sessionLogger()
+	if logger == nil {
+		return
+	}
+	logger.Warn().
+		Str("request_id", requestID).
+		Str("operation", operation).
+		Str("api_url", provider.APIURL).
+		Str("model", provider.Model).
+		Err(err).
+		Msg("Switching to fallback provider")
+}
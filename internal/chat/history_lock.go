@@ -0,0 +1,49 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	// historyLockTimeout bounds the wait for another instance's history lock.
+	historyLockTimeout = 2 * time.Second
+	historyLockPoll    = 20 * time.Millisecond
+)
+
+// lockHistoryFile takes an advisory lock on an open history file, shared for
+// reads and exclusive for writes, so instances sharing a history file do not
+// interleave partial lines. It retries until historyLockTimeout and then
+// returns ErrHistoryLocked.
+func lockHistoryFile(file *os.File, exclusive bool) (unlock func(), err error) {
+	deadline := time.Now().Add(historyLockTimeout)
+	for {
+		locked, err := tryLockFile(file, exclusive)
+		if err != nil {
+			return nil, err
+		}
+		if locked {
+			return func() { _ = unlockFile(file) }, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, ErrHistoryLocked
+		}
+		time.Sleep(historyLockPoll)
+	}
+}
@@ -0,0 +1,316 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ImportFormat names a conversation export format understood by ImportConversation.
+type ImportFormat string
+
+const (
+	// ImportFormatAuto detects the format from the file contents.
+	ImportFormatAuto ImportFormat = ""
+	// ImportFormatChatGPT is the conversations.json written by ChatGPT's data
+	// export: one conversation or an array of them, each a "mapping" tree.
+	ImportFormatChatGPT ImportFormat = "chatgpt"
+	// ImportFormatOpenAI is a list of chat completion messages, either a bare
+	// array or an object with a "messages" array.
+	ImportFormatOpenAI ImportFormat = "openai"
+)
+
+// ErrNothingToImport is returned when an export holds no user or assistant messages.
+var ErrNothingToImport = errors.New("no user or assistant messages to import")
+
+// ImportResult reports what ImportConversation loaded.
+type ImportResult struct {
+	Title    string // conversation title, when the format has one
+	Messages int
+	Skipped  int // system prompts, tool traffic and empty or non-text messages
+}
+
+// ParseImportFormat maps a format name to an ImportFormat.
+func ParseImportFormat(name string) (ImportFormat, error) {
+	switch ImportFormat(strings.ToLower(strings.TrimSpace(name))) {
+	case "", "auto":
+		return ImportFormatAuto, nil
+	case ImportFormatChatGPT:
+		return ImportFormatChatGPT, nil
+	case ImportFormatOpenAI:
+		return ImportFormatOpenAI, nil
+	default:
+		return "", fmt.Errorf("unknown import format %q (use chatgpt or openai)", name)
+	}
+}
+
+// ImportConversation replaces the conversation after the system message with
+// the user and assistant messages of an exported chat. System prompts and tool
+// traffic are skipped since they do not apply to this session. When a ChatGPT
+// export holds several conversations the most recently updated one is used.
+// Imported messages count as saved: they already live in the export, and
+// appending them would repeat them in the history file on every import. Only
+// the messages that follow are appended on the next save.
+func (s *Session) ImportConversation(path string, format ImportFormat) (ImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImportResult{}, NewHistoryError("import", path, err)
+	}
+	if format == ImportFormatAuto {
+		format = detectImportFormat(data)
+	}
+
+	var result ImportResult
+	var messages []openai.ChatCompletionMessage
+	switch format {
+	case ImportFormatChatGPT:
+		messages, result, err = parseChatGPTExport(data)
+	case ImportFormatOpenAI:
+		messages, result, err = parseMessagesExport(data)
+	default:
+		err = fmt.Errorf("unknown import format %q", format)
+	}
+	if err != nil {
+		return ImportResult{}, NewHistoryError("import", path, err)
+	}
+	if len(messages) == 0 {
+		return ImportResult{}, NewHistoryError("import", path, ErrNothingToImport)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Messages = append([]openai.ChatCompletionMessage{s.Messages[0]}, messages...)
+	s.lastSavedMsgCount = len(messages)
+	s.pinnedTurns = nil
+	s.trimHistoryLocked()
+	result.Messages = len(messages)
+	return result, nil
+}
+
+// detectImportFormat picks ChatGPT when the top level, or its first element,
+// has a "mapping" tree.
+func detectImportFormat(data []byte) ImportFormat {
+	trimmed := bytes.TrimSpace(data)
+	var probe struct {
+		Mapping json.RawMessage `json:"mapping"`
+	}
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []json.RawMessage
+		if json.Unmarshal(trimmed, &items) == nil && len(items) > 0 {
+			trimmed = items[0]
+		}
+	}
+	if json.Unmarshal(trimmed, &probe) == nil && len(probe.Mapping) > 0 {
+		return ImportFormatChatGPT
+	}
+	return ImportFormatOpenAI
+}
+
+type chatGPTConversation struct {
+	Title       string                 `json:"title"`
+	UpdateTime  float64                `json:"update_time"`
+	CurrentNode string                 `json:"current_node"`
+	Mapping     map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	Message  *chatGPTMessage `json:"message"`
+	Parent   string          `json:"parent"`
+	Children []string        `json:"children"`
+}
+
+type chatGPTMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	Content struct {
+		ContentType string        `json:"content_type"`
+		Parts       []interface{} `json:"parts"`
+	} `json:"content"`
+	Recipient string `json:"recipient"`
+	Metadata  struct {
+		Hidden bool `json:"is_visually_hidden_from_conversation"`
+	} `json:"metadata"`
+}
+
+func parseChatGPTExport(data []byte) ([]openai.ChatCompletionMessage, ImportResult, error) {
+	var conversations []chatGPTConversation
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &conversations); err != nil {
+			return nil, ImportResult{}, err
+		}
+	} else {
+		var conversation chatGPTConversation
+		if err := json.Unmarshal(trimmed, &conversation); err != nil {
+			return nil, ImportResult{}, err
+		}
+		conversations = append(conversations, conversation)
+	}
+	if len(conversations) == 0 {
+		return nil, ImportResult{}, ErrNothingToImport
+	}
+	sort.SliceStable(conversations, func(i, j int) bool {
+		return conversations[i].UpdateTime > conversations[j].UpdateTime
+	})
+	conversation := conversations[0]
+	if len(conversation.Mapping) == 0 {
+		return nil, ImportResult{}, fmt.Errorf("conversation %q has no messages", conversation.Title)
+	}
+
+	result := ImportResult{Title: conversation.Title}
+	var messages []openai.ChatCompletionMessage
+	for _, node := range chatGPTThread(conversation) {
+		if node.Message == nil {
+			continue // the empty root of the tree
+		}
+		msg, ok := chatGPTToMessage(node.Message)
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	return messages, result, nil
+}
+
+// chatGPTThread returns the nodes from the root to the conversation's current
+// node, which is the branch shown when the chat was last open. Without a
+// current node it follows the latest child from the root.
+func chatGPTThread(conversation chatGPTConversation) []chatGPTNode {
+	var thread []chatGPTNode
+	if _, ok := conversation.Mapping[conversation.CurrentNode]; ok {
+		seen := make(map[string]bool)
+		for id := conversation.CurrentNode; id != "" && !seen[id]; {
+			seen[id] = true
+			node, ok := conversation.Mapping[id]
+			if !ok {
+				break
+			}
+			thread = append(thread, node)
+			id = node.Parent
+		}
+		for i, j := 0, len(thread)-1; i < j; i, j = i+1, j-1 {
+			thread[i], thread[j] = thread[j], thread[i]
+		}
+		return thread
+	}
+
+	roots := make([]string, 0, 1)
+	for id, node := range conversation.Mapping {
+		if _, ok := conversation.Mapping[node.Parent]; !ok {
+			roots = append(roots, id)
+		}
+	}
+	if len(roots) == 0 {
+		return nil
+	}
+	sort.Strings(roots)
+	seen := make(map[string]bool)
+	for id := roots[0]; id != "" && !seen[id]; {
+		seen[id] = true
+		node := conversation.Mapping[id]
+		thread = append(thread, node)
+		id = ""
+		if len(node.Children) > 0 {
+			id = node.Children[len(node.Children)-1]
+		}
+	}
+	return thread
+}
+
+// chatGPTToMessage converts visible user and assistant text. Tool calls
+// (assistant messages addressed to a tool), tool output and system prompts are
+// skipped.
+func chatGPTToMessage(message *chatGPTMessage) (openai.ChatCompletionMessage, bool) {
+	if message.Metadata.Hidden {
+		return openai.ChatCompletionMessage{}, false
+	}
+	role := message.Author.Role
+	if role != openai.ChatMessageRoleUser && role != openai.ChatMessageRoleAssistant {
+		return openai.ChatCompletionMessage{}, false
+	}
+	if role == openai.ChatMessageRoleAssistant && message.Recipient != "" && message.Recipient != "all" {
+		return openai.ChatCompletionMessage{}, false
+	}
+	switch message.Content.ContentType {
+	case "text", "multimodal_text":
+	default:
+		return openai.ChatCompletionMessage{}, false
+	}
+	var parts []string
+	for _, part := range message.Content.Parts {
+		if text, ok := part.(string); ok && strings.TrimSpace(text) != "" {
+			parts = append(parts, text)
+		}
+	}
+	if len(parts) == 0 {
+		return openai.ChatCompletionMessage{}, false
+	}
+	return openai.ChatCompletionMessage{Role: role, Content: strings.Join(parts, "\n")}, true
+}
+
+func parseMessagesExport(data []byte) ([]openai.ChatCompletionMessage, ImportResult, error) {
+	var raw []openai.ChatCompletionMessage
+	title := ""
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, ImportResult{}, err
+		}
+	} else {
+		var wrapper struct {
+			Title    string                         `json:"title"`
+			Messages []openai.ChatCompletionMessage `json:"messages"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil {
+			return nil, ImportResult{}, err
+		}
+		raw = wrapper.Messages
+		title = wrapper.Title
+	}
+
+	result := ImportResult{Title: title}
+	messages := make([]openai.ChatCompletionMessage, 0, len(raw))
+	for _, msg := range raw {
+		content := msg.Content
+		if content == "" {
+			var parts []string
+			for _, part := range msg.MultiContent {
+				if part.Type == openai.ChatMessagePartTypeText && strings.TrimSpace(part.Text) != "" {
+					parts = append(parts, part.Text)
+				}
+			}
+			content = strings.Join(parts, "\n")
+		}
+		switch {
+		case msg.Role != openai.ChatMessageRoleUser && msg.Role != openai.ChatMessageRoleAssistant,
+			len(msg.ToolCalls) > 0 || msg.FunctionCall != nil,
+			strings.TrimSpace(content) == "":
+			result.Skipped++
+			continue
+		}
+		messages = append(messages, openai.ChatCompletionMessage{Role: msg.Role, Content: content})
+	}
+	return messages, result, nil
+}
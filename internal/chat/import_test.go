@@ -0,0 +1,163 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+)
+
+const chatGPTExportFixture = `[
+  {
+    "title": "Older chat",
+    "update_time": 1700000000.5,
+    "current_node": "x",
+    "mapping": {
+      "x": {"id": "x", "message": {"author": {"role": "user"}, "content": {"content_type": "text", "parts": ["old"]}}, "parent": null, "children": []}
+    }
+  },
+  {
+    "title": "Go generics",
+    "update_time": 1710000000.5,
+    "current_node": "a3",
+    "mapping": {
+      "root": {"id": "root", "message": null, "parent": null, "children": ["sys"]},
+      "sys": {"id": "sys", "message": {"author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]}, "metadata": {"is_visually_hidden_from_conversation": true}}, "parent": "root", "children": ["u1"]},
+      "u1": {"id": "u1", "message": {"author": {"role": "user"}, "content": {"content_type": "text", "parts": ["What are generics?"]}}, "parent": "sys", "children": ["a1"]},
+      "a1": {"id": "a1", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["Type parameters."]}, "recipient": "all"}, "parent": "u1", "children": ["u2"]},
+      "u2": {"id": "u2", "message": {"author": {"role": "user"}, "content": {"content_type": "multimodal_text", "parts": [{"content_type": "image_asset_pointer"}, "Show an example"]}}, "parent": "a1", "children": ["call", "a2-old"]},
+      "a2-old": {"id": "a2-old", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["abandoned branch"]}, "recipient": "all"}, "parent": "u2", "children": []},
+      "call": {"id": "call", "message": {"author": {"role": "assistant"}, "content": {"content_type": "code", "text": "print(1)"}, "recipient": "python"}, "parent": "u2", "children": ["tool"]},
+      "tool": {"id": "tool", "message": {"author": {"role": "tool"}, "content": {"content_type": "execution_output", "text": "1"}}, "parent": "call", "children": ["a3"]},
+      "a3": {"id": "a3", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["func Map[T any]()"]}, "recipient": "all"}, "parent": "tool", "children": []}
+    }
+  }
+]`
+
+func writeImportFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write export: %v", err)
+	}
+	return path
+}
+
+func TestImportConversationChatGPT(t *testing.T) {
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, &MockChatClient{})
+	session.AddMessage(openai.ChatMessageRoleUser, "replaced by the import")
+
+	result, err := session.ImportConversation(writeImportFile(t, chatGPTExportFixture), ImportFormatAuto)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Title != "Go generics" || result.Messages != 4 || result.Skipped != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	want := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleUser, Content: "What are generics?"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "Type parameters."},
+		{Role: openai.ChatMessageRoleUser, Content: "Show an example"},
+		{Role: openai.ChatMessageRoleAssistant, Content: "func Map[T any]()"},
+	}
+	history := session.GetHistory()
+	if len(history) != len(want) {
+		t.Fatalf("expected %d messages, got %+v", len(want), history)
+	}
+	for i, msg := range want {
+		if history[i].Role != msg.Role || history[i].Content != msg.Content {
+			t.Errorf("message %d: expected %s %q, got %s %q", i, msg.Role, msg.Content, history[i].Role, history[i].Content)
+		}
+	}
+	if session.Messages[0].Role != openai.ChatMessageRoleSystem {
+		t.Fatal("expected the session system prompt to be kept")
+	}
+}
+
+func TestImportConversationMessages(t *testing.T) {
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, &MockChatClient{})
+	path := writeImportFile(t, `{"messages": [
+		{"role": "system", "content": "other prompt"},
+		{"role": "user", "content": "hi"},
+		{"role": "assistant", "tool_calls": [{"id": "1", "type": "function", "function": {"name": "ls", "arguments": "{}"}}]},
+		{"role": "tool", "tool_call_id": "1", "content": "a.txt"},
+		{"role": "assistant", "content": "hello"}
+	]}`)
+
+	result, err := session.ImportConversation(path, ImportFormatOpenAI)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Messages != 2 || result.Skipped != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if history := session.GetHistory(); history[0].Content != "hi" || history[1].Content != "hello" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	_, err = session.ImportConversation(writeImportFile(t, `[{"role": "system", "content": "only"}]`), ImportFormatAuto)
+	if !errors.Is(err, ErrNothingToImport) {
+		t.Fatalf("expected ErrNothingToImport, got %v", err)
+	}
+	if len(session.GetHistory()) != 2 {
+		t.Fatal("expected a failed import to keep the conversation")
+	}
+	if _, err := session.ImportConversation(writeImportFile(t, `not json`), ImportFormatAuto); err == nil {
+		t.Fatal("expected error for invalid JSON")
+	}
+}
+
+func TestImportConversationKeepsHistoryFile(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, &MockChatClient{})
+	session.AddMessage(openai.ChatMessageRoleUser, "before")
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+
+	export := writeImportFile(t, `[{"role": "user", "content": "hi"}, {"role": "assistant", "content": "hello"}]`)
+	for range 2 {
+		if _, err := session.ImportConversation(export, ImportFormatAuto); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := session.SaveConversationHistory(historyFile); err != nil {
+			t.Fatalf("failed to save history: %v", err)
+		}
+	}
+	session.AddMessage(openai.ChatMessageRoleUser, "after")
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+
+	loaded := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, &MockChatClient{})
+	if err := loaded.LoadConversationHistory(historyFile, 0); err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	var contents []string
+	for _, msg := range loaded.GetHistory() {
+		contents = append(contents, msg.Content)
+	}
+	if len(contents) != 2 || contents[0] != "before" || contents[1] != "after" {
+		t.Fatalf("expected only the messages written in this session, got %q", contents)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// ModelLister is implemented by clients that can list the provider's models.
+// openai.Client implements it; clients without it report ErrModelsUnsupported.
+type ModelLister interface {
+	ListModels(ctx context.Context) (openai.ModelsList, error)
+}
+
+// ListModels returns the IDs of the models offered by the primary provider,
+// sorted, keeping only those containing filter (case-insensitive).
+func (s *Session) ListModels(ctx context.Context, filter string) ([]string, error) {
+	lister, ok := s.Client.(ModelLister)
+	if !ok {
+		return nil, ErrModelsUnsupported
+	}
+	list, err := lister.ListModels(ctx)
+	if err != nil {
+		if isUnsupportedEndpoint(err) {
+			return nil, ErrModelsUnsupported
+		}
+		return nil, NewAPIError("list_models", err)
+	}
+
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	ids := make([]string, 0, len(list.Models))
+	for _, model := range list.Models {
+		if model.ID == "" || !strings.Contains(strings.ToLower(model.ID), filter) {
+			continue
+		}
+		ids = append(ids, model.ID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Preflight checks that the primary provider is reachable and accepts the API
+// key by listing its models. A provider without a model list still counts as
+// reachable. Clients that cannot list models return ErrModelsUnsupported, as
+// there is nothing to check.
+func (s *Session) Preflight(ctx context.Context) error {
+	lister, ok := s.Client.(ModelLister)
+	if !ok {
+		return ErrModelsUnsupported
+	}
+	_, err := lister.ListModels(ctx)
+	if err == nil || isUnsupportedEndpoint(err) {
+		return nil
+	}
+	switch status := httpStatus(err); {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return fmt.Errorf("authentication failed at %s, check api_key: %w", s.BaseURL, err)
+	case status != 0:
+		return fmt.Errorf("%s answered with status %d: %w", s.BaseURL, status, err)
+	default:
+		return fmt.Errorf("cannot reach %s: %w", s.BaseURL, err)
+	}
+}
+
+// isUnsupportedEndpoint reports whether the provider answered that it has no
+// model listing endpoint, as opposed to a failed request.
+func isUnsupportedEndpoint(err error) bool {
+	switch httpStatus(err) {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// httpStatus returns the HTTP status of a provider error, or 0 when the
+// request never got an answer.
+func httpStatus(err error) int {
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		return reqErr.HTTPStatusCode
+	}
+	return 0
+}
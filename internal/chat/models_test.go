@@ -0,0 +1,133 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+
+	"promptline/internal/config"
+)
+
+func newModelsSession(t *testing.T, handler http.HandlerFunc) *Session {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}
+	return NewSessionWithClient(cfg, openai.NewClientWithConfig(clientConfig))
+}
+
+func TestListModels(t *testing.T) {
+	session := newModelsSession(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"gpt-4o"},{"id":"text-embedding-3"},{"id":"GPT-4o-mini"}]}`))
+	})
+
+	all, err := session.ListModels(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if want := []string{"GPT-4o-mini", "gpt-4o", "text-embedding-3"}; !reflect.DeepEqual(all, want) {
+		t.Fatalf("expected %v, got %v", want, all)
+	}
+
+	filtered, err := session.ListModels(context.Background(), "gpt")
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if want := []string{"GPT-4o-mini", "gpt-4o"}; !reflect.DeepEqual(filtered, want) {
+		t.Fatalf("expected %v, got %v", want, filtered)
+	}
+}
+
+func TestListModelsUnsupported(t *testing.T) {
+	session := newModelsSession(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	if _, err := session.ListModels(context.Background(), ""); !errors.Is(err, ErrModelsUnsupported) {
+		t.Fatalf("expected ErrModelsUnsupported for a 404, got %v", err)
+	}
+
+	echo := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, NewEchoClient(EchoModePlain))
+	if _, err := echo.ListModels(context.Background(), ""); !errors.Is(err, ErrModelsUnsupported) {
+		t.Fatalf("expected ErrModelsUnsupported for a client without ListModels, got %v", err)
+	}
+}
+
+func TestListModelsServerError(t *testing.T) {
+	session := newModelsSession(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"boom"}}`, http.StatusInternalServerError)
+	})
+	_, err := session.ListModels(context.Background(), "")
+	if err == nil || errors.Is(err, ErrModelsUnsupported) {
+		t.Fatalf("expected an API error, got %v", err)
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	ok := newModelsSession(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[]}`))
+	})
+	if err := ok.Preflight(context.Background()); err != nil {
+		t.Fatalf("expected preflight success, got %v", err)
+	}
+
+	noList := newModelsSession(t, func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	if err := noList.Preflight(context.Background()); err != nil {
+		t.Fatalf("expected a provider without /models to pass, got %v", err)
+	}
+
+	badKey := newModelsSession(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided"}}`))
+	})
+	if err := badKey.Preflight(context.Background()); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = url
+	down := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini", APIURL: url}, openai.NewClientWithConfig(clientConfig))
+	if err := down.Preflight(context.Background()); err == nil || !strings.Contains(err.Error(), "cannot reach "+url) {
+		t.Fatalf("expected connection error, got %v", err)
+	}
+
+	echo := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, NewEchoClient(EchoModePlain))
+	if err := echo.Preflight(context.Background()); !errors.Is(err, ErrModelsUnsupported) {
+		t.Fatalf("expected echo sessions to be skipped, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// observerBuffer is how many events an observer may fall behind before new
+// events are dropped for it.
+const observerBuffer = 64
+
+// SessionEventType identifies what a SessionEvent reports.
+type SessionEventType string
+
+const (
+	SessionEventUserMessage      SessionEventType = "user_message"
+	SessionEventAssistantMessage SessionEventType = "assistant_message"
+	SessionEventToolExecuted     SessionEventType = "tool_executed"
+	SessionEventError            SessionEventType = "error"
+)
+
+// SessionEvent is delivered to observers when the conversation changes.
+type SessionEvent struct {
+	Type    SessionEventType
+	Time    time.Time
+	Message *openai.ChatCompletionMessage // user and assistant messages
+	Tool    *ToolTrace                    // tool_executed
+	Err     error                         // error
+}
+
+// Observer receives session events. It runs on its own goroutine, one event at
+// a time and in order, so a slow observer never holds up the conversation.
+type Observer func(SessionEvent)
+
+type observer struct {
+	events chan SessionEvent
+	done   chan struct{}
+}
+
+// AddObserver subscribes fn to session events until the returned function is
+// called or the session is closed. Events are queued for fn without blocking;
+// an observer more than observerBuffer events behind misses the newer ones.
+func (s *Session) AddObserver(fn Observer) (remove func()) {
+	o := &observer{events: make(chan SessionEvent, observerBuffer), done: make(chan struct{})}
+	go func() {
+		defer close(o.done)
+		for event := range o.events {
+			fn(event)
+		}
+	}()
+
+	s.observersMu.Lock()
+	s.observers = append(s.observers, o)
+	s.observersMu.Unlock()
+	return func() { s.removeObserver(o) }
+}
+
+func (s *Session) removeObserver(o *observer) {
+	s.observersMu.Lock()
+	defer s.observersMu.Unlock()
+	for i, candidate := range s.observers {
+		if candidate == o {
+			s.observers = append(s.observers[:i], s.observers[i+1:]...)
+			close(o.events)
+			return
+		}
+	}
+}
+
+// closeObservers unsubscribes every observer and waits until each has handled
+// the events queued for it.
+func (s *Session) closeObservers() {
+	s.observersMu.Lock()
+	observers := s.observers
+	s.observers = nil
+	for _, o := range observers {
+		close(o.events)
+	}
+	s.observersMu.Unlock()
+	for _, o := range observers {
+		<-o.done
+	}
+}
+
+// emit queues event for every observer. It never blocks, so it is safe to call
+// with s.mu held.
+func (s *Session) emit(event SessionEvent) {
+	s.observersMu.Lock()
+	defer s.observersMu.Unlock()
+	if len(s.observers) == 0 {
+		return
+	}
+	event.Time = time.Now()
+	for _, o := range s.observers {
+		select {
+		case o.events <- event:
+		default:
+			if logger := s.sessionLogger(); logger != nil {
+				logger.Warn().Str("event", string(event.Type)).Msg("Observer is falling behind, event dropped")
+			}
+		}
+	}
+}
+
+// sendError reports err to observers and to the caller's event channel.
+func (s *Session) sendError(events chan<- StreamEvent, err error) {
+	s.emit(SessionEvent{Type: SessionEventError, Err: err})
+	events <- NewErrorEvent(err)
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Pins are kept per turn: a user message and every message that follows it
+// up to the next user message, so a pinned tool call keeps its results.
+
+// PinTurn pins turn n, counting user messages from 1, or the latest turn when
+// n is 0, so trimming the history never drops it. It returns the number of
+// the pinned turn and its user message.
+func (s *Session) PinTurn(n int) (int, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	starts := s.turnStartsLocked()
+	index, turn, err := pickTurn(starts, n)
+	if err != nil {
+		return 0, "", err
+	}
+	if s.pinnedTurns == nil {
+		s.pinnedTurns = make(map[int]bool)
+	}
+	s.pinnedTurns[index] = true
+	return turn, s.Messages[index].Content, nil
+}
+
+// UnpinTurn removes the pin of turn n, or of every turn when n is 0, and
+// returns how many pins were removed.
+func (s *Session) UnpinTurn(n int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n == 0 {
+		removed := len(s.pinnedTurns)
+		s.pinnedTurns = nil
+		return removed, nil
+	}
+	index, turn, err := pickTurn(s.turnStartsLocked(), n)
+	if err != nil {
+		return 0, err
+	}
+	if !s.pinnedTurns[index] {
+		return 0, fmt.Errorf("turn %d is not pinned", turn)
+	}
+	delete(s.pinnedTurns, index)
+	return 1, nil
+}
+
+// PinnedTurns returns the numbers of the pinned turns in order.
+func (s *Session) PinnedTurns() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var turns []int
+	for i, start := range s.turnStartsLocked() {
+		if s.pinnedTurns[start] {
+			turns = append(turns, i+1)
+		}
+	}
+	return turns
+}
+
+// pickTurn maps a 1-based turn number, or 0 for the latest, to the index of
+// its user message.
+func pickTurn(starts []int, n int) (int, int, error) {
+	if len(starts) == 0 {
+		return 0, 0, ErrNoSuchTurn
+	}
+	if n == 0 {
+		n = len(starts)
+	}
+	if n < 0 || n > len(starts) {
+		return 0, 0, fmt.Errorf("%w: %d (the conversation has %d)", ErrNoSuchTurn, n, len(starts))
+	}
+	return starts[n-1], n, nil
+}
+
+// turnStartsLocked returns the indexes of the user messages. The caller must
+// hold s.mu.
+func (s *Session) turnStartsLocked() []int {
+	var starts []int
+	for i, msg := range s.Messages {
+		if i > 0 && msg.Role == openai.ChatMessageRoleUser {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// pinnedMessagesLocked expands the pinned turns to the indexes of all their
+// messages. The caller must hold s.mu.
+func (s *Session) pinnedMessagesLocked() map[int]bool {
+	if len(s.pinnedTurns) == 0 {
+		return nil
+	}
+	pinned := make(map[int]bool)
+	inPinned := false
+	for i := 1; i < len(s.Messages); i++ {
+		if s.Messages[i].Role == openai.ChatMessageRoleUser {
+			inPinned = s.pinnedTurns[i]
+		}
+		if inPinned {
+			pinned[i] = true
+		}
+	}
+	return pinned
+}
+
+// prunePinsLocked forgets pins of user messages that are no longer in the
+// conversation, after it was cut short. The caller must hold s.mu.
+func (s *Session) prunePinsLocked() {
+	for index := range s.pinnedTurns {
+		if index >= len(s.Messages) || s.Messages[index].Role != openai.ChatMessageRoleUser {
+			delete(s.pinnedTurns, index)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"github.com/sashabaranov/go-openai"
+)
+
+// applyPrefill appends the configured assistant_prefill as a partial assistant
+// message when the request answers a user message, so the model continues
+// from it. It returns the prefill that was sent, or "".
+func (s *Session) applyPrefill(req *openai.ChatCompletionRequest) string {
+	prefill := s.Config.AssistantPrefill
+	if prefill == "" || len(req.Messages) == 0 || req.Messages[len(req.Messages)-1].Role != openai.ChatMessageRoleUser {
+		return ""
+	}
+	req.Messages = append(req.Messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleAssistant,
+		Content: prefill,
+	})
+	return prefill
+}
+
+// applyUserPrefix prepends the configured user_message_prefix to every user
+// message of the request. Only the request carries it: the history, the
+// history file and the screen keep the text the user typed.
+func (s *Session) applyUserPrefix(req *openai.ChatCompletionRequest) {
+	prefix := s.Config.UserMessagePrefix
+	if prefix == "" || s.NoUserPrefix {
+		return
+	}
+	for i, msg := range req.Messages {
+		if msg.Role != openai.ChatMessageRoleUser {
+			continue
+		}
+		if len(msg.MultiContent) > 0 {
+			parts := []openai.ChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: prefix}}
+			req.Messages[i].MultiContent = append(parts, msg.MultiContent...)
+			continue
+		}
+		req.Messages[i].Content = prefix + "\n\n" + msg.Content
+	}
+}
+
+// completePrefill prepends the prefill to a reply with text, so the stored
+// message reads as the model's complete answer.
+func completePrefill(prefill string, reply *openai.ChatCompletionMessage) {
+	if prefill != "" && reply.Content != "" {
+		reply.Content = prefill + reply.Content
+	}
+}
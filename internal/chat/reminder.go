@@ -0,0 +1,80 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// reminderHeading starts every re-injected reminder so it is recognisable in history.
+const reminderHeading = "REMINDER:\n"
+
+// defaultReminder condenses the system prompt to the section the model most
+// often forgets in long sessions: the tool-calling rules. Without such a
+// section it falls back to the first paragraph.
+func defaultReminder(systemPrompt string) string {
+	if start := strings.Index(systemPrompt, "CRITICAL RULES:"); start >= 0 {
+		section := systemPrompt[start:]
+		if end := strings.Index(section, "\n\n"); end >= 0 {
+			section = section[:end]
+		}
+		return strings.TrimSpace(section)
+	}
+	paragraph, _, _ := strings.Cut(strings.TrimSpace(systemPrompt), "\n\n")
+	return paragraph
+}
+
+// isReminder reports whether msg is a reminder added by injectReminder.
+// Reminders only steer the live conversation and are not kept in the history
+// file, where they would pile up across resumed sessions.
+func isReminder(msg openai.ChatCompletionMessage) bool {
+	return msg.Role == openai.ChatMessageRoleSystem && strings.HasPrefix(msg.Content, reminderHeading)
+}
+
+// reminderText returns the configured reminder or the default excerpt.
+func (s *Session) reminderText() string {
+	if text := strings.TrimSpace(s.Config.ReminderText); text != "" {
+		return text
+	}
+	return defaultReminder(defaultSystemPrompt)
+}
+
+// injectReminder counts a user turn and, once every Config.ReminderEveryNTurns
+// turns, appends the reminder as a system message ahead of the next user message.
+func (s *Session) injectReminder() {
+	every := s.Config.ReminderEveryNTurns
+	if every <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	turns := s.userTurns
+	s.userTurns++
+	if turns == 0 || turns%every != 0 {
+		return
+	}
+	s.Messages = append(s.Messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: reminderHeading + s.reminderText(),
+	})
+	s.trimHistoryLocked()
+	if logger := s.sessionLogger(); logger != nil {
+		logger.Debug().Int("turn", turns+1).Msg("System reminder injected")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DumpNextRequest writes the JSON body of the next chat request to w, pretty
+// printed, just before it is sent. Only one request is dumped; calling it again
+// replaces a pending target and nil cancels it. API keys travel in headers, not
+// in the body, and any copy of a configured key in the body is redacted.
+func (s *Session) DumpNextRequest(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requestDump = w
+}
+
+// RequestDumpPending reports whether DumpNextRequest is waiting for a request.
+func (s *Session) RequestDumpPending() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requestDump != nil
+}
+
+// dumpRequest writes req to the pending DumpNextRequest target, if any.
+// Failures are logged and never stop the request.
+func (s *Session) dumpRequest(requestID string, req openai.ChatCompletionRequest) {
+	s.mu.Lock()
+	w := s.requestDump
+	s.requestDump = nil
+	s.mu.Unlock()
+	if w == nil {
+		return
+	}
+
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err == nil {
+		_, err = w.Write(append(s.redactAPIKeys(data), '\n'))
+	}
+	if err != nil {
+		if logger := s.sessionLogger(); logger != nil {
+			logger.Warn().Err(err).Str("request_id", requestID).Msg("Failed to dump request")
+		}
+	}
+}
+
+// redactAPIKeys replaces the configured API keys, including fallback keys, in data.
+func (s *Session) redactAPIKeys(data []byte) []byte {
+	if s.Config == nil {
+		return data
+	}
+	keys := []string{s.Config.APIKey}
+	for _, provider := range s.Config.Fallbacks {
+		keys = append(keys, provider.APIKey)
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		// Keys are matched as they appear inside JSON strings.
+		quoted, _ := json.Marshal(key)
+		data = bytes.ReplaceAll(data, quoted[1:len(quoted)-1], []byte("[REDACTED]"))
+	}
+	return data
+}
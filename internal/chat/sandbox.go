@@ -0,0 +1,167 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"promptline/internal/tools"
+)
+
+// sandbox is the per-session directory used when sandbox_dir is configured.
+type sandbox struct {
+	dir      string
+	prevDir  string
+	prevRoot string
+	keep     bool
+}
+
+// OpenSandbox moves the session tools into a new directory created inside
+// Config.SandboxDir and returns its path. Config.SandboxSeedFiles are copied in
+// first. Tools resolve paths against the registry working directory and never
+// leave the work root, so both point at the sandbox until Close. The process
+// working directory is left alone, and the relative file paths of the
+// configuration are made absolute first, so history, transcripts and the event
+// socket stay in the startup directory rather than being removed with the
+// sandbox. Without a configured sandbox directory it does nothing and returns
+// an empty path.
+func (s *Session) OpenSandbox() (string, error) {
+	if s.Config == nil || s.Config.SandboxDir == "" {
+		return "", nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sandbox != nil {
+		return s.sandbox.dir, nil
+	}
+
+	startDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine working directory: %v", err)
+	}
+	s.Config.ResolvePaths(startDir)
+	prevDir, err := s.ToolRegistry.WorkingDirectory()
+	if err != nil {
+		return "", err
+	}
+	prevRoot, err := tools.WorkRoot()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(s.Config.SandboxDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create sandbox directory: %v", err)
+	}
+	dir, err := os.MkdirTemp(s.Config.SandboxDir, "promptline-"+s.SessionID+"-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create sandbox: %v", err)
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	for _, seed := range s.Config.SandboxSeedFiles {
+		if err := copySeedFile(seed, dir); err != nil {
+			_ = os.RemoveAll(dir)
+			return "", err
+		}
+	}
+	if err := tools.ConfigureWorkRoot(dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	s.ToolRegistry.SetWorkingDirectory(dir)
+
+	s.sandbox = &sandbox{dir: dir, prevDir: prevDir, prevRoot: prevRoot, keep: s.Config.KeepSandbox}
+	if logger := s.sessionLogger(); logger != nil {
+		logger.Info().Str("sandbox", dir).Int("seed_files", len(s.Config.SandboxSeedFiles)).Msg("Session sandbox created")
+	}
+	return dir, nil
+}
+
+// SandboxPath returns the session sandbox directory, or "" when there is none.
+func (s *Session) SandboxPath() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sandbox == nil {
+		return ""
+	}
+	return s.sandbox.dir
+}
+
+// closeSandbox points the tools back at the directory the session started in
+// and removes the sandbox unless it is kept.
+func (s *Session) closeSandbox() error {
+	s.mu.Lock()
+	box := s.sandbox
+	s.sandbox = nil
+	s.mu.Unlock()
+	if box == nil {
+		return nil
+	}
+
+	s.ToolRegistry.SetWorkingDirectory(box.prevDir)
+	if err := tools.ConfigureWorkRoot(box.prevRoot); err != nil {
+		return err
+	}
+	if box.keep {
+		return nil
+	}
+	if err := os.RemoveAll(box.dir); err != nil {
+		return fmt.Errorf("failed to remove sandbox: %v", err)
+	}
+	return nil
+}
+
+// copySeedFile copies a file into the sandbox. Paths inside the working
+// directory keep their relative layout; any other file lands at the top level.
+func copySeedFile(seed, dir string) error {
+	info, err := os.Stat(seed)
+	if err != nil {
+		return fmt.Errorf("sandbox seed file: %v", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("sandbox seed file %s is not a regular file", seed)
+	}
+
+	rel := filepath.Clean(seed)
+	if !filepath.IsLocal(rel) {
+		rel = filepath.Base(rel)
+	}
+	target := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("sandbox seed file %s: %v", seed, err)
+	}
+
+	src, err := os.Open(seed)
+	if err != nil {
+		return fmt.Errorf("sandbox seed file: %v", err)
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return fmt.Errorf("sandbox seed file %s: %v", seed, err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return fmt.Errorf("sandbox seed file %s: %v", seed, err)
+	}
+	return dst.Close()
+}
@@ -0,0 +1,226 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"promptline/internal/config"
+	"promptline/internal/tools"
+)
+
+func TestSessionSandbox(t *testing.T) {
+	start := t.TempDir()
+	t.Chdir(start)
+	previousRoot, err := tools.WorkRoot()
+	if err != nil {
+		t.Fatalf("failed to read work root: %v", err)
+	}
+	if err := tools.ConfigureWorkRoot(""); err != nil {
+		t.Fatalf("failed to configure work root: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = tools.ConfigureWorkRoot(previousRoot)
+	})
+	if err := os.MkdirAll(filepath.Join(start, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(start, "src", "seed.txt"), []byte("seed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		APIKey:           "test-key",
+		Model:            "gpt-4o-mini",
+		SandboxDir:       filepath.Join(start, "sandboxes"),
+		SandboxSeedFiles: []string{"src/seed.txt"},
+		Tools:            config.ToolSettings{Allow: []string{"create_file", "read_file"}},
+	}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+	dir, err := session.OpenSandbox()
+	if err != nil {
+		t.Fatalf("OpenSandbox failed: %v", err)
+	}
+	if session.SandboxPath() != dir {
+		t.Errorf("expected sandbox path %q, got %q", dir, session.SandboxPath())
+	}
+
+	result := session.ToolRegistry.Execute("read_file", map[string]interface{}{"path": "src/seed.txt"})
+	if result.Error != nil || result.Result != "seed" {
+		t.Errorf("expected seeded file in sandbox, got %q (%v)", result.Result, result.Error)
+	}
+
+	result = session.ToolRegistry.Execute("create_file", map[string]interface{}{"path": "out.txt", "content": "hello"})
+	if result.Error != nil {
+		t.Fatalf("create_file failed: %v", result.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "out.txt")); err != nil {
+		t.Errorf("expected write inside the sandbox: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(start, "out.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected no write in the startup directory, got %v", err)
+	}
+
+	result = session.ToolRegistry.Execute("create_file", map[string]interface{}{"path": "../../escape.txt", "content": "x"})
+	if !errors.Is(result.Error, tools.ErrPathEscapesWorkdir) {
+		t.Errorf("expected escape to be blocked, got %v", result.Error)
+	}
+	result = session.ToolRegistry.Execute("create_file", map[string]interface{}{"path": filepath.Join(start, "escape.txt"), "content": "x"})
+	if result.Error == nil {
+		t.Error("expected absolute path outside the sandbox to be blocked")
+	}
+	if _, err := os.Stat(filepath.Join(start, "escape.txt")); !os.IsNotExist(err) {
+		t.Errorf("expected no escaped write, got %v", err)
+	}
+
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected sandbox to be removed, got %v", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(start); cwd != resolved {
+		t.Errorf("expected working directory to stay %q, got %q", resolved, cwd)
+	}
+}
+
+func TestSessionSandboxKeepsHistory(t *testing.T) {
+	start := t.TempDir()
+	t.Chdir(start)
+	previousRoot, err := tools.WorkRoot()
+	if err != nil {
+		t.Fatalf("failed to read work root: %v", err)
+	}
+	if err := tools.ConfigureWorkRoot(""); err != nil {
+		t.Fatalf("failed to configure work root: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = tools.ConfigureWorkRoot(previousRoot)
+	})
+
+	cfg := &config.Config{
+		APIKey:      "test-key",
+		Model:       "gpt-4o-mini",
+		SandboxDir:  "sandboxes",
+		HistoryFile: "history.jsonl",
+	}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+	if _, err := session.OpenSandbox(); err != nil {
+		t.Fatalf("OpenSandbox failed: %v", err)
+	}
+	session.AddMessage("user", "remember me")
+	session.AddMessage("assistant", "remembered")
+	if err := session.SaveConversationHistory(cfg.HistoryFile); err != nil {
+		t.Fatalf("SaveConversationHistory failed: %v", err)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(start, "history.jsonl")); err != nil {
+		t.Fatalf("expected history in the startup directory: %v", err)
+	}
+
+	resumed := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, NewEchoClient(EchoModePlain))
+	if err := resumed.LoadConversationHistory("history.jsonl", 100); err != nil {
+		t.Fatalf("LoadConversationHistory failed: %v", err)
+	}
+	var contents []string
+	for _, msg := range resumed.MessagesSnapshot() {
+		if msg.Role != "system" {
+			contents = append(contents, msg.Content)
+		}
+	}
+	if len(contents) != 2 || contents[0] != "remember me" || contents[1] != "remembered" {
+		t.Errorf("expected history to survive the sandbox, got %q", contents)
+	}
+}
+
+func TestSessionSandboxKept(t *testing.T) {
+	t.Chdir(t.TempDir())
+	previousRoot, err := tools.WorkRoot()
+	if err != nil {
+		t.Fatalf("failed to read work root: %v", err)
+	}
+	if err := tools.ConfigureWorkRoot(""); err != nil {
+		t.Fatalf("failed to configure work root: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = tools.ConfigureWorkRoot(previousRoot)
+	})
+
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", SandboxDir: t.TempDir(), KeepSandbox: true}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+	dir, err := session.OpenSandbox()
+	if err != nil {
+		t.Fatalf("OpenSandbox failed: %v", err)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("expected kept sandbox, got %v", err)
+	}
+}
+
+func TestSessionCloseRemovesTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if _, err := tools.RemoveCreatedTemps(); err != nil {
+		t.Fatalf("failed to reset temp tracking: %v", err)
+	}
+
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", Tools: config.ToolSettings{Allow: []string{"mktemp"}}}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+	var created []string
+	for _, args := range []map[string]interface{}{{}, {"dir": true}} {
+		result := session.ToolRegistry.Execute("mktemp", args)
+		if result.Error != nil {
+			t.Fatalf("mktemp failed: %v", result.Error)
+		}
+		created = append(created, result.Result)
+	}
+
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	for _, path := range created {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", path, err)
+		}
+	}
+
+	keep := false
+	cfg.CleanTempOnClose = &keep
+	session = NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+	result := session.ToolRegistry.Execute("mktemp", map[string]interface{}{})
+	if result.Error != nil {
+		t.Fatalf("mktemp failed: %v", result.Error)
+	}
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(result.Result); err != nil {
+		t.Errorf("expected temp file to stay with clean_temp_on_close off: %v", err)
+	}
+}
@@ -19,6 +19,7 @@ package chat
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -53,9 +54,27 @@ type Session struct {
 	Logger            *zerolog.Logger
 	SessionID         string
 	DryRun            bool
+	ToolsDisabled     bool           // send requests without tool definitions so the model cannot call tools
+	NoUserPrefix      bool           // send user messages without Config.UserMessagePrefix
+	ClientFactory     ClientFactory  // builds clients for Config.Fallbacks; nil uses the OpenAI client
+	TokenEstimator    TokenEstimator // sizes the conversation for ContextUsage (nil uses CharTokenEstimator); when set, history is also trimmed to the context window of the model
+	StreamSink        StreamSink     // also receives every StreamEvent of a reply, e.g. an EventSocket; nil sends them to the caller only
 	requestCounter    uint64
 	mu                sync.Mutex
 	lastSavedMsgCount int // Track how many messages were last saved (protected by mu)
+	saveCount         uint64
+	checkpoints       map[string]checkpoint
+	fallbackClients   map[int]ChatClient
+	nextTemperature   *float32     // one-shot override for the next request (protected by mu)
+	attachments       []Attachment // files for the next user message (protected by mu)
+	userTurns         int          // user messages sent since the last clear, for reminders (protected by mu)
+	sandbox           *sandbox     // per-session directory from OpenSandbox (protected by mu)
+	turnTrace         []ToolTrace  // tool calls of the current user turn (protected by mu)
+	pinnedTurns       map[int]bool // indexes of user messages whose turns trimming keeps (protected by mu)
+	requestDump       io.Writer    // one-shot target of DumpNextRequest (protected by mu)
+	transcriptMu      sync.Mutex
+	observersMu       sync.Mutex
+	observers         []*observer // subscribers added with AddObserver (protected by observersMu)
 }
 
 // ToolApprovalFunc determines whether a tool call is approved for execution.
@@ -85,9 +104,8 @@ func NewSession(cfg *config.Config) *Session {
 	clientConfig := openai.DefaultConfig(cfg.APIKey)
 	if cfg.APIURL != "" {
 		clientConfig.BaseURL = cfg.APIURL
-		// For DashScope, we might need to set a custom HTTP client
-		clientConfig.HTTPClient = &http.Client{}
 	}
+	clientConfig.HTTPClient = newHTTPClient(cfg)
 
 	client := openai.NewClientWithConfig(clientConfig)
 	sess := NewSessionWithClient(cfg, client)
@@ -95,6 +113,18 @@ func NewSession(cfg *config.Config) *Session {
 	return sess
 }
 
+// newHTTPClient returns the HTTP client for provider requests with the proxy
+// and TLS settings of cfg. LoadConfig has already checked them; should the CA
+// file have become unreadable since, the default transport is used and
+// certificate checks fail instead of being skipped.
+func newHTTPClient(cfg *config.Config) *http.Client {
+	client := &http.Client{}
+	if transport, err := cfg.HTTPTransport(); err == nil && transport != nil {
+		client.Transport = transport
+	}
+	return client
+}
+
 // NewSessionWithClient creates a new chat session with a provided client (for testing).
 func NewSessionWithClient(cfg *config.Config, client ChatClient) *Session {
 	// Initialize tool registry
@@ -106,23 +136,26 @@ func NewSessionWithClient(cfg *config.Config, client ChatClient) *Session {
 	toolRegistry := tools.NewRegistryWithPolicy(cfg.ToolPolicy())
 	toolRegistry.ConfigureRateLimits(cfg.ToolRateLimitsConfig())
 	toolRegistry.ConfigureTimeouts(cfg.ToolTimeoutsConfig())
+	toolRegistry.ConfigureCache(cfg.ToolCacheConfig())
+	toolRegistry.ConfigureDescriptions(cfg.ToolDescriptions)
 	tools.ConfigureOutputFilters(cfg.ToolOutputFiltersConfig())
+	tools.ConfigureLineEnding(cfg.LineEnding)
+	tools.ConfigureLatin1Fallback(cfg.Latin1Fallback)
 
 	if client == nil {
 		clientConfig := openai.DefaultConfig(cfg.APIKey)
 		if cfg.APIURL != "" {
 			clientConfig.BaseURL = cfg.APIURL
 		}
+		clientConfig.HTTPClient = newHTTPClient(cfg)
 		client = openai.NewClientWithConfig(clientConfig)
 	}
 
-	systemPrompt := defaultSystemPrompt
-
 	// Initialize with system message
 	messages := []openai.ChatCompletionMessage{
 		{
 			Role:    openai.ChatMessageRoleSystem,
-			Content: systemPrompt,
+			Content: composeSystemPrompt(cfg),
 		},
 	}
 
@@ -146,10 +179,14 @@ func NewSessionWithClient(cfg *config.Config, client ChatClient) *Session {
 func (s *Session) AddMessage(role, content string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Messages = append(s.Messages, openai.ChatCompletionMessage{
+	msg := openai.ChatCompletionMessage{
 		Role:    role,
 		Content: content,
-	})
+	}
+	s.Messages = append(s.Messages, msg)
+	if role == openai.ChatMessageRoleUser {
+		s.emit(SessionEvent{Type: SessionEventUserMessage, Message: &msg})
+	}
 	s.trimHistoryLocked()
 }
 
@@ -157,11 +194,17 @@ func (s *Session) AddMessage(role, content string) {
 func (s *Session) AddAssistantMessage(content string, toolCalls []openai.ToolCall) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.Messages = append(s.Messages, openai.ChatCompletionMessage{
+	msg := openai.ChatCompletionMessage{
 		Role:      openai.ChatMessageRoleAssistant,
 		Content:   content,
 		ToolCalls: toolCalls,
-	})
+	}
+	s.Messages = append(s.Messages, msg)
+	s.emit(SessionEvent{Type: SessionEventAssistantMessage, Message: &msg})
+	if len(toolCalls) == 0 {
+		// A reply without tool calls ends the turn.
+		s.compactToolResultsLocked()
+	}
 	s.trimHistoryLocked()
 }
 
@@ -179,9 +222,17 @@ func (s *Session) AddToolResultMessage(call openai.ToolCall, result *tools.ToolR
 		content = result.Result
 		if result.Error != nil {
 			content = fmt.Sprintf("Error: %v", result.Error)
+			if hint := tools.ErrorHint(result.Error); hint != "" {
+				content += "\nHint: " + hint
+			}
 		}
 	}
 
+	// The cap applies to the model's copy only; the caller displays result as is.
+	if s.Config != nil {
+		content = limitToolResult(content, s.Config.ToolResultMaxChars)
+	}
+
 	name := call.Function.Name
 	if name == "" {
 		name = "unknown_tool"
@@ -192,6 +243,8 @@ func (s *Session) AddToolResultMessage(call openai.ToolCall, result *tools.ToolR
 		Name:       name,
 		ToolCallID: call.ID,
 	})
+	trace := s.recordToolTraceLocked(call, name, result)
+	s.emit(SessionEvent{Type: SessionEventToolExecuted, Tool: &trace})
 	s.trimHistoryLocked()
 }
 
@@ -224,36 +277,38 @@ func (s *Session) MessagesSnapshot() []openai.ChatCompletionMessage {
 // GetResponseWithContext gets a response from the OpenAI API
 // Handles tool calls recursively until a final text response is received
 func (s *Session) GetResponseWithContext(ctx context.Context, prompt string) (string, error) {
-	s.AddMessage(openai.ChatMessageRoleUser, prompt)
+	s.addUserMessage(prompt)
 
 	// Loop to handle tool calls
-	for {
+	for attempt := 0; ; {
 		start := time.Now()
 		requestID := s.nextRequestID()
-		req := openai.ChatCompletionRequest{
-			Model:    s.Config.Model,
-			Messages: s.MessagesSnapshot(),
-			Tools:    s.ToolRegistry.OpenAITools(),
-		}
-
-		if s.Config.Temperature != nil {
-			req.Temperature = *s.Config.Temperature
-		}
-
-		if s.Config.MaxTokens != nil {
-			req.MaxTokens = *s.Config.MaxTokens
-		}
-
-		s.debugLogRequest(requestID, "create_completion", req)
-		resp, err := s.Client.CreateChatCompletion(ctx, req)
+		req := s.chatRequest(false)
+		prefill := s.applyPrefill(&req)
+		resp, err := s.createCompletion(ctx, requestID, req)
 		if err != nil {
 			s.debugLogError(requestID, "create_completion", err)
-			return "", NewAPIError("create_completion", err)
+			s.writeTranscript(requestID, req, nil, nil, time.Since(start), err)
+			apiErr := NewAPIError("create_completion", err)
+			s.emit(SessionEvent{Type: SessionEventError, Err: apiErr})
+			return "", apiErr
 		}
 		s.debugLogCompletion(requestID, "create_completion", time.Since(start), resp)
 
 		response := resp.Choices[0].Message
+		completePrefill(prefill, &response)
 		s.AddAssistantMessage(response.Content, response.ToolCalls)
+		s.writeTranscript(requestID, req, &response, &resp.Usage, time.Since(start), nil)
+
+		if isEmptyReply(&response) {
+			if s.retryEmptyReply(requestID, attempt) {
+				attempt++
+				continue
+			}
+			s.emit(SessionEvent{Type: SessionEventError, Err: ErrEmptyResponse})
+			return "", ErrEmptyResponse
+		}
+		attempt = 0
 
 		// If no tool calls, return the response
 		if len(response.ToolCalls) == 0 {
@@ -279,6 +334,17 @@ func (s *Session) ExecuteToolCallWithApproval(call openai.ToolCall) *tools.ToolR
 	if name == "" {
 		return invalidToolResult("unknown_tool", fmt.Errorf("%w: tool call missing function name", tools.ErrInvalidArguments))
 	}
+	if resolved, ok := s.ToolRegistry.ResolveAlias(name); ok {
+		if logger := s.sessionLogger(); logger != nil {
+			logger.Debug().
+				Str("alias", name).
+				Str("tool_name", resolved).
+				Msg("Tool alias resolved")
+		}
+		// The approver and the executor see the registered name.
+		name = resolved
+		call.Function.Name = resolved
+	}
 	if err := s.ToolRegistry.ValidateToolCall(name, call.Function.Arguments); err != nil {
 		return err
 	}
@@ -295,7 +361,16 @@ func (s *Session) ExecuteToolCallWithApproval(call openai.ToolCall) *tools.ToolR
 	case tools.PermissionDeny:
 		return deniedToolResult(name, fmt.Sprintf("Tool %q is denied by policy.", name), tools.ErrToolNotAllowed)
 	case tools.PermissionAsk:
-		if s.ToolApprover == nil {
+		approver := s.ToolApprover
+		if approver == nil {
+			// Without an interactive approver, fall back to the approval hook.
+			if hook := s.approvalHook(); hook != "" {
+				approver = func(call openai.ToolCall) (bool, error) {
+					return s.runApprovalHook(hook, call)
+				}
+			}
+		}
+		if approver == nil {
 			return deniedToolResult(name, fmt.Sprintf("Tool %q requires user approval, but no approver is configured.", name), tools.ErrToolDeniedByUser)
 		}
 		if logger := s.sessionLogger(); logger != nil {
@@ -303,7 +378,32 @@ func (s *Session) ExecuteToolCallWithApproval(call openai.ToolCall) *tools.ToolR
 				Str("tool_name", name).
 				Msg("Awaiting tool approval")
 		}
-		approved, err := s.ToolApprover(call)
+		approved, err := approver(call)
+		if errors.Is(err, ErrDeniedByHook) {
+			if logger := s.sessionLogger(); logger != nil {
+				logger.Debug().
+					Str("tool_name", name).
+					Err(err).
+					Msg("Tool approval denied by hook")
+			}
+			return deniedToolResult(name, fmt.Sprintf("Tool %q was %v", name, err), fmt.Errorf("%w: %w", tools.ErrToolDeniedByUser, err))
+		}
+		if errors.Is(err, tools.ErrToolApprovalTimeout) {
+			if logger := s.sessionLogger(); logger != nil {
+				logger.Debug().
+					Str("tool_name", name).
+					Msg("Tool approval timed out")
+			}
+			return deniedToolResult(name, fmt.Sprintf("Tool %q was denied: timed out awaiting approval.", name), fmt.Errorf("%w: %w", tools.ErrToolDeniedByUser, err))
+		}
+		if errors.Is(err, context.Canceled) {
+			if logger := s.sessionLogger(); logger != nil {
+				logger.Debug().
+					Str("tool_name", name).
+					Msg("Tool approval cancelled")
+			}
+			return deniedToolResult(name, fmt.Sprintf("Tool %q was denied: the user cancelled the turn.", name), fmt.Errorf("%w: %w", tools.ErrToolDeniedByUser, err))
+		}
 		if err != nil {
 			if logger := s.sessionLogger(); logger != nil {
 				logger.Debug().
@@ -360,6 +460,14 @@ const (
 	StreamEventContent StreamEventType = iota
 	StreamEventToolCall
 	StreamEventError
+	StreamEventToolProgress
+)
+
+// Tool argument progress is reported once arguments reach toolProgressMinBytes,
+// then at most once per toolProgressInterval for each tool call.
+const (
+	toolProgressMinBytes = 256
+	toolProgressInterval = 150 * time.Millisecond
 )
 
 // StreamEvent represents a chunk of streamed data from the model.
@@ -368,6 +476,9 @@ type StreamEvent struct {
 	Content  string
 	ToolCall *openai.ToolCall
 	Err      error
+	// ToolName and ArgBytes describe a tool call whose arguments are still streaming.
+	ToolName string
+	ArgBytes int
 }
 
 // NewContentEvent creates a content streaming event.
@@ -380,6 +491,11 @@ func NewToolCallEvent(toolCall *openai.ToolCall) StreamEvent {
 	return StreamEvent{Type: StreamEventToolCall, ToolCall: toolCall}
 }
 
+// NewToolProgressEvent reports how many argument bytes a pending tool call has received.
+func NewToolProgressEvent(toolName string, argBytes int) StreamEvent {
+	return StreamEvent{Type: StreamEventToolProgress, ToolName: toolName, ArgBytes: argBytes}
+}
+
 // NewErrorEvent creates an error streaming event.
 func NewErrorEvent(err error) StreamEvent {
 	return StreamEvent{Type: StreamEventError, Err: err}
@@ -388,34 +504,239 @@ func NewErrorEvent(err error) StreamEvent {
 // StreamResponseWithContext gets a streaming response from the OpenAI API and sends it through a channel of events.
 // If includeUserMessage is true, the prompt is added as a user message before sending the request.
 func (s *Session) StreamResponseWithContext(ctx context.Context, prompt string, includeUserMessage bool, events chan<- StreamEvent) {
+	events = s.tapStreamEvents(events)
 	defer close(events)
 
 	if includeUserMessage && prompt != "" {
-		s.AddMessage(openai.ChatMessageRoleUser, prompt)
+		s.addUserMessage(prompt)
 	}
 
-	start := time.Now()
-	requestID := s.nextRequestID()
-	stream, err := s.createStream(ctx, requestID)
-	if err != nil {
-		s.debugLogError(requestID, "create_stream", err)
-		events <- NewErrorEvent(NewStreamError("create_stream", err))
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		requestID := s.nextRequestID()
+		req := s.chatRequest(true)
+		prefill := s.applyPrefill(&req)
+		// The watchdog cancels only this request's context, so a stall is told
+		// apart from the caller cancelling.
+		streamCtx, cancelStream := context.WithCancel(ctx)
+		watchdog := newStallWatchdog(s.Config.StreamStallTimeout(), cancelStream)
+		stream, err := s.createStream(streamCtx, requestID, req)
+		if err != nil {
+			watchdog.stop()
+			cancelStream()
+			if watchdog.stalled() {
+				err = s.stallError()
+			}
+			s.debugLogError(requestID, "create_stream", err)
+			s.writeTranscript(requestID, req, nil, nil, time.Since(start), err)
+			s.sendError(events, NewStreamError("create_stream", err))
+			return
+		}
+
+		reply, usage, err := s.processStream(ctx, stream, watchdog, events, start, requestID, prefill)
+		watchdog.stop()
+		stream.Close()
+		cancelStream()
+		s.writeTranscript(requestID, req, reply, usage, time.Since(start), err)
+		if err != nil || !isEmptyReply(reply) {
+			return
+		}
+		if !s.retryEmptyReply(requestID, attempt) {
+			s.sendError(events, ErrEmptyResponse)
+			return
+		}
+	}
+}
+
+// CompleteResponseWithContext is the non-streaming counterpart of
+// StreamResponseWithContext. It waits for the full reply and reports it as a
+// single content event followed by any tool call events, so callers handle
+// both modes the same way. It closes events when done.
+func (s *Session) CompleteResponseWithContext(ctx context.Context, prompt string, includeUserMessage bool, events chan<- StreamEvent) {
+	events = s.tapStreamEvents(events)
+	defer close(events)
+
+	if includeUserMessage && prompt != "" {
+		s.addUserMessage(prompt)
+	}
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		requestID := s.nextRequestID()
+		req := s.chatRequest(false)
+		prefill := s.applyPrefill(&req)
+		resp, err := s.createCompletion(ctx, requestID, req)
+		if err != nil {
+			s.debugLogError(requestID, "create_completion", err)
+			s.writeTranscript(requestID, req, nil, nil, time.Since(start), err)
+			s.sendError(events, NewAPIError("create_completion", err))
+			return
+		}
+		s.debugLogCompletion(requestID, "create_completion", time.Since(start), resp)
+
+		message := resp.Choices[0].Message
+		completePrefill(prefill, &message)
+		s.AddAssistantMessage(message.Content, message.ToolCalls)
+		s.writeTranscript(requestID, req, &message, &resp.Usage, time.Since(start), nil)
+		if isEmptyReply(&message) {
+			if s.retryEmptyReply(requestID, attempt) {
+				continue
+			}
+			s.sendError(events, ErrEmptyResponse)
+			return
+		}
+		if message.Content != "" {
+			events <- NewContentEvent(message.Content)
+		}
+		s.emitToolCalls(message.ToolCalls, events)
+		return
+	}
+}
+
+// RespondWithContext answers with StreamResponseWithContext or
+// CompleteResponseWithContext depending on the streaming setting.
+func (s *Session) RespondWithContext(ctx context.Context, prompt string, includeUserMessage bool, events chan<- StreamEvent) {
+	if !s.Config.StreamingEnabled() {
+		s.CompleteResponseWithContext(ctx, prompt, includeUserMessage, events)
 		return
 	}
-	defer stream.Close()
+	s.StreamResponseWithContext(ctx, prompt, includeUserMessage, events)
+}
 
-	s.processStream(ctx, stream, events, start, requestID)
+// RegenerateLastResponse drops the last assistant turn, including any tool calls
+// and results, and requests a new answer to the same user message. A non-nil
+// tempOverride replaces the configured temperature for this request only.
+// Like StreamResponseWithContext it closes events when done.
+func (s *Session) RegenerateLastResponse(ctx context.Context, tempOverride *float32, events chan<- StreamEvent) {
+	if err := s.dropLastResponse(tempOverride); err != nil {
+		s.sendError(events, err)
+		close(events)
+		return
+	}
+	s.RespondWithContext(ctx, "", false, events)
 }
 
-func (s *Session) createStream(ctx context.Context, requestID string) (*openai.ChatCompletionStream, error) {
+// dropLastResponse removes everything after the last user message and arms
+// the temperature override for the next request.
+func (s *Session) dropLastResponse(tempOverride *float32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastUser := s.lastUserIndexLocked()
+	if lastUser < 0 || lastUser == len(s.Messages)-1 {
+		return ErrNothingToRegenerate
+	}
+	s.Messages = s.Messages[:lastUser+1]
+	s.prunePinsLocked()
+	// The history file is append-only: dropped messages that were already saved
+	// stay there, and the new answer is appended after them.
+	if s.lastSavedMsgCount > lastUser {
+		s.lastSavedMsgCount = lastUser
+	}
+	s.nextTemperature = tempOverride
+	return nil
+}
+
+// LastUserMessage returns the text of the last user message, if any.
+func (s *Session) LastUserMessage() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastUser := s.lastUserIndexLocked()
+	if lastUser < 0 {
+		return "", false
+	}
+	return s.Messages[lastUser].Content, true
+}
+
+// ReplaceLastUserTurn swaps the last user message for newText and drops the
+// answer and tool traffic that followed it. Call RespondWithContext without
+// including a user message afterwards to answer the edited prompt.
+func (s *Session) ReplaceLastUserTurn(newText string) error {
+	if strings.TrimSpace(newText) == "" {
+		return ErrEmptyMessage
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastUser := s.lastUserIndexLocked()
+	if lastUser < 0 {
+		return ErrNothingToEdit
+	}
+	// An edited turn keeps its pin.
+	wasPinned := s.pinnedTurns[lastUser]
+	s.Messages = s.Messages[:lastUser]
+	s.prunePinsLocked()
+	if wasPinned {
+		s.pinnedTurns[lastUser] = true
+	}
+	// As with /retry, the replaced turn stays in the append-only history file.
+	// lastSavedMsgCount counts messages after the system prompt, and only
+	// lastUser-1 of them are left.
+	if s.lastSavedMsgCount > lastUser-1 {
+		s.lastSavedMsgCount = lastUser - 1
+	}
+	msg := openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: newText,
+	}
+	s.Messages = append(s.Messages, msg)
+	s.emit(SessionEvent{Type: SessionEventUserMessage, Message: &msg})
+	return nil
+}
+
+// lastUserIndexLocked returns the index of the last user message, or -1.
+// The caller must hold s.mu.
+func (s *Session) lastUserIndexLocked() int {
+	for i := len(s.Messages) - 1; i > 0; i-- {
+		if s.Messages[i].Role == openai.ChatMessageRoleUser {
+			return i
+		}
+	}
+	return -1
+}
+
+// takeTemperatureOverride returns and clears the one-shot temperature override.
+func (s *Session) takeTemperatureOverride() *float32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	temp := s.nextTemperature
+	s.nextTemperature = nil
+	return temp
+}
+
+func (s *Session) createStream(ctx context.Context, requestID string, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+	s.debugLogRequest(requestID, "create_stream", req)
+	s.dumpRequest(requestID, req)
+	return withFailover(s, ctx, requestID, "create_stream", req, func(client ChatClient, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+		return client.CreateChatCompletionStream(ctx, req)
+	})
+}
+
+func (s *Session) createCompletion(ctx context.Context, requestID string, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	s.debugLogRequest(requestID, "create_completion", req)
+	s.dumpRequest(requestID, req)
+	resp, err := withFailover(s, ctx, requestID, "create_completion", req, func(client ChatClient, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+		return client.CreateChatCompletion(ctx, req)
+	})
+	if err == nil && len(resp.Choices) == 0 {
+		err = errors.New("response contains no choices")
+	}
+	return resp, err
+}
+
+// chatRequest builds a request for the current conversation from the session config.
+func (s *Session) chatRequest(stream bool) openai.ChatCompletionRequest {
 	req := openai.ChatCompletionRequest{
 		Model:    s.Config.Model,
 		Messages: s.MessagesSnapshot(),
-		Stream:   true,
-		Tools:    s.ToolRegistry.OpenAITools(),
+		Stream:   stream,
+	}
+	s.applyUserPrefix(&req)
+	if !s.ToolsDisabled {
+		req.Tools = s.ToolRegistry.OpenAITools()
 	}
 
-	if s.Config.Temperature != nil {
+	if temp := s.takeTemperatureOverride(); temp != nil {
+		req.Temperature = *temp
+	} else if s.Config.Temperature != nil {
 		req.Temperature = *s.Config.Temperature
 	}
 
@@ -423,20 +744,33 @@ func (s *Session) createStream(ctx context.Context, requestID string) (*openai.C
 		req.MaxTokens = *s.Config.MaxTokens
 	}
 
-	s.debugLogRequest(requestID, "create_stream", req)
-	return s.Client.CreateChatCompletionStream(ctx, req)
+	if len(s.Config.Stop) > 0 {
+		req.Stop = s.Config.Stop
+	}
+	return req
 }
 
 // processStream handles the streaming loop and local state accumulation.
 // Thread-safety: The contentBuilder, toolCalls, and argBuilders are local to
 // this function call and not shared with other goroutines, so no locking needed.
-func (s *Session) processStream(ctx context.Context, stream *openai.ChatCompletionStream, events chan<- StreamEvent, start time.Time, requestID string) {
+// It returns the assembled assistant message and the token usage, when the
+// provider reports it, or the error that ended the stream.
+//
+// Providers differ in the chunks they send: a first delta may carry only the
+// role, and the last chunk may carry only usage with no choices. Neither
+// produces events; a usage chunk is kept for the transcript.
+//
+// A non-empty prefill, sent as a partial assistant message, is emitted and
+// added to the reply just before its first text.
+func (s *Session) processStream(ctx context.Context, stream *openai.ChatCompletionStream, watchdog *stallWatchdog, events chan<- StreamEvent, start time.Time, requestID string, prefill string) (*openai.ChatCompletionMessage, *openai.Usage, error) {
 	contentBuilder := getBuilder()
 	defer putBuilder(contentBuilder)
 	toolCalls := make(map[string]*openai.ToolCall)
 	argBuilders := make(map[string]*strings.Builder)
 	indexToKey := make(map[int]string)
+	progress := make(map[string]time.Time)
 	var firstChunk time.Time
+	var usage *openai.Usage
 	recvCount := 0
 
 	for {
@@ -444,43 +778,69 @@ func (s *Session) processStream(ctx context.Context, stream *openai.ChatCompleti
 		case <-ctx.Done():
 			s.debugLogStreamEnd(requestID, "stream_cancelled", time.Since(start), recvCount, len(toolCalls), ctx.Err())
 			releaseBuilders(argBuilders)
-			events <- NewErrorEvent(ctx.Err())
-			return
+			s.sendError(events, ctx.Err())
+			return nil, nil, ctx.Err()
 		default:
 			response, err := stream.Recv()
+			if err != nil && watchdog.stalled() {
+				err = s.stallError()
+			}
 			if err != nil {
 				s.debugLogStreamEnd(requestID, "stream_recv", time.Since(start), recvCount, len(toolCalls), err)
-				s.handleStreamEnd(err, contentBuilder, toolCalls, argBuilders, events)
-				return
+				reply, err := s.handleStreamEnd(err, contentBuilder, toolCalls, argBuilders, events)
+				if err != nil {
+					return nil, nil, err
+				}
+				return reply, usage, nil
 			}
+			watchdog.reset()
 			recvCount++
 			if firstChunk.IsZero() {
 				firstChunk = time.Now()
 				s.debugLogFirstChunk(requestID, time.Since(start))
 			}
+			if response.Usage != nil {
+				usage = response.Usage
+			}
 
 			if len(response.Choices) == 0 {
 				continue
 			}
+			if prefill != "" && response.Choices[0].Delta.Content != "" {
+				contentBuilder.WriteString(prefill)
+				events <- NewContentEvent(prefill)
+				prefill = ""
+			}
 
-			s.handleStreamChunk(response.Choices[0].Delta, contentBuilder, toolCalls, argBuilders, indexToKey, events)
+			s.handleStreamChunk(response.Choices[0].Delta, contentBuilder, toolCalls, argBuilders, indexToKey, progress, events)
 		}
 	}
 }
 
-func (s *Session) handleStreamEnd(err error, contentBuilder *strings.Builder, toolCalls map[string]*openai.ToolCall, argBuilders map[string]*strings.Builder, events chan<- StreamEvent) {
+// stallError describes a stream abandoned by the stall watchdog.
+func (s *Session) stallError() error {
+	return fmt.Errorf("%w: no data received for %s", ErrStreamStalled, s.Config.StreamStallTimeout())
+}
+
+func (s *Session) handleStreamEnd(err error, contentBuilder *strings.Builder, toolCalls map[string]*openai.ToolCall, argBuilders map[string]*strings.Builder, events chan<- StreamEvent) (*openai.ChatCompletionMessage, error) {
 	if err == io.EOF {
 		finalCalls := finalizeToolCalls(toolCalls, argBuilders)
-		s.AddAssistantMessage(contentBuilder.String(), finalCalls)
+		reply := &openai.ChatCompletionMessage{
+			Role:      openai.ChatMessageRoleAssistant,
+			Content:   contentBuilder.String(),
+			ToolCalls: finalCalls,
+		}
+		s.AddAssistantMessage(reply.Content, finalCalls)
 		releaseBuilders(argBuilders)
 		s.emitToolCalls(finalCalls, events)
-		return
+		return reply, nil
 	}
 	releaseBuilders(argBuilders)
-	events <- NewErrorEvent(NewStreamError("receive_chunk", err))
+	s.sendError(events, NewStreamError("receive_chunk", err))
+	return nil, err
 }
 
-func (s *Session) handleStreamChunk(delta openai.ChatCompletionStreamChoiceDelta, contentBuilder *strings.Builder, toolCalls map[string]*openai.ToolCall, argBuilders map[string]*strings.Builder, indexToKey map[int]string, events chan<- StreamEvent) {
+func (s *Session) handleStreamChunk(delta openai.ChatCompletionStreamChoiceDelta, contentBuilder *strings.Builder, toolCalls map[string]*openai.ToolCall, argBuilders map[string]*strings.Builder, indexToKey map[int]string, progress map[string]time.Time, events chan<- StreamEvent) {
 	if delta.Content != "" {
 		contentBuilder.WriteString(delta.Content)
 		events <- NewContentEvent(delta.Content)
@@ -490,10 +850,26 @@ func (s *Session) handleStreamChunk(delta openai.ChatCompletionStreamChoiceDelta
 		key, entry := accumulateToolCall(toolCalls, argBuilders, indexToKey, tc)
 		if entry != nil && key != "" {
 			toolCalls[key] = entry
+			if tc.Function.Arguments != "" {
+				emitToolProgress(key, entry.Function.Name, argBuilders[key].Len(), progress, events)
+			}
 		}
 	}
 }
 
+// emitToolProgress sends a throttled progress event for a tool call whose arguments grew.
+func emitToolProgress(key, name string, argBytes int, progress map[string]time.Time, events chan<- StreamEvent) {
+	if progress == nil || argBytes < toolProgressMinBytes {
+		return
+	}
+	now := time.Now()
+	if last, ok := progress[key]; ok && now.Sub(last) < toolProgressInterval {
+		return
+	}
+	progress[key] = now
+	events <- NewToolProgressEvent(name, argBytes)
+}
+
 func (s *Session) emitToolCalls(finalCalls []openai.ToolCall, events chan<- StreamEvent) {
 	for _, call := range finalCalls {
 		callCopy := call
@@ -725,6 +1101,12 @@ func (s *Session) ClearHistory() {
 	defer s.mu.Unlock()
 	systemMsg := s.Messages[0]
 	s.Messages = []openai.ChatCompletionMessage{systemMsg}
+	s.attachments = nil
+	s.userTurns = 0
+	s.turnTrace = nil
+	s.pinnedTurns = nil
+	// Earlier messages are already in the history file; what follows is new.
+	s.lastSavedMsgCount = 0
 }
 
 // GetHistory returns the conversation history excluding system message
@@ -737,6 +1119,14 @@ func (s *Session) GetHistory() []openai.ChatCompletionMessage {
 	return s.Messages[1:]
 }
 
+// UnsavedMessageCount returns how many conversation messages have not been
+// written by SaveConversationHistory yet.
+func (s *Session) UnsavedMessageCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return max(0, len(s.Messages)-1-s.lastSavedMsgCount)
+}
+
 // SaveConversationHistory appends new messages to the history file
 func (s *Session) SaveConversationHistory(filepath string) error {
 	s.mu.Lock()
@@ -750,25 +1140,63 @@ func (s *Session) SaveConversationHistory(filepath string) error {
 		return nil // Nothing new to save
 	}
 
+	if s.Config != nil && s.Config.HistoryMaxBytes > 0 {
+		if err := rotateHistoryFile(filepath, s.Config.HistoryMaxBytes, time.Now()); err != nil {
+			return NewHistoryError("rotate", filepath, err)
+		}
+	}
+
 	file, err := os.OpenFile(filepath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return NewHistoryError("open", filepath, err)
 	}
 	defer file.Close()
+	unlock, err := lockHistoryFile(file, true)
+	if err != nil {
+		return NewHistoryError("lock", filepath, err)
+	}
+	defer unlock()
 
 	encoder := json.NewEncoder(file)
 	// Only save messages we haven't saved yet
 	for i := s.lastSavedMsgCount; i < len(history); i++ {
+		if isReminder(history[i]) {
+			continue
+		}
 		if err := encoder.Encode(history[i]); err != nil {
 			return NewHistoryError("encode", filepath, err)
 		}
 	}
 
 	s.lastSavedMsgCount = len(history)
+	s.saveCount++
 	s.trimHistoryLocked()
 	return nil
 }
 
+// rotateHistoryFile renames path with a timestamp suffix once it reaches
+// maxBytes, so the next write starts a fresh file.
+func rotateHistoryFile(path string, maxBytes int64, now time.Time) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Size() < maxBytes {
+		return nil
+	}
+	rotated := path + "." + now.Format("20060102-150405")
+	for i := 1; ; i++ {
+		if _, err := os.Stat(rotated); os.IsNotExist(err) {
+			break
+		}
+		rotated = fmt.Sprintf("%s.%s-%d", path, now.Format("20060102-150405"), i)
+	}
+	return os.Rename(path, rotated)
+}
+
 // LoadConversationHistory loads conversation history from a file with a line limit
 func (s *Session) LoadConversationHistory(filepath string, maxLines int) error {
 	s.mu.Lock()
@@ -782,6 +1210,11 @@ func (s *Session) LoadConversationHistory(filepath string, maxLines int) error {
 		return NewHistoryError("open", filepath, err)
 	}
 	defer file.Close()
+	unlock, err := lockHistoryFile(file, false)
+	if err != nil {
+		return NewHistoryError("lock", filepath, err)
+	}
+	defer unlock()
 
 	// Read all lines
 	var messages []openai.ChatCompletionMessage
@@ -798,6 +1231,9 @@ func (s *Session) LoadConversationHistory(filepath string, maxLines int) error {
 			}
 			return NewHistoryError("decode", filepath, err)
 		}
+		if isReminder(msg) {
+			continue
+		}
 		messages = append(messages, msg)
 		if limit > 0 && len(messages) > limit {
 			messages = messages[1:]
@@ -815,26 +1251,54 @@ func (s *Session) LoadConversationHistory(filepath string, maxLines int) error {
 }
 
 func (s *Session) trimHistoryLocked() {
-	if s.Config == nil || s.Config.HistoryMaxMessages <= 0 {
-		return
-	}
-	if len(s.Messages) <= 1 {
+	if s.Config == nil || len(s.Messages) <= 1 {
 		return
 	}
-	historyCount := len(s.Messages) - 1
-	overflow := historyCount - s.Config.HistoryMaxMessages
-	if overflow <= 0 {
-		return
+	// Only the first bound messages after the system prompt may be dropped.
+	bound := s.lastSavedMsgCount
+	drop := 0
+	if s.Config.HistoryMaxMessages > 0 {
+		overflow := len(s.Messages) - 1 - s.Config.HistoryMaxMessages
+		drop = max(0, min(overflow, bound))
 	}
-	drop := overflow
-	if drop > s.lastSavedMsgCount {
-		drop = s.lastSavedMsgCount
+	if tokenDrop := s.tokenOverflowLocked(); tokenDrop > drop {
+		// Messages not yet in the history file are kept so it stays complete.
+		if s.Config.HistoryFile != "" {
+			tokenDrop = min(tokenDrop, bound)
+		} else {
+			bound = len(s.Messages) - 1
+		}
+		drop = max(drop, tokenDrop)
 	}
 	if drop <= 0 {
 		return
 	}
-	s.Messages = append([]openai.ChatCompletionMessage{s.Messages[0]}, s.Messages[1+drop:]...)
-	s.lastSavedMsgCount -= drop
+
+	// The oldest messages go first, except those of pinned turns, which are
+	// skipped rather than counted.
+	pinned := s.pinnedMessagesLocked()
+	kept := []openai.ChatCompletionMessage{s.Messages[0]}
+	var keptPins map[int]bool
+	removed, removedSaved := 0, 0
+	for i := 1; i < len(s.Messages); i++ {
+		if removed < drop && i <= bound && !pinned[i] {
+			removed++
+			if i <= s.lastSavedMsgCount {
+				removedSaved++
+			}
+			continue
+		}
+		if s.pinnedTurns[i] {
+			if keptPins == nil {
+				keptPins = make(map[int]bool)
+			}
+			keptPins[len(kept)] = true
+		}
+		kept = append(kept, s.Messages[i])
+	}
+	s.Messages = kept
+	s.pinnedTurns = keptPins
+	s.lastSavedMsgCount -= removedSaved
 }
 
 // PrintHistory prints the conversation history
@@ -863,7 +1327,22 @@ func (s *Session) FormatToolCallDisplay(toolCall openai.ToolCall, result *tools.
 	return tools.FormatToolResult(toolCall, result, false)
 }
 
-// Close is a no-op for compatibility but may be used for cleanup in the future
+// Close releases session resources: it lets observers handle their queued
+// events and unsubscribes them, forgets the files kept for read_file
+// show_diff, deletes the files mktemp created unless clean_temp_on_close is
+// false, and leaves and removes the sandbox created by OpenSandbox, unless
+// keep_sandbox is set.
 func (s *Session) Close() error {
-	return nil
+	s.closeObservers()
+	tools.ResetReadCache()
+	var errs []error
+	if s.Config == nil || s.Config.CleanTempOnCloseEnabled() {
+		if _, err := tools.RemoveCreatedTemps(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := s.closeSandbox(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
 }
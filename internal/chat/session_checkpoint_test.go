@@ -0,0 +1,156 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+)
+
+func newCheckpointSession() *Session {
+	return NewSession(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"})
+}
+
+func readHistoryContents(t *testing.T, path string) []string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	var contents []string
+	decoder := json.NewDecoder(file)
+	for {
+		var msg openai.ChatCompletionMessage
+		if err := decoder.Decode(&msg); err != nil {
+			break
+		}
+		contents = append(contents, msg.Content)
+	}
+	return contents
+}
+
+func TestCheckpointRestore(t *testing.T) {
+	session := newCheckpointSession()
+	session.AddMessage(openai.ChatMessageRoleUser, "main question")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "main answer")
+
+	if err := session.Checkpoint("main"); err != nil {
+		t.Fatalf("Checkpoint failed: %v", err)
+	}
+	session.AddMessage(openai.ChatMessageRoleUser, "tangent")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "tangent answer")
+
+	if err := session.RestoreCheckpoint("main"); err != nil {
+		t.Fatalf("RestoreCheckpoint failed: %v", err)
+	}
+	history := session.GetHistory()
+	if len(history) != 2 || history[1].Content != "main answer" {
+		t.Fatalf("expected history rolled back to checkpoint, got %+v", history)
+	}
+
+	// The checkpoint is a snapshot, so changes after restoring don't leak into it.
+	session.AddMessage(openai.ChatMessageRoleUser, "another tangent")
+	if err := session.RestoreCheckpoint("main"); err != nil {
+		t.Fatalf("second RestoreCheckpoint failed: %v", err)
+	}
+	if len(session.GetHistory()) != 2 {
+		t.Fatalf("expected checkpoint to be unchanged, got %d messages", len(session.GetHistory()))
+	}
+}
+
+func TestCheckpointErrors(t *testing.T) {
+	session := newCheckpointSession()
+	if err := session.Checkpoint("  "); err == nil {
+		t.Error("expected error for empty checkpoint name")
+	}
+	if err := session.RestoreCheckpoint("missing"); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Errorf("expected ErrCheckpointNotFound, got %v", err)
+	}
+}
+
+func TestCheckpointsListed(t *testing.T) {
+	session := newCheckpointSession()
+	if len(session.Checkpoints()) != 0 {
+		t.Fatal("expected no checkpoints in a new session")
+	}
+	_ = session.Checkpoint("start")
+	session.AddMessage(openai.ChatMessageRoleUser, "hello")
+	_ = session.Checkpoint("after-hello")
+
+	checkpoints := session.Checkpoints()
+	if len(checkpoints) != 2 {
+		t.Fatalf("expected 2 checkpoints, got %d", len(checkpoints))
+	}
+	if checkpoints[0].Name != "start" || checkpoints[0].Messages != 0 {
+		t.Errorf("unexpected first checkpoint %+v", checkpoints[0])
+	}
+	if checkpoints[1].Name != "after-hello" || checkpoints[1].Messages != 1 {
+		t.Errorf("unexpected second checkpoint %+v", checkpoints[1])
+	}
+}
+
+func TestRestoreCheckpointReconcilesSavedCount(t *testing.T) {
+	t.Run("checkpoint after save", func(t *testing.T) {
+		historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+		session := newCheckpointSession()
+		session.AddMessage(openai.ChatMessageRoleUser, "saved")
+		if err := session.SaveConversationHistory(historyFile); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+		_ = session.Checkpoint("cp")
+		session.AddMessage(openai.ChatMessageRoleUser, "tangent")
+		_ = session.RestoreCheckpoint("cp")
+		session.AddMessage(openai.ChatMessageRoleUser, "after restore")
+		if err := session.SaveConversationHistory(historyFile); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+
+		got := readHistoryContents(t, historyFile)
+		if len(got) != 2 || got[0] != "saved" || got[1] != "after restore" {
+			t.Fatalf("unexpected history file contents %q", got)
+		}
+	})
+
+	t.Run("save after checkpoint", func(t *testing.T) {
+		historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+		session := newCheckpointSession()
+		session.AddMessage(openai.ChatMessageRoleUser, "before checkpoint")
+		_ = session.Checkpoint("cp")
+		session.AddMessage(openai.ChatMessageRoleUser, "tangent")
+		if err := session.SaveConversationHistory(historyFile); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+		_ = session.RestoreCheckpoint("cp")
+		session.AddMessage(openai.ChatMessageRoleUser, "after restore")
+		if err := session.SaveConversationHistory(historyFile); err != nil {
+			t.Fatalf("save failed: %v", err)
+		}
+
+		got := readHistoryContents(t, historyFile)
+		if len(got) != 3 || got[0] != "before checkpoint" || got[2] != "after restore" {
+			t.Fatalf("expected checkpoint messages saved once, got %q", got)
+		}
+	})
+}
@@ -138,3 +138,34 @@ func TestMockClientCallTracking(t *testing.T) {
 		t.Errorf("expected second call to have 4 messages, got %d", len(mockClient.CompletionCalls[1].Messages))
 	}
 }
+
+func TestStopSequencesPropagateToRequests(t *testing.T) {
+	mockClient := &MockChatClient{}
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "gpt-4o-mini",
+		Stop:   []string{"\n\n###", "END"},
+	}
+	session := NewSessionWithClient(cfg, mockClient)
+
+	if _, err := session.GetResponse("Hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mockClient.CompletionCalls) != 1 {
+		t.Fatalf("expected 1 completion call, got %d", len(mockClient.CompletionCalls))
+	}
+	if got := mockClient.CompletionCalls[0].Stop; len(got) != 2 || got[0] != "\n\n###" || got[1] != "END" {
+		t.Errorf("expected stop sequences in completion request, got %q", got)
+	}
+
+	events := make(chan StreamEvent, 10)
+	go session.StreamResponseWithContext(context.Background(), "Again", true, events)
+	for range events {
+	}
+	if len(mockClient.CompletionStreamCalls) != 1 {
+		t.Fatalf("expected 1 stream call, got %d", len(mockClient.CompletionStreamCalls))
+	}
+	if got := mockClient.CompletionStreamCalls[0].Stop; len(got) != 2 || got[1] != "END" {
+		t.Errorf("expected stop sequences in stream request, got %q", got)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+)
+
+func newFallbackSession(primary ChatClient, fallback ChatClient) (*Session, *[]config.ProviderConfig) {
+	cfg := &config.Config{
+		APIKey: "primary-key",
+		Model:  "gpt-4o-mini",
+		Fallbacks: []config.ProviderConfig{
+			{APIURL: "http://fallback.invalid/v1", Model: "fallback-model"},
+		},
+	}
+	session := NewSessionWithClient(cfg, primary)
+	var built []config.ProviderConfig
+	session.ClientFactory = func(provider config.ProviderConfig) ChatClient {
+		built = append(built, provider)
+		return fallback
+	}
+	return session, &built
+}
+
+func TestFallbackOnServerError(t *testing.T) {
+	primaryDown := true
+	primary := &MockChatClient{
+		CreateCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			if primaryDown {
+				return openai.ChatCompletionResponse{}, &openai.APIError{HTTPStatusCode: http.StatusServiceUnavailable, Message: "unavailable"}
+			}
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "primary"},
+			}}}, nil
+		},
+	}
+	fallback := &MockChatClient{}
+	session, built := newFallbackSession(primary, fallback)
+
+	response, err := session.GetResponseWithContext(context.Background(), "Hello")
+	if err != nil {
+		t.Fatalf("expected fallback to answer, got %v", err)
+	}
+	if response != "mock response" {
+		t.Fatalf("expected fallback response, got %q", response)
+	}
+	if len(fallback.CompletionCalls) != 1 {
+		t.Fatalf("expected 1 fallback call, got %d", len(fallback.CompletionCalls))
+	}
+	if got := fallback.CompletionCalls[0].Model; got != "fallback-model" {
+		t.Fatalf("expected fallback model, got %q", got)
+	}
+	if len(*built) != 1 || (*built)[0].APIKey != "primary-key" || (*built)[0].APIURL != "http://fallback.invalid/v1" {
+		t.Fatalf("unexpected fallback provider: %+v", *built)
+	}
+
+	primaryDown = false
+	response, err = session.GetResponseWithContext(context.Background(), "Again")
+	if err != nil {
+		t.Fatalf("expected primary to answer, got %v", err)
+	}
+	if response != "primary" {
+		t.Fatalf("expected primary to be used again, got %q", response)
+	}
+	if len(primary.CompletionCalls) != 2 || len(fallback.CompletionCalls) != 1 {
+		t.Fatalf("unexpected call counts: primary=%d fallback=%d", len(primary.CompletionCalls), len(fallback.CompletionCalls))
+	}
+}
+
+func TestFallbackSkippedForClientError(t *testing.T) {
+	primary := &MockChatClient{
+		CreateCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			return openai.ChatCompletionResponse{}, &openai.APIError{HTTPStatusCode: http.StatusBadRequest, Message: "bad request"}
+		},
+	}
+	fallback := &MockChatClient{}
+	session, _ := newFallbackSession(primary, fallback)
+
+	if _, err := session.GetResponseWithContext(context.Background(), "Hello"); err == nil {
+		t.Fatal("expected client error to be returned")
+	}
+	if len(fallback.CompletionCalls) != 0 {
+		t.Fatalf("expected no fallback calls, got %d", len(fallback.CompletionCalls))
+	}
+}
+
+func TestFallbackStreamOnConnectionError(t *testing.T) {
+	primary := &MockChatClient{
+		CreateCompletionStreamFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		},
+	}
+	session, _ := newFallbackSession(primary, NewEchoClient(EchoModeUpper))
+
+	content, _ := collectStream(t, session, "hello", true)
+	if content != "HELLO" {
+		t.Fatalf("expected fallback stream content, got %q", content)
+	}
+	if len(primary.CompletionStreamCalls) != 1 {
+		t.Fatalf("expected primary to be tried once, got %d", len(primary.CompletionStreamCalls))
+	}
+}
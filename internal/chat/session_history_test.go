@@ -18,15 +18,55 @@ package chat
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 	"promptline/internal/config"
 	"promptline/internal/tools"
 )
 
+func TestUnsavedMessageCount(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	session := NewSession(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"})
+	if got := session.UnsavedMessageCount(); got != 0 {
+		t.Fatalf("expected no unsaved messages in a new session, got %d", got)
+	}
+
+	session.AddMessage(openai.ChatMessageRoleUser, "Hello")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "Hi there!")
+	if got := session.UnsavedMessageCount(); got != 2 {
+		t.Fatalf("expected 2 unsaved messages, got %d", got)
+	}
+
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("SaveConversationHistory failed: %v", err)
+	}
+	if got := session.UnsavedMessageCount(); got != 0 {
+		t.Fatalf("expected no unsaved messages after saving, got %d", got)
+	}
+
+	session.AddMessage(openai.ChatMessageRoleUser, "More")
+	if got := session.UnsavedMessageCount(); got != 1 {
+		t.Fatalf("expected 1 unsaved message, got %d", got)
+	}
+
+	session.ClearHistory()
+	if got := session.UnsavedMessageCount(); got != 0 {
+		t.Fatalf("expected no unsaved messages after clearing, got %d", got)
+	}
+	session.AddMessage(openai.ChatMessageRoleUser, "Fresh start")
+	if got := session.UnsavedMessageCount(); got != 1 {
+		t.Fatalf("expected a message after clearing to be unsaved, got %d", got)
+	}
+}
+
 func TestSaveConversationHistory(t *testing.T) {
 	tempDir := t.TempDir()
 	historyFile := filepath.Join(tempDir, "history.jsonl")
@@ -111,6 +151,60 @@ func TestSaveConversationHistoryAppends(t *testing.T) {
 	}
 }
 
+func TestSaveConversationHistoryRotates(t *testing.T) {
+	tempDir := t.TempDir()
+	historyFile := filepath.Join(tempDir, "history.jsonl")
+
+	session := NewSession(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"})
+	session.AddMessage(openai.ChatMessageRoleUser, "Message 1")
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("First save failed: %v", err)
+	}
+	info, err := os.Stat(historyFile)
+	if err != nil {
+		t.Fatalf("Failed to stat history file: %v", err)
+	}
+
+	// Below the threshold the file keeps growing.
+	session.Config.HistoryMaxBytes = info.Size() + 1
+	session.AddMessage(openai.ChatMessageRoleAssistant, "Response 1")
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("Second save failed: %v", err)
+	}
+	if rotated, _ := filepath.Glob(historyFile + ".*"); len(rotated) != 0 {
+		t.Fatalf("expected no rotation below the threshold, got %v", rotated)
+	}
+
+	// At the threshold the file is rotated and the new message starts a fresh one.
+	info, err = os.Stat(historyFile)
+	if err != nil {
+		t.Fatalf("Failed to stat history file: %v", err)
+	}
+	session.Config.HistoryMaxBytes = info.Size()
+	session.AddMessage(openai.ChatMessageRoleUser, "Message 2")
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("Third save failed: %v", err)
+	}
+	rotated, _ := filepath.Glob(historyFile + ".*")
+	if len(rotated) != 1 {
+		t.Fatalf("expected one rotated file, got %v", rotated)
+	}
+	if got := readHistoryContents(t, rotated[0]); len(got) != 2 {
+		t.Fatalf("expected rotated file to keep 2 messages, got %v", got)
+	}
+	if got := readHistoryContents(t, historyFile); len(got) != 1 || got[0] != "Message 2" {
+		t.Fatalf("expected fresh file with the new message, got %v", got)
+	}
+
+	loaded := NewSession(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"})
+	if err := loaded.LoadConversationHistory(historyFile, 0); err != nil {
+		t.Fatalf("LoadConversationHistory failed: %v", err)
+	}
+	if history := loaded.GetHistory(); len(history) != 1 || history[0].Content != "Message 2" {
+		t.Fatalf("expected load to read the active file, got %+v", history)
+	}
+}
+
 func TestLoadConversationHistory(t *testing.T) {
 	tempDir := t.TempDir()
 	historyFile := filepath.Join(tempDir, "history.jsonl")
@@ -375,6 +469,59 @@ func TestSaveConversationHistoryTrimsInMemory(t *testing.T) {
 	}
 }
 
+func TestPinnedTurnSurvivesTrimming(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:             "test-key",
+		Model:              "gpt-4o-mini",
+		HistoryMaxMessages: 4,
+	}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+	if _, _, err := session.PinTurn(0); !errors.Is(err, ErrNoSuchTurn) {
+		t.Fatalf("expected ErrNoSuchTurn without messages, got %v", err)
+	}
+
+	session.AddMessage(openai.ChatMessageRoleUser, "project rules")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "noted")
+	session.AddMessage(openai.ChatMessageRoleUser, "second")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "second answer")
+	if turn, text, err := session.PinTurn(1); err != nil || turn != 1 || text != "project rules" {
+		t.Fatalf("expected turn 1 pinned, got %d %q %v", turn, text, err)
+	}
+	if _, _, err := session.PinTurn(3); !errors.Is(err, ErrNoSuchTurn) {
+		t.Fatalf("expected ErrNoSuchTurn for turn 3, got %v", err)
+	}
+	session.AddMessage(openai.ChatMessageRoleUser, "third")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "third answer")
+
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := session.SaveConversationHistory(historyPath); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+
+	var contents []string
+	for _, msg := range session.GetHistory() {
+		contents = append(contents, msg.Content)
+	}
+	if want := []string{"project rules", "noted", "third", "third answer"}; !slices.Equal(contents, want) {
+		t.Fatalf("expected the pinned turn to survive trimming, got %q", contents)
+	}
+	if turns := session.PinnedTurns(); !slices.Equal(turns, []int{1}) {
+		t.Fatalf("expected turn 1 still pinned, got %v", turns)
+	}
+
+	if removed, err := session.UnpinTurn(0); err != nil || removed != 1 {
+		t.Fatalf("expected one pin removed, got %d, %v", removed, err)
+	}
+	session.AddMessage(openai.ChatMessageRoleUser, "fourth")
+	session.AddMessage(openai.ChatMessageRoleAssistant, "fourth answer")
+	if err := session.SaveConversationHistory(historyPath); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+	if history := session.GetHistory(); history[0].Content != "third" {
+		t.Fatalf("expected the unpinned turn to be trimmed, got %q first", history[0].Content)
+	}
+}
+
 func TestNewSessionWithCustomHTTPClient(t *testing.T) {
 	cfg := &config.Config{
 		APIKey: "test-key",
@@ -392,3 +539,77 @@ func TestNewSessionWithCustomHTTPClient(t *testing.T) {
 		t.Errorf("Expected custom API URL, got %s", session.Config.APIURL)
 	}
 }
+
+func TestSaveConversationHistoryConcurrentInstances(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", HistoryFile: historyFile}
+	// A long message makes each save several writes, which would interleave
+	// without the lock.
+	long := string(make([]byte, 64*1024))
+
+	const instances, saves = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, instances*saves)
+	for i := 0; i < instances; i++ {
+		session := NewSession(cfg)
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < saves; j++ {
+				session.AddMessage(openai.ChatMessageRoleUser, fmt.Sprintf("instance %d message %d %s", id, j, long))
+				errs <- session.SaveConversationHistory(historyFile)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent save failed: %v", err)
+		}
+	}
+
+	loaded := NewSession(cfg)
+	if err := loaded.LoadConversationHistory(historyFile, 0); err != nil {
+		t.Fatalf("history corrupted by concurrent saves: %v", err)
+	}
+	if got := len(loaded.GetHistory()); got != instances*saves {
+		t.Fatalf("expected %d messages, got %d", instances*saves, got)
+	}
+}
+
+func TestSaveConversationHistoryWaitsForLock(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	other, err := os.OpenFile(historyFile, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	unlock, err := lockHistoryFile(other, true)
+	if err != nil {
+		t.Fatalf("failed to lock history file: %v", err)
+	}
+
+	session := NewSession(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"})
+	session.AddMessage(openai.ChatMessageRoleUser, "Hello")
+	done := make(chan error, 1)
+	go func() { done <- session.SaveConversationHistory(historyFile) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected the save to wait for the lock, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("expected the save to succeed once unlocked: %v", err)
+	}
+
+	if _, err := lockHistoryFile(other, true); err != nil {
+		t.Fatal(err)
+	}
+	session.AddMessage(openai.ChatMessageRoleAssistant, "Hi")
+	if err := session.SaveConversationHistory(historyFile); !errors.Is(err, ErrHistoryLocked) {
+		t.Fatalf("expected ErrHistoryLocked while another instance holds the lock, got %v", err)
+	}
+}
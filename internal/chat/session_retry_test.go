@@ -0,0 +1,254 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/config"
+)
+
+func TestRegenerateLastResponse(t *testing.T) {
+	echo := NewEchoClient(EchoModePlain)
+	mock := &MockChatClient{
+		CreateCompletionStreamFunc: echo.CreateChatCompletionStream,
+	}
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, mock)
+
+	call := openai.ToolCall{ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "ls", Arguments: `{}`}}
+	session.AddMessage(openai.ChatMessageRoleUser, "first")
+	session.AddAssistantMessage("first answer", nil)
+	session.AddMessage(openai.ChatMessageRoleUser, "again")
+	session.AddAssistantMessage("", []openai.ToolCall{call})
+	session.AddToolResultMessage(call, nil)
+	session.AddAssistantMessage("poor answer", nil)
+
+	temperature := float32(0.9)
+	events := make(chan StreamEvent, 10)
+	go session.RegenerateLastResponse(context.Background(), &temperature, events)
+	var content string
+	for event := range events {
+		if event.Type == StreamEventError {
+			t.Fatalf("unexpected error: %v", event.Err)
+		}
+		content += event.Content
+	}
+	if content != "again" {
+		t.Fatalf("expected regenerated answer, got %q", content)
+	}
+
+	if len(mock.CompletionStreamCalls) != 1 {
+		t.Fatalf("expected 1 stream call, got %d", len(mock.CompletionStreamCalls))
+	}
+	req := mock.CompletionStreamCalls[0]
+	if req.Temperature != 0.9 {
+		t.Fatalf("expected temperature override 0.9, got %v", req.Temperature)
+	}
+	if last := req.Messages[len(req.Messages)-1]; last.Role != openai.ChatMessageRoleUser || last.Content != "again" {
+		t.Fatalf("expected request to end with the last user message, got %+v", last)
+	}
+	history := session.GetHistory()
+	if len(history) != 4 || history[3].Content != "again" || history[1].Content != "first answer" {
+		t.Fatalf("unexpected history after retry: %+v", history)
+	}
+
+	collectStream(t, session, "next", true)
+	if got := mock.CompletionStreamCalls[1].Temperature; got != 0 {
+		t.Fatalf("expected temperature override to apply once, got %v", got)
+	}
+}
+
+func TestRegenerateLastResponseWithoutAnswer(t *testing.T) {
+	session := newEchoSession(EchoModePlain)
+	session.AddMessage(openai.ChatMessageRoleUser, "pending")
+
+	events := make(chan StreamEvent, 1)
+	go session.RegenerateLastResponse(context.Background(), nil, events)
+	var got error
+	for event := range events {
+		if event.Type == StreamEventError {
+			got = event.Err
+		}
+	}
+	if !errors.Is(got, ErrNothingToRegenerate) {
+		t.Fatalf("expected ErrNothingToRegenerate, got %v", got)
+	}
+}
+
+func TestReplaceLastUserTurn(t *testing.T) {
+	session := newEchoSession(EchoModePlain)
+	if _, ok := session.LastUserMessage(); ok {
+		t.Fatal("expected no user message in a new session")
+	}
+	if err := session.ReplaceLastUserTurn("edited"); !errors.Is(err, ErrNothingToEdit) {
+		t.Fatalf("expected ErrNothingToEdit, got %v", err)
+	}
+
+	call := openai.ToolCall{ID: "call_1", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "ls", Arguments: `{}`}}
+	session.AddMessage(openai.ChatMessageRoleUser, "first")
+	session.AddAssistantMessage("first answer", nil)
+	session.AddMessage(openai.ChatMessageRoleUser, "typo")
+	session.AddAssistantMessage("", []openai.ToolCall{call})
+	session.AddToolResultMessage(call, nil)
+	session.AddAssistantMessage("answer to typo", nil)
+
+	if text, ok := session.LastUserMessage(); !ok || text != "typo" {
+		t.Fatalf("expected last user message %q, got %q", "typo", text)
+	}
+	if err := session.ReplaceLastUserTurn("  "); !errors.Is(err, ErrEmptyMessage) {
+		t.Fatalf("expected ErrEmptyMessage, got %v", err)
+	}
+	if err := session.ReplaceLastUserTurn("fixed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events := make(chan StreamEvent, 10)
+	go session.RespondWithContext(context.Background(), "", false, events)
+	var content string
+	for event := range events {
+		if event.Type == StreamEventError {
+			t.Fatalf("unexpected error: %v", event.Err)
+		}
+		content += event.Content
+	}
+	if content != "fixed" {
+		t.Fatalf("expected the edited prompt to be answered, got %q", content)
+	}
+	history := session.GetHistory()
+	if len(history) != 4 || history[1].Content != "first answer" || history[2].Content != "fixed" || history[3].Content != "fixed" {
+		t.Fatalf("unexpected history after edit: %+v", history)
+	}
+}
+
+func TestReplaceLastUserTurnSavesEdit(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	session := newEchoSession(EchoModePlain)
+	session.AddMessage(openai.ChatMessageRoleUser, "typo")
+	session.AddAssistantMessage("answer to typo", nil)
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+	if err := session.ReplaceLastUserTurn("fixed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+
+	loaded := newEchoSession(EchoModePlain)
+	if err := loaded.LoadConversationHistory(historyFile, 0); err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	history := loaded.GetHistory()
+	if len(history) != 3 || history[2].Content != "fixed" {
+		t.Fatalf("expected the edited message after the replaced turn, got %+v", history)
+	}
+}
+
+func TestEmptyReplyIsRetried(t *testing.T) {
+	replies := []string{"", "hello"}
+	mock := &MockChatClient{
+		CreateCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			content := replies[0]
+			replies = replies[1:]
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: content},
+			}}}, nil
+		},
+	}
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, mock)
+
+	response, err := session.GetResponse("hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "hello" || len(mock.CompletionCalls) != 2 {
+		t.Fatalf("expected retried answer after 2 calls, got %q after %d", response, len(mock.CompletionCalls))
+	}
+	if retry := mock.CompletionCalls[1].Messages; retry[len(retry)-1].Content != "hi" {
+		t.Fatalf("expected the retry to end with the user message, got %+v", retry[len(retry)-1])
+	}
+	if history := session.GetHistory(); len(history) != 2 || history[1].Content != "hello" {
+		t.Fatalf("expected the empty reply to be dropped, got %+v", history)
+	}
+}
+
+func TestEmptyReplyRetrySavesAnswer(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	session := newEchoSession(EchoModePlain)
+	session.AddMessage(openai.ChatMessageRoleUser, "hi")
+	session.AddAssistantMessage("", nil)
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+	if !session.retryEmptyReply("req", 0) {
+		t.Fatal("expected the empty reply to be retried")
+	}
+	session.AddAssistantMessage("hello", nil)
+	if err := session.SaveConversationHistory(historyFile); err != nil {
+		t.Fatalf("failed to save history: %v", err)
+	}
+
+	loaded := newEchoSession(EchoModePlain)
+	if err := loaded.LoadConversationHistory(historyFile, 0); err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	var contents []string
+	for _, msg := range loaded.GetHistory() {
+		contents = append(contents, msg.Content)
+	}
+	// The empty reply was saved before the retry and stays in the file.
+	if len(contents) != 3 || contents[0] != "hi" || contents[1] != "" || contents[2] != "hello" {
+		t.Fatalf("expected hi, the saved empty reply and hello once each, got %q", contents)
+	}
+}
+
+func TestEmptyReplyReported(t *testing.T) {
+	retries := 0
+	mock := &MockChatClient{
+		CreateCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant},
+			}}}, nil
+		},
+	}
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini", EmptyReplyRetries: &retries}, mock)
+	if _, err := session.GetResponse("hi"); !errors.Is(err, ErrEmptyResponse) {
+		t.Fatalf("expected ErrEmptyResponse, got %v", err)
+	}
+	if len(mock.CompletionCalls) != 1 {
+		t.Fatalf("expected no retry, got %d calls", len(mock.CompletionCalls))
+	}
+
+	// The echo client streams an empty reply to an empty message.
+	streaming := newEchoSession(EchoModePlain)
+	events := make(chan StreamEvent, 10)
+	go streaming.StreamResponseWithContext(context.Background(), " ", true, events)
+	var got error
+	for event := range events {
+		if event.Type == StreamEventError {
+			got = event.Err
+		}
+	}
+	if !errors.Is(got, ErrEmptyResponse) {
+		t.Fatalf("expected streamed ErrEmptyResponse, got %v", got)
+	}
+}
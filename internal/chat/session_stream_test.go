@@ -19,6 +19,10 @@ package chat
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -339,6 +343,33 @@ func TestStreamResponseWithContextCancellation(t *testing.T) {
 	}
 }
 
+func TestStreamStallIsReported(t *testing.T) {
+	stall := 1
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", StreamStallSeconds: &stall}
+	echo := NewEchoClient(EchoModePlain)
+	echo.ChunkDelay = 3 * time.Second
+	session := NewSessionWithClient(cfg, echo)
+
+	events := make(chan StreamEvent, 10)
+	start := time.Now()
+	go session.StreamResponseWithContext(context.Background(), "a reply long enough for several chunks", true, events)
+	var got error
+	for event := range events {
+		if event.Type == StreamEventError {
+			got = event.Err
+		}
+	}
+	if !errors.Is(got, ErrStreamStalled) || errors.Is(got, context.Canceled) {
+		t.Fatalf("expected ErrStreamStalled, got %v", got)
+	}
+	if elapsed := time.Since(start); elapsed > 2500*time.Millisecond {
+		t.Fatalf("expected the stall to be detected after about 1s, took %v", elapsed)
+	}
+	if history := session.GetHistory(); len(history) != 1 {
+		t.Fatalf("expected the stalled reply to be left out of the history, got %+v", history)
+	}
+}
+
 func TestHandleStreamChunk(t *testing.T) {
 	cfg := &config.Config{
 		APIKey: "test-key",
@@ -406,7 +437,7 @@ func TestHandleStreamChunk(t *testing.T) {
 			indexToKey := make(map[int]string)
 			events := make(chan StreamEvent, 10)
 
-			session.handleStreamChunk(tt.delta, &contentBuilder, toolCalls, argBuilders, indexToKey, events)
+			session.handleStreamChunk(tt.delta, &contentBuilder, toolCalls, argBuilders, indexToKey, nil, events)
 
 			if contentBuilder.Len() != tt.wantContentLen {
 				t.Errorf("Expected content length %d, got %d", tt.wantContentLen, contentBuilder.Len())
@@ -551,3 +582,113 @@ func TestStreamEventHelpers(t *testing.T) {
 		}
 	})
 }
+
+func TestEmitToolProgressThrottles(t *testing.T) {
+	events := make(chan StreamEvent, 10)
+	progress := make(map[string]time.Time)
+
+	emitToolProgress("call_1", "create_file", toolProgressMinBytes-1, progress, events)
+	if len(events) != 0 {
+		t.Fatalf("expected no progress below %d bytes, got %d events", toolProgressMinBytes, len(events))
+	}
+
+	emitToolProgress("call_1", "create_file", toolProgressMinBytes, progress, events)
+	emitToolProgress("call_1", "create_file", toolProgressMinBytes*2, progress, events)
+	if len(events) != 1 {
+		t.Fatalf("expected throttled progress to emit once, got %d events", len(events))
+	}
+	event := <-events
+	if event.Type != StreamEventToolProgress || event.ToolName != "create_file" || event.ArgBytes != toolProgressMinBytes {
+		t.Errorf("unexpected progress event %+v", event)
+	}
+
+	progress["call_1"] = time.Now().Add(-toolProgressInterval)
+	emitToolProgress("call_1", "create_file", toolProgressMinBytes*3, progress, events)
+	if len(events) != 1 {
+		t.Errorf("expected progress after the throttle interval, got %d events", len(events))
+	}
+}
+
+func TestStreamEmitsToolProgressForLargeArguments(t *testing.T) {
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, NewEchoClient(EchoModePlain))
+	content := strings.Repeat("x", toolProgressMinBytes*2)
+	prompt := `tool: create_file {"path":"big.txt","content":"` + content + `"}`
+
+	events := make(chan StreamEvent, 10)
+	go session.StreamResponseWithContext(context.Background(), prompt, true, events)
+
+	var progressEvents int
+	var sawToolCall bool
+	for event := range events {
+		switch event.Type {
+		case StreamEventToolProgress:
+			if sawToolCall {
+				t.Error("expected progress before the finalized tool call")
+			}
+			if event.ToolName != "create_file" {
+				t.Errorf("expected progress for create_file, got %q", event.ToolName)
+			}
+			progressEvents++
+		case StreamEventToolCall:
+			sawToolCall = true
+		case StreamEventError:
+			t.Fatalf("unexpected error: %v", event.Err)
+		}
+	}
+	if progressEvents == 0 {
+		t.Error("expected at least one tool progress event")
+	}
+	if !sawToolCall {
+		t.Error("expected finalized tool call")
+	}
+}
+
+func TestStreamIgnoresRoleOnlyDeltaAndRecordsUsageChunk(t *testing.T) {
+	chunks := []string{
+		`{"choices":[{"index":0,"delta":{"role":"assistant"}}]}`,
+		`{"choices":[{"index":0,"delta":{"content":"hi"}}]}`,
+		`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL
+	client := openai.NewClientWithConfig(clientConfig)
+	mock := &MockChatClient{
+		CreateCompletionStreamFunc: client.CreateChatCompletionStream,
+	}
+
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", TranscriptFile: path}
+	session := NewSessionWithClient(cfg, mock)
+
+	content, calls := collectStream(t, session, "hello", true)
+	if content != "hi" {
+		t.Errorf("expected content %q, got %q", "hi", content)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected no tool calls, got %d", len(calls))
+	}
+
+	messages := session.MessagesSnapshot()
+	last := messages[len(messages)-1]
+	if last.Role != openai.ChatMessageRoleAssistant || last.Content != "hi" {
+		t.Errorf("expected assistant reply %q in history, got %+v", "hi", last)
+	}
+
+	records := readTranscript(t, path)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 transcript record, got %d", len(records))
+	}
+	if records[0].Usage == nil || records[0].Usage.TotalTokens != 6 {
+		t.Errorf("expected usage with 6 total tokens, got %+v", records[0].Usage)
+	}
+}
@@ -17,7 +17,11 @@
 package chat
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -465,3 +469,247 @@ func TestSessionConcurrentOperations(t *testing.T) {
 		t.Errorf("Expected at least 1001 messages, got %d", len(snapshot))
 	}
 }
+
+func TestNewSessionAppliesToolSettings(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:           "test-key",
+		Model:            "gpt-4o-mini",
+		ToolDescriptions: map[string]string{"grep": "Search inside files"},
+		ToolGuidelines:   "Prefer grep over reading whole files.",
+	}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+
+	if !strings.HasSuffix(session.Messages[0].Content, "\nTOOL GUIDELINES:\nPrefer grep over reading whole files.\n") {
+		t.Errorf("expected guidelines at the end of the system prompt, got %q", session.Messages[0].Content)
+	}
+	for _, tool := range session.ToolRegistry.OpenAITools() {
+		if tool.Function.Name == "grep" && tool.Function.Description != "Search inside files" {
+			t.Errorf("expected grep description override, got %q", tool.Function.Description)
+		}
+	}
+}
+
+func TestSystemPromptParts(t *testing.T) {
+	projectFile := filepath.Join(t.TempDir(), ".promptline.md")
+	if err := os.WriteFile(projectFile, []byte("Run go test before committing.\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		APIKey:            "test-key",
+		Model:             "gpt-4o-mini",
+		ToolGuidelines:    "Prefer grep over reading whole files.",
+		ProjectPromptFile: projectFile,
+		PromptAddendum:    "Answer in English.",
+	}
+	prompt := NewSessionWithClient(cfg, &MockChatClient{}).Messages[0].Content
+
+	parts := []string{
+		defaultSystemPrompt,
+		"\nTOOL GUIDELINES:\nPrefer grep over reading whole files.\n",
+		"\nPROJECT INSTRUCTIONS (from " + projectFile + "):\nRun go test before committing.\n",
+		"\nADDITIONAL INSTRUCTIONS:\nAnswer in English.\n",
+	}
+	if want := strings.Join(parts, ""); prompt != want {
+		t.Fatalf("expected every part in order, got %q", prompt)
+	}
+
+	// Missing parts are left out.
+	cfg.ToolGuidelines = ""
+	cfg.ProjectPromptFile = filepath.Join(t.TempDir(), "missing.md")
+	cfg.PromptAddendum = ""
+	if prompt := NewSessionWithClient(cfg, &MockChatClient{}).Messages[0].Content; prompt != defaultSystemPrompt {
+		t.Fatalf("expected only the base prompt, got %q", prompt)
+	}
+}
+
+func TestReminderInjectedEveryNTurns(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:              "test-key",
+		Model:               "gpt-4o-mini",
+		ReminderEveryNTurns: 2,
+		ReminderText:        "Use tools only when needed.",
+	}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+
+	for _, prompt := range []string{"one", "two", "three", "four", "five"} {
+		if _, err := session.GetResponse(prompt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var order []string
+	for _, msg := range session.Messages[1:] {
+		switch msg.Role {
+		case openai.ChatMessageRoleSystem:
+			if msg.Content != "REMINDER:\nUse tools only when needed." {
+				t.Fatalf("unexpected reminder content %q", msg.Content)
+			}
+			order = append(order, "reminder")
+		case openai.ChatMessageRoleUser:
+			order = append(order, msg.Content)
+		}
+	}
+	want := "one two reminder three four reminder five"
+	if got := strings.Join(order, " "); got != want {
+		t.Fatalf("unexpected message order %q, want %q", got, want)
+	}
+}
+
+func TestReminderNotSavedToHistory(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	cfg := &config.Config{
+		APIKey:              "test-key",
+		Model:               "gpt-4o-mini",
+		ReminderEveryNTurns: 1,
+		ReminderText:        "Use tools only when needed.",
+	}
+	for range 2 {
+		session := NewSessionWithClient(cfg, &MockChatClient{})
+		if err := session.LoadConversationHistory(historyFile, 0); err != nil {
+			t.Fatalf("failed to load history: %v", err)
+		}
+		for _, prompt := range []string{"one", "two", "three"} {
+			if _, err := session.GetResponse(prompt); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if err := session.SaveConversationHistory(historyFile); err != nil {
+			t.Fatalf("failed to save history: %v", err)
+		}
+	}
+
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "gpt-4o-mini"}, &MockChatClient{})
+	if err := session.LoadConversationHistory(historyFile, 0); err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	history := session.GetHistory()
+	if len(history) != 12 {
+		t.Fatalf("expected 6 user and 6 assistant messages, got %d", len(history))
+	}
+	for _, msg := range history {
+		if msg.Role == openai.ChatMessageRoleSystem {
+			t.Fatalf("expected no reminder in the history file, got %q", msg.Content)
+		}
+	}
+}
+
+func TestDefaultReminderUsesToolRules(t *testing.T) {
+	reminder := defaultReminder(defaultSystemPrompt)
+	if !strings.HasPrefix(reminder, "CRITICAL RULES:") || strings.Contains(reminder, "PERMISSIONS:") {
+		t.Fatalf("unexpected default reminder %q", reminder)
+	}
+}
+
+func TestAssistantPrefill(t *testing.T) {
+	var sent []openai.ChatCompletionMessage
+	mock := &MockChatClient{
+		CreateCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			sent = req.Messages
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: " the sky is blue."},
+			}}}, nil
+		},
+	}
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", AssistantPrefill: "Reasoning:"}
+	session := NewSessionWithClient(cfg, mock)
+
+	reply, err := session.GetResponse("why?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last := sent[len(sent)-1]
+	if last.Role != openai.ChatMessageRoleAssistant || last.Content != "Reasoning:" {
+		t.Fatalf("expected the prefill as the last request message, got %+v", last)
+	}
+	if reply != "Reasoning: the sky is blue." {
+		t.Fatalf("expected the reply to start with the prefill, got %q", reply)
+	}
+	history := session.GetHistory()
+	if stored := history[len(history)-1]; stored.Content != reply {
+		t.Fatalf("expected the stored reply %q, got %q", reply, stored.Content)
+	}
+
+	cfg.AssistantPrefill = "{"
+	content, _ := collectStream(t, NewSessionWithClient(cfg, NewEchoClient(EchoModePlain)), "\"a\":1}", true)
+	if content != "{\"a\":1}" {
+		t.Fatalf("expected streamed content to start with the prefill, got %q", content)
+	}
+}
+
+func TestDumpNextRequest(t *testing.T) {
+	cfg := &config.Config{APIKey: "sk-secret-key", Model: "gpt-4o-mini"}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+
+	var dump strings.Builder
+	session.DumpNextRequest(&dump)
+	if !session.RequestDumpPending() {
+		t.Fatal("expected a pending request dump")
+	}
+	collectStream(t, session, "my key is sk-secret-key", true)
+
+	var req openai.ChatCompletionRequest
+	if err := json.Unmarshal([]byte(dump.String()), &req); err != nil {
+		t.Fatalf("expected the dump to be a request body: %v\n%s", err, dump.String())
+	}
+	if req.Model != "gpt-4o-mini" || !req.Stream || len(req.Tools) == 0 {
+		t.Fatalf("expected model, stream flag and tools in the dump, got %+v", req)
+	}
+	if last := req.Messages[len(req.Messages)-1]; last.Content != "my key is [REDACTED]" {
+		t.Fatalf("expected the API key to be redacted, got %q", last.Content)
+	}
+	if strings.Contains(dump.String(), "sk-secret-key") {
+		t.Fatalf("expected no API key in the dump:\n%s", dump.String())
+	}
+	if session.RequestDumpPending() {
+		t.Fatal("expected the dump to apply to one request only")
+	}
+
+	dump.Reset()
+	collectStream(t, session, "again", true)
+	if dump.Len() != 0 {
+		t.Fatalf("expected only the next request to be dumped, got %q", dump.String())
+	}
+}
+
+func TestUserMessagePrefix(t *testing.T) {
+	var sent []openai.ChatCompletionMessage
+	mock := &MockChatClient{
+		CreateCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			sent = req.Messages
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "ok"},
+			}}}, nil
+		},
+	}
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", UserMessagePrefix: "Project: promptline. Use Go 1.24."}
+	session := NewSessionWithClient(cfg, mock)
+
+	for _, prompt := range []string{"first", "second"} {
+		if _, err := session.GetResponse(prompt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	var users []string
+	for _, msg := range sent {
+		if msg.Role == openai.ChatMessageRoleUser {
+			users = append(users, msg.Content)
+		}
+	}
+	want := []string{cfg.UserMessagePrefix + "\n\nfirst", cfg.UserMessagePrefix + "\n\nsecond"}
+	if strings.Join(users, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected every sent user message to carry the prefix, got %q", users)
+	}
+	for _, msg := range session.GetHistory() {
+		if strings.Contains(msg.Content, cfg.UserMessagePrefix) {
+			t.Fatalf("expected the history to keep the typed text, got %q", msg.Content)
+		}
+	}
+
+	session.NoUserPrefix = true
+	if _, err := session.GetResponse("third"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if last := sent[len(sent)-1]; last.Content != "third" {
+		t.Fatalf("expected no prefix once disabled, got %q", last.Content)
+	}
+}
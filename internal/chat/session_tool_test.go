@@ -17,7 +17,13 @@
 package chat
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
@@ -110,6 +116,103 @@ func TestAddToolResultMessageWithError(t *testing.T) {
 	}
 }
 
+// TestAddToolResultMessageTruncatesForModel verifies tool_result_max_chars
+// limits the message content but not the displayed result.
+func TestAddToolResultMessageTruncatesForModel(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:             "test-key",
+		Model:              "test-model",
+		ToolResultMaxChars: 10,
+	}
+	session := NewSession(cfg)
+
+	toolCall := openai.ToolCall{
+		ID:       "call-789",
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: "cat", Arguments: `{"path":"big.txt"}`},
+	}
+	output := "ééééé" + strings.Repeat("x", 1000)
+	result := &tools.ToolResult{Function: "cat", Result: output}
+
+	session.AddToolResultMessage(toolCall, result)
+
+	want := "ééééé" + strings.Repeat("x", 5) + "\n[output truncated, 995 bytes omitted]"
+	if got := session.Messages[1].Content; got != want {
+		t.Fatalf("expected truncated content %q, got %q", want, got)
+	}
+	if result.Result != output {
+		t.Fatal("expected the tool result itself to stay whole")
+	}
+	if display := session.FormatToolCallDisplay(toolCall, result); strings.Contains(display, "output truncated") {
+		t.Fatalf("expected the display to be independent of the model limit, got %q", display)
+	}
+
+	session.Config.ToolResultMaxChars = 0
+	session.AddToolResultMessage(toolCall, result)
+	if got := session.Messages[2].Content; got != output {
+		t.Fatalf("expected no limit by default, got %d bytes", len(got))
+	}
+}
+
+// TestCompactOldToolResults verifies compact_old_tool_results elides the tool
+// results of older turns once a turn ends, keeping recent and pinned ones.
+func TestCompactOldToolResults(t *testing.T) {
+	cfg := &config.Config{
+		APIKey:             "test-key",
+		Model:              "test-model",
+		CompactToolResults: 1,
+	}
+	session := NewSessionWithClient(cfg, &MockChatClient{})
+
+	turn := func(n int) {
+		call := openai.ToolCall{
+			ID:       fmt.Sprintf("call-%d", n),
+			Type:     openai.ToolTypeFunction,
+			Function: openai.FunctionCall{Name: "cat", Arguments: `{"path":"a.txt"}`},
+		}
+		session.AddMessage(openai.ChatMessageRoleUser, fmt.Sprintf("question %d", n))
+		session.AddAssistantMessage("", []openai.ToolCall{call})
+		session.AddToolResultMessage(call, &tools.ToolResult{Function: "cat", Result: fmt.Sprintf("long output of turn %d", n)})
+		session.AddAssistantMessage(fmt.Sprintf("conclusion %d", n), nil)
+	}
+	turn(1)
+	turn(2)
+	if _, _, err := session.PinTurn(2); err != nil {
+		t.Fatalf("pin failed: %v", err)
+	}
+	turn(3)
+
+	// Mid-turn, before the final reply, the previous results are still whole.
+	session.AddMessage(openai.ChatMessageRoleUser, "question 4")
+	call := openai.ToolCall{ID: "call-4", Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "cat"}}
+	session.AddAssistantMessage("", []openai.ToolCall{call})
+	session.AddToolResultMessage(call, &tools.ToolResult{Function: "cat", Result: "long output of turn 4"})
+	if got := toolContents(session); !slices.Equal(got, []string{elidedToolResult, "long output of turn 2", "long output of turn 3", "long output of turn 4"}) {
+		t.Fatalf("unexpected tool results mid-turn: %q", got)
+	}
+
+	session.AddAssistantMessage("conclusion 4", nil)
+	if got := toolContents(session); !slices.Equal(got, []string{elidedToolResult, "long output of turn 2", elidedToolResult, "long output of turn 4"}) {
+		t.Fatalf("expected old unpinned results elided, got %q", got)
+	}
+	for _, msg := range session.Messages {
+		if msg.Role == openai.ChatMessageRoleAssistant && msg.Content == "conclusion 1" {
+			return
+		}
+	}
+	t.Fatal("expected the assistant conclusions to stay")
+}
+
+func toolContents(session *Session) []string {
+	var contents []string
+	for _, msg := range session.MessagesSnapshot() {
+		if msg.Role == openai.ChatMessageRoleTool {
+			contents = append(contents, msg.Content)
+		}
+	}
+	return contents
+}
+
 // TestToolCallMessageSequence verifies the correct message sequence
 func TestToolCallMessageSequence(t *testing.T) {
 	cfg := &config.Config{
@@ -202,6 +305,122 @@ func TestExecuteToolCallWithApprovalDeniedByUser(t *testing.T) {
 	}
 }
 
+func TestExecuteToolCallWithApprovalHook(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	seen := filepath.Join(dir, "seen.json")
+	hook := filepath.Join(dir, "hook.sh")
+	script := `#!/bin/sh
+input=$(cat)
+printf '%s\n' "$input" >> "` + seen + `"
+case "$input" in
+*'"tool":"get_current_datetime"'*) exit 0 ;;
+esac
+echo "read_file is not allowed in CI"
+exit 1
+`
+	if err := os.WriteFile(hook, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		APIKey:       "test-key",
+		Model:        "test-model",
+		ApprovalHook: hook,
+		Tools: config.ToolSettings{
+			Ask: []string{"get_current_datetime", "read_file"},
+		},
+	}
+	session := NewSession(cfg)
+
+	approved := session.ExecuteToolCallWithApproval(openai.ToolCall{
+		ID:       "call-hook-1",
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: "get_current_datetime", Arguments: "{}"},
+	})
+	if approved.Error != nil {
+		t.Fatalf("expected the hook to approve get_current_datetime, got: %v", approved.Error)
+	}
+
+	denied := session.ExecuteToolCallWithApproval(openai.ToolCall{
+		ID:       "call-hook-2",
+		Type:     openai.ToolTypeFunction,
+		Function: openai.FunctionCall{Name: "read_file", Arguments: `{"path":"secret.txt"}`},
+	})
+	if !errors.Is(denied.Error, tools.ErrToolDeniedByUser) || !errors.Is(denied.Error, ErrDeniedByHook) {
+		t.Fatalf("expected the hook to deny read_file, got: %v", denied.Error)
+	}
+	if !strings.Contains(denied.Result, "read_file is not allowed in CI") {
+		t.Errorf("expected the hook's reason in the result, got: %s", denied.Result)
+	}
+
+	data, err := os.ReadFile(seen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"arguments":{"path":"secret.txt"}`) {
+		t.Errorf("expected the hook to receive the arguments as JSON, got: %s", data)
+	}
+}
+
+func TestExecuteToolCallWithApprovalTimeout(t *testing.T) {
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "test-model",
+	}
+	session := NewSession(cfg)
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) {
+		return false, tools.ErrToolApprovalTimeout
+	}
+
+	toolCall := openai.ToolCall{
+		ID:   "call-approve-timeout",
+		Type: openai.ToolTypeFunction,
+		Function: openai.FunctionCall{
+			Name:      "get_current_datetime",
+			Arguments: "{}",
+		},
+	}
+
+	result := session.ExecuteToolCallWithApproval(toolCall)
+	if !errors.Is(result.Error, tools.ErrToolApprovalTimeout) || !errors.Is(result.Error, tools.ErrToolDeniedByUser) {
+		t.Fatalf("expected approval timeout denial, got: %v", result.Error)
+	}
+	if !strings.Contains(result.Result, "timed out awaiting approval") {
+		t.Errorf("expected timeout message, got: %s", result.Result)
+	}
+}
+
+func TestExecuteToolCallWithApprovalCancelled(t *testing.T) {
+	cfg := &config.Config{
+		APIKey: "test-key",
+		Model:  "test-model",
+	}
+	session := NewSession(cfg)
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) {
+		return false, context.Canceled
+	}
+
+	toolCall := openai.ToolCall{
+		ID:   "call-approve-cancel",
+		Type: openai.ToolTypeFunction,
+		Function: openai.FunctionCall{
+			Name:      "get_current_datetime",
+			Arguments: "{}",
+		},
+	}
+
+	result := session.ExecuteToolCallWithApproval(toolCall)
+	if !errors.Is(result.Error, context.Canceled) || !errors.Is(result.Error, tools.ErrToolDeniedByUser) {
+		t.Fatalf("expected cancelled denial, got: %v", result.Error)
+	}
+	if !strings.Contains(result.Result, "cancelled the turn") {
+		t.Errorf("expected cancellation message, got: %s", result.Result)
+	}
+}
+
 func TestExecuteToolCallWithApprovalAllowed(t *testing.T) {
 	cfg := &config.Config{
 		APIKey: "test-key",
@@ -367,3 +586,136 @@ func TestToolResultsInHistory(t *testing.T) {
 		t.Error("tool result message not found in history")
 	}
 }
+
+func TestToolsDisabledSendsNoTools(t *testing.T) {
+	client := &MockChatClient{}
+	session := NewSessionWithClient(&config.Config{APIKey: "test-key", Model: "test-model"}, client)
+
+	if _, err := session.GetResponseWithContext(context.Background(), "hello"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	session.ToolsDisabled = true
+	if _, err := session.GetResponseWithContext(context.Background(), "hello again"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(client.CompletionCalls) != 2 {
+		t.Fatalf("expected two requests, got %d", len(client.CompletionCalls))
+	}
+	if len(client.CompletionCalls[0].Tools) == 0 {
+		t.Error("expected tools to be sent by default")
+	}
+	if tools := client.CompletionCalls[1].Tools; len(tools) != 0 {
+		t.Errorf("expected no tools when disabled, got %d", len(tools))
+	}
+}
+
+func TestLastTurnTrace(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "test-model"}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) {
+		return false, nil
+	}
+
+	if _, err := session.GetResponse(`tool: get_current_datetime`); err != nil {
+		t.Fatalf("GetResponse failed: %v", err)
+	}
+	if _, err := session.GetResponse(`tool: create_file {"path":"x.txt","content":"x"}`); err != nil {
+		t.Fatalf("GetResponse failed: %v", err)
+	}
+
+	trace := session.LastTurnTrace()
+	if len(trace) != 1 {
+		t.Fatalf("expected only the last turn's tool call, got %+v", trace)
+	}
+	entry := trace[0]
+	if entry.Name != "create_file" || entry.Arguments != `{"path":"x.txt","content":"x"}` {
+		t.Errorf("unexpected trace entry %+v", entry)
+	}
+	if entry.Success || entry.Error == "" {
+		t.Errorf("expected the denied call to be recorded as a failure, got %+v", entry)
+	}
+
+	session.AddToolResultMessage(openai.ToolCall{Function: openai.FunctionCall{Name: "cat"}}, &tools.ToolResult{Result: strings.Repeat("x", maxTraceResultRunes+10)})
+	trace = session.LastTurnTrace()
+	if last := trace[len(trace)-1]; !last.Success || !last.Truncated || len(last.Result) != maxTraceResultRunes {
+		t.Errorf("expected a truncated successful entry, got %+v", last)
+	}
+
+	session.ClearHistory()
+	if len(session.LastTurnTrace()) != 0 {
+		t.Error("expected ClearHistory to drop the trace")
+	}
+}
+
+func TestObserversReceiveTurnEvents(t *testing.T) {
+	cfg := &config.Config{APIKey: "test-key", Model: "test-model"}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+	session.ToolApprover = func(call openai.ToolCall) (bool, error) {
+		return true, nil
+	}
+
+	var events []SessionEvent
+	session.AddObserver(func(event SessionEvent) {
+		events = append(events, event)
+	})
+	var removed []SessionEvent
+	remove := session.AddObserver(func(event SessionEvent) {
+		removed = append(removed, event)
+	})
+	remove()
+
+	if _, err := session.GetResponse(`tool: get_current_datetime`); err != nil {
+		t.Fatalf("GetResponse failed: %v", err)
+	}
+	// Close waits for the observers to drain their queues.
+	if err := session.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	want := []SessionEventType{
+		SessionEventUserMessage,
+		SessionEventAssistantMessage,
+		SessionEventToolExecuted,
+		SessionEventAssistantMessage,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), events)
+	}
+	for i, event := range events {
+		if event.Type != want[i] || event.Time.IsZero() {
+			t.Fatalf("event %d: expected %s, got %+v", i, want[i], event)
+		}
+	}
+	if events[0].Message.Content != `tool: get_current_datetime` {
+		t.Errorf("unexpected user message %+v", events[0].Message)
+	}
+	if len(events[1].Message.ToolCalls) != 1 {
+		t.Errorf("expected the tool call on the first assistant message, got %+v", events[1].Message)
+	}
+	if tool := events[2].Tool; tool.Name != "get_current_datetime" || !tool.Success {
+		t.Errorf("unexpected tool event %+v", tool)
+	}
+	if len(removed) != 0 {
+		t.Errorf("expected a removed observer to get no events, got %+v", removed)
+	}
+
+	failing := NewSessionWithClient(cfg, &MockChatClient{
+		CreateCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			return openai.ChatCompletionResponse{}, errors.New("boom")
+		},
+	})
+	var errorEvents []SessionEvent
+	failing.AddObserver(func(event SessionEvent) {
+		if event.Type == SessionEventError {
+			errorEvents = append(errorEvents, event)
+		}
+	})
+	if _, err := failing.GetResponse("hi"); err == nil {
+		t.Fatal("expected an error")
+	}
+	failing.Close()
+	if len(errorEvents) != 1 || errorEvents[0].Err == nil {
+		t.Fatalf("expected one error event, got %+v", errorEvents)
+	}
+}
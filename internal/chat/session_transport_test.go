@@ -0,0 +1,64 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"promptline/internal/config"
+)
+
+func TestNewSessionUsesProxyAndCASettings(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":"hello over tls"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	untrusted := NewSession(&config.Config{APIKey: "k", APIURL: srv.URL + "/v1", Model: "gpt-4o-mini"})
+	if _, err := untrusted.GetResponse("hi"); err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("expected the self-signed certificate to be refused, got %v", err)
+	}
+
+	trusted := NewSession(&config.Config{APIKey: "k", APIURL: srv.URL + "/v1", Model: "gpt-4o-mini", TLSCAFile: caFile})
+	reply, err := trusted.GetResponse("hi")
+	if err != nil || reply != "hello over tls" {
+		t.Fatalf("expected the configured CA to be trusted, got %q, %v", reply, err)
+	}
+
+	client := newHTTPClient(&config.Config{ProxyURL: "socks5://127.0.0.1:1080"})
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected a configured transport, got %T", client.Transport)
+	}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+	if proxy, err := transport.Proxy(req); err != nil || proxy == nil || proxy.String() != "socks5://127.0.0.1:1080" {
+		t.Fatalf("expected the transport to use the proxy, got %v, %v", proxy, err)
+	}
+}
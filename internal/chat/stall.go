@@ -0,0 +1,63 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// stallWatchdog cancels a stream that goes too long without a chunk. A zero
+// timeout disables it.
+type stallWatchdog struct {
+	timeout time.Duration
+	timer   *time.Timer
+	fired   atomic.Bool
+}
+
+// newStallWatchdog returns a watchdog that calls cancel once timeout passes
+// without a reset.
+func newStallWatchdog(timeout time.Duration, cancel context.CancelFunc) *stallWatchdog {
+	w := &stallWatchdog{timeout: timeout}
+	if timeout > 0 {
+		w.timer = time.AfterFunc(timeout, func() {
+			w.fired.Store(true)
+			cancel()
+		})
+	}
+	return w
+}
+
+// reset restarts the countdown after a chunk arrived.
+func (w *stallWatchdog) reset() {
+	if w.timer != nil {
+		w.timer.Reset(w.timeout)
+	}
+}
+
+// stop disarms the watchdog.
+func (w *stallWatchdog) stop() {
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// stalled reports whether the watchdog cancelled the stream.
+func (w *stallWatchdog) stalled() bool {
+	return w.fired.Load()
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"promptline/internal/config"
+)
+
+// projectPromptMaxBytes caps how much of the project instruction file goes
+// into the system prompt.
+const projectPromptMaxBytes = 32 * 1024
+
+// composeSystemPrompt builds the system prompt from its parts, in order: the
+// embedded base prompt with any tool guidelines, the project instruction file
+// and the configured addendum. Every part but the base is optional and is
+// left out when empty, missing or unreadable.
+func composeSystemPrompt(cfg *config.Config) string {
+	prompt := defaultSystemPrompt
+	if guidelines := strings.TrimSpace(cfg.ToolGuidelines); guidelines != "" {
+		prompt += "\nTOOL GUIDELINES:\n" + guidelines + "\n"
+	}
+	if project := readProjectPrompt(cfg.ProjectPromptFile); project != "" {
+		prompt += fmt.Sprintf("\nPROJECT INSTRUCTIONS (from %s):\n%s\n", cfg.ProjectPromptFile, project)
+	}
+	if addendum := strings.TrimSpace(cfg.PromptAddendum); addendum != "" {
+		prompt += "\nADDITIONAL INSTRUCTIONS:\n" + addendum + "\n"
+	}
+	return prompt
+}
+
+// readProjectPrompt returns the trimmed text of the project instruction file,
+// cut at projectPromptMaxBytes, or "" when there is none.
+func readProjectPrompt(path string) string {
+	if strings.TrimSpace(path) == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if len(data) > projectPromptMaxBytes {
+		cut := data[:projectPromptMaxBytes]
+		for len(cut) > 0 && !utf8.Valid(cut) {
+			cut = cut[:len(cut)-1]
+		}
+		return strings.TrimSpace(string(cut)) + fmt.Sprintf("\n[truncated at %d of %d bytes]", len(cut), len(data))
+	}
+	return strings.TrimSpace(string(data))
+}
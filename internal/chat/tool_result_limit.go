@@ -0,0 +1,66 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"fmt"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// elidedToolResult replaces tool results compacted by compactToolResultsLocked.
+const elidedToolResult = "[tool output elided]"
+
+// limitToolResult cuts a tool result to maxChars characters before it enters
+// the conversation, noting how many bytes were left out. A limit of zero or
+// less keeps the result whole.
+func limitToolResult(content string, maxChars int) string {
+	if maxChars <= 0 || len(content) <= maxChars {
+		return content
+	}
+	count := 0
+	for i := range content {
+		if count == maxChars {
+			return fmt.Sprintf("%s\n[output truncated, %d bytes omitted]", content[:i], len(content)-i)
+		}
+		count++
+	}
+	return content
+}
+
+// compactToolResultsLocked replaces the tool results of all but the last
+// Config.CompactToolResults turns with elidedToolResult once a turn is
+// answered. The model has already acted on them, and its replies, which stay,
+// carry what it concluded. Pinned turns are left whole. The caller must hold
+// s.mu.
+func (s *Session) compactToolResultsLocked() {
+	if s.Config == nil || s.Config.CompactToolResults <= 0 {
+		return
+	}
+	starts := s.turnStartsLocked()
+	if len(starts) <= s.Config.CompactToolResults {
+		return
+	}
+	cutoff := starts[len(starts)-s.Config.CompactToolResults]
+	pinned := s.pinnedMessagesLocked()
+	for i := 1; i < cutoff; i++ {
+		msg := &s.Messages[i]
+		if msg.Role == openai.ChatMessageRoleTool && !pinned[i] && len(msg.Content) > len(elidedToolResult) {
+			msg.Content = elidedToolResult
+		}
+	}
+}
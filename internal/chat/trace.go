@@ -0,0 +1,66 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/tools"
+)
+
+// maxTraceResultRunes caps the tool output kept in a trace entry.
+const maxTraceResultRunes = 500
+
+// ToolTrace records one tool call made while answering a user message.
+type ToolTrace struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Success   bool   `json:"success"`
+	Error     string `json:"error,omitempty"`
+}
+
+// LastTurnTrace returns the tool calls of the current or most recent user turn,
+// in the order they ran. The trace is kept in memory only and restarts with
+// each user message.
+func (s *Session) LastTurnTrace() []ToolTrace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ToolTrace(nil), s.turnTrace...)
+}
+
+// recordToolTraceLocked appends a tool result to the turn trace and returns the
+// entry. The caller must hold s.mu.
+func (s *Session) recordToolTraceLocked(call openai.ToolCall, name string, result *tools.ToolResult) ToolTrace {
+	entry := ToolTrace{Name: name, Arguments: call.Function.Arguments}
+	switch {
+	case result == nil:
+		entry.Error = "tool result is nil"
+	case result.Error != nil:
+		entry.Error = result.Error.Error()
+		entry.Result = result.Result
+	default:
+		entry.Success = true
+		entry.Result = result.Result
+	}
+	if runes := []rune(entry.Result); len(runes) > maxTraceResultRunes {
+		entry.Result = string(runes[:maxTraceResultRunes])
+		entry.Truncated = true
+	}
+	s.turnTrace = append(s.turnTrace, entry)
+	return entry
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"promptline/internal/tools"
+)
+
+// transcriptRecord is one line of the transcript file: a complete request and
+// the reply it produced.
+type transcriptRecord struct {
+	Time      time.Time                      `json:"time"`
+	SessionID string                         `json:"session_id"`
+	RequestID string                         `json:"request_id"`
+	Model     string                         `json:"model"`
+	Stream    bool                           `json:"stream"`
+	Messages  []openai.ChatCompletionMessage `json:"messages"`
+	Response  string                         `json:"response"`
+	ToolCalls []openai.ToolCall              `json:"tool_calls,omitempty"`
+	Usage     *openai.Usage                  `json:"usage,omitempty"`
+	LatencyMS int64                          `json:"latency_ms"`
+	Error     string                         `json:"error,omitempty"`
+}
+
+// writeTranscript appends a request/response record to Config.TranscriptFile.
+// Secrets are redacted with the tool output redaction patterns. Failures are
+// logged and never interrupt the conversation.
+func (s *Session) writeTranscript(requestID string, req openai.ChatCompletionRequest, reply *openai.ChatCompletionMessage, usage *openai.Usage, latency time.Duration, err error) {
+	if s.Config == nil || s.Config.TranscriptFile == "" {
+		return
+	}
+
+	record := transcriptRecord{
+		Time:      time.Now().UTC(),
+		SessionID: s.SessionID,
+		RequestID: requestID,
+		Model:     req.Model,
+		Stream:    req.Stream,
+		Messages:  redactMessages(req.Messages),
+		LatencyMS: latency.Milliseconds(),
+	}
+	if reply != nil {
+		record.Response = tools.RedactSecrets(reply.Content)
+		record.ToolCalls = redactToolCalls(reply.ToolCalls)
+	}
+	if usage != nil && usage.TotalTokens > 0 {
+		record.Usage = usage
+	}
+	if err != nil {
+		record.Error = tools.RedactSecrets(err.Error())
+	}
+
+	line, marshalErr := json.Marshal(record)
+	if marshalErr == nil {
+		marshalErr = s.appendTranscriptLine(append(line, '\n'))
+	}
+	if marshalErr != nil {
+		if logger := s.sessionLogger(); logger != nil {
+			logger.Warn().Err(marshalErr).Str("transcript_file", s.Config.TranscriptFile).Msg("Failed to write transcript")
+		}
+	}
+}
+
+func (s *Session) appendTranscriptLine(line []byte) error {
+	s.transcriptMu.Lock()
+	defer s.transcriptMu.Unlock()
+	file, err := os.OpenFile(s.Config.TranscriptFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func redactMessages(messages []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	redacted := make([]openai.ChatCompletionMessage, len(messages))
+	for i, msg := range messages {
+		msg.Content = tools.RedactSecrets(msg.Content)
+		if len(msg.MultiContent) > 0 {
+			parts := make([]openai.ChatMessagePart, len(msg.MultiContent))
+			for j, part := range msg.MultiContent {
+				part.Text = tools.RedactSecrets(part.Text)
+				parts[j] = part
+			}
+			msg.MultiContent = parts
+		}
+		msg.ToolCalls = redactToolCalls(msg.ToolCalls)
+		redacted[i] = msg
+	}
+	return redacted
+}
+
+func redactToolCalls(calls []openai.ToolCall) []openai.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	redacted := make([]openai.ToolCall, len(calls))
+	for i, call := range calls {
+		call.Function.Arguments = tools.RedactSecrets(call.Function.Arguments)
+		redacted[i] = call
+	}
+	return redacted
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package chat
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"promptline/internal/config"
+)
+
+func readTranscript(t *testing.T, path string) []transcriptRecord {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open transcript: %v", err)
+	}
+	defer file.Close()
+	var records []transcriptRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record transcriptRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("invalid transcript line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestTranscriptRecordsEachTurn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", TranscriptFile: path}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModeUpper))
+
+	secret := "sk-abcdefghijklmnopqrstuvwx"
+	collectStream(t, session, "my key is "+secret, true)
+	if _, err := session.GetResponse("second turn"); err != nil {
+		t.Fatalf("GetResponse failed: %v", err)
+	}
+
+	records := readTranscript(t, path)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 transcript records, got %d", len(records))
+	}
+	streamed, complete := records[0], records[1]
+	if !streamed.Stream || complete.Stream {
+		t.Errorf("expected stream flags true/false, got %v/%v", streamed.Stream, complete.Stream)
+	}
+	if streamed.Model != "gpt-4o-mini" || streamed.SessionID != session.SessionID || streamed.RequestID == "" {
+		t.Errorf("unexpected record metadata %+v", streamed)
+	}
+	last := streamed.Messages[len(streamed.Messages)-1]
+	if strings.Contains(last.Content, secret) || strings.Contains(streamed.Response, secret) {
+		t.Errorf("expected secrets to be redacted, got %q / %q", last.Content, streamed.Response)
+	}
+	if complete.Response != "SECOND TURN" {
+		t.Errorf("unexpected response %q", complete.Response)
+	}
+	// The second request carries the whole conversation so far.
+	if len(complete.Messages) != len(streamed.Messages)+2 {
+		t.Errorf("expected %d messages in second request, got %d", len(streamed.Messages)+2, len(complete.Messages))
+	}
+}
+
+func TestTranscriptRecordsToolCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	cfg := &config.Config{APIKey: "test-key", Model: "gpt-4o-mini", TranscriptFile: path}
+	session := NewSessionWithClient(cfg, NewEchoClient(EchoModePlain))
+
+	collectStream(t, session, `tool: cat {"path":"a.txt"}`, true)
+	records := readTranscript(t, path)
+	if len(records) != 1 || len(records[0].ToolCalls) != 1 || records[0].ToolCalls[0].Function.Name != "cat" {
+		t.Fatalf("expected a record with the cat tool call, got %+v", records)
+	}
+}
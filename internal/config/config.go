@@ -18,29 +18,110 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"promptline/internal/tools"
 )
 
+// ErrMissingAPIKey is returned by LoadConfig when no API key is configured.
+var ErrMissingAPIKey = errors.New("API key is required")
+
+// MaxStopSequences is the number of stop sequences accepted by OpenAI-compatible APIs.
+const MaxStopSequences = 4
+
+// defaultEmptyReplyRetries is how often an empty model reply is retried when
+// empty_reply_retries is not set.
+const defaultEmptyReplyRetries = 1
+
+// defaultStreamStallSeconds is how long a stream may go without a chunk when
+// stream_stall_seconds is not set.
+const defaultStreamStallSeconds = 60
+
+// defaultRenderFlushMillis is how often streamed text is written to the
+// terminal when render_flush_interval_ms is not set.
+const defaultRenderFlushMillis = 50
+
 // Config represents the application configuration
 type Config struct {
-	APIKey             string            `json:"api_key"`
-	APIURL             string            `json:"api_url,omitempty"`
-	Model              string            `json:"model"`
-	Temperature        *float32          `json:"temperature,omitempty"`
-	MaxTokens          *int              `json:"max_tokens,omitempty"`
-	Tools              ToolSettings      `json:"tools,omitempty"`
-	ToolLimits         ToolLimits        `json:"tool_limits,omitempty"`
-	ToolPathWhitelist  []string          `json:"tool_path_whitelist,omitempty"`
-	ToolRateLimits     ToolRateLimits    `json:"tool_rate_limits,omitempty"`
-	ToolTimeouts       ToolTimeouts      `json:"tool_timeouts,omitempty"`
-	ToolOutputFilters  ToolOutputFilters `json:"tool_output_filters,omitempty"`
-	HistoryFile        string            `json:"history_file,omitempty"`
-	CommandHistoryFile string            `json:"command_history_file,omitempty"`
-	HistoryMaxMessages int               `json:"history_max_messages,omitempty"`
+	APIKey              string            `json:"api_key"`
+	APIURL              string            `json:"api_url,omitempty"`
+	AllowNoAuth         bool              `json:"allow_no_auth,omitempty"`
+	ProxyURL            string            `json:"proxy_url,omitempty"`
+	TLSCAFile           string            `json:"tls_ca_file,omitempty"`
+	TLSInsecure         bool              `json:"tls_insecure_skip_verify,omitempty"`
+	Model               string            `json:"model"`
+	Temperature         *float32          `json:"temperature,omitempty"`
+	MaxTokens           *int              `json:"max_tokens,omitempty"`
+	Stop                []string          `json:"stop,omitempty"`
+	Fallbacks           []ProviderConfig  `json:"fallbacks,omitempty"`
+	Tools               ToolSettings      `json:"tools,omitempty"`
+	AutoApproveReadOnly bool              `json:"auto_approve_read_only,omitempty"`
+	ToolAliases         map[string]string `json:"tool_aliases,omitempty"`
+	DisabledTools       []string          `json:"disabled_tools,omitempty"`
+	AdvertisedTools     []string          `json:"advertised_tools,omitempty"`
+	HideDeniedTools     bool              `json:"hide_denied_tools,omitempty"`
+	ToolDescriptions    map[string]string `json:"tool_descriptions,omitempty"`
+	ToolGuidelines      string            `json:"tool_guidelines,omitempty"`
+	ProjectPromptFile   string            `json:"project_prompt_file,omitempty"`
+	PromptAddendum      string            `json:"system_prompt_addendum,omitempty"`
+	ToolLimits          ToolLimits        `json:"tool_limits,omitempty"`
+	ToolPathWhitelist   []string          `json:"tool_path_whitelist,omitempty"`
+	SandboxDir          string            `json:"sandbox_dir,omitempty"`
+	SandboxSeedFiles    []string          `json:"sandbox_seed_files,omitempty"`
+	KeepSandbox         bool              `json:"keep_sandbox,omitempty"`
+	CleanTempOnClose    *bool             `json:"clean_temp_on_close,omitempty"`
+	ToolRateLimits      ToolRateLimits    `json:"tool_rate_limits,omitempty"`
+	ToolTimeouts        ToolTimeouts      `json:"tool_timeouts,omitempty"`
+	ToolOutputFilters   ToolOutputFilters `json:"tool_output_filters,omitempty"`
+	ToolResultMaxChars  int               `json:"tool_result_max_chars,omitempty"`
+	CompactToolResults  int               `json:"compact_old_tool_results,omitempty"`
+	LineEnding          string            `json:"line_ending,omitempty"`
+	Latin1Fallback      bool              `json:"latin1_fallback,omitempty"`
+	ReminderEveryNTurns int               `json:"reminder_every_n_turns,omitempty"`
+	ReminderText        string            `json:"reminder_text,omitempty"`
+	AssistantPrefill    string            `json:"assistant_prefill,omitempty"`
+	UserMessagePrefix   string            `json:"user_message_prefix,omitempty"`
+	SaveCodeBlocks      bool              `json:"save_code_blocks,omitempty"`
+	ToolCache           ToolCache         `json:"tool_cache,omitempty"`
+	ApprovalPreview     ApprovalPreview   `json:"approval_preview,omitempty"`
+	ApprovalHook        string            `json:"approval_hook,omitempty"`
+	HistoryFile         string            `json:"history_file,omitempty"`
+	CommandHistoryFile  string            `json:"command_history_file,omitempty"`
+	Keybindings         map[string]string `json:"keybindings,omitempty"`
+	HistoryMaxMessages  int               `json:"history_max_messages,omitempty"`
+	HistoryMaxBytes     int64             `json:"history_max_bytes,omitempty"`
+	ResumeOnStartup     bool              `json:"resume_on_startup,omitempty"`
+	TranscriptFile      string            `json:"transcript_file,omitempty"`
+	EventSocket         string            `json:"event_socket,omitempty"`
+	Streaming           *bool             `json:"streaming,omitempty"`
+	PreflightCheck      *bool             `json:"preflight_check,omitempty"`
+	ConfirmUnsavedQuit  *bool             `json:"confirm_unsaved_quit,omitempty"`
+	EmptyReplyRetries   *int              `json:"empty_reply_retries,omitempty"`
+	StreamStallSeconds  *int              `json:"stream_stall_seconds,omitempty"`
+	RenderFlushInterval *int              `json:"render_flush_interval_ms,omitempty"`
+	IdleTimeoutMinutes  int               `json:"idle_timeout_minutes,omitempty"`
+	IdleAction          string            `json:"idle_action,omitempty"`
+	PasteMaxBytes       int               `json:"paste_max_bytes,omitempty"`
+	ContextWindow       int               `json:"context_window,omitempty"`
+	ModelContextWindows map[string]int    `json:"model_context_windows,omitempty"`
+	Banner              string            `json:"banner,omitempty"`
+	StartupTip          string            `json:"startup_tip,omitempty"`
+}
+
+// ProviderConfig describes an alternate endpoint used when the primary one fails.
+// Empty fields inherit the primary settings, except APIURL which falls back to
+// the OpenAI default.
+type ProviderConfig struct {
+	APIURL string `json:"api_url,omitempty"`
+	APIKey string `json:"api_key,omitempty"`
+	Model  string `json:"model,omitempty"`
 }
 
 // ToolSettings describes tool allow/ask/deny lists.
@@ -67,15 +148,31 @@ type ToolRateLimits struct {
 
 // ToolTimeouts configures tool execution timeouts.
 type ToolTimeouts struct {
-	DefaultSeconds int            `json:"default_seconds,omitempty"`
-	PerToolSeconds map[string]int `json:"per_tool_seconds,omitempty"`
+	DefaultSeconds  int            `json:"default_seconds,omitempty"`
+	PerToolSeconds  map[string]int `json:"per_tool_seconds,omitempty"`
+	ApprovalSeconds int            `json:"approval_seconds,omitempty"`
+}
+
+// ToolCache configures result caching for read-only tools.
+type ToolCache struct {
+	MaxEntries int `json:"max_entries,omitempty"`
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+}
+
+// ApprovalPreview controls how tool arguments are shown in approval prompts.
+type ApprovalPreview struct {
+	MaxChars int  `json:"max_chars,omitempty"`
+	Pretty   bool `json:"pretty,omitempty"`
 }
 
 // ToolOutputFilters configures output sanitization for tool results.
 type ToolOutputFilters struct {
-	MaxChars     int  `json:"max_chars,omitempty"`
-	StripANSI    bool `json:"strip_ansi,omitempty"`
-	StripControl bool `json:"strip_control,omitempty"`
+	MaxChars               int      `json:"max_chars,omitempty"`
+	StripANSI              bool     `json:"strip_ansi,omitempty"`
+	StripControl           bool     `json:"strip_control,omitempty"`
+	RedactPatterns         []string `json:"redact_patterns,omitempty"`
+	TrimTrailingWhitespace bool     `json:"trim_trailing_whitespace,omitempty"`
+	CollapseBlankLines     bool     `json:"collapse_blank_lines,omitempty"`
 }
 
 // DefaultConfig returns a config with default values
@@ -84,7 +181,23 @@ func DefaultConfig() *Config {
 	defaultAPIURL := "https://api.openai.com/v1"
 	defaultHistoryFile := ".promptline_conversation_history"
 	defaultCommandHistoryFile := ".promptline_history"
+	defaultProjectPromptFile := ".promptline.md"
 	defaultHistoryMax := 100
+	defaultPasteMaxBytes := 64 * 1024
+	defaultContextWindow := 128000
+	defaultModelContextWindows := map[string]int{
+		"gpt-3.5-turbo": 16385,
+		"gpt-4":         8192,
+		"gpt-4-turbo":   128000,
+		"gpt-4o":        128000,
+		"gpt-4.1":       1047576,
+		"gpt-5":         400000,
+		"o1":            200000,
+		"o1-mini":       128000,
+		"o3":            200000,
+		"o4-mini":       200000,
+	}
+	defaultApprovalPreview := ApprovalPreview{MaxChars: 400}
 	defaultToolLimits := ToolLimits{
 		MaxFileSizeBytes:    tools.DefaultLimits().MaxFileSizeBytes,
 		MaxDirectoryDepth:   tools.DefaultLimits().MaxDirectoryDepth,
@@ -92,30 +205,42 @@ func DefaultConfig() *Config {
 	}
 	defaultToolRateLimits := ToolRateLimits{
 		DefaultPerMinute: tools.DefaultRateLimitConfig().DefaultPerMinute,
-		CooldownSeconds: map[string]int{},
+		CooldownSeconds:  map[string]int{},
 	}
 	defaultToolTimeouts := ToolTimeouts{
 		PerToolSeconds: map[string]int{},
 	}
+	defaultToolCache := ToolCache{
+		TTLSeconds: int(tools.DefaultCacheConfig().TTL / time.Second),
+	}
 	defaultToolOutputFilters := ToolOutputFilters{
-		MaxChars:     tools.DefaultOutputFilterConfig().MaxChars,
-		StripANSI:    tools.DefaultOutputFilterConfig().StripANSI,
-		StripControl: tools.DefaultOutputFilterConfig().StripControl,
+		MaxChars:       tools.DefaultOutputFilterConfig().MaxChars,
+		StripANSI:      tools.DefaultOutputFilterConfig().StripANSI,
+		StripControl:   tools.DefaultOutputFilterConfig().StripControl,
+		RedactPatterns: tools.DefaultRedactPatterns(),
 	}
 	return &Config{
-		Model:              defaultModel,
-		APIURL:             defaultAPIURL,
-		ToolLimits:         defaultToolLimits,
-		ToolRateLimits:     defaultToolRateLimits,
-		ToolTimeouts:       defaultToolTimeouts,
-		ToolOutputFilters:  defaultToolOutputFilters,
-		HistoryFile:        defaultHistoryFile,
-		CommandHistoryFile: defaultCommandHistoryFile,
-		HistoryMaxMessages: defaultHistoryMax,
+		Model:               defaultModel,
+		APIURL:              defaultAPIURL,
+		ToolLimits:          defaultToolLimits,
+		ToolRateLimits:      defaultToolRateLimits,
+		ToolTimeouts:        defaultToolTimeouts,
+		ToolOutputFilters:   defaultToolOutputFilters,
+		ToolCache:           defaultToolCache,
+		ApprovalPreview:     defaultApprovalPreview,
+		HistoryFile:         defaultHistoryFile,
+		CommandHistoryFile:  defaultCommandHistoryFile,
+		ProjectPromptFile:   defaultProjectPromptFile,
+		HistoryMaxMessages:  defaultHistoryMax,
+		PasteMaxBytes:       defaultPasteMaxBytes,
+		ContextWindow:       defaultContextWindow,
+		ModelContextWindows: defaultModelContextWindows,
 	}
 }
 
 // LoadConfig loads configuration from a JSON file, applies env overrides, and validates required fields.
+// Without a required API key it returns ErrMissingAPIKey together with the
+// loaded configuration, for callers that never contact the provider.
 func LoadConfig(filepath string) (*Config, error) {
 	config := DefaultConfig()
 
@@ -160,13 +285,37 @@ func LoadConfig(filepath string) (*Config, error) {
 	}
 
 	// Validation
-	if config.APIKey == "" {
-		return nil, fmt.Errorf("API key is required (set api_key in config.json or OPENAI_API_KEY/DASHSCOPE_API_KEY)")
+	if config.APIKey == "" && config.RequiresAPIKey() {
+		return config, fmt.Errorf("%w for %s: export OPENAI_API_KEY (or DASHSCOPE_API_KEY), set \"api_key\" in %s, or set \"allow_no_auth\": true if the endpoint takes no key", ErrMissingAPIKey, config.APIURL, filepath)
+	}
+	if _, err := config.HTTPTransport(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+// RequiresAPIKey reports whether requests to api_url need an API key. Local
+// endpoints such as a model server on localhost, and any endpoint when
+// allow_no_auth is set, may be used without one.
+func (c *Config) RequiresAPIKey() bool {
+	return !c.AllowNoAuth && !isLocalEndpoint(c.APIURL)
+}
+
+// isLocalEndpoint reports whether rawURL points at this machine.
+func isLocalEndpoint(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // ToolPolicy converts config settings into a tool policy.
 func (c *Config) ToolPolicy() tools.Policy {
 	policy := tools.Policy{}
@@ -195,6 +344,23 @@ func (c *Config) ToolPolicy() tools.Policy {
 		}
 		policy.Deny = deny
 	}
+	policy.AutoApproveReadOnly = c.AutoApproveReadOnly
+	policy.Aliases = c.ToolAliases
+	if len(c.DisabledTools) > 0 {
+		disabled := make(map[string]bool, len(c.DisabledTools))
+		for _, name := range c.DisabledTools {
+			disabled[name] = true
+		}
+		policy.Disabled = disabled
+	}
+	if len(c.AdvertisedTools) > 0 {
+		advertised := make(map[string]bool, len(c.AdvertisedTools))
+		for _, name := range c.AdvertisedTools {
+			advertised[name] = true
+		}
+		policy.Advertised = advertised
+	}
+	policy.HideDenied = c.HideDeniedTools
 	return policy
 }
 
@@ -254,12 +420,154 @@ func (c *Config) ToolTimeoutsConfig() tools.TimeoutConfig {
 	}
 }
 
+// ToolCacheConfig returns result cache configuration for tools. The cache stays
+// disabled unless both max_entries and ttl_seconds are positive.
+func (c *Config) ToolCacheConfig() tools.CacheConfig {
+	if c.ToolCache.MaxEntries <= 0 || c.ToolCache.TTLSeconds <= 0 {
+		return tools.CacheConfig{}
+	}
+	return tools.CacheConfig{
+		MaxEntries: c.ToolCache.MaxEntries,
+		TTL:        time.Duration(c.ToolCache.TTLSeconds) * time.Second,
+	}
+}
+
+// StreamingEnabled reports whether responses are streamed. Streaming is on
+// unless the config explicitly disables it.
+func (c *Config) StreamingEnabled() bool {
+	return c.Streaming == nil || *c.Streaming
+}
+
+// CleanTempOnCloseEnabled reports whether files created by mktemp are removed
+// when the session closes. Cleanup is on unless the config disables it.
+func (c *Config) CleanTempOnCloseEnabled() bool {
+	return c.CleanTempOnClose == nil || *c.CleanTempOnClose
+}
+
+// PreflightEnabled reports whether the provider is checked at startup. The
+// check is on unless the config explicitly disables it.
+func (c *Config) PreflightEnabled() bool {
+	return c.PreflightCheck == nil || *c.PreflightCheck
+}
+
+// ModelContextWindow returns the context window of model in tokens: the
+// model_context_windows entry that is the longest prefix of the model name,
+// so "gpt-4o" covers "gpt-4o-2024-08-06", or context_window for models not
+// listed there.
+func (c *Config) ModelContextWindow(model string) int {
+	window, matched := 0, -1
+	for name, size := range c.ModelContextWindows {
+		if size > 0 && len(name) > matched && strings.HasPrefix(model, name) {
+			window, matched = size, len(name)
+		}
+	}
+	if matched < 0 {
+		return c.ContextWindow
+	}
+	return window
+}
+
+// ConfirmUnsavedQuitEnabled reports whether quitting asks what to do with
+// messages not yet written to history_file. It is on unless disabled.
+func (c *Config) ConfirmUnsavedQuitEnabled() bool {
+	return c.ConfirmUnsavedQuit == nil || *c.ConfirmUnsavedQuit
+}
+
+// EmptyReplyRetryCount returns how many times an empty model reply is
+// requested again before it is reported. It defaults to one retry.
+func (c *Config) EmptyReplyRetryCount() int {
+	if c.EmptyReplyRetries == nil {
+		return defaultEmptyReplyRetries
+	}
+	return max(*c.EmptyReplyRetries, 0)
+}
+
+// StreamStallTimeout returns how long a streamed reply may go without a chunk
+// before it is abandoned. Zero disables the check.
+func (c *Config) StreamStallTimeout() time.Duration {
+	seconds := defaultStreamStallSeconds
+	if c.StreamStallSeconds != nil {
+		seconds = *c.StreamStallSeconds
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// ResolvePaths makes the relative file paths of the configuration absolute
+// against base, so they keep naming the same files when tools later work in
+// another directory, such as a session sandbox.
+func (c *Config) ResolvePaths(base string) {
+	for _, path := range []*string{
+		&c.HistoryFile,
+		&c.CommandHistoryFile,
+		&c.TranscriptFile,
+		&c.EventSocket,
+		&c.ProjectPromptFile,
+		&c.TLSCAFile,
+		&c.SandboxDir,
+	} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(base, *path)
+		}
+	}
+}
+
+// RenderFlushPeriod returns how long streamed text may be held back before it
+// is written to the terminal. Zero writes every chunk as it arrives.
+func (c *Config) RenderFlushPeriod() time.Duration {
+	millis := defaultRenderFlushMillis
+	if c.RenderFlushInterval != nil {
+		millis = *c.RenderFlushInterval
+	}
+	if millis <= 0 {
+		return 0
+	}
+	return time.Duration(millis) * time.Millisecond
+}
+
+// Idle actions taken once the prompt has waited idle_timeout_minutes.
+const (
+	IdleActionQuit = "quit"
+	IdleActionLock = "lock"
+)
+
+// IdleTimeout returns how long the prompt may wait for input before the idle
+// action runs. Zero disables it.
+func (c *Config) IdleTimeout() time.Duration {
+	if c.IdleTimeoutMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(c.IdleTimeoutMinutes) * time.Minute
+}
+
+// IdleActionMode returns the configured idle action, quit by default.
+func (c *Config) IdleActionMode() string {
+	if c.IdleAction == "" {
+		return IdleActionQuit
+	}
+	return c.IdleAction
+}
+
+// ToolApprovalTimeout returns how long an approval prompt waits before denying the tool.
+// Zero means the prompt waits indefinitely.
+func (c *Config) ToolApprovalTimeout() time.Duration {
+	if c.ToolTimeouts.ApprovalSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.ToolTimeouts.ApprovalSeconds) * time.Second
+}
+
 // ToolOutputFiltersConfig returns output filter configuration for tools.
 func (c *Config) ToolOutputFiltersConfig() tools.OutputFilterConfig {
 	return tools.OutputFilterConfig{
-		MaxChars:     c.ToolOutputFilters.MaxChars,
-		StripANSI:    c.ToolOutputFilters.StripANSI,
-		StripControl: c.ToolOutputFilters.StripControl,
+		MaxChars:               c.ToolOutputFilters.MaxChars,
+		StripANSI:              c.ToolOutputFilters.StripANSI,
+		StripControl:           c.ToolOutputFilters.StripControl,
+		RedactPatterns:         c.ToolOutputFilters.RedactPatterns,
+		TrimTrailingWhitespace: c.ToolOutputFilters.TrimTrailingWhitespace,
+		CollapseBlankLines:     c.ToolOutputFilters.CollapseBlankLines,
 	}
 }
 
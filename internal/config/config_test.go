@@ -17,9 +17,13 @@
 package config
 
 import (
+	"errors"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"promptline/internal/tools"
 )
@@ -63,6 +67,87 @@ func TestMissingAPIKeyReturnsError(t *testing.T) {
 	}
 }
 
+func TestMissingAPIKeyForRemoteEndpoint(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("DASHSCOPE_API_KEY", "")
+	t.Setenv("OPENAI_API_URL", "")
+
+	path := writeTempConfig(t, `{"api_url":"https://api.example.com/v1"}`)
+	cfg, err := LoadConfig(path)
+	if !errors.Is(err, ErrMissingAPIKey) {
+		t.Fatalf("expected ErrMissingAPIKey, got %v", err)
+	}
+	if cfg == nil || cfg.APIURL != "https://api.example.com/v1" {
+		t.Fatalf("expected the loaded config alongside ErrMissingAPIKey, got %+v", cfg)
+	}
+	for _, want := range []string{"https://api.example.com/v1", "OPENAI_API_KEY", `"api_key"`, path, "allow_no_auth"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected the error to mention %s, got %q", want, err)
+		}
+	}
+
+	for _, local := range []string{"http://localhost:11434/v1", "http://127.0.0.1:8080/v1", "http://[::1]:8000/v1"} {
+		cfg, err := LoadConfig(writeTempConfig(t, `{"api_url":"`+local+`"}`))
+		if err != nil {
+			t.Fatalf("expected %s to work without a key, got %v", local, err)
+		}
+		if cfg.RequiresAPIKey() {
+			t.Fatalf("expected %s to be treated as local", local)
+		}
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_url":"http://gpu-box.lan:8000/v1","allow_no_auth":true}`))
+	if err != nil {
+		t.Fatalf("expected allow_no_auth to permit an empty key, got %v", err)
+	}
+	if cfg.APIKey != "" || cfg.RequiresAPIKey() {
+		t.Fatalf("expected a keyless config, got key %q", cfg.APIKey)
+	}
+}
+
+func TestProxyAndTLSSettings(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k"}`))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if transport, err := cfg.HTTPTransport(); err != nil || transport != nil {
+		t.Fatalf("expected the default transport without settings, got %v, %v", transport, err)
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"k","proxy_url":"http://proxy.corp:3128","tls_insecure_skip_verify":true}`))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	transport, err := cfg.HTTPTransport()
+	if err != nil || transport == nil {
+		t.Fatalf("expected a transport, got %v, %v", transport, err)
+	}
+	req, _ := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/chat/completions", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.corp:3128" {
+		t.Fatalf("expected requests to go through the proxy, got %v, %v", proxy, err)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Fatal("expected certificate checks to be skipped")
+	}
+
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for _, content := range []string{
+		`{"api_key":"k","proxy_url":"ftp://proxy.corp"}`,
+		`{"api_key":"k","proxy_url":"http://"}`,
+		`{"api_key":"k","tls_ca_file":"` + filepath.Join(dir, "missing.pem") + `"}`,
+		`{"api_key":"k","tls_ca_file":"` + notPEM + `"}`,
+	} {
+		if _, err := LoadConfig(writeTempConfig(t, content)); err == nil {
+			t.Fatalf("expected %s to be rejected at load", content)
+		}
+	}
+}
+
 func TestConfigValidationRejectsUnknownField(t *testing.T) {
 	path := writeTempConfig(t, `{"api_key":"k","unknown_field":123}`)
 	_, err := LoadConfig(path)
@@ -212,6 +297,21 @@ func TestToolTimeoutsCustom(t *testing.T) {
 	}
 }
 
+func TestToolApprovalTimeout(t *testing.T) {
+	if timeout := DefaultConfig().ToolApprovalTimeout(); timeout != 0 {
+		t.Fatalf("expected no approval timeout by default, got %v", timeout)
+	}
+
+	path := writeTempConfig(t, `{"api_key":"k","tool_timeouts":{"approval_seconds":30}}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if timeout := cfg.ToolApprovalTimeout(); timeout != 30*time.Second {
+		t.Errorf("expected 30s approval timeout, got %v", timeout)
+	}
+}
+
 func TestToolOutputFiltersCustom(t *testing.T) {
 	content := `{
 		"api_key": "k",
@@ -237,6 +337,48 @@ func TestToolOutputFiltersCustom(t *testing.T) {
 	}
 }
 
+func TestToolOutputFiltersWhitespace(t *testing.T) {
+	if filters := DefaultConfig().ToolOutputFiltersConfig(); filters.TrimTrailingWhitespace || filters.CollapseBlankLines {
+		t.Fatal("expected whitespace filters to be off by default")
+	}
+
+	path := writeTempConfig(t, `{"api_key":"k","tool_output_filters":{"trim_trailing_whitespace":true,"collapse_blank_lines":true}}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filters := cfg.ToolOutputFiltersConfig()
+	if !filters.TrimTrailingWhitespace || !filters.CollapseBlankLines {
+		t.Fatalf("expected whitespace filters enabled, got %+v", filters)
+	}
+
+	path = writeTempConfig(t, `{"api_key":"k","tool_output_filters":{"collapse_blank_lines":"yes"}}`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for non-boolean collapse_blank_lines")
+	}
+}
+
+func TestToolOutputFilterRedactPatterns(t *testing.T) {
+	cfg := DefaultConfig()
+	if len(cfg.ToolOutputFiltersConfig().RedactPatterns) == 0 {
+		t.Fatal("expected default redact patterns")
+	}
+
+	path := writeTempConfig(t, `{"api_key":"k","tool_output_filters":{"redact_patterns":["secret-[a-z]+"]}}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.ToolOutputFiltersConfig().RedactPatterns; len(got) != 1 || got[0] != "secret-[a-z]+" {
+		t.Errorf("unexpected redact patterns %q", got)
+	}
+
+	path = writeTempConfig(t, `{"api_key":"k","tool_output_filters":{"redact_patterns":["("]}}`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for invalid redact pattern")
+	}
+}
+
 func TestToolPolicyEmpty(t *testing.T) {
 	path := writeTempConfig(t, `{"api_key":"test-key"}`)
 	cfg, err := LoadConfig(path)
@@ -338,6 +480,439 @@ func TestTemperatureAndMaxTokensSet(t *testing.T) {
 	}
 }
 
+func TestStopSequencesSet(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"k","stop":["END","\n\n"]}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Stop) != 2 || cfg.Stop[0] != "END" || cfg.Stop[1] != "\n\n" {
+		t.Errorf("unexpected stop sequences %q", cfg.Stop)
+	}
+}
+
+func TestStopSequencesRejectsTooMany(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"k","stop":["a","b","c","d","e"]}`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for more than 4 stop sequences")
+	}
+}
+
+func TestHistoryMaxBytes(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"k","history_file":"conv.jsonl","history_max_bytes":1048576}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.HistoryFile != "conv.jsonl" || cfg.HistoryMaxBytes != 1048576 {
+		t.Errorf("unexpected history settings %q %d", cfg.HistoryFile, cfg.HistoryMaxBytes)
+	}
+}
+
+func TestStreamingDefaultsOn(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.StreamingEnabled() {
+		t.Error("expected streaming to be on by default")
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"k","streaming":false}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.StreamingEnabled() {
+		t.Error("expected streaming to be disabled")
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","streaming":"no"}`)); err == nil {
+		t.Error("expected error for non-boolean streaming")
+	}
+}
+
+func TestPasteMaxBytes(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PasteMaxBytes != 64*1024 {
+		t.Errorf("expected default paste limit of 64 KiB, got %d", cfg.PasteMaxBytes)
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"k","paste_max_bytes":1000}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PasteMaxBytes != 1000 {
+		t.Errorf("expected paste limit 1000, got %d", cfg.PasteMaxBytes)
+	}
+}
+
+func TestContextWindow(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ContextWindow != 128000 {
+		t.Errorf("expected default context window 128000, got %d", cfg.ContextWindow)
+	}
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","context_window":"big"}`)); err == nil {
+		t.Error("expected error for non-numeric context_window")
+	}
+}
+
+func TestModelContextWindow(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","context_window":32000,"model_context_windows":{"gpt-4o":64000,"llama3":8192}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := map[string]int{
+		"gpt-4o-2024-08-06": 64000,   // config entry replaces the default
+		"gpt-4":             8192,    // default entry kept
+		"gpt-4.1-mini":      1047576, // longest prefix wins over gpt-4
+		"llama3:70b":        8192,    // config entry added
+		"mistral-large":     32000,   // context_window for unlisted models
+	}
+	for model, want := range tests {
+		if got := cfg.ModelContextWindow(model); got != want {
+			t.Errorf("%s: expected %d, got %d", model, want, got)
+		}
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","model_context_windows":{"gpt-4o":"big"}}`)); err == nil {
+		t.Error("expected error for non-numeric model_context_windows entry")
+	}
+}
+
+func TestBannerAndStartupTip(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","banner":"Hello\nWorld","startup_tip":"try /context"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Banner != "Hello\nWorld" || cfg.StartupTip != "try /context" {
+		t.Errorf("unexpected banner %q and tip %q", cfg.Banner, cfg.StartupTip)
+	}
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","banner":["x"]}`)); err == nil {
+		t.Error("expected error for non-string banner")
+	}
+}
+
+func TestToolCacheConfig(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ToolCacheConfig().MaxEntries != 0 {
+		t.Errorf("expected tool cache disabled by default, got %+v", cfg.ToolCacheConfig())
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"k","tool_cache":{"max_entries":50}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache := cfg.ToolCacheConfig()
+	if cache.MaxEntries != 50 || cache.TTL != 30*time.Second {
+		t.Errorf("expected 50 entries with the default TTL, got %+v", cache)
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","tool_cache":{"size":5}}`)); err == nil {
+		t.Error("expected error for unknown tool_cache key")
+	}
+}
+
+func TestTranscriptFile(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","transcript_file":"transcript.jsonl"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TranscriptFile != "transcript.jsonl" {
+		t.Errorf("unexpected transcript file %q", cfg.TranscriptFile)
+	}
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"k","transcript_file":true}`)); err == nil {
+		t.Error("expected error for non-string transcript_file")
+	}
+}
+
+func TestAutoApproveReadOnly(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"test-key","auto_approve_read_only":true}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.AutoApproveReadOnly || !cfg.ToolPolicy().AutoApproveReadOnly {
+		t.Fatal("expected auto_approve_read_only to reach the tool policy")
+	}
+
+	path = writeTempConfig(t, `{"api_key":"test-key","auto_approve_read_only":"yes"}`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for non-boolean auto_approve_read_only")
+	}
+}
+
+func TestToolAliases(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"test-key","tool_aliases":{"list_files":"ls","cat_file":"read_file"}}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := cfg.ToolPolicy().Aliases["cat_file"]; got != "read_file" {
+		t.Fatalf("expected cat_file alias to reach the tool policy, got %q", got)
+	}
+
+	path = writeTempConfig(t, `{"api_key":"test-key","tool_aliases":{"list_files":1}}`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for non-string tool alias")
+	}
+}
+
+func TestDisabledTools(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","disabled_tools":["mkfifo"]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.ToolPolicy().Disabled["mkfifo"] {
+		t.Fatalf("expected mkfifo to be disabled in the tool policy, got %v", cfg.ToolPolicy().Disabled)
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","disabled_tools":"mkfifo"}`)); err == nil {
+		t.Fatal("expected error for non-array disabled_tools")
+	}
+}
+
+func TestAdvertisedTools(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","advertised_tools":["grep","ls"],"hide_denied_tools":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	policy := cfg.ToolPolicy()
+	if len(policy.Advertised) != 2 || !policy.Advertised["grep"] || !policy.Advertised["ls"] {
+		t.Fatalf("expected grep and ls to be advertised, got %v", policy.Advertised)
+	}
+	if !policy.HideDenied {
+		t.Fatal("expected hide_denied_tools to reach the tool policy")
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","advertised_tools":"grep"}`)); err == nil {
+		t.Fatal("expected error for non-array advertised_tools")
+	}
+}
+
+func TestSandboxSettings(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","sandbox_dir":"/tmp/pl","sandbox_seed_files":["go.mod"],"keep_sandbox":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SandboxDir != "/tmp/pl" || len(cfg.SandboxSeedFiles) != 1 || !cfg.KeepSandbox {
+		t.Fatalf("unexpected sandbox settings: %q %v %v", cfg.SandboxDir, cfg.SandboxSeedFiles, cfg.KeepSandbox)
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","keep_sandbox":"yes"}`)); err == nil {
+		t.Fatal("expected error for non-boolean keep_sandbox")
+	}
+}
+
+func TestKeybindings(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","keybindings":{"history-prev":"ctrl+k","quit":"Ctrl+Q"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bindings, err := cfg.KeyBindings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bindings[KeyActionHistoryPrev] != 11 || bindings[KeyActionQuit] != 17 || bindings[KeyActionSend] != '\r' {
+		t.Fatalf("unexpected bindings: %v", bindings)
+	}
+
+	for _, body := range []string{
+		`{"api_key":"test-key","keybindings":{"toggle-everything":"ctrl+t"}}`,
+		`{"api_key":"test-key","keybindings":{"send":"alt+enter"}}`,
+		`{"api_key":"test-key","keybindings":{"history-prev":"ctrl+n"}}`,
+	} {
+		if _, err := LoadConfig(writeTempConfig(t, body)); err == nil {
+			t.Errorf("expected error for %s", body)
+		}
+	}
+}
+
+func TestToolDescriptionsAndGuidelines(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"test-key","tool_descriptions":{"ls":"List a directory"},"tool_guidelines":"Prefer grep over cat."}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ToolDescriptions["ls"] != "List a directory" || cfg.ToolGuidelines != "Prefer grep over cat." {
+		t.Fatalf("unexpected tool settings: %v %q", cfg.ToolDescriptions, cfg.ToolGuidelines)
+	}
+
+	path = writeTempConfig(t, `{"api_key":"test-key","tool_descriptions":["ls"]}`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for non-object tool_descriptions")
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","line_ending":"crlf"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.LineEnding != "crlf" {
+		t.Fatalf("expected crlf line ending, got %q", cfg.LineEnding)
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","line_ending":"cr"}`)); err == nil {
+		t.Fatal("expected error for unknown line_ending")
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.IdleTimeout() != 0 || cfg.IdleActionMode() != IdleActionQuit {
+		t.Fatalf("expected idle timeout off with quit action, got %v %q", cfg.IdleTimeout(), cfg.IdleActionMode())
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"test-key","idle_timeout_minutes":15,"idle_action":"lock"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.IdleTimeout() != 15*time.Minute || cfg.IdleActionMode() != IdleActionLock {
+		t.Fatalf("expected a 15 minute lock, got %v %q", cfg.IdleTimeout(), cfg.IdleActionMode())
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","idle_action":"sleep"}`)); err == nil {
+		t.Fatal("expected error for unknown idle_action")
+	}
+}
+
+func TestLatin1Fallback(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","latin1_fallback":true}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Latin1Fallback {
+		t.Fatal("expected latin1_fallback to be enabled")
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","latin1_fallback":"yes"}`)); err == nil {
+		t.Fatal("expected error for non-boolean latin1_fallback")
+	}
+}
+
+func TestReminderSettings(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","reminder_every_n_turns":5,"reminder_text":"Use tools sparingly."}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ReminderEveryNTurns != 5 || cfg.ReminderText != "Use tools sparingly." {
+		t.Fatalf("unexpected reminder settings: %d %q", cfg.ReminderEveryNTurns, cfg.ReminderText)
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","reminder_every_n_turns":"5"}`)); err == nil {
+		t.Fatal("expected error for non-numeric reminder_every_n_turns")
+	}
+}
+
+func TestEmptyReplyRetries(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.EmptyReplyRetryCount() != 1 {
+		t.Fatalf("expected 1 retry by default, got %d", cfg.EmptyReplyRetryCount())
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"test-key","empty_reply_retries":0}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.EmptyReplyRetryCount() != 0 {
+		t.Fatalf("expected retries to be disabled, got %d", cfg.EmptyReplyRetryCount())
+	}
+}
+
+func TestApprovalPreview(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ApprovalPreview.MaxChars != 400 || cfg.ApprovalPreview.Pretty {
+		t.Fatalf("unexpected default approval preview: %+v", cfg.ApprovalPreview)
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"test-key","approval_preview":{"max_chars":1000,"pretty":true}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ApprovalPreview.MaxChars != 1000 || !cfg.ApprovalPreview.Pretty {
+		t.Fatalf("unexpected approval preview: %+v", cfg.ApprovalPreview)
+	}
+
+	if _, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key","approval_preview":{"pretty":"yes"}}`)); err == nil {
+		t.Fatal("expected error for non-boolean approval_preview.pretty")
+	}
+}
+
+func TestPreflightCheck(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.PreflightEnabled() {
+		t.Fatal("expected preflight check to be on by default")
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"test-key","preflight_check":false}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PreflightEnabled() {
+		t.Fatal("expected preflight_check false to disable the check")
+	}
+}
+
+func TestConfirmUnsavedQuit(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, `{"api_key":"test-key"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.ConfirmUnsavedQuitEnabled() {
+		t.Fatal("expected the unsaved quit confirmation to be on by default")
+	}
+
+	cfg, err = LoadConfig(writeTempConfig(t, `{"api_key":"test-key","confirm_unsaved_quit":false}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ConfirmUnsavedQuitEnabled() {
+		t.Fatal("expected confirm_unsaved_quit false to disable the confirmation")
+	}
+}
+
+func TestFallbacksSet(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"k","fallbacks":[{"api_url":"http://backup/v1","model":"m2"},{"api_key":"k3"}]}`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Fallbacks) != 2 {
+		t.Fatalf("expected 2 fallbacks, got %d", len(cfg.Fallbacks))
+	}
+	if cfg.Fallbacks[0].APIURL != "http://backup/v1" || cfg.Fallbacks[0].Model != "m2" || cfg.Fallbacks[1].APIKey != "k3" {
+		t.Errorf("unexpected fallbacks %+v", cfg.Fallbacks)
+	}
+}
+
+func TestFallbacksRejectsUnknownField(t *testing.T) {
+	path := writeTempConfig(t, `{"api_key":"k","fallbacks":[{"url":"http://backup/v1"}]}`)
+	if _, err := LoadConfig(path); err == nil {
+		t.Fatal("expected error for unknown fallback field")
+	}
+}
+
 func TestLoadConfigMissingFileReturnsDefault(t *testing.T) {
 	// Missing file with env key should still work
 	t.Setenv("OPENAI_API_KEY", "test-key")
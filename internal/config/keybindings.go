@@ -0,0 +1,100 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Key binding actions accepted in the keybindings section.
+const (
+	KeyActionSend        = "send"
+	KeyActionCancel      = "cancel"
+	KeyActionQuit        = "quit"
+	KeyActionHistoryPrev = "history-prev"
+	KeyActionHistoryNext = "history-next"
+	KeyActionSearch      = "search"
+)
+
+// DefaultKeybindings are the keys the console uses when keybindings does not
+// override them.
+var DefaultKeybindings = map[string]string{
+	KeyActionSend:        "enter",
+	KeyActionCancel:      "ctrl+c",
+	KeyActionQuit:        "ctrl+d",
+	KeyActionHistoryPrev: "ctrl+p",
+	KeyActionHistoryNext: "ctrl+n",
+	KeyActionSearch:      "ctrl+r",
+}
+
+// ParseKeySpec parses a key such as "ctrl+k", "enter" or "tab" into the rune
+// the terminal sends for it. Only keys that arrive as a single control
+// character can be bound.
+func ParseKeySpec(spec string) (rune, error) {
+	key := strings.ToLower(strings.TrimSpace(spec))
+	switch key {
+	case "enter", "return":
+		return '\r', nil
+	case "tab":
+		return '\t', nil
+	}
+	if letter, ok := strings.CutPrefix(key, "ctrl+"); ok && len(letter) == 1 && letter[0] >= 'a' && letter[0] <= 'z' {
+		return rune(letter[0]-'a') + 1, nil
+	}
+	return 0, fmt.Errorf("unknown key %q (use ctrl+a to ctrl+z, enter or tab)", spec)
+}
+
+// KeyBindings returns the key rune for every action, with the configured
+// overrides applied to DefaultKeybindings.
+func (c *Config) KeyBindings() (map[string]rune, error) {
+	specs := make(map[string]string, len(DefaultKeybindings))
+	for action, spec := range DefaultKeybindings {
+		specs[action] = spec
+	}
+	for action, spec := range c.Keybindings {
+		if _, ok := DefaultKeybindings[action]; !ok {
+			return nil, fmt.Errorf("unknown key binding action %q (use %s)", action, strings.Join(keyActions(), ", "))
+		}
+		specs[action] = spec
+	}
+
+	bindings := make(map[string]rune, len(specs))
+	owners := make(map[rune]string, len(specs))
+	for _, action := range keyActions() {
+		key, err := ParseKeySpec(specs[action])
+		if err != nil {
+			return nil, fmt.Errorf("key binding %s: %v", action, err)
+		}
+		if other, taken := owners[key]; taken {
+			return nil, fmt.Errorf("key %q is bound to both %s and %s", specs[action], other, action)
+		}
+		owners[key] = action
+		bindings[action] = key
+	}
+	return bindings, nil
+}
+
+func keyActions() []string {
+	actions := make([]string, 0, len(DefaultKeybindings))
+	for action := range DefaultKeybindings {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
@@ -19,7 +19,10 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"sort"
+
+	"promptline/internal/tools"
 )
 
 // SchemaJSON returns the JSON schema for config.json.
@@ -67,22 +70,117 @@ func validateConfigMap(raw map[string]interface{}, prefix string) error {
 		"api_key": func(v interface{}) error { return validateString(v, prefix+"api_key") },
 		"api_url": func(v interface{}) error { return validateString(v, prefix+"api_url") },
 		"model":   func(v interface{}) error { return validateString(v, prefix+"model") },
+		"allow_no_auth": func(v interface{}) error {
+			return validateBool(v, prefix+"allow_no_auth")
+		},
+		"proxy_url": func(v interface{}) error {
+			return validateString(v, prefix+"proxy_url")
+		},
+		"tls_ca_file": func(v interface{}) error {
+			return validateString(v, prefix+"tls_ca_file")
+		},
+		"tls_insecure_skip_verify": func(v interface{}) error {
+			return validateBool(v, prefix+"tls_insecure_skip_verify")
+		},
 		"temperature": func(v interface{}) error {
 			return validateNumber(v, prefix+"temperature")
 		},
 		"max_tokens": func(v interface{}) error { return validateNumber(v, prefix+"max_tokens") },
+		"stop":       func(v interface{}) error { return validateStopSequences(v, prefix+"stop") },
+		"fallbacks":  func(v interface{}) error { return validateFallbacks(v, prefix+"fallbacks") },
 		"history_file": func(v interface{}) error {
 			return validateString(v, prefix+"history_file")
 		},
 		"command_history_file": func(v interface{}) error {
 			return validateString(v, prefix+"command_history_file")
 		},
+		"keybindings": func(v interface{}) error {
+			return validateKeybindings(v, prefix+"keybindings")
+		},
 		"history_max_messages": func(v interface{}) error {
 			return validateNumber(v, prefix+"history_max_messages")
 		},
+		"history_max_bytes": func(v interface{}) error {
+			return validateNumber(v, prefix+"history_max_bytes")
+		},
+		"resume_on_startup": func(v interface{}) error {
+			return validateBool(v, prefix+"resume_on_startup")
+		},
+		"transcript_file": func(v interface{}) error {
+			return validateString(v, prefix+"transcript_file")
+		},
+		"event_socket": func(v interface{}) error {
+			return validateString(v, prefix+"event_socket")
+		},
+		"streaming": func(v interface{}) error {
+			return validateBool(v, prefix+"streaming")
+		},
+		"preflight_check": func(v interface{}) error {
+			return validateBool(v, prefix+"preflight_check")
+		},
+		"empty_reply_retries": func(v interface{}) error {
+			return validateNumber(v, prefix+"empty_reply_retries")
+		},
+		"stream_stall_seconds": func(v interface{}) error {
+			return validateNumber(v, prefix+"stream_stall_seconds")
+		},
+		"render_flush_interval_ms": func(v interface{}) error {
+			return validateNumber(v, prefix+"render_flush_interval_ms")
+		},
+		"confirm_unsaved_quit": func(v interface{}) error {
+			return validateBool(v, prefix+"confirm_unsaved_quit")
+		},
+		"idle_timeout_minutes": func(v interface{}) error {
+			return validateNumber(v, prefix+"idle_timeout_minutes")
+		},
+		"idle_action": func(v interface{}) error {
+			return validateIdleAction(v, prefix+"idle_action")
+		},
+		"paste_max_bytes": func(v interface{}) error {
+			return validateNumber(v, prefix+"paste_max_bytes")
+		},
+		"context_window": func(v interface{}) error {
+			return validateNumber(v, prefix+"context_window")
+		},
+		"model_context_windows": func(v interface{}) error {
+			return validateStringNumberMap(v, prefix+"model_context_windows")
+		},
+		"banner": func(v interface{}) error {
+			return validateString(v, prefix+"banner")
+		},
+		"startup_tip": func(v interface{}) error {
+			return validateString(v, prefix+"startup_tip")
+		},
 		"tools": func(v interface{}) error {
 			return validateToolsConfig(v, prefix+"tools.")
 		},
+		"auto_approve_read_only": func(v interface{}) error {
+			return validateBool(v, prefix+"auto_approve_read_only")
+		},
+		"tool_aliases": func(v interface{}) error {
+			return validateStringStringMap(v, prefix+"tool_aliases")
+		},
+		"disabled_tools": func(v interface{}) error {
+			return validateStringArray(v, prefix+"disabled_tools")
+		},
+		"advertised_tools": func(v interface{}) error {
+			return validateStringArray(v, prefix+"advertised_tools")
+		},
+		"hide_denied_tools": func(v interface{}) error {
+			return validateBool(v, prefix+"hide_denied_tools")
+		},
+		"tool_descriptions": func(v interface{}) error {
+			return validateStringStringMap(v, prefix+"tool_descriptions")
+		},
+		"tool_guidelines": func(v interface{}) error {
+			return validateString(v, prefix+"tool_guidelines")
+		},
+		"project_prompt_file": func(v interface{}) error {
+			return validateString(v, prefix+"project_prompt_file")
+		},
+		"system_prompt_addendum": func(v interface{}) error {
+			return validateString(v, prefix+"system_prompt_addendum")
+		},
 		"tool_limits": func(v interface{}) error {
 			return validateToolLimits(v, prefix+"tool_limits.")
 		},
@@ -95,9 +193,57 @@ func validateConfigMap(raw map[string]interface{}, prefix string) error {
 		"tool_timeouts": func(v interface{}) error {
 			return validateToolTimeouts(v, prefix+"tool_timeouts.")
 		},
+		"tool_cache": func(v interface{}) error {
+			return validateToolCache(v, prefix+"tool_cache.")
+		},
 		"tool_output_filters": func(v interface{}) error {
 			return validateToolOutputFilters(v, prefix+"tool_output_filters.")
 		},
+		"tool_result_max_chars": func(v interface{}) error {
+			return validateNumber(v, prefix+"tool_result_max_chars")
+		},
+		"compact_old_tool_results": func(v interface{}) error {
+			return validateNumber(v, prefix+"compact_old_tool_results")
+		},
+		"sandbox_dir": func(v interface{}) error {
+			return validateString(v, prefix+"sandbox_dir")
+		},
+		"sandbox_seed_files": func(v interface{}) error {
+			return validateStringArray(v, prefix+"sandbox_seed_files")
+		},
+		"keep_sandbox": func(v interface{}) error {
+			return validateBool(v, prefix+"keep_sandbox")
+		},
+		"clean_temp_on_close": func(v interface{}) error {
+			return validateBool(v, prefix+"clean_temp_on_close")
+		},
+		"latin1_fallback": func(v interface{}) error {
+			return validateBool(v, prefix+"latin1_fallback")
+		},
+		"reminder_every_n_turns": func(v interface{}) error {
+			return validateNumber(v, prefix+"reminder_every_n_turns")
+		},
+		"reminder_text": func(v interface{}) error {
+			return validateString(v, prefix+"reminder_text")
+		},
+		"assistant_prefill": func(v interface{}) error {
+			return validateString(v, prefix+"assistant_prefill")
+		},
+		"user_message_prefix": func(v interface{}) error {
+			return validateString(v, prefix+"user_message_prefix")
+		},
+		"save_code_blocks": func(v interface{}) error {
+			return validateBool(v, prefix+"save_code_blocks")
+		},
+		"line_ending": func(v interface{}) error {
+			return validateLineEnding(v, prefix+"line_ending")
+		},
+		"approval_hook": func(v interface{}) error {
+			return validateString(v, prefix+"approval_hook")
+		},
+		"approval_preview": func(v interface{}) error {
+			return validateApprovalPreview(v, prefix+"approval_preview.")
+		},
 	}
 
 	for key, value := range raw {
@@ -170,6 +316,31 @@ func validateToolTimeouts(value interface{}, prefix string) error {
 	allowed := map[string]func(interface{}) error{
 		"default_seconds":  func(v interface{}) error { return validateNumber(v, prefix+"default_seconds") },
 		"per_tool_seconds": func(v interface{}) error { return validateStringNumberMap(v, prefix+"per_tool_seconds") },
+		"approval_seconds": func(v interface{}) error { return validateNumber(v, prefix+"approval_seconds") },
+	}
+	return validateSection(section, allowed, prefix)
+}
+
+func validateToolCache(value interface{}, prefix string) error {
+	section, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%stool_cache must be an object", prefix)
+	}
+	allowed := map[string]func(interface{}) error{
+		"max_entries": func(v interface{}) error { return validateNumber(v, prefix+"max_entries") },
+		"ttl_seconds": func(v interface{}) error { return validateNumber(v, prefix+"ttl_seconds") },
+	}
+	return validateSection(section, allowed, prefix)
+}
+
+func validateApprovalPreview(value interface{}, prefix string) error {
+	section, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%sapproval_preview must be an object", prefix)
+	}
+	allowed := map[string]func(interface{}) error{
+		"max_chars": func(v interface{}) error { return validateNumber(v, prefix+"max_chars") },
+		"pretty":    func(v interface{}) error { return validateBool(v, prefix+"pretty") },
 	}
 	return validateSection(section, allowed, prefix)
 }
@@ -183,10 +354,42 @@ func validateToolOutputFilters(value interface{}, prefix string) error {
 		"max_chars":     func(v interface{}) error { return validateNumber(v, prefix+"max_chars") },
 		"strip_ansi":    func(v interface{}) error { return validateBool(v, prefix+"strip_ansi") },
 		"strip_control": func(v interface{}) error { return validateBool(v, prefix+"strip_control") },
+		"redact_patterns": func(v interface{}) error {
+			return validatePatternArray(v, prefix+"redact_patterns")
+		},
+		"trim_trailing_whitespace": func(v interface{}) error {
+			return validateBool(v, prefix+"trim_trailing_whitespace")
+		},
+		"collapse_blank_lines": func(v interface{}) error {
+			return validateBool(v, prefix+"collapse_blank_lines")
+		},
 	}
 	return validateSection(section, allowed, prefix)
 }
 
+func validateFallbacks(value interface{}, name string) error {
+	list, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("%s must be an array of objects", name)
+	}
+	for i, item := range list {
+		section, ok := item.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be an array of objects", name)
+		}
+		prefix := fmt.Sprintf("%s[%d].", name, i)
+		allowed := map[string]func(interface{}) error{
+			"api_url": func(v interface{}) error { return validateString(v, prefix+"api_url") },
+			"api_key": func(v interface{}) error { return validateString(v, prefix+"api_key") },
+			"model":   func(v interface{}) error { return validateString(v, prefix+"model") },
+		}
+		if err := validateSection(section, allowed, prefix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validateSection(section map[string]interface{}, allowed map[string]func(interface{}) error, prefix string) error {
 	keys := make([]string, 0, len(section))
 	for key := range section {
@@ -212,6 +415,43 @@ func validateString(value interface{}, name string) error {
 	return nil
 }
 
+func validateLineEnding(value interface{}, name string) error {
+	mode, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%s must be a string", name)
+	}
+	if _, err := tools.ParseLineEnding(mode); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+func validateIdleAction(value interface{}, name string) error {
+	action, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%s must be a string", name)
+	}
+	if action != IdleActionQuit && action != IdleActionLock {
+		return fmt.Errorf("%s must be %q or %q, got %q", name, IdleActionQuit, IdleActionLock, action)
+	}
+	return nil
+}
+
+func validateKeybindings(value interface{}, name string) error {
+	if err := validateStringStringMap(value, name); err != nil {
+		return err
+	}
+	section := value.(map[string]interface{})
+	cfg := Config{Keybindings: make(map[string]string, len(section))}
+	for action, spec := range section {
+		cfg.Keybindings[action] = spec.(string)
+	}
+	if _, err := cfg.KeyBindings(); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
 func validateNumber(value interface{}, name string) error {
 	if _, ok := value.(float64); !ok {
 		return fmt.Errorf("%s must be a number", name)
@@ -239,6 +479,28 @@ func validateStringArray(value interface{}, name string) error {
 	return nil
 }
 
+func validatePatternArray(value interface{}, name string) error {
+	if err := validateStringArray(value, name); err != nil {
+		return err
+	}
+	for _, item := range value.([]interface{}) {
+		if _, err := regexp.Compile(item.(string)); err != nil {
+			return fmt.Errorf("%s contains an invalid pattern %q: %v", name, item, err)
+		}
+	}
+	return nil
+}
+
+func validateStopSequences(value interface{}, name string) error {
+	if err := validateStringArray(value, name); err != nil {
+		return err
+	}
+	if count := len(value.([]interface{})); count > MaxStopSequences {
+		return fmt.Errorf("%s accepts at most %d sequences, got %d", name, MaxStopSequences, count)
+	}
+	return nil
+}
+
 func validateStringNumberMap(value interface{}, name string) error {
 	section, ok := value.(map[string]interface{})
 	if !ok {
@@ -252,6 +514,19 @@ func validateStringNumberMap(value interface{}, name string) error {
 	return nil
 }
 
+func validateStringStringMap(value interface{}, name string) error {
+	section, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s must be an object of string values", name)
+	}
+	for key, entry := range section {
+		if _, ok := entry.(string); !ok {
+			return fmt.Errorf("%s.%s must be a string", name, key)
+		}
+	}
+	return nil
+}
+
 const configSchemaJSON = `{
   "$schema": "https://json-schema.org/draft/2020-12/schema",
   "title": "Promptline Config",
@@ -259,12 +534,55 @@ const configSchemaJSON = `{
   "properties": {
     "api_key": { "type": "string" },
     "api_url": { "type": "string" },
+    "allow_no_auth": { "type": "boolean" },
+    "proxy_url": { "type": "string" },
+    "tls_ca_file": { "type": "string" },
+    "tls_insecure_skip_verify": { "type": "boolean" },
     "model": { "type": "string" },
     "temperature": { "type": "number" },
     "max_tokens": { "type": "number" },
+    "stop": { "type": "array", "items": { "type": "string" }, "maxItems": 4 },
+    "fallbacks": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "api_url": { "type": "string" },
+          "api_key": { "type": "string" },
+          "model": { "type": "string" }
+        }
+      }
+    },
     "history_file": { "type": "string" },
     "command_history_file": { "type": "string" },
+    "keybindings": { "type": "object", "additionalProperties": { "type": "string" } },
     "history_max_messages": { "type": "number" },
+    "history_max_bytes": { "type": "number" },
+    "resume_on_startup": { "type": "boolean" },
+    "transcript_file": { "type": "string" },
+    "event_socket": { "type": "string" },
+    "streaming": { "type": "boolean" },
+    "preflight_check": { "type": "boolean" },
+    "empty_reply_retries": { "type": "number" },
+    "stream_stall_seconds": { "type": "number" },
+    "render_flush_interval_ms": { "type": "number" },
+    "confirm_unsaved_quit": { "type": "boolean" },
+    "idle_timeout_minutes": { "type": "number" },
+    "idle_action": { "type": "string", "enum": ["quit", "lock"] },
+    "paste_max_bytes": { "type": "number" },
+    "context_window": { "type": "number" },
+    "model_context_windows": { "type": "object", "additionalProperties": { "type": "number" } },
+    "banner": { "type": "string" },
+    "startup_tip": { "type": "string" },
+    "auto_approve_read_only": { "type": "boolean" },
+    "tool_aliases": { "type": "object", "additionalProperties": { "type": "string" } },
+    "disabled_tools": { "type": "array", "items": { "type": "string" } },
+    "advertised_tools": { "type": "array", "items": { "type": "string" } },
+    "hide_denied_tools": { "type": "boolean" },
+    "tool_descriptions": { "type": "object", "additionalProperties": { "type": "string" } },
+    "tool_guidelines": { "type": "string" },
+    "project_prompt_file": { "type": "string" },
+    "system_prompt_addendum": { "type": "string" },
     "tools": {
       "type": "object",
       "properties": {
@@ -295,7 +613,23 @@ const configSchemaJSON = `{
       "type": "object",
       "properties": {
         "default_seconds": { "type": "number" },
-        "per_tool_seconds": { "type": "object", "additionalProperties": { "type": "number" } }
+        "per_tool_seconds": { "type": "object", "additionalProperties": { "type": "number" } },
+        "approval_seconds": { "type": "number" }
+      }
+    },
+    "tool_cache": {
+      "type": "object",
+      "properties": {
+        "max_entries": { "type": "number" },
+        "ttl_seconds": { "type": "number" }
+      }
+    },
+    "approval_hook": { "type": "string" },
+    "approval_preview": {
+      "type": "object",
+      "properties": {
+        "max_chars": { "type": "number" },
+        "pretty": { "type": "boolean" }
       }
     },
     "tool_output_filters": {
@@ -303,9 +637,25 @@ const configSchemaJSON = `{
       "properties": {
         "max_chars": { "type": "number" },
         "strip_ansi": { "type": "boolean" },
-        "strip_control": { "type": "boolean" }
+        "strip_control": { "type": "boolean" },
+        "redact_patterns": { "type": "array", "items": { "type": "string" } },
+        "trim_trailing_whitespace": { "type": "boolean" },
+        "collapse_blank_lines": { "type": "boolean" }
       }
-    }
+    },
+    "tool_result_max_chars": { "type": "number" },
+    "compact_old_tool_results": { "type": "number" },
+    "line_ending": { "type": "string", "enum": ["preserve", "lf", "crlf"] },
+    "sandbox_dir": { "type": "string" },
+    "sandbox_seed_files": { "type": "array", "items": { "type": "string" } },
+    "keep_sandbox": { "type": "boolean" },
+    "clean_temp_on_close": { "type": "boolean" },
+    "latin1_fallback": { "type": "boolean" },
+    "reminder_every_n_turns": { "type": "number" },
+    "reminder_text": { "type": "string" },
+    "assistant_prefill": { "type": "string" },
+    "user_message_prefix": { "type": "string" },
+    "save_code_blocks": { "type": "boolean", "default": false }
   }
 }`
 
@@ -329,6 +679,7 @@ const exampleConfigJSON = `{
       "realpath",
       "mkdir",
       "pwd",
+      "cd",
       "dirname",
       "basename",
       "grep",
@@ -342,6 +693,7 @@ const exampleConfigJSON = `{
       "comm",
       "strings",
       "more",
+      "view_code",
       "hexdump",
       "cmp",
       "md5sum",
@@ -364,6 +716,7 @@ const exampleConfigJSON = `{
       "mkfifo",
       "mktemp",
       "find",
+      "search",
       "chmod",
       "date"
     ]
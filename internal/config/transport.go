@@ -0,0 +1,96 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// ParsedProxyURL returns proxy_url as a URL, or nil when it is not set.
+// http, https and socks5 proxies are supported.
+func (c *Config) ParsedProxyURL() (*url.URL, error) {
+	if c.ProxyURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(c.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %v", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy_url %q: scheme must be http, https or socks5", c.ProxyURL)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy_url %q: missing host", c.ProxyURL)
+	}
+	return u, nil
+}
+
+// RootCAs returns the system certificate pool with the certificates of
+// tls_ca_file added, or nil when no CA file is configured.
+func (c *Config) RootCAs() (*x509.CertPool, error) {
+	if c.TLSCAFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.TLSCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tls_ca_file: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("tls_ca_file %s contains no PEM certificates", c.TLSCAFile)
+	}
+	return pool, nil
+}
+
+// HTTPTransport returns the transport for provider requests built from
+// proxy_url, tls_ca_file and tls_insecure_skip_verify, or nil when none is
+// set and the default transport, which honours HTTPS_PROXY, applies.
+func (c *Config) HTTPTransport() (*http.Transport, error) {
+	if c.ProxyURL == "" && c.TLSCAFile == "" && !c.TLSInsecure {
+		return nil, nil
+	}
+	proxy, err := c.ParsedProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	roots, err := c.RootCAs()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if roots != nil || c.TLSInsecure {
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:            roots,
+			InsecureSkipVerify: c.TLSInsecure,
+		}
+	}
+	return transport, nil
+}
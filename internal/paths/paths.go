@@ -17,6 +17,7 @@
 package paths
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -25,6 +26,9 @@ import (
 	"unicode/utf8"
 )
 
+// ErrPathEscapesBase is returned when a path resolves outside its base directory.
+var ErrPathEscapesBase = errors.New("path escapes working directory")
+
 // ValidatePathString validates raw path input before resolution.
 func ValidatePathString(path string, maxLen int) error {
 	if strings.TrimSpace(path) == "" {
@@ -70,7 +74,7 @@ func ResolveWithinBase(path, baseDir string) (string, error) {
 	cleanRel := filepath.Clean(path)
 	absPath := filepath.Clean(filepath.Join(baseResolved, cleanRel))
 	if !HasPathPrefix(absPath, baseResolved) {
-		return "", fmt.Errorf("path escapes working directory")
+		return "", ErrPathEscapesBase
 	}
 
 	resolved, err := ResolveSymlinkedPath(absPath, baseResolved)
@@ -79,7 +83,7 @@ func ResolveWithinBase(path, baseDir string) (string, error) {
 	}
 
 	if !HasPathPrefix(resolved, baseResolved) {
-		return "", fmt.Errorf("path escapes working directory")
+		return "", ErrPathEscapesBase
 	}
 
 	return resolved, nil
@@ -103,7 +107,7 @@ func ResolveSymlinkedPath(path, baseResolved string) (string, error) {
 		return "", fmt.Errorf("failed to resolve parent path: %v", err)
 	}
 	if !HasPathPrefix(parentResolved, baseResolved) {
-		return "", fmt.Errorf("path escapes working directory")
+		return "", ErrPathEscapesBase
 	}
 	return filepath.Join(parentResolved, filepath.Base(path)), nil
 }
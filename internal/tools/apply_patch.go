@@ -0,0 +1,445 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const devNull = "/dev/null"
+
+type applyPatchArgs struct {
+	Patch string `json:"patch" jsonschema:"description=Unified diff with --- / +++ file headers and @@ hunks,minLength=1" validate:"required,min=1"`
+	Fuzzy bool   `json:"fuzzy,omitempty" jsonschema:"description=Match context lines ignoring whitespace differences"`
+}
+
+// filePatch is the part of a unified diff that touches one file.
+type filePatch struct {
+	OldPath string
+	NewPath string
+	Hunks   []patchHunk
+}
+
+func (p filePatch) creates() bool { return p.OldPath == devNull }
+func (p filePatch) deletes() bool { return p.NewPath == devNull }
+
+// target is the path the patch reads and writes.
+func (p filePatch) target() string {
+	if p.deletes() {
+		return p.OldPath
+	}
+	return p.NewPath
+}
+
+type patchHunk struct {
+	OldStart int
+	Old      []string // context and removed lines
+	New      []string // context and added lines
+	// OldNoEOL and NewNoEOL record "\ No newline at end of file" markers.
+	OldNoEOL bool
+	NewNoEOL bool
+}
+
+// patchResult is a validated file change ready to be written.
+type patchResult struct {
+	Path     string
+	Resolved string
+	Content  string
+	Mode     os.FileMode
+	Hunks    int
+	Created  bool
+	Deleted  bool
+}
+
+var errPatchNoFiles = errors.New("patch contains no file changes")
+
+// parseUnifiedDiff splits a unified diff into per-file hunks. Lines outside
+// file sections, such as "diff --git" and "index" headers, are ignored.
+func parseUnifiedDiff(patch string) ([]filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	var files []filePatch
+	for i := 0; i < len(lines); i++ {
+		if !strings.HasPrefix(lines[i], "--- ") {
+			continue
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			return nil, fmt.Errorf("line %d: '---' header without a following '+++' header", i+1)
+		}
+		file := filePatch{
+			OldPath: patchHeaderPath(lines[i][4:]),
+			NewPath: patchHeaderPath(lines[i+1][4:]),
+		}
+		if file.OldPath == devNull && file.NewPath == devNull {
+			return nil, fmt.Errorf("line %d: both paths are %s", i+1, devNull)
+		}
+		// Only the target is validated and written, so a rename would leave
+		// OldPath unchecked and in place.
+		if !file.creates() && !file.deletes() && file.OldPath != file.NewPath {
+			return nil, fmt.Errorf("line %d: renames are not supported (%s -> %s); patch the file and move it with mv", i+1, file.OldPath, file.NewPath)
+		}
+		i += 2
+		for i < len(lines) && strings.HasPrefix(lines[i], "@@") {
+			hunk, next, err := parsePatchHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			file.Hunks = append(file.Hunks, hunk)
+			i = next
+		}
+		if len(file.Hunks) == 0 {
+			return nil, fmt.Errorf("%s: no hunks", file.target())
+		}
+		files = append(files, file)
+		i--
+	}
+	if len(files) == 0 {
+		return nil, errPatchNoFiles
+	}
+	return files, nil
+}
+
+// patchHeaderPath strips the a/ b/ prefixes and any trailing timestamp.
+func patchHeaderPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = strings.TrimSpace(path)
+	if path == devNull {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(path, "a/"); ok {
+		return rest
+	}
+	if rest, ok := strings.CutPrefix(path, "b/"); ok {
+		return rest
+	}
+	return path
+}
+
+// parsePatchHunk reads the hunk starting at lines[start] and returns the index
+// of the first line after it.
+func parsePatchHunk(lines []string, start int) (patchHunk, int, error) {
+	oldStart, oldCount, newCount, err := parseHunkHeader(lines[start])
+	if err != nil {
+		return patchHunk{}, 0, fmt.Errorf("line %d: %w", start+1, err)
+	}
+	hunk := patchHunk{OldStart: oldStart}
+	i := start + 1
+	last := byte(' ')
+	for ; i < len(lines) && (len(hunk.Old) < oldCount || len(hunk.New) < newCount || strings.HasPrefix(lines[i], `\`)); i++ {
+		line := lines[i]
+		if line == "" {
+			// Editors often strip the single space of an empty context line.
+			line = " "
+		}
+		switch line[0] {
+		case ' ':
+			hunk.Old = append(hunk.Old, line[1:])
+			hunk.New = append(hunk.New, line[1:])
+		case '-':
+			hunk.Old = append(hunk.Old, line[1:])
+		case '+':
+			hunk.New = append(hunk.New, line[1:])
+		case '\\':
+			if last != '+' {
+				hunk.OldNoEOL = true
+			}
+			if last != '-' {
+				hunk.NewNoEOL = true
+			}
+			continue
+		default:
+			return patchHunk{}, 0, fmt.Errorf("line %d: unexpected hunk line %q", i+1, line)
+		}
+		last = line[0]
+	}
+	if len(hunk.Old) != oldCount || len(hunk.New) != newCount {
+		return patchHunk{}, 0, fmt.Errorf("line %d: hunk expects %d old and %d new lines, found %d and %d", start+1, oldCount, newCount, len(hunk.Old), len(hunk.New))
+	}
+	return hunk, i, nil
+}
+
+// parseHunkHeader parses "@@ -l[,s] +l[,s] @@ ...".
+func parseHunkHeader(header string) (oldStart, oldCount, newCount int, err error) {
+	fields := strings.Fields(header)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	oldStart, oldCount, err = parseHunkRange(fields[1][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	_, newCount, err = parseHunkRange(fields[2][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hunk header %q", header)
+	}
+	return oldStart, oldCount, newCount, nil
+}
+
+func parseHunkRange(r string) (int, int, error) {
+	startText, countText, hasCount := strings.Cut(r, ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid range %q", r)
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil || count < 0 {
+			return 0, 0, fmt.Errorf("invalid range %q", r)
+		}
+	}
+	return start, count, nil
+}
+
+// applyFilePatch applies hunks in order to content. Each hunk must match
+// exactly (or ignoring whitespace when fuzzy is set); it is looked for at its
+// stated line first and then at growing distances, like patch(1) does.
+func applyFilePatch(content string, hunks []patchHunk, fuzzy bool) (string, error) {
+	lines, eol := splitPatchLines(content)
+	var out []string
+	pos := 0
+	offset := 0
+	for n, hunk := range hunks {
+		expected := hunk.OldStart - 1 + offset
+		if len(hunk.Old) == 0 {
+			// Pure insertion: the start is the line after which to insert.
+			expected = hunk.OldStart + offset
+		}
+		at := findHunk(lines, hunk.Old, pos, expected, fuzzy)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (@@ -%d) does not match the file", n+1, hunk.OldStart)
+		}
+		out = append(out, lines[pos:at]...)
+		out = append(out, hunk.New...)
+		pos = at + len(hunk.Old)
+		offset = at - (hunk.OldStart - 1)
+		if len(hunk.Old) == 0 {
+			offset = at - hunk.OldStart
+		}
+		if pos == len(lines) {
+			if hunk.NewNoEOL {
+				eol = false
+			} else if hunk.OldNoEOL || len(lines) == 0 {
+				eol = true
+			}
+		}
+	}
+	out = append(out, lines[pos:]...)
+	if len(out) == 0 {
+		return "", nil
+	}
+	result := strings.Join(out, "\n")
+	if eol {
+		result += "\n"
+	}
+	return result, nil
+}
+
+// splitPatchLines splits content into lines and reports whether it ends with
+// a newline.
+func splitPatchLines(content string) ([]string, bool) {
+	if content == "" {
+		return nil, false
+	}
+	eol := strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), eol
+}
+
+func findHunk(lines, old []string, minPos, expected int, fuzzy bool) int {
+	maxPos := len(lines) - len(old)
+	if maxPos < minPos {
+		return -1
+	}
+	expected = max(minPos, min(expected, maxPos))
+	for delta := 0; expected-delta >= minPos || expected+delta <= maxPos; delta++ {
+		if at := expected - delta; at >= minPos && hunkMatches(lines[at:], old, fuzzy) {
+			return at
+		}
+		if at := expected + delta; delta > 0 && at <= maxPos && hunkMatches(lines[at:], old, fuzzy) {
+			return at
+		}
+	}
+	return -1
+}
+
+func hunkMatches(lines, old []string, fuzzy bool) bool {
+	for i, want := range old {
+		got := lines[i]
+		if fuzzy {
+			got, want = strings.Join(strings.Fields(got), " "), strings.Join(strings.Fields(want), " ")
+		}
+		if got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// planPatch parses the patch and computes every file change without writing,
+// so a hunk that fails to apply leaves all files untouched.
+func planPatch(ctx context.Context, args map[string]interface{}) ([]patchResult, error) {
+	parsed, err := unmarshalAndValidate[applyPatchArgs](args)
+	if err != nil {
+		return nil, err
+	}
+	files, err := parseUnifiedDiff(parsed.Patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	workdir, err := toolWorkdir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine working directory: %v", err)
+	}
+	limits := getLimits()
+
+	results := make([]patchResult, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		result, err := planFilePatch(file, workdir, parsed.Fuzzy, limits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.target(), err)
+		}
+		if seen[result.Resolved] {
+			return nil, fmt.Errorf("%s: patched more than once", file.target())
+		}
+		seen[result.Resolved] = true
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func planFilePatch(file filePatch, workdir string, fuzzy bool, limits Limits) (patchResult, error) {
+	path, err := extractPathArg(map[string]interface{}{"path": file.target()})
+	if err != nil {
+		return patchResult{}, err
+	}
+	resolved, err := resolvePathWithinBaseAllowMissing(path, workdir)
+	if err != nil {
+		return patchResult{}, err
+	}
+	result := patchResult{Path: path, Resolved: resolved, Mode: 0o644, Hunks: len(file.Hunks), Created: file.creates(), Deleted: file.deletes()}
+
+	content := ""
+	info, err := os.Stat(resolved)
+	switch {
+	case err == nil && info.IsDir():
+		return patchResult{}, fmt.Errorf("path is a directory")
+	case err == nil && file.creates():
+		return patchResult{}, fmt.Errorf("file already exists")
+	case err == nil:
+		if info.Size() > limits.MaxFileSizeBytes {
+			return patchResult{}, fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
+		}
+		data, err := os.ReadFile(resolved)
+		if err != nil {
+			return patchResult{}, fmt.Errorf("failed to read file: %v", err)
+		}
+		if !isTextContent(data) {
+			return patchResult{}, fmt.Errorf("file %w; apply_patch supports text only", ErrBinaryContent)
+		}
+		content = string(data)
+		result.Mode = info.Mode().Perm()
+	case os.IsNotExist(err) && !file.creates():
+		return patchResult{}, fmt.Errorf("file does not exist")
+	case !os.IsNotExist(err):
+		return patchResult{}, fmt.Errorf("failed to stat file: %v", err)
+	}
+
+	updated, err := applyFilePatch(content, file.Hunks, fuzzy)
+	if err != nil {
+		return patchResult{}, err
+	}
+	updated = normalizeLineEndings(updated)
+	if file.deletes() && updated != "" {
+		return patchResult{}, fmt.Errorf("deletion patch does not remove the whole file")
+	}
+	if int64(len(updated)) > limits.MaxFileSizeBytes {
+		return patchResult{}, fmt.Errorf("patched content %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
+	}
+	result.Content = updated
+	return result, nil
+}
+
+func validateApplyPatchArgs(ctx context.Context, args map[string]interface{}) error {
+	_, err := planPatch(ctx, args)
+	return err
+}
+
+func applyPatch(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	results, err := planPatch(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	hunks := 0
+	for i, result := range results {
+		if err := ensureContext(ctx); err != nil {
+			return "", fmt.Errorf("stopped after %d of %d files: %w", i, len(results), err)
+		}
+		if err := writePatchResult(result); err != nil {
+			return "", fmt.Errorf("stopped after %d of %d files: %s: %v", i, len(results), result.Path, err)
+		}
+		hunks += result.Hunks
+		fmt.Fprintf(&b, "\n  %s (%s)", result.Path, describePatchResult(result))
+	}
+	return fmt.Sprintf("Applied %d hunk(s) to %d file(s):%s", hunks, len(results), b.String()), nil
+}
+
+func writePatchResult(result patchResult) error {
+	if result.Deleted {
+		return os.Remove(result.Resolved)
+	}
+	if err := os.MkdirAll(filepath.Dir(result.Resolved), 0o755); err != nil {
+		return fmt.Errorf("failed to create parent directories: %v", err)
+	}
+	return os.WriteFile(result.Resolved, []byte(result.Content), result.Mode)
+}
+
+func describePatchResult(result patchResult) string {
+	switch {
+	case result.Created:
+		return "created"
+	case result.Deleted:
+		return "deleted"
+	case result.Hunks == 1:
+		return "1 hunk"
+	default:
+		return fmt.Sprintf("%d hunks", result.Hunks)
+	}
+}
+
+// summarizeApplyPatch lists the files a patch would change. A patch that does
+// not apply gets no summary; validation reports why.
+func summarizeApplyPatch(ctx context.Context, args map[string]interface{}) string {
+	results, err := planPatch(ctx, args)
+	if err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(results))
+	for _, result := range results {
+		lines = append(lines, fmt.Sprintf("%s (%s)", result.Resolved, describePatchResult(result)))
+	}
+	return formatSummary(fmt.Sprintf("apply_patch will change %d file(s):", len(results)), lines)
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"apply_patch": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	if err := os.WriteFile(filepath.Join(absDir, "list.txt"), []byte(original), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	target := filepath.ToSlash(filepath.Join(relDir, "list.txt"))
+	created := filepath.ToSlash(filepath.Join(relDir, "new.txt"))
+	patch := "diff --git a/" + target + " b/" + target + "\n" +
+		"--- a/" + target + "\n" +
+		"+++ b/" + target + "\n" +
+		"@@ -1,3 +1,3 @@\n" +
+		" one\n" +
+		"-two\n" +
+		"+TWO\n" +
+		" three\n" +
+		"@@ -6,3 +6,4 @@\n" +
+		" six\n" +
+		" seven\n" +
+		"+seven and a half\n" +
+		" eight\n" +
+		"--- /dev/null\n" +
+		"+++ b/" + created + "\n" +
+		"@@ -0,0 +1,2 @@\n" +
+		"+hello\n" +
+		"+world\n"
+
+	result := registry.Execute("apply_patch", map[string]interface{}{"patch": patch})
+	if result.Error != nil {
+		t.Fatalf("expected apply_patch success, got: %v", result.Error)
+	}
+	if !strings.Contains(result.Result, "Applied 3 hunk(s) to 2 file(s)") ||
+		!strings.Contains(result.Result, target+" (2 hunks)") ||
+		!strings.Contains(result.Result, created+" (created)") {
+		t.Fatalf("unexpected summary: %q", result.Result)
+	}
+
+	data, err := os.ReadFile(filepath.Join(absDir, "list.txt"))
+	if err != nil {
+		t.Fatalf("failed to read patched file: %v", err)
+	}
+	want := "one\nTWO\nthree\nfour\nfive\nsix\nseven\nseven and a half\neight\n"
+	if string(data) != want {
+		t.Fatalf("unexpected patched content %q", data)
+	}
+	data, err = os.ReadFile(filepath.Join(absDir, "new.txt"))
+	if err != nil || string(data) != "hello\nworld\n" {
+		t.Fatalf("unexpected created content %q: %v", data, err)
+	}
+}
+
+func TestApplyPatchRejectsContextMismatch(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"apply_patch": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	first := filepath.Join(absDir, "first.txt")
+	second := filepath.Join(absDir, "second.txt")
+	if err := os.WriteFile(first, []byte("alpha\nbeta\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(second, []byte("gamma\n  delta\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	firstRel := filepath.ToSlash(filepath.Join(relDir, "first.txt"))
+	secondRel := filepath.ToSlash(filepath.Join(relDir, "second.txt"))
+	patch := "--- " + firstRel + "\n+++ " + firstRel + "\n" +
+		"@@ -1,2 +1,2 @@\n alpha\n-beta\n+BETA\n" +
+		"--- " + secondRel + "\n+++ " + secondRel + "\n" +
+		"@@ -1,2 +1,2 @@\n gamma\n-delta\n+DELTA\n"
+
+	result := registry.Execute("apply_patch", map[string]interface{}{"patch": patch})
+	if result.Error == nil {
+		t.Fatal("expected apply_patch to reject the mismatched hunk")
+	}
+	if !strings.Contains(result.Error.Error(), secondRel) || !strings.Contains(result.Error.Error(), "hunk 1") {
+		t.Fatalf("expected error naming the file and hunk, got: %v", result.Error)
+	}
+	if data, _ := os.ReadFile(first); string(data) != "alpha\nbeta\n" {
+		t.Fatalf("expected first file untouched, got %q", data)
+	}
+
+	result = registry.Execute("apply_patch", map[string]interface{}{"patch": patch, "fuzzy": true})
+	if result.Error != nil {
+		t.Fatalf("expected fuzzy apply to succeed, got: %v", result.Error)
+	}
+	if data, _ := os.ReadFile(second); string(data) != "gamma\nDELTA\n" {
+		t.Fatalf("unexpected fuzzy result %q", data)
+	}
+
+	result = registry.Execute("apply_patch", map[string]interface{}{
+		"patch": "--- ../outside.txt\n+++ ../outside.txt\n@@ -0,0 +1 @@\n+x\n",
+	})
+	if result.Error == nil {
+		t.Fatal("expected apply_patch to reject paths outside the working directory")
+	}
+
+	renamed := filepath.ToSlash(filepath.Join(relDir, "renamed.txt"))
+	result = registry.Execute("apply_patch", map[string]interface{}{
+		"patch": "--- ../outside.txt\n+++ " + renamed + "\n@@ -1 +1 @@\n-x\n+y\n",
+	})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "renames are not supported") {
+		t.Fatalf("expected apply_patch to reject a rename, got: %v", result.Error)
+	}
+	if _, err := os.Stat(filepath.Join(absDir, "renamed.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected no file written for a rename, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"os"
+)
+
+type baseDirKey struct{}
+
+// withBaseDir scopes path resolution of a tool call to dir instead of the
+// process working directory.
+func withBaseDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, baseDirKey{}, dir)
+}
+
+// toolWorkdir returns the directory a tool call resolves relative paths
+// against: the ExecuteOptions.BaseDir override or the registry working
+// directory when set, otherwise the process working directory.
+func toolWorkdir(ctx context.Context) (string, error) {
+	if ctx != nil {
+		if dir, ok := ctx.Value(baseDirKey{}).(string); ok && dir != "" {
+			return dir, nil
+		}
+	}
+	return os.Getwd()
+}
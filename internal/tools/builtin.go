@@ -43,8 +43,9 @@ func registerBuiltInTools(r *Registry) {
 			"type":       "object",
 			"properties": map[string]interface{}{},
 		},
-		ExecuteFunc:  getCurrentDatetime,
-		VersionValue: builtinToolVersion,
+		ExecuteFunc:   getCurrentDatetime,
+		ReadOnlyValue: true,
+		VersionValue:  builtinToolVersion,
 	})
 
 	register(&ToolDefinition{
@@ -57,12 +58,32 @@ func registerBuiltInTools(r *Registry) {
 					"type":        "string",
 					"description": "Path to the file to read",
 				},
+				"show_diff": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Return a unified diff against the last read of this file instead of the full content",
+				},
+				"offset": map[string]interface{}{
+					"type":        "integer",
+					"description": "Lines (or bytes) to skip before reading; use it with limit to page through files of any size",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum lines (or bytes) to return from offset",
+				},
+				"unit": map[string]interface{}{
+					"type":        "string",
+					"description": "Unit of offset and limit: lines (default) or bytes",
+					"enum":        []interface{}{"lines", "bytes"},
+				},
 			},
 			"required": []string{"path"},
 		},
-		ExecuteFunc:  readFile,
-		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
-		VersionValue: builtinToolVersion,
+		ExecuteFunc:    readFile,
+		ValidateFunc:   validateReadFileArgs,
+		CacheableValue: true,
+		CacheableFunc:  withoutShowDiff,
+		ReadOnlyValue:  true,
+		VersionValue:   builtinToolVersion,
 	})
 
 	register(&ToolDefinition{
@@ -83,6 +104,84 @@ func registerBuiltInTools(r *Registry) {
 		VersionValue:     builtinToolVersion,
 	})
 
+	register(&ToolDefinition{
+		NameValue:           "write_files",
+		DescriptionValue:    "Write several text files in one call; every path is checked before anything is written",
+		ParametersValue:     mustSchemaParametersFor[writeFilesArgs](),
+		ExecuteFunc:         writeFiles,
+		ValidateContextFunc: validateWriteFilesArgs,
+		ConfirmSummaryFunc:  summarizeWriteFiles,
+		VersionValue:        builtinToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:          "replace_in_tree",
+		DescriptionValue:   "Replace a regular expression in every matching text file under a directory; dry_run reports the changes without writing",
+		ParametersValue:    mustSchemaParametersFor[replaceInTreeArgs](),
+		ExecuteFunc:        replaceInTree,
+		ValidateFunc:       validateReplaceInTreeArgs,
+		ConfirmSummaryFunc: summarizeReplaceInTree,
+		VersionValue:       builtinToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:           "apply_patch",
+		DescriptionValue:    "Apply a unified diff to files in the working directory; nothing is written unless every hunk matches",
+		ParametersValue:     mustSchemaParametersFor[applyPatchArgs](),
+		ExecuteFunc:         applyPatch,
+		ValidateContextFunc: validateApplyPatchArgs,
+		ConfirmSummaryFunc:  summarizeApplyPatch,
+		VersionValue:        builtinToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "watch_dir",
+		DescriptionValue: "Watch a directory for a bounded time and report created/modified/deleted files",
+		ParametersValue:  mustSchemaParametersFor[watchDirArgs](),
+		ExecuteFunc:      watchDir,
+		ReadOnlyValue:    true,
+		VersionValue:     builtinToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:           "git_status",
+		DescriptionValue:    "Show the current branch and changed files of the git repository in porcelain format",
+		ParametersValue:     mustSchemaParametersFor[gitStatusArgs](),
+		ExecuteFunc:         gitStatus,
+		ReadOnlyValue:       true,
+		VersionValue:        builtinToolVersion,
+		DefaultTimeoutValue: gitTimeout,
+	})
+
+	register(&ToolDefinition{
+		NameValue:           "git_diff",
+		DescriptionValue:    "Show the git diff of unstaged changes, staged changes or changes since a ref",
+		ParametersValue:     mustSchemaParametersFor[gitDiffArgs](),
+		ExecuteFunc:         gitDiff,
+		ValidateFunc:        validateGitDiffArgs,
+		ReadOnlyValue:       true,
+		VersionValue:        builtinToolVersion,
+		DefaultTimeoutValue: gitTimeout,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "run",
+		DescriptionValue: "Run a program with arguments (no shell) in the working directory and return its exit code with stdout and stderr in separate sections",
+		ParametersValue:  mustSchemaParametersFor[runArgs](),
+		ExecuteFunc:      runTool,
+		ValidateFunc:     validateRunArgs,
+		VersionValue:     builtinToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "time",
+		DescriptionValue: "Run a program like run and report its wall-clock, user and system time along with its output; use it to measure performance",
+		ParametersValue:  mustSchemaParametersFor[runArgs](),
+		ExecuteFunc:      timeTool,
+		ValidateFunc:     validateRunArgs,
+		VersionValue:     builtinToolVersion,
+	})
+
 }
 
 const builtinToolVersion = "1.0.0"
@@ -114,7 +213,7 @@ func validatePath(path string) error {
 	// Check against dangerous paths
 	for _, dangerous := range dangerousPaths {
 		if strings.HasPrefix(absPath, dangerous) {
-			return fmt.Errorf("access to %s is restricted for security", dangerous)
+			return fmt.Errorf("access to %s is %w", dangerous, ErrPathRestricted)
 		}
 	}
 
@@ -139,41 +238,78 @@ func readFile(ctx context.Context, args map[string]interface{}) (string, error)
 	if err != nil {
 		return "", err
 	}
+	offset, limit, unit, paged, err := readPageArgs(args)
+	if err != nil {
+		return "", err
+	}
+	if paged {
+		return readFilePage(ctx, path, offset, limit, unit)
+	}
+	resolved, content, err := readTextFileResolved(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if getBoolArg(args, "show_diff") {
+		return readFileDiff(path, resolved, content), nil
+	}
+	lastReads.swap(resolved, content)
+	return content, nil
+}
+
+// withoutShowDiff reports whether a read_file or cat call may be served from
+// the result cache: a show_diff answer depends on the previous read.
+func withoutShowDiff(args map[string]interface{}) bool {
+	return !getBoolArg(args, "show_diff")
+}
+
+// ReadTextFile reads a text file with the same path, size and content checks
+// as the read_file tool.
+func ReadTextFile(path string) (string, error) {
+	return readTextFile(context.Background(), path)
+}
 
-	workdir, err := os.Getwd()
+func readTextFile(ctx context.Context, path string) (string, error) {
+	_, content, err := readTextFileResolved(ctx, path)
+	return content, err
+}
+
+// readTextFileResolved is readTextFile that also returns the resolved path.
+func readTextFileResolved(ctx context.Context, path string) (string, string, error) {
+	workdir, err := toolWorkdir(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to determine working directory: %v", err)
+		return "", "", fmt.Errorf("failed to determine working directory: %v", err)
 	}
 
 	resolved, err := resolvePathWithinBase(path, workdir)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	limits := getLimits()
 	info, err := os.Stat(resolved)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %v", err)
+		return "", "", fmt.Errorf("failed to read file: %v", err)
 	}
 	if info.Size() > limits.MaxFileSizeBytes {
-		return "", fmt.Errorf("file exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+		return "", "", fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 	}
 
 	if err := ensureContext(ctx); err != nil {
-		return "", err
+		return "", "", err
 	}
 
 	// Use os.ReadFile instead of exec for better security
 	content, err := os.ReadFile(resolved)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file: %v", err)
+		return "", "", fmt.Errorf("failed to read file: %v", err)
 	}
 
-	if !isTextContent(content) {
-		return "", fmt.Errorf("file appears to be binary; read_file supports text only")
+	content, err = textContent(content)
+	if err != nil {
+		return "", "", fmt.Errorf("file %w; read_file supports text only", err)
 	}
 
-	return string(content), nil
+	return resolved, string(content), nil
 }
 
 func getPathArg(args map[string]interface{}) string {
@@ -189,28 +325,28 @@ func getBoolArg(args map[string]interface{}, key string) bool {
 	return ok && val
 }
 
-func validatePathWithinWorkdir(path string) (string, error) {
+func validatePathWithinWorkdir(ctx context.Context, path string) (string, error) {
 	if err := paths.ValidatePathString(path, maxPathLength); err != nil {
 		return "", err
 	}
 
-	cleanPath := filepath.Clean(path)
-	absPath, err := filepath.Abs(cleanPath)
+	baseAbs, err := toolWorkdir(ctx)
 	if err != nil {
-		return "", fmt.Errorf("invalid path: %v", err)
+		return "", fmt.Errorf("failed to determine working directory: %v", err)
+	}
+
+	absPath := filepath.Clean(path)
+	if !filepath.IsAbs(absPath) {
+		absPath = filepath.Join(baseAbs, absPath)
 	}
 
 	// Prevent access to masked/dangerous paths even if under workdir.
 	for _, dangerous := range dangerousPaths {
 		if strings.HasPrefix(absPath, dangerous) {
-			return "", fmt.Errorf("access to %s is restricted for security", dangerous)
+			return "", fmt.Errorf("access to %s is %w", dangerous, ErrPathRestricted)
 		}
 	}
 
-	baseAbs, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to determine working directory: %v", err)
-	}
 	baseResolved, err := filepath.EvalSymlinks(baseAbs)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve base directory: %v", err)
@@ -234,7 +370,7 @@ func resolvePathWithinBase(path, baseDir string) (string, error) {
 
 	for _, dangerous := range dangerousPaths {
 		if strings.HasPrefix(resolved, dangerous) {
-			return "", fmt.Errorf("access to %s is restricted for security", dangerous)
+			return "", fmt.Errorf("access to %s is %w", dangerous, ErrPathRestricted)
 		}
 	}
 
@@ -273,12 +409,12 @@ func resolvePathWithinBaseAllowMissing(path, baseDir string) (string, error) {
 	cleanRel := filepath.Clean(path)
 	candidate := filepath.Clean(filepath.Join(baseResolved, cleanRel))
 	if !paths.HasPathPrefix(candidate, baseResolved) {
-		return "", fmt.Errorf("path escapes working directory")
+		return "", ErrPathEscapesWorkdir
 	}
 
 	for _, dangerous := range dangerousPaths {
 		if strings.HasPrefix(candidate, dangerous) {
-			return "", fmt.Errorf("access to %s is restricted for security", dangerous)
+			return "", fmt.Errorf("access to %s is %w", dangerous, ErrPathRestricted)
 		}
 	}
 
@@ -302,7 +438,7 @@ func resolveExistingAncestor(candidate, baseResolved string) (string, error) {
 				return "", fmt.Errorf("failed to resolve path: %v", err)
 			}
 			if !paths.HasPathPrefix(resolvedProbe, baseResolved) {
-				return "", fmt.Errorf("path escapes working directory")
+				return "", ErrPathEscapesWorkdir
 			}
 			remainder, err := filepath.Rel(probe, candidate)
 			if err != nil {
@@ -313,7 +449,7 @@ func resolveExistingAncestor(candidate, baseResolved string) (string, error) {
 			}
 			resolved := filepath.Join(resolvedProbe, remainder)
 			if !paths.HasPathPrefix(resolved, baseResolved) {
-				return "", fmt.Errorf("path escapes working directory")
+				return "", ErrPathEscapesWorkdir
 			}
 			return resolved, nil
 		} else if !os.IsNotExist(err) {
@@ -347,7 +483,7 @@ func validatePathWhitelist(absPath, baseResolved string) error {
 		}
 	}
 
-	return fmt.Errorf("path is outside allowed tool base directories")
+	return fmt.Errorf("path is outside allowed tool base directories: %w", ErrPathRestricted)
 }
 
 func isTextContent(data []byte) bool {
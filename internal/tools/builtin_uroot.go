@@ -22,9 +22,11 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/exec"
 	"os/user"
@@ -35,6 +37,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"promptline/internal/paths"
 
@@ -66,6 +69,8 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "List directory contents",
 		ParametersValue: mustSchemaParametersFor[lsArgs](),
 		ExecuteFunc:  executeLs,
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -73,8 +78,11 @@ func registerURootTools(r *Registry) {
 		NameValue:        "cat",
 		DescriptionValue: "Concatenate and print files",
 		ParametersValue: mustSchemaParametersFor[catArgs](),
-		ExecuteFunc:  wrapURootCommand(buildCatArgs, runCat),
-		ValidateFunc: validatePathsArg("paths", "path"),
+		ExecuteFunc:  catTool,
+		ValidateFunc: validateCatArgs,
+		CacheableValue: true,
+		CacheableFunc: withoutShowDiff,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -93,6 +101,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[moveArgs](),
 		ExecuteFunc:  wrapURootCommand(buildMoveArgs, runMove),
 		ValidateFunc: validateRequiredStrings([]string{"destination"}, []string{"sources"}),
+		ConfirmSummaryFunc: summarizeMove,
 		VersionValue: urootToolVersion,
 	})
 
@@ -102,6 +111,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[removeArgs](),
 		ExecuteFunc:  wrapURootCommand(buildRemoveArgs, runRemove),
 		ValidateFunc: validatePathsArg("paths", "path"),
+		ConfirmSummaryFunc: summarizeRemove,
 		VersionValue: urootToolVersion,
 	})
 
@@ -119,7 +129,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Create files or update timestamps",
 		ParametersValue: mustSchemaParametersFor[touchArgs](),
 		ExecuteFunc:  wrapURootCommand(buildTouchArgs, runTouch),
-		ValidateFunc: validatePathsArg("paths", "path"),
+		ValidateContextFunc: validateTouchArgs,
 		VersionValue: urootToolVersion,
 	})
 
@@ -129,6 +139,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[truncateArgs](),
 		ExecuteFunc:  truncateFile,
 		ValidateFunc: validateTruncateArgs,
+		ConfirmSummaryFunc: summarizeTruncate,
 		VersionValue: urootToolVersion,
 	})
 
@@ -138,6 +149,8 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[readlinkArgs](),
 		ExecuteFunc:  readLinkPath,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -147,6 +160,8 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[realpathArgs](),
 		ExecuteFunc:  realpathPath,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -156,6 +171,8 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[grepArgs](),
 		ExecuteFunc:  grepText,
 		ValidateFunc: validateGrepArgs,
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -165,6 +182,8 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[headArgs](),
 		ExecuteFunc:  headText,
 		ValidateFunc: validatePathsArg("paths", "path"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -174,6 +193,8 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[tailArgs](),
 		ExecuteFunc:  tailText,
 		ValidateFunc: validatePathsArg("paths", "path"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -183,6 +204,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[sortArgs](),
 		ExecuteFunc:  sortText,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -192,15 +214,28 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[uniqArgs](),
 		ExecuteFunc:  uniqText,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
+	register(&ToolDefinition{
+		NameValue:        "merge",
+		DescriptionValue: "Merge already sorted files into one sorted output",
+		ParametersValue:  mustSchemaParametersFor[mergeArgs](),
+		ExecuteFunc:      mergeFiles,
+		ValidateFunc:     validatePathsArg("paths", ""),
+		ReadOnlyValue:    true,
+		VersionValue:     urootToolVersion,
+	})
+
 	register(&ToolDefinition{
 		NameValue:        "wc",
-		DescriptionValue: "Word, line, and byte count",
+		DescriptionValue: "Word, line, and byte count of files or of the text files in a directory",
 		ParametersValue: mustSchemaParametersFor[wcArgs](),
 		ExecuteFunc:  wordCount,
 		ValidateFunc: validatePathsArg("paths", "path"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -210,6 +245,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[translateArgs](),
 		ExecuteFunc:  translateText,
 		ValidateFunc: validateTranslateArgs,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -228,6 +264,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[commArgs](),
 		ExecuteFunc:  compareFiles,
 		ValidateFunc: validateCommArgs,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -237,6 +274,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[stringsArgs](),
 		ExecuteFunc:  stringsText,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -246,15 +284,40 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[moreArgs](),
 		ExecuteFunc:  moreText,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		ReadOnlyValue: true,
+		VersionValue: urootToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "view_code",
+		DescriptionValue: "Show a text file or line range with line numbers and a language label",
+		ParametersValue: mustSchemaParametersFor[viewCodeArgs](),
+		ExecuteFunc:  viewCodeTool,
+		ValidateFunc: validateViewCodeArgs,
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
+	register(&ToolDefinition{
+		NameValue:        "table",
+		DescriptionValue: "Render CSV data from a file or inline content as an aligned text table",
+		ParametersValue:  mustSchemaParametersFor[tableArgs](),
+		ExecuteFunc:      tableTool,
+		ValidateFunc:     validateTableArgs,
+		CacheableValue:   true,
+		ReadOnlyValue:    true,
+		VersionValue:     urootToolVersion,
+	})
+
 	register(&ToolDefinition{
 		NameValue:        "hexdump",
 		DescriptionValue: "Display file contents in hexadecimal",
 		ParametersValue: mustSchemaParametersFor[hexdumpArgs](),
 		ExecuteFunc:  hexDump,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -264,6 +327,8 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[cmpArgs](),
 		ExecuteFunc:  compareBytes,
 		ValidateFunc: validateCommArgs,
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -273,6 +338,8 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[md5sumArgs](),
 		ExecuteFunc:  md5Sum,
 		ValidateFunc: validatePathsArg("paths", "path"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -282,18 +349,52 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[shasumArgs](),
 		ExecuteFunc:  shaSum,
 		ValidateFunc: validatePathsArg("paths", "path"),
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
+	register(&ToolDefinition{
+		NameValue:        "hash_tree",
+		DescriptionValue: "List the SHA checksum of every file under a directory as a sorted manifest",
+		ParametersValue:  mustSchemaParametersFor[hashTreeArgs](),
+		ExecuteFunc:      hashTree,
+		ReadOnlyValue:    true,
+		VersionValue:     urootToolVersion,
+	})
+
 	register(&ToolDefinition{
 		NameValue:        "base64",
 		DescriptionValue: "Base64 encode or decode files",
 		ParametersValue: mustSchemaParametersFor[base64Args](),
 		ExecuteFunc:  base64Tool,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
+	register(&ToolDefinition{
+		NameValue:        "decode",
+		DescriptionValue: "Decode url-encoded text, html entities or a JWT (unverified), or pretty-print JSON",
+		ParametersValue:  mustSchemaParametersFor[decodeArgs](),
+		ExecuteFunc:      decodeTool,
+		ValidateFunc:     validateDecodeArgs,
+		CacheableValue:   true,
+		ReadOnlyValue:    true,
+		VersionValue:     urootToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "validate",
+		DescriptionValue: "Check that a JSON or YAML file parses and report the line and column of the first error",
+		ParametersValue:  mustSchemaParametersFor[validateFileArgs](),
+		ExecuteFunc:      validateFileTool,
+		ValidateFunc:     validateValidateFileArgs,
+		CacheableValue:   true,
+		ReadOnlyValue:    true,
+		VersionValue:     urootToolVersion,
+	})
+
 	register(&ToolDefinition{
 		NameValue:        "mkdir",
 		DescriptionValue: "Create directories",
@@ -308,6 +409,15 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Print the working directory",
 		ParametersValue: mustSchemaParametersFor[noArgs](),
 		ExecuteFunc:  printWorkingDirectory,
+		ReadOnlyValue: true,
+		VersionValue: urootToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "cd",
+		DescriptionValue: "Change the working directory used by subsequent tools (cannot leave the startup directory)",
+		ParametersValue: mustSchemaParametersFor[cdArgs](),
+		ExecuteFunc:  changeDirectoryTool,
 		VersionValue: urootToolVersion,
 	})
 
@@ -317,6 +427,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[pathArg](),
 		ExecuteFunc:  dirNamePath,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -326,6 +437,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[pathArg](),
 		ExecuteFunc:  baseNamePath,
 		ValidateFunc: RequireNonEmptyArg("path", "missing or invalid 'path' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -334,6 +446,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Print system information",
 		ParametersValue: mustSchemaParametersFor[noArgs](),
 		ExecuteFunc:  unameTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -342,6 +455,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Print system hostname",
 		ParametersValue: mustSchemaParametersFor[noArgs](),
 		ExecuteFunc:  hostnameTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -350,6 +464,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Show how long the system has been running",
 		ParametersValue: mustSchemaParametersFor[noArgs](),
 		ExecuteFunc:  uptimeTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -358,6 +473,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Display memory usage",
 		ParametersValue: mustSchemaParametersFor[noArgs](),
 		ExecuteFunc:  freeTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -366,6 +482,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Report filesystem disk space usage",
 		ParametersValue: mustSchemaParametersFor[dfArgs](),
 		ExecuteFunc:  dfTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -374,6 +491,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Estimate file space usage",
 		ParametersValue: mustSchemaParametersFor[duArgs](),
 		ExecuteFunc:  duTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -382,6 +500,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Report process status",
 		ParametersValue: mustSchemaParametersFor[psArgs](),
 		ExecuteFunc:  psTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -391,6 +510,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[pidofArgs](),
 		ExecuteFunc:  pidofTool,
 		ValidateFunc: RequireNonEmptyArg("name", "missing or invalid 'name' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -399,6 +519,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Print user identity",
 		ParametersValue: mustSchemaParametersFor[idArgs](),
 		ExecuteFunc:  idTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -407,6 +528,16 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Display text",
 		ParametersValue: mustSchemaParametersFor[echoArgs](),
 		ExecuteFunc:  echoTool,
+		ReadOnlyValue: true,
+		VersionValue: urootToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "printf",
+		DescriptionValue: "Format values with a printf-style format string",
+		ParametersValue: mustSchemaParametersFor[printfArgs](),
+		ExecuteFunc:  printfTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -416,6 +547,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[seqArgs](),
 		ExecuteFunc:  seqTool,
 		ValidateFunc: validateSeqArgs,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -432,6 +564,7 @@ func registerURootTools(r *Registry) {
 		DescriptionValue: "Print terminal name",
 		ParametersValue: mustSchemaParametersFor[noArgs](),
 		ExecuteFunc:  ttyTool,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -441,6 +574,7 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[whichArgs](),
 		ExecuteFunc:  whichTool,
 		ValidateFunc: RequireNonEmptyArg("name", "missing or invalid 'name' parameter"),
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -461,11 +595,43 @@ func registerURootTools(r *Registry) {
 		VersionValue: urootToolVersion,
 	})
 
+	register(&ToolDefinition{
+		NameValue:        "clean_temp",
+		DescriptionValue: "Delete everything in the .tmp directory used by mktemp",
+		ParametersValue:  mustSchemaParametersFor[noArgs](),
+		ExecuteFunc:      cleanTempTool,
+		VersionValue:     urootToolVersion,
+	})
+
 	register(&ToolDefinition{
 		NameValue:        "find",
 		DescriptionValue: "Search for files",
 		ParametersValue: mustSchemaParametersFor[findArgs](),
 		ExecuteFunc:  findTool,
+		ValidateFunc: validateFindArgs,
+		CacheableValue: true,
+		ReadOnlyValue: true,
+		VersionValue: urootToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:          "find_delete",
+		DescriptionValue:   "Find regular files by name, age or size and delete them, listing what was removed",
+		ParametersValue:    mustSchemaParametersFor[findDeleteArgs](),
+		ExecuteFunc:        findDelete,
+		ValidateFunc:       validateFindDeleteArgs,
+		ConfirmSummaryFunc: summarizeFindDelete,
+		VersionValue:       urootToolVersion,
+	})
+
+	register(&ToolDefinition{
+		NameValue:        "search",
+		DescriptionValue: "Find files matching a name glob that contain a pattern, with the matching lines",
+		ParametersValue: mustSchemaParametersFor[searchArgs](),
+		ExecuteFunc:  searchTool,
+		ValidateFunc: validateSearchArgs,
+		CacheableValue: true,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 
@@ -475,19 +641,22 @@ func registerURootTools(r *Registry) {
 		ParametersValue: mustSchemaParametersFor[chmodArgs](),
 		ExecuteFunc:  chmodTool,
 		ValidateFunc: validateChmodArgs,
+		ConfirmSummaryFunc: summarizeChmod,
 		VersionValue: urootToolVersion,
 	})
 
 	register(&ToolDefinition{
 		NameValue:        "date",
-		DescriptionValue: "Display date and time",
+		DescriptionValue: "Display the current date and time, or parse a timestamp, shift it by a duration and convert it to a timezone",
 		ParametersValue: mustSchemaParametersFor[dateArgs](),
 		ExecuteFunc:  dateTool,
+		ValidateFunc: validateDateArgs,
+		ReadOnlyValue: true,
 		VersionValue: urootToolVersion,
 	})
 }
 
-func wrapURootCommand(buildArgs func(map[string]interface{}) ([]string, error), run urootCommand) ExecutorFunc {
+func wrapURootCommand(buildArgs func(context.Context, map[string]interface{}) ([]string, error), run urootCommand) ExecutorFunc {
 	return func(ctx context.Context, args map[string]interface{}) (string, error) {
 		if err := ensureContext(ctx); err != nil {
 			return "", err
@@ -498,7 +667,7 @@ func wrapURootCommand(buildArgs func(map[string]interface{}) ([]string, error),
 		if run == nil {
 			return "", fmt.Errorf("missing u-root command runner")
 		}
-		cmdArgs, err := buildArgs(args)
+		cmdArgs, err := buildArgs(ctx, args)
 		if err != nil {
 			return "", err
 		}
@@ -509,12 +678,12 @@ func wrapURootCommand(buildArgs func(map[string]interface{}) ([]string, error),
 	}
 }
 
-func resolveToolPath(path string) (string, error) {
+func resolveToolPath(ctx context.Context, path string) (string, error) {
 	if err := paths.ValidatePathString(path, maxPathLength); err != nil {
 		return "", err
 	}
 
-	baseResolved, err := resolveBaseDir()
+	baseResolved, err := resolveBaseDir(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -530,12 +699,12 @@ func resolveToolPath(path string) (string, error) {
 	return resolvePathWithinBase(path, baseResolved)
 }
 
-func resolveToolPathNoSymlink(path string) (string, error) {
+func resolveToolPathNoSymlink(ctx context.Context, path string) (string, error) {
 	if err := paths.ValidatePathString(path, maxPathLength); err != nil {
 		return "", err
 	}
 
-	baseResolved, err := resolveBaseDir()
+	baseResolved, err := resolveBaseDir(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -553,13 +722,13 @@ func resolveToolPathNoSymlink(path string) (string, error) {
 		return "", err
 	}
 	if !paths.HasPathPrefix(parentResolved, baseResolved) {
-		return "", fmt.Errorf("path escapes working directory")
+		return "", ErrPathEscapesWorkdir
 	}
 	resolved := filepath.Join(parentResolved, filepath.Base(abs))
 
 	for _, dangerous := range dangerousPaths {
 		if strings.HasPrefix(resolved, dangerous) {
-			return "", fmt.Errorf("access to %s is restricted for security", dangerous)
+			return "", fmt.Errorf("access to %s is %w", dangerous, ErrPathRestricted)
 		}
 	}
 	if err := validatePathWhitelist(resolved, baseResolved); err != nil {
@@ -575,7 +744,7 @@ func resolveAbsolutePath(path, baseResolved string) (string, error) {
 		return "", err
 	}
 	if !paths.HasPathPrefix(resolved, baseResolved) {
-		return "", fmt.Errorf("path escapes working directory")
+		return "", ErrPathEscapesWorkdir
 	}
 	return resolved, nil
 }
@@ -587,7 +756,7 @@ func ensureResolvedPathWithinBase(path, baseResolved string) (string, error) {
 	}
 	for _, dangerous := range dangerousPaths {
 		if strings.HasPrefix(resolved, dangerous) {
-			return "", fmt.Errorf("access to %s is restricted for security", dangerous)
+			return "", fmt.Errorf("access to %s is %w", dangerous, ErrPathRestricted)
 		}
 	}
 	if err := validatePathWhitelist(resolved, baseResolved); err != nil {
@@ -596,8 +765,8 @@ func ensureResolvedPathWithinBase(path, baseResolved string) (string, error) {
 	return resolved, nil
 }
 
-func resolveBaseDir() (string, error) {
-	workdir, err := os.Getwd()
+func resolveBaseDir(ctx context.Context) (string, error) {
+	workdir, err := toolWorkdir(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to determine working directory: %v", err)
 	}
@@ -617,7 +786,7 @@ func runCoreCommand(ctx context.Context, cmd core.Command, args []string) (strin
 	var stderr bytes.Buffer
 	cmd.SetIO(strings.NewReader(""), &stdout, &stderr)
 
-	workdir, err := os.Getwd()
+	workdir, err := toolWorkdir(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to determine working directory: %v", err)
 	}
@@ -641,8 +810,15 @@ func executeLs(ctx context.Context, args map[string]interface{}) (string, error)
 	path := getPathArg(args)
 	recursive := getBoolArg(args, "recursive")
 	showHidden := getBoolArg(args, "show_hidden")
+	format, _ := getStringLike(args["format"])
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case "", "short", "long", "json":
+	default:
+		return "", fmt.Errorf("unsupported format '%s' (use short, long or json)", format)
+	}
 
-	resolved, err := resolveListPath(path)
+	resolved, err := resolveListPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -653,6 +829,14 @@ func executeLs(ctx context.Context, args map[string]interface{}) (string, error)
 	if !info.IsDir() {
 		return "", fmt.Errorf("path '%s' is not a directory", resolved)
 	}
+	ignoreRules, err := ignoreRulesFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	ignore, err := ignoreRules.matcher(resolved)
+	if err != nil {
+		return "", err
+	}
 
 	limits := getLimits()
 	var limiter strings.Builder
@@ -666,6 +850,10 @@ func executeLs(ctx context.Context, args map[string]interface{}) (string, error)
 		}
 	}
 
+	if format == "json" {
+		return listDirectoryJSON(ctx, resolved, recursive, showHidden, ignore)
+	}
+
 	var cmdArgs []string
 	if showHidden {
 		cmdArgs = append(cmdArgs, "-a")
@@ -673,6 +861,9 @@ func executeLs(ctx context.Context, args map[string]interface{}) (string, error)
 	if recursive {
 		cmdArgs = append(cmdArgs, "-R")
 	}
+	if format == "long" {
+		cmdArgs = append(cmdArgs, "-l")
+	}
 	if path != "" {
 		cmdArgs = append(cmdArgs, path)
 	}
@@ -681,7 +872,17 @@ func executeLs(ctx context.Context, args map[string]interface{}) (string, error)
 		return "", err
 	}
 	if !showHidden {
-		output = filterHiddenOutput(output)
+		if format == "long" {
+			output = filterHiddenLongOutput(output)
+		} else {
+			output = filterHiddenOutput(output)
+		}
+	}
+	if ignore != nil {
+		output, err = filterIgnoredOutput(ctx, output, resolved, recursive, format == "long", ignore)
+		if err != nil {
+			return "", err
+		}
 	}
 	if strings.TrimSpace(output) == "" {
 		return "Directory is empty", nil
@@ -689,12 +890,173 @@ func executeLs(ctx context.Context, args map[string]interface{}) (string, error)
 	return output, nil
 }
 
-func buildCatArgs(args map[string]interface{}) ([]string, error) {
+// lsEntry is one element of the ls json format.
+type lsEntry struct {
+	Name  string `json:"name"`
+	Size  int64  `json:"size"`
+	Mode  string `json:"mode"`
+	MTime string `json:"mtime"`
+	IsDir bool   `json:"is_dir"`
+}
+
+// listDirectoryJSON lists root as a JSON array. Names are relative to root so
+// recursive listings stay unambiguous. Limits are checked by the caller.
+func listDirectoryJSON(ctx context.Context, root string, recursive, showHidden bool, ignore *ignoreMatcher) (string, error) {
+	entries := []lsEntry{}
+	err := filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if ctxErr := ensureContext(ctx); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			return err
+		}
+		if filePath == root {
+			return nil
+		}
+		if shouldSkipHidden(filePath, info, root, showHidden) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		if ignore.match(filepath.ToSlash(rel), info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		entries = append(entries, lsEntry{
+			Name:  filepath.ToSlash(rel),
+			Size:  info.Size(),
+			Mode:  info.Mode().String(),
+			MTime: info.ModTime().UTC().Format(time.RFC3339),
+			IsDir: info.IsDir(),
+		})
+		if info.IsDir() && !recursive {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func buildCatArgs(ctx context.Context, args map[string]interface{}) ([]string, error) {
 	paths, err := extractPaths(args, "paths", "path")
 	if err != nil {
 		return nil, err
 	}
-	return resolveToolPaths(paths)
+	return resolveToolPaths(ctx, paths)
+}
+
+// catTool runs the core cat command, or reads the files as text when line
+// numbers or a line range are requested. With show_diff it returns the change
+// since the last read of a single file, like read_file.
+func catTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if getBoolArg(args, "show_diff") {
+		if err := validateCatArgs(args); err != nil {
+			return "", err
+		}
+		paths, err := extractPaths(args, "paths", "path")
+		if err != nil {
+			return "", err
+		}
+		resolved, content, err := readTextFileResolved(ctx, paths[0])
+		if err != nil {
+			return "", err
+		}
+		return readFileDiff(paths[0], resolved, content), nil
+	}
+	if !catWantsLines(args) {
+		return wrapURootCommand(buildCatArgs, runCat)(ctx, args)
+	}
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	if err := validateCatArgs(args); err != nil {
+		return "", err
+	}
+	paths, err := buildCatArgs(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	number := getBoolArg(args, "number")
+
+	var b strings.Builder
+	for _, path := range paths {
+		if err := ensureContext(ctx); err != nil {
+			return "", err
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		if info.IsDir() {
+			return "", fmt.Errorf("path '%s' is a directory", path)
+		}
+		lines, err := readTextLines(path)
+		if err != nil {
+			return "", err
+		}
+		if len(lines) > 0 && lines[len(lines)-1] == "" {
+			lines = lines[:len(lines)-1]
+		}
+		start, end, err := viewCodeRange(args, len(lines))
+		if err != nil {
+			return "", err
+		}
+		for i := start; i >= 1 && i <= end; i++ {
+			if number {
+				fmt.Fprintf(&b, "%6d\t", i)
+			}
+			b.WriteString(lines[i-1])
+			b.WriteByte('\n')
+		}
+	}
+	return b.String(), nil
+}
+
+// catWantsLines reports whether any of the line options are set.
+func catWantsLines(args map[string]interface{}) bool {
+	_, hasStart := args["start"]
+	_, hasEnd := args["end"]
+	return getBoolArg(args, "number") || hasStart || hasEnd
+}
+
+func validateCatArgs(args map[string]interface{}) error {
+	paths, err := extractPaths(args, "paths", "path")
+	if err != nil {
+		return err
+	}
+	_, hasStart := args["start"]
+	_, hasEnd := args["end"]
+	if getBoolArg(args, "show_diff") {
+		if len(paths) > 1 {
+			return fmt.Errorf("show_diff needs a single path")
+		}
+		if hasStart || hasEnd || getBoolArg(args, "number") {
+			return fmt.Errorf("show_diff cannot be combined with number, start or end")
+		}
+		return nil
+	}
+	if !hasStart && !hasEnd {
+		return nil
+	}
+	if len(paths) > 1 {
+		return fmt.Errorf("start and end need a single path")
+	}
+	_, _, err = viewCodeRange(args, int(^uint(0)>>1))
+	return err
 }
 
 func runCat(ctx context.Context, args []string) (string, error) {
@@ -708,13 +1070,13 @@ func runCat(ctx context.Context, args []string) (string, error) {
 			return "", fmt.Errorf("path '%s' is a directory", path)
 		}
 		if info.Size() > limits.MaxFileSizeBytes {
-			return "", fmt.Errorf("file exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+			return "", fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 		}
 	}
 	return runCoreCommand(ctx, corecat.New(), args)
 }
 
-func buildCopyArgs(args map[string]interface{}) ([]string, error) {
+func buildCopyArgs(ctx context.Context, args map[string]interface{}) ([]string, error) {
 	srcs, err := extractStringSliceArg(args, "sources")
 	if err != nil {
 		return nil, err
@@ -724,11 +1086,11 @@ func buildCopyArgs(args map[string]interface{}) ([]string, error) {
 		return nil, err
 	}
 
-	resolvedSources, err := resolveToolPaths(srcs)
+	resolvedSources, err := resolveToolPaths(ctx, srcs)
 	if err != nil {
 		return nil, err
 	}
-	resolvedDest, err := resolveToolPath(dest)
+	resolvedDest, err := resolveToolPath(ctx, dest)
 	if err != nil {
 		return nil, err
 	}
@@ -743,7 +1105,7 @@ func buildCopyArgs(args map[string]interface{}) ([]string, error) {
 			return nil, fmt.Errorf("source '%s' is a directory (set recursive to true)", source)
 		}
 		if !info.IsDir() && info.Size() > limits.MaxFileSizeBytes {
-			return nil, fmt.Errorf("file exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+			return nil, fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 		}
 	}
 
@@ -766,7 +1128,7 @@ func runCopy(ctx context.Context, args []string) (string, error) {
 	return runCoreCommand(ctx, corecp.New(), args)
 }
 
-func buildMoveArgs(args map[string]interface{}) ([]string, error) {
+func buildMoveArgs(ctx context.Context, args map[string]interface{}) ([]string, error) {
 	srcs, err := extractStringSliceArg(args, "sources")
 	if err != nil {
 		return nil, err
@@ -776,11 +1138,11 @@ func buildMoveArgs(args map[string]interface{}) ([]string, error) {
 		return nil, err
 	}
 
-	resolvedSources, err := resolveToolPaths(srcs)
+	resolvedSources, err := resolveToolPaths(ctx, srcs)
 	if err != nil {
 		return nil, err
 	}
-	resolvedDest, err := resolveToolPath(dest)
+	resolvedDest, err := resolveToolPath(ctx, dest)
 	if err != nil {
 		return nil, err
 	}
@@ -801,12 +1163,12 @@ func runMove(ctx context.Context, args []string) (string, error) {
 	return runCoreCommand(ctx, coremv.New(), args)
 }
 
-func buildRemoveArgs(args map[string]interface{}) ([]string, error) {
+func buildRemoveArgs(ctx context.Context, args map[string]interface{}) ([]string, error) {
 	pathsArg, err := extractPaths(args, "paths", "path")
 	if err != nil {
 		return nil, err
 	}
-	resolved, err := resolveToolPaths(pathsArg)
+	resolved, err := resolveToolPaths(ctx, pathsArg)
 	if err != nil {
 		return nil, err
 	}
@@ -826,12 +1188,12 @@ func runRemove(ctx context.Context, args []string) (string, error) {
 	return runCoreCommand(ctx, corerm.New(), args)
 }
 
-func buildTouchArgs(args map[string]interface{}) ([]string, error) {
+func buildTouchArgs(ctx context.Context, args map[string]interface{}) ([]string, error) {
 	pathsArg, err := extractPaths(args, "paths", "path")
 	if err != nil {
 		return nil, err
 	}
-	resolved, err := resolveToolPaths(pathsArg)
+	resolved, err := resolveToolPaths(ctx, pathsArg)
 	if err != nil {
 		return nil, err
 	}
@@ -846,23 +1208,45 @@ func buildTouchArgs(args map[string]interface{}) ([]string, error) {
 	if getBoolArg(args, "no_create") {
 		cmdArgs = append(cmdArgs, "-c")
 	}
-	if datetime, ok := getStringLike(args["datetime"]); ok {
+	if reference, ok := getStringLike(args["reference"]); ok {
+		modTime, err := touchReferenceTime(ctx, reference)
+		if err != nil {
+			return nil, err
+		}
+		cmdArgs = append(cmdArgs, "-d", modTime.Format(time.RFC3339Nano))
+	} else if datetime, ok := getStringLike(args["datetime"]); ok {
 		cmdArgs = append(cmdArgs, "-d", datetime)
 	}
 	cmdArgs = append(cmdArgs, resolved...)
 	return cmdArgs, nil
 }
 
+// touchReferenceTime returns the modification time of the reference file.
+func touchReferenceTime(ctx context.Context, reference string) (time.Time, error) {
+	resolved, err := resolveToolPath(ctx, reference)
+	if err != nil {
+		return time.Time{}, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, fmt.Errorf("reference file %s does not exist", reference)
+		}
+		return time.Time{}, fmt.Errorf("cannot stat reference file %s: %v", reference, err)
+	}
+	return info.ModTime(), nil
+}
+
 func runTouch(ctx context.Context, args []string) (string, error) {
 	return runCoreCommand(ctx, coretouch.New(), args)
 }
 
-func buildMkdirArgs(args map[string]interface{}) ([]string, error) {
+func buildMkdirArgs(ctx context.Context, args map[string]interface{}) ([]string, error) {
 	pathsArg, err := extractPaths(args, "paths", "path")
 	if err != nil {
 		return nil, err
 	}
-	resolved, err := resolveToolPaths(pathsArg)
+	resolved, err := resolveToolPaths(ctx, pathsArg)
 	if err != nil {
 		return nil, err
 	}
@@ -900,7 +1284,11 @@ func grepText(ctx context.Context, args map[string]interface{}) (string, error)
 
 	recursive := getBoolArg(args, "recursive")
 	showHidden := getBoolArg(args, "show_hidden")
-	files, err := collectGrepFiles(ctx, paths, recursive, showHidden)
+	ignore, err := ignoreRulesFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	files, err := collectGrepFiles(ctx, paths, recursive, showHidden, ignore)
 	if err != nil {
 		return "", err
 	}
@@ -934,11 +1322,12 @@ func grepText(ctx context.Context, args map[string]interface{}) (string, error)
 		if err != nil {
 			return "", err
 		}
-		if !isTextContent(data) {
+		data, err = textContent(data)
+		if err != nil {
 			if file.FromDir {
 				continue
 			}
-			return "", fmt.Errorf("file appears to be binary; tool supports text only")
+			return "", fmt.Errorf("file %w; tool supports text only", err)
 		}
 		text := strings.ReplaceAll(string(data), "\r\n", "\n")
 		lines := strings.Split(text, "\n")
@@ -983,6 +1372,21 @@ type walkOptions struct {
 	pattern     string
 	typeFilter  string
 	regularOnly bool
+	// Zero times and sizes disable the matching filter; see findFilters.
+	newerThan  time.Time
+	olderThan  time.Time
+	minSize    int64
+	maxSize    int64
+	hasMaxSize bool
+	ignore     *ignoreMatcher // nil ignores nothing
+}
+
+func (o walkOptions) hasTimeFilter() bool {
+	return !o.newerThan.IsZero() || !o.olderThan.IsZero()
+}
+
+func (o walkOptions) hasSizeFilter() bool {
+	return o.minSize > 0 || o.hasMaxSize
 }
 
 func walkDirEntries(ctx context.Context, root string, opts walkOptions) ([]walkEntry, error) {
@@ -1016,6 +1420,12 @@ func walkDirEntries(ctx context.Context, root string, opts walkOptions) ([]walkE
 			}
 			return nil
 		}
+		if opts.ignore.match(filepath.ToSlash(rel), d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 		if opts.pattern != "" {
 			match, err := filepath.Match(opts.pattern, filepath.Base(path))
 			if err != nil {
@@ -1039,6 +1449,24 @@ func walkDirEntries(ctx context.Context, root string, opts walkOptions) ([]walkE
 				return nil
 			}
 		}
+		if opts.hasTimeFilter() || opts.hasSizeFilter() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !opts.newerThan.IsZero() && !info.ModTime().After(opts.newerThan) {
+				return nil
+			}
+			if !opts.olderThan.IsZero() && !info.ModTime().Before(opts.olderThan) {
+				return nil
+			}
+			if opts.hasSizeFilter() {
+				// Size filters only make sense for files, like find -size.
+				if d.IsDir() || info.Size() < opts.minSize || (opts.hasMaxSize && info.Size() > opts.maxSize) {
+					return nil
+				}
+			}
+		}
 		matches = append(matches, walkEntry{Path: path, Rel: rel, IsDir: d.IsDir()})
 		entries++
 		if opts.maxEntries > 0 && entries >= opts.maxEntries {
@@ -1052,8 +1480,8 @@ func walkDirEntries(ctx context.Context, root string, opts walkOptions) ([]walkE
 	return matches, nil
 }
 
-func collectGrepFiles(ctx context.Context, inputPaths []string, recursive bool, showHidden bool) ([]grepFile, error) {
-	baseResolved, err := resolveBaseDir()
+func collectGrepFiles(ctx context.Context, inputPaths []string, recursive bool, showHidden bool, ignore ignoreRules) ([]grepFile, error) {
+	baseResolved, err := resolveBaseDir(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1069,7 +1497,7 @@ func collectGrepFiles(ctx context.Context, inputPaths []string, recursive bool,
 				return nil, fmt.Errorf("pattern %q matched no files", input)
 			}
 			for _, match := range matches {
-				resolvedMatch, err := resolveToolPath(match)
+				resolvedMatch, err := resolveToolPath(ctx, match)
 				if err != nil {
 					return nil, err
 				}
@@ -1085,7 +1513,7 @@ func collectGrepFiles(ctx context.Context, inputPaths []string, recursive bool,
 			}
 			continue
 		}
-		resolved, err := resolveToolPath(input)
+		resolved, err := resolveToolPath(ctx, input)
 		if err != nil {
 			return nil, err
 		}
@@ -1108,12 +1536,17 @@ func collectGrepFiles(ctx context.Context, inputPaths []string, recursive bool,
 		if recursive {
 			maxDepth = limits.MaxDirectoryDepth
 		}
+		matcher, err := ignore.matcher(resolved)
+		if err != nil {
+			return nil, err
+		}
 		entries, err := walkDirEntries(ctx, resolved, walkOptions{
 			maxDepth:    maxDepth,
 			maxEntries:  limits.MaxDirectoryEntries,
 			showHidden:  showHidden,
 			typeFilter:  "file",
 			regularOnly: true,
+			ignore:      matcher,
 		})
 		if err != nil {
 			return nil, err
@@ -1148,11 +1581,11 @@ func expandGlobPattern(baseResolved, input string) ([]string, error) {
 		pattern = filepath.Clean(filepath.Join(baseResolved, input))
 	}
 	if !paths.HasPathPrefix(pattern, baseResolved) {
-		return nil, fmt.Errorf("path escapes working directory")
+		return nil, ErrPathEscapesWorkdir
 	}
 	for _, dangerous := range dangerousPaths {
 		if strings.HasPrefix(pattern, dangerous) {
-			return nil, fmt.Errorf("access to %s is restricted for security", dangerous)
+			return nil, fmt.Errorf("access to %s is %w", dangerous, ErrPathRestricted)
 		}
 	}
 	return filepath.Glob(pattern)
@@ -1174,7 +1607,7 @@ func sortText(ctx context.Context, args map[string]interface{}) (string, error)
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -1199,7 +1632,7 @@ func uniqText(ctx context.Context, args map[string]interface{}) (string, error)
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -1231,27 +1664,47 @@ func wordCount(ctx context.Context, args map[string]interface{}) (string, error)
 	if err != nil {
 		return "", err
 	}
-	resolvedPaths, err := resolveToolPaths(paths)
+	files, err := collectGrepFiles(ctx, paths, getBoolArg(args, "recursive"), false, ignoreRules{})
 	if err != nil {
 		return "", err
 	}
+	named := len(paths) > 1 || len(files) != 1 || files[0].FromDir
 	var output []string
-	for idx, path := range resolvedPaths {
+	var totalLines, totalWords, totalBytes, counted int
+	for _, file := range files {
 		if err := ensureContext(ctx); err != nil {
 			return "", err
 		}
-		data, err := readFileLimited(path, false)
+		data, err := readFileLimited(file.Path, true)
 		if err != nil {
 			return "", err
 		}
+		data, err = textContent(data)
+		if err != nil {
+			// Directories are counted like wc over a glob, without binaries.
+			if file.FromDir {
+				continue
+			}
+			return "", fmt.Errorf("file %w; tool supports text only", err)
+		}
 		lines := countLines(data)
 		words := countWords(data)
 		bytesCount := len(data)
-		if len(resolvedPaths) > 1 {
-			output = append(output, fmt.Sprintf("%d %d %d %s", lines, words, bytesCount, paths[idx]))
+		if named {
+			output = append(output, fmt.Sprintf("%d %d %d %s", lines, words, bytesCount, file.Display))
 		} else {
 			output = append(output, fmt.Sprintf("%d %d %d", lines, words, bytesCount))
 		}
+		totalLines += lines
+		totalWords += words
+		totalBytes += bytesCount
+		counted++
+	}
+	if counted == 0 {
+		return "No text files to count", nil
+	}
+	if counted > 1 {
+		output = append(output, fmt.Sprintf("%d %d %d total", totalLines, totalWords, totalBytes))
 	}
 	return strings.Join(output, "\n"), nil
 }
@@ -1260,21 +1713,20 @@ func translateText(ctx context.Context, args map[string]interface{}) (string, er
 	if err := ensureContext(ctx); err != nil {
 		return "", err
 	}
-	from, err := extractStringArg(args, "from")
+	from, err := translateSet(args)
 	if err != nil {
 		return "", err
 	}
-	to, err := extractStringArg(args, "to")
+	to, err := translateTarget(args)
 	if err != nil {
 		return "", err
 	}
-	if from == "" || to == "" {
-		return "", fmt.Errorf("from and to must be non-empty")
-	}
+	deleteChars := getBoolArg(args, "delete")
+	squeeze := getBoolArg(args, "squeeze")
 
 	var input string
 	if path, ok := getStringLike(args["path"]); ok {
-		resolved, err := resolveToolPath(path)
+		resolved, err := resolveToolPath(ctx, path)
 		if err != nil {
 			return "", err
 		}
@@ -1289,18 +1741,69 @@ func translateText(ctx context.Context, args map[string]interface{}) (string, er
 		return "", fmt.Errorf("missing or invalid 'path' or 'input' parameter")
 	}
 
-	mapping := buildTranslationMap(from, to)
+	// Like tr, squeezing applies to the last set given: to when present,
+	// otherwise from.
+	var mapping map[rune]rune
+	deleteSet := map[rune]bool{}
+	squeezeSet := map[rune]bool{}
+	switch {
+	case deleteChars:
+		for _, r := range from {
+			deleteSet[r] = true
+		}
+	case to != "":
+		mapping = buildTranslationMap(from, to)
+	}
+	if squeeze {
+		set := to
+		if set == "" && !deleteChars {
+			set = from
+		}
+		for _, r := range set {
+			squeezeSet[r] = true
+		}
+	}
+
 	var output strings.Builder
+	var last rune
+	wrote := false
 	for _, r := range input {
+		if deleteSet[r] {
+			continue
+		}
 		if replacement, ok := mapping[r]; ok {
-			output.WriteRune(replacement)
-		} else {
-			output.WriteRune(r)
+			r = replacement
 		}
+		if wrote && r == last && squeezeSet[r] {
+			continue
+		}
+		output.WriteRune(r)
+		last = r
+		wrote = true
 	}
 	return output.String(), nil
 }
 
+// translateSet returns the tr source set. Whitespace is a valid set, so the
+// value is not trimmed like other string arguments.
+func translateSet(args map[string]interface{}) (string, error) {
+	from, _ := args["from"].(string)
+	if from == "" {
+		return "", fmt.Errorf("from must be non-empty")
+	}
+	return from, nil
+}
+
+// translateTarget returns the tr replacement set, which may only be omitted
+// when deleting or squeezing.
+func translateTarget(args map[string]interface{}) (string, error) {
+	to, _ := args["to"].(string)
+	if to == "" && !getBoolArg(args, "delete") && !getBoolArg(args, "squeeze") {
+		return "", fmt.Errorf("to must be non-empty unless delete or squeeze is set")
+	}
+	return to, nil
+}
+
 func teeText(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err := ensureContext(ctx); err != nil {
 		return "", err
@@ -1309,17 +1812,18 @@ func teeText(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	content = normalizeLineEndings(content)
 	pathsArg, err := extractPaths(args, "paths", "path")
 	if err != nil {
 		return "", err
 	}
-	resolvedPaths, err := resolveToolPaths(pathsArg)
+	resolvedPaths, err := resolveToolPaths(ctx, pathsArg)
 	if err != nil {
 		return "", err
 	}
 	limits := getLimits()
 	if limits.MaxFileSizeBytes > 0 && int64(len(content)) > limits.MaxFileSizeBytes {
-		return "", fmt.Errorf("content exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+		return "", fmt.Errorf("content %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 	}
 	for _, path := range resolvedPaths {
 		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
@@ -1341,11 +1845,11 @@ func compareFiles(ctx context.Context, args map[string]interface{}) (string, err
 	if err != nil {
 		return "", err
 	}
-	resolved1, err := resolveToolPath(path1)
+	resolved1, err := resolveToolPath(ctx, path1)
 	if err != nil {
 		return "", err
 	}
-	resolved2, err := resolveToolPath(path2)
+	resolved2, err := resolveToolPath(ctx, path2)
 	if err != nil {
 		return "", err
 	}
@@ -1358,6 +1862,19 @@ func compareFiles(ctx context.Context, args map[string]interface{}) (string, err
 		return "", err
 	}
 
+	// Like comm, each column is indented by one tab per shown column before it.
+	show1 := !getBoolArg(args, "suppress1")
+	show2 := !getBoolArg(args, "suppress2")
+	show3 := !getBoolArg(args, "suppress3")
+	prefix2 := ""
+	if show1 {
+		prefix2 = "\t"
+	}
+	prefix3 := prefix2
+	if show2 {
+		prefix3 += "\t"
+	}
+
 	var output []string
 	i, j := 0, 0
 	for i < len(lines1) || j < len(lines2) {
@@ -1365,21 +1882,21 @@ func compareFiles(ctx context.Context, args map[string]interface{}) (string, err
 			return "", err
 		}
 		switch {
-		case i >= len(lines1):
-			output = append(output, "\t"+lines2[j])
-			j++
-		case j >= len(lines2):
-			output = append(output, lines1[i])
-			i++
-		case lines1[i] == lines2[j]:
-			output = append(output, "\t\t"+lines1[i])
-			i++
+		case i >= len(lines1) || (j < len(lines2) && lines2[j] < lines1[i]):
+			if show2 {
+				output = append(output, prefix2+lines2[j])
+			}
 			j++
-		case lines1[i] < lines2[j]:
-			output = append(output, lines1[i])
+		case j >= len(lines2) || lines1[i] < lines2[j]:
+			if show1 {
+				output = append(output, lines1[i])
+			}
 			i++
 		default:
-			output = append(output, "\t"+lines2[j])
+			if show3 {
+				output = append(output, prefix3+lines1[i])
+			}
+			i++
 			j++
 		}
 	}
@@ -1394,7 +1911,7 @@ func stringsText(ctx context.Context, args map[string]interface{}) (string, erro
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -1439,7 +1956,7 @@ func hexDump(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -1498,11 +2015,11 @@ func compareBytes(ctx context.Context, args map[string]interface{}) (string, err
 	if err != nil {
 		return "", err
 	}
-	resolved1, err := resolveToolPath(path1)
+	resolved1, err := resolveToolPath(ctx, path1)
 	if err != nil {
 		return "", err
 	}
-	resolved2, err := resolveToolPath(path2)
+	resolved2, err := resolveToolPath(ctx, path2)
 	if err != nil {
 		return "", err
 	}
@@ -1570,7 +2087,7 @@ func md5Sum(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	resolvedPaths, err := resolveToolPaths(pathsArg)
+	resolvedPaths, err := resolveToolPaths(ctx, pathsArg)
 	if err != nil {
 		return "", err
 	}
@@ -1610,7 +2127,7 @@ func shaSum(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	resolvedPaths, err := resolveToolPaths(pathsArg)
+	resolvedPaths, err := resolveToolPaths(ctx, pathsArg)
 	if err != nil {
 		return "", err
 	}
@@ -1657,7 +2174,7 @@ func base64Tool(ctx context.Context, args map[string]interface{}) (string, error
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -1676,7 +2193,7 @@ func base64Tool(ctx context.Context, args map[string]interface{}) (string, error
 		return "", err
 	}
 	if getBoolArg(args, "decode") && !isTextContent([]byte(output)) {
-		return "", fmt.Errorf("decoded output appears to be binary")
+		return "", fmt.Errorf("decoded output %w", ErrBinaryContent)
 	}
 	return strings.TrimRight(output, "\n"), nil
 }
@@ -1795,7 +2312,7 @@ func dfTool(ctx context.Context, args map[string]interface{}) (string, error) {
 	if path == "" {
 		path = "."
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -1831,7 +2348,7 @@ func duTool(ctx context.Context, args map[string]interface{}) (string, error) {
 	if path == "" {
 		path = "."
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -1855,11 +2372,85 @@ func duTool(ctx context.Context, args map[string]interface{}) (string, error) {
 		maxEntries = 2000
 	}
 
-	total, err := computeDiskUsage(ctx, resolved, info, maxDepth, maxEntries)
+	top, hasTop, err := getOptionalIntArg(args, "top")
+	if err != nil {
+		return "", err
+	}
+	if hasTop && top < 0 {
+		return "", fmt.Errorf("top must be positive")
+	}
+	if !getBoolArg(args, "per_file") && top == 0 {
+		total, err := computeDiskUsage(ctx, resolved, info, maxDepth, maxEntries, nil)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Path: %s\nTotal: %s (%d bytes)", resolved, formatBytes(total), total), nil
+	}
+
+	if top == 0 {
+		top = duDefaultTop
+	}
+	top = min(top, duMaxTop)
+	sizes := make(map[string]int64)
+	total, err := computeDiskUsage(ctx, resolved, info, maxDepth, maxEntries, func(path string, size int64) {
+		// Charge each file to itself and every directory between it and the root.
+		for current := path; current != resolved; current = filepath.Dir(current) {
+			sizes[current] += size
+			if filepath.Dir(current) == current {
+				break
+			}
+		}
+	})
 	if err != nil {
 		return "", err
 	}
-	return fmt.Sprintf("Path: %s\nTotal: %s (%d bytes)", resolved, formatBytes(total), total), nil
+	return formatDiskUsageBreakdown(resolved, total, sizes, top), nil
+}
+
+const (
+	duDefaultTop = 20
+	duMaxTop     = 100
+)
+
+func formatDiskUsageBreakdown(root string, total int64, sizes map[string]int64, top int) string {
+	type usage struct {
+		rel   string
+		size  int64
+		isDir bool
+	}
+	entries := make([]usage, 0, len(sizes))
+	for path, size := range sizes {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		isDir := false
+		if info, err := os.Lstat(path); err == nil {
+			isDir = info.IsDir()
+		}
+		entries = append(entries, usage{rel: rel, size: size, isDir: isDir})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].size != entries[j].size {
+			return entries[i].size > entries[j].size
+		}
+		return entries[i].rel < entries[j].rel
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Path: %s\nTotal: %s (%d bytes)", root, formatBytes(total), total)
+	if len(entries) > top {
+		fmt.Fprintf(&b, "\nLargest %d of %d entries:", top, len(entries))
+		entries = entries[:top]
+	}
+	for _, entry := range entries {
+		name := entry.rel
+		if entry.isDir {
+			name += string(filepath.Separator)
+		}
+		fmt.Fprintf(&b, "\n%10s  %s", formatBytes(entry.size), name)
+	}
+	return b.String()
 }
 
 func psTool(ctx context.Context, args map[string]interface{}) (string, error) {
@@ -1991,7 +2582,127 @@ func echoTool(ctx context.Context, args map[string]interface{}) (string, error)
 	return strings.Join(parts, " "), nil
 }
 
-func seqTool(ctx context.Context, args map[string]interface{}) (string, error) {
+// maxPrintfWidth caps width and precision so a format cannot request a huge
+// padding allocation.
+const maxPrintfWidth = 1024
+
+// printfVerb is one conversion parsed from a printf format string.
+type printfVerb struct {
+	verb rune
+	spec string // the full conversion, e.g. "%-08.2f"
+}
+
+func printfTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[printfArgs](args)
+	if err != nil {
+		return "", err
+	}
+	verbs, err := parsePrintfFormat(parsed.Format)
+	if err != nil {
+		return "", err
+	}
+	if len(verbs) != len(parsed.Args) {
+		return "", fmt.Errorf("format has %d verb(s) but %d arg(s) were given", len(verbs), len(parsed.Args))
+	}
+	values := make([]interface{}, len(parsed.Args))
+	for i, arg := range parsed.Args {
+		value, err := printfValue(verbs[i], arg)
+		if err != nil {
+			return "", fmt.Errorf("arg %d for %s: %w", i+1, verbs[i].spec, err)
+		}
+		values[i] = value
+	}
+	return fmt.Sprintf(parsed.Format, values...), nil
+}
+
+// parsePrintfFormat accepts the verbs s, d, f, x, v and q with the flags
+// "-+# 0", a width and a precision. Argument indexes and "*" widths are
+// rejected so every verb consumes exactly one argument.
+func parsePrintfFormat(format string) ([]printfVerb, error) {
+	var verbs []printfVerb
+	runes := []rune(format)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '%' {
+			continue
+		}
+		start := i
+		i++
+		if i < len(runes) && runes[i] == '%' {
+			continue
+		}
+		for i < len(runes) && strings.ContainsRune("-+# 0", runes[i]) {
+			i++
+		}
+		width, next := scanPrintfNumber(runes, i)
+		i = next
+		precision := 0
+		if i < len(runes) && runes[i] == '.' {
+			precision, i = scanPrintfNumber(runes, i+1)
+		}
+		if width > maxPrintfWidth || precision > maxPrintfWidth {
+			return nil, fmt.Errorf("width and precision must be at most %d", maxPrintfWidth)
+		}
+		if i >= len(runes) {
+			return nil, fmt.Errorf("incomplete verb %q at end of format", string(runes[start:]))
+		}
+		if !strings.ContainsRune("sdfxvq", runes[i]) {
+			return nil, fmt.Errorf("unsupported verb %q (use %%s %%d %%f %%x %%v or %%q)", string(runes[start:i+1]))
+		}
+		verbs = append(verbs, printfVerb{verb: runes[i], spec: string(runes[start : i+1])})
+	}
+	return verbs, nil
+}
+
+func scanPrintfNumber(runes []rune, i int) (int, int) {
+	n := 0
+	for i < len(runes) && runes[i] >= '0' && runes[i] <= '9' {
+		if n <= maxPrintfWidth {
+			n = n*10 + int(runes[i]-'0')
+		}
+		i++
+	}
+	return n, i
+}
+
+// printfValue converts a JSON argument to the Go type its verb expects, so
+// numbers print as numbers and %d never sees a float.
+func printfValue(verb printfVerb, arg interface{}) (interface{}, error) {
+	switch v := arg.(type) {
+	case string:
+		switch verb.verb {
+		case 'd', 'f':
+			return nil, fmt.Errorf("expected a number, got string %q", v)
+		}
+		return v, nil
+	case bool:
+		switch verb.verb {
+		case 'd', 'f', 'x':
+			return nil, fmt.Errorf("expected a number, got %t", v)
+		}
+		return strconv.FormatBool(v), nil
+	case float64:
+		switch verb.verb {
+		case 'd', 'x':
+			if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+				return nil, fmt.Errorf("expected an integer, got %v", v)
+			}
+			return int64(v), nil
+		case 'f':
+			return v, nil
+		default:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+	case nil:
+		return nil, fmt.Errorf("null is not supported")
+	default:
+		return nil, fmt.Errorf("expected a string, number or boolean")
+	}
+}
+
+func seqTool(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err := ensureContext(ctx); err != nil {
 		return "", err
 	}
@@ -2024,6 +2735,11 @@ func seqTool(ctx context.Context, args map[string]interface{}) (string, error) {
 		return "", fmt.Errorf("step must be negative when start > end")
 	}
 
+	format, separator, err := seqFormatArgs(args)
+	if err != nil {
+		return "", err
+	}
+
 	limits := getLimits()
 	maxEntries := limits.MaxDirectoryEntries
 	if maxEntries <= 0 {
@@ -2036,13 +2752,54 @@ func seqTool(ctx context.Context, args map[string]interface{}) (string, error) {
 		if (step > 0 && i > end) || (step < 0 && i < end) {
 			break
 		}
-		output = append(output, strconv.Itoa(i))
+		if format == "" {
+			output = append(output, strconv.Itoa(i))
+		} else {
+			output = append(output, fmt.Sprintf(format, i))
+		}
 		count++
 		if count >= maxEntries {
 			return "", fmt.Errorf("sequence length exceeds limit of %d", maxEntries)
 		}
 	}
-	return strings.Join(output, "\n"), nil
+	return strings.Join(output, separator), nil
+}
+
+// maxSeqSeparator bounds the separator so it cannot inflate the output.
+const maxSeqSeparator = 16
+
+// seqFormatArgs returns the optional number format and the separator for seq.
+// The format must hold exactly one integer verb (%d or %x with flags and
+// width); other text in it, such as a "file-" prefix, is printed as is.
+func seqFormatArgs(args map[string]interface{}) (string, string, error) {
+	separator := "\n"
+	if value, ok := args["separator"]; ok {
+		sep, ok := value.(string)
+		if !ok {
+			return "", "", fmt.Errorf("missing or invalid 'separator' parameter")
+		}
+		if utf8.RuneCountInString(sep) > maxSeqSeparator {
+			return "", "", fmt.Errorf("separator must be at most %d characters", maxSeqSeparator)
+		}
+		if sep != "" {
+			separator = sep
+		}
+	}
+	format, _ := args["format"].(string)
+	if format == "" {
+		return "", separator, nil
+	}
+	verbs, err := parsePrintfFormat(format)
+	if err != nil {
+		return "", "", err
+	}
+	if len(verbs) != 1 {
+		return "", "", fmt.Errorf("format must contain exactly one verb, found %d", len(verbs))
+	}
+	if verb := verbs[0]; verb.verb != 'd' && verb.verb != 'x' {
+		return "", "", fmt.Errorf("format verb %q is not an integer verb (use %%d or %%x)", verb.spec)
+	}
+	return format, separator, nil
 }
 
 func printenvTool(ctx context.Context, args map[string]interface{}) (string, error) {
@@ -2100,7 +2857,7 @@ func mkfifoTool(ctx context.Context, args map[string]interface{}) (string, error
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -2125,11 +2882,11 @@ func mktempTool(ctx context.Context, args map[string]interface{}) (string, error
 	if err := ensureContext(ctx); err != nil {
 		return "", err
 	}
-	baseResolved, err := resolveBaseDir()
+	baseResolved, err := resolveBaseDir(ctx)
 	if err != nil {
 		return "", err
 	}
-	tempRoot := filepath.Join(baseResolved, ".tmp")
+	tempRoot := filepath.Join(baseResolved, tempDirName)
 	if err := os.MkdirAll(tempRoot, 0o700); err != nil {
 		return "", err
 	}
@@ -2148,12 +2905,14 @@ func mktempTool(ctx context.Context, args map[string]interface{}) (string, error
 		if err != nil {
 			return "", err
 		}
+		trackTempPath(path)
 		return path, nil
 	}
 	file, err := os.CreateTemp(tempRoot, prefix)
 	if err != nil {
 		return "", err
 	}
+	trackTempPath(file.Name())
 	if err := file.Close(); err != nil {
 		return "", err
 	}
@@ -2171,7 +2930,7 @@ func findTool(ctx context.Context, args map[string]interface{}) (string, error)
 	if path == "" {
 		path = "."
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -2203,13 +2962,23 @@ func findTool(ctx context.Context, args map[string]interface{}) (string, error)
 		return "", fmt.Errorf("type must be 'file' or 'dir'")
 	}
 
-	entries, err := walkDirEntries(ctx, resolved, walkOptions{
-		maxDepth:   maxDepth,
-		maxEntries: maxEntries,
-		showHidden: showHidden,
-		pattern:    pattern,
-		typeFilter: typeFilter,
-	})
+	opts, err := findFilters(args, time.Now())
+	if err != nil {
+		return "", err
+	}
+	opts.maxDepth = maxDepth
+	opts.maxEntries = maxEntries
+	opts.showHidden = showHidden
+	opts.pattern = pattern
+	opts.typeFilter = typeFilter
+	ignore, err := ignoreRulesFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	if opts.ignore, err = ignore.matcher(resolved); err != nil {
+		return "", err
+	}
+	entries, err := walkDirEntries(ctx, resolved, opts)
 	if err != nil {
 		return "", err
 	}
@@ -2223,6 +2992,208 @@ func findTool(ctx context.Context, args map[string]interface{}) (string, error)
 	return strings.Join(matches, "\n"), nil
 }
 
+func validateFindArgs(args map[string]interface{}) error {
+	if _, err := findFilters(args, time.Now()); err != nil {
+		return err
+	}
+	_, err := ignoreRulesFromArgs(args)
+	return err
+}
+
+// findFilters reads the find age and size filters into walk options.
+func findFilters(args map[string]interface{}, now time.Time) (walkOptions, error) {
+	var opts walkOptions
+	var err error
+	if opts.newerThan, err = findTimeArg(args, "newer_than", now); err != nil {
+		return opts, err
+	}
+	if opts.olderThan, err = findTimeArg(args, "older_than", now); err != nil {
+		return opts, err
+	}
+	if !opts.newerThan.IsZero() && !opts.olderThan.IsZero() && !opts.newerThan.Before(opts.olderThan) {
+		return opts, fmt.Errorf("newer_than and older_than leave no time range to match")
+	}
+
+	minSize, _, err := getOptionalIntArg(args, "min_size")
+	if err != nil || minSize < 0 {
+		return opts, fmt.Errorf("missing or invalid 'min_size' parameter: expected a number of bytes >= 0")
+	}
+	maxSize, hasMax, err := getOptionalIntArg(args, "max_size")
+	if err != nil || maxSize < 0 {
+		return opts, fmt.Errorf("missing or invalid 'max_size' parameter: expected a number of bytes >= 0")
+	}
+	if hasMax && maxSize < minSize {
+		return opts, fmt.Errorf("max_size must not be smaller than min_size")
+	}
+	opts.minSize = int64(minSize)
+	opts.maxSize = int64(maxSize)
+	opts.hasMaxSize = hasMax
+	return opts, nil
+}
+
+// findTimeArg parses an age ("24h", "90m", "7d") counted back from now, or an
+// absolute RFC3339 time. A missing or empty value returns the zero time.
+func findTimeArg(args map[string]interface{}, key string, now time.Time) (time.Time, error) {
+	raw, ok := getStringLike(args[key])
+	raw = strings.TrimSpace(raw)
+	if !ok || raw == "" {
+		if _, present := args[key]; present && !ok {
+			return time.Time{}, fmt.Errorf("missing or invalid '%s' parameter", key)
+		}
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	age, err := parseDayDuration(raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: expected a duration like 24h or 7d, or an RFC3339 time", key, raw)
+	}
+	if age <= 0 {
+		return time.Time{}, fmt.Errorf("invalid %s %q: duration must be positive", key, raw)
+	}
+	return now.Add(-age), nil
+}
+
+// parseDayDuration parses a Go duration ("90m", "48h") or a number of days ("7d").
+func parseDayDuration(raw string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(raw, "d"); found {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// searchTool walks a tree like find and greps each regular file it visits,
+// returning "path:line:text" for every matching line.
+func searchTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	pattern, err := extractStringArg(args, "pattern")
+	if err != nil {
+		return "", err
+	}
+	if getBoolArg(args, "ignore_case") {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	maxMatches, err := extractIntArg(args, "max_matches", 1000)
+	if err != nil {
+		return "", err
+	}
+	if maxMatches <= 0 {
+		return "", fmt.Errorf("max_matches must be positive")
+	}
+
+	path := getPathArg(args)
+	if path == "" {
+		path = "."
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	limits := getLimits()
+	maxDepth := limits.MaxDirectoryDepth
+	if depth, err := extractIntArg(args, "max_depth", 0); err != nil {
+		return "", err
+	} else if depth > 0 && depth < maxDepth {
+		maxDepth = depth
+	}
+	if maxDepth <= 0 {
+		maxDepth = 1
+	}
+	maxEntries := limits.MaxDirectoryEntries
+	if maxEntries <= 0 {
+		maxEntries = 2000
+	}
+	name, _ := getStringLike(args["name"])
+	ignore, err := ignoreRulesFromArgs(args)
+	if err != nil {
+		return "", err
+	}
+	matcher, err := ignore.matcher(resolved)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := walkDirEntries(ctx, resolved, walkOptions{
+		maxDepth:    maxDepth,
+		maxEntries:  maxEntries,
+		showHidden:  getBoolArg(args, "show_hidden"),
+		pattern:     name,
+		regularOnly: true,
+		ignore:      matcher,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var output, unreadable []string
+	for _, entry := range entries {
+		if err := ensureContext(ctx); err != nil {
+			return "", err
+		}
+		if limits.MaxFileSizeBytes > 0 {
+			if info, err := os.Stat(entry.Path); err != nil || info.Size() > limits.MaxFileSizeBytes {
+				continue
+			}
+		}
+		data, err := readFileLimited(entry.Path, true)
+		if err != nil {
+			unreadable = append(unreadable, entry.Path)
+			continue
+		}
+		if data, err = textContent(data); err != nil {
+			continue
+		}
+		text := strings.ReplaceAll(string(data), "\r\n", "\n")
+		for i, line := range strings.Split(text, "\n") {
+			if !re.MatchString(line) {
+				continue
+			}
+			output = append(output, fmt.Sprintf("%s:%d:%s", entry.Path, i+1, line))
+			if len(output) >= maxMatches {
+				return searchOutput(output, unreadable), nil
+			}
+		}
+	}
+	return searchOutput(output, unreadable), nil
+}
+
+// maxSearchUnreadableNames caps the unreadable files named by search.
+const maxSearchUnreadableNames = 10
+
+// searchOutput joins the search matches and names the files that could not be
+// read, so a permission error on one file does not end the search.
+func searchOutput(matches, unreadable []string) string {
+	out := strings.Join(matches, "\n")
+	if len(unreadable) == 0 {
+		return out
+	}
+	names := unreadable
+	if len(names) > maxSearchUnreadableNames {
+		names = names[:maxSearchUnreadableNames]
+	}
+	note := fmt.Sprintf("(skipped %d unreadable files: %s", len(unreadable), strings.Join(names, ", "))
+	if len(unreadable) > len(names) {
+		note += ", …"
+	}
+	note += ")"
+	if out == "" {
+		return note
+	}
+	return out + "\n" + note
+}
+
 func chmodTool(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err := ensureContext(ctx); err != nil {
 		return "", err
@@ -2242,7 +3213,7 @@ func chmodTool(ctx context.Context, args map[string]interface{}) (string, error)
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -2259,19 +3230,99 @@ func dateTool(ctx context.Context, args map[string]interface{}) (string, error)
 	if err := ensureContext(ctx); err != nil {
 		return "", err
 	}
-	now := time.Now()
+	t, err := dateFromArgs(args, time.Now())
+	if err != nil {
+		return "", err
+	}
 	format := ""
 	if args != nil {
 		format, _ = getStringLike(args["format"])
 	}
 	switch strings.TrimSpace(strings.ToLower(format)) {
 	case "":
-		return now.Format(time.RFC3339), nil
+		return t.Format(time.RFC3339), nil
 	case "unix":
-		return strconv.FormatInt(now.Unix(), 10), nil
+		return strconv.FormatInt(t.Unix(), 10), nil
 	default:
-		return now.Format(format), nil
+		return t.Format(format), nil
+	}
+}
+
+func validateDateArgs(args map[string]interface{}) error {
+	_, err := dateFromArgs(args, time.Now())
+	return err
+}
+
+// dateInputLayouts are tried in order on a date input without a unix timestamp.
+// Layouts without a zone are read in the requested timezone.
+var dateInputLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// dateFromArgs returns the time the date tool reports: input (or now), shifted
+// by add and subtract, in the requested timezone (or the local one).
+func dateFromArgs(args map[string]interface{}, now time.Time) (time.Time, error) {
+	loc := time.Local
+	if name, ok := getStringLike(args["timezone"]); ok {
+		zone, err := time.LoadLocation(strings.TrimSpace(name))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid timezone %q: expected an IANA name like Europe/Amsterdam or UTC", name)
+		}
+		loc = zone
+	}
+
+	t := now
+	if input, present := args["input"]; present {
+		parsed, err := parseDateInput(input, loc)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = parsed
+	}
+	for _, key := range []string{"add", "subtract"} {
+		raw, ok := getStringLike(args[key])
+		if !ok {
+			if _, present := args[key]; present {
+				return time.Time{}, fmt.Errorf("missing or invalid '%s' parameter", key)
+			}
+			continue
+		}
+		d, err := parseDayDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q: expected a duration like 48h, 90m or 7d", key, raw)
+		}
+		if key == "subtract" {
+			d = -d
+		}
+		t = t.Add(d)
+	}
+	return t.In(loc), nil
+}
+
+// parseDateInput reads an RFC3339 (or similar) timestamp, a date, or unix
+// seconds.
+func parseDateInput(input interface{}, loc *time.Location) (time.Time, error) {
+	raw, ok := getStringLike(input)
+	if !ok {
+		return time.Time{}, fmt.Errorf("missing or invalid 'input' parameter")
+	}
+	raw = strings.TrimSpace(raw)
+	if seconds, err := strconv.ParseFloat(raw, 64); err == nil {
+		whole := math.Floor(seconds)
+		return time.Unix(int64(whole), int64((seconds-whole)*float64(time.Second))), nil
+	}
+	for _, layout := range dateInputLayouts {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, nil
+		}
 	}
+	return time.Time{}, fmt.Errorf("invalid input %q: expected an RFC3339 time such as 2025-01-02T15:04:05Z, a date such as 2025-01-02, or unix seconds", raw)
 }
 
 type processInfo struct {
@@ -2371,7 +3422,9 @@ func parseModeString(mode string) (os.FileMode, error) {
 	return os.FileMode(parsed), nil
 }
 
-func computeDiskUsage(ctx context.Context, root string, info os.FileInfo, maxDepth int, maxEntries int) (int64, error) {
+// computeDiskUsage sums regular file sizes under root. When onFile is set it is
+// called with the path and size of every counted file.
+func computeDiskUsage(ctx context.Context, root string, info os.FileInfo, maxDepth int, maxEntries int, onFile func(path string, size int64)) (int64, error) {
 	if !info.IsDir() {
 		return info.Size(), nil
 	}
@@ -2405,6 +3458,9 @@ func computeDiskUsage(ctx context.Context, root string, info os.FileInfo, maxDep
 			return err
 		}
 		total += info.Size()
+		if onFile != nil {
+			onFile(path, info.Size())
+		}
 		return nil
 	})
 	if err != nil {
@@ -2535,11 +3591,11 @@ func linkPath(ctx context.Context, args map[string]interface{}) (string, error)
 		return "", err
 	}
 
-	resolvedTarget, err := resolveToolPath(target)
+	resolvedTarget, err := resolveToolPath(ctx, target)
 	if err != nil {
 		return "", err
 	}
-	resolvedLink, err := resolveToolPath(linkPathArg)
+	resolvedLink, err := resolveToolPath(ctx, linkPathArg)
 	if err != nil {
 		return "", err
 	}
@@ -2571,7 +3627,7 @@ func printWorkingDirectory(ctx context.Context, args map[string]interface{}) (st
 	if err := ensureContext(ctx); err != nil {
 		return "", err
 	}
-	workdir, err := os.Getwd()
+	workdir, err := toolWorkdir(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to determine working directory: %v", err)
 	}
@@ -2623,7 +3679,7 @@ func truncateFile(ctx context.Context, args map[string]interface{}) (string, err
 		return "", fmt.Errorf("size exceeds maximum of %d bytes", limits.MaxFileSizeBytes)
 	}
 
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -2653,7 +3709,7 @@ func readLinkPath(ctx context.Context, args map[string]interface{}) (string, err
 	if err != nil {
 		return "", err
 	}
-	resolved, err := resolveToolPathNoSymlink(path)
+	resolved, err := resolveToolPathNoSymlink(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -2668,7 +3724,7 @@ func readLinkPath(ctx context.Context, args map[string]interface{}) (string, err
 		if err != nil {
 			return "", err
 		}
-		baseResolved, err := resolveBaseDir()
+		baseResolved, err := resolveBaseDir(ctx)
 		if err != nil {
 			return "", err
 		}
@@ -2680,7 +3736,7 @@ func readLinkPath(ctx context.Context, args map[string]interface{}) (string, err
 	}
 
 	if filepath.IsAbs(linkTarget) {
-		baseResolved, err := resolveBaseDir()
+		baseResolved, err := resolveBaseDir(ctx)
 		if err != nil {
 			return "", err
 		}
@@ -2690,7 +3746,7 @@ func readLinkPath(ctx context.Context, args map[string]interface{}) (string, err
 		return linkTarget, nil
 	}
 
-	baseResolved, err := resolveBaseDir()
+	baseResolved, err := resolveBaseDir(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -2710,7 +3766,7 @@ func realpathPath(ctx context.Context, args map[string]interface{}) (string, err
 		return "", err
 	}
 
-	resolved, err := resolveToolPath(path)
+	resolved, err := resolveToolPath(ctx, path)
 	if err != nil {
 		return "", err
 	}
@@ -2719,7 +3775,7 @@ func realpathPath(ctx context.Context, args map[string]interface{}) (string, err
 	if err != nil {
 		return "", err
 	}
-	baseResolved, err := resolveBaseDir()
+	baseResolved, err := resolveBaseDir(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -2804,10 +3860,10 @@ func extractPaths(args map[string]interface{}, primary, fallback string) ([]stri
 	return nil, fmt.Errorf("missing or invalid '%s' parameter", primary)
 }
 
-func resolveToolPaths(paths []string) ([]string, error) {
+func resolveToolPaths(ctx context.Context, paths []string) ([]string, error) {
 	resolved := make([]string, 0, len(paths))
 	for _, path := range paths {
-		resolvedPath, err := resolveToolPath(path)
+		resolvedPath, err := resolveToolPath(ctx, path)
 		if err != nil {
 			return nil, err
 		}
@@ -2868,6 +3924,18 @@ func validateRequiredStrings(required []string, listKeys []string) func(map[stri
 	}
 }
 
+func validateTouchArgs(ctx context.Context, args map[string]interface{}) error {
+	if _, err := extractPaths(args, "paths", "path"); err != nil {
+		return err
+	}
+	if reference, ok := getStringLike(args["reference"]); ok {
+		if _, err := touchReferenceTime(ctx, reference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func validateTruncateArgs(args map[string]interface{}) error {
 	if _, err := extractPathArg(args); err != nil {
 		return err
@@ -2878,15 +3946,15 @@ func validateTruncateArgs(args map[string]interface{}) error {
 	return nil
 }
 
-func resolveListPath(path string) (string, error) {
+func resolveListPath(ctx context.Context, path string) (string, error) {
 	if strings.TrimSpace(path) == "" || path == "." {
-		workdir, err := os.Getwd()
+		workdir, err := toolWorkdir(ctx)
 		if err != nil {
 			return "", fmt.Errorf("failed to determine working directory: %v", err)
 		}
 		return workdir, nil
 	}
-	return validatePathWithinWorkdir(path)
+	return validatePathWithinWorkdir(ctx, path)
 }
 
 func filterHiddenOutput(output string) string {
@@ -2906,6 +3974,85 @@ func filterHiddenOutput(output string) string {
 	return strings.Join(kept, "\n")
 }
 
+// filterIgnoredOutput drops ignored entries, and everything inside ignored
+// directories, from ls output. Recursive listings print paths relative to the
+// working directory (or absolute ones); plain listings print names in root.
+func filterIgnoredOutput(ctx context.Context, output, root string, recursive, long bool, ignore *ignoreMatcher) (string, error) {
+	workdir, err := resolveBaseDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(output, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			kept = append(kept, line)
+			continue
+		}
+		name := strings.TrimSpace(line)
+		if long {
+			name = longListingName(fields)
+		}
+		full := name
+		if !filepath.IsAbs(full) {
+			if recursive {
+				full = filepath.Join(workdir, name)
+			} else {
+				full = filepath.Join(root, name)
+			}
+		}
+		rel, err := filepath.Rel(root, full)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			kept = append(kept, line)
+			continue
+		}
+		isDir := false
+		if info, err := os.Lstat(full); err == nil {
+			isDir = info.IsDir()
+		}
+		if ignore.matchAny(filepath.ToSlash(rel), isDir) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// filterHiddenLongOutput drops hidden entries from ls -l output, where the
+// name is the last field rather than the whole line.
+func filterHiddenLongOutput(output string) string {
+	lines := strings.Split(output, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			kept = append(kept, line)
+			continue
+		}
+		if len(fields) == 1 && strings.HasSuffix(fields[0], ":") {
+			// Directory header printed by -R.
+			if containsHiddenSegment(strings.TrimSuffix(fields[0], ":")) {
+				continue
+			}
+		} else if strings.HasPrefix(longListingName(fields), ".") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// longListingName returns the entry name of an ls -l line, skipping a symlink target.
+func longListingName(fields []string) string {
+	for i := len(fields) - 1; i > 0; i-- {
+		if fields[i] == "->" {
+			return fields[i-1]
+		}
+	}
+	return fields[len(fields)-1]
+}
+
 func containsHiddenSegment(path string) bool {
 	cleaned := filepath.Clean(path)
 	parts := strings.Split(cleaned, string(os.PathSeparator))
@@ -2928,7 +4075,7 @@ func readHeadTail(ctx context.Context, args map[string]interface{}, head bool, d
 	if err != nil {
 		return "", err
 	}
-	resolvedPaths, err := resolveToolPaths(pathsArg)
+	resolvedPaths, err := resolveToolPaths(ctx, pathsArg)
 	if err != nil {
 		return "", err
 	}
@@ -2982,16 +4129,20 @@ func readFileLimited(path string, allowBinary bool) ([]byte, error) {
 	}
 	limits := getLimits()
 	if limits.MaxFileSizeBytes > 0 && info.Size() > limits.MaxFileSizeBytes {
-		return nil, fmt.Errorf("file exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+		return nil, fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	if !allowBinary && !isTextContent(data) {
-		return nil, fmt.Errorf("file appears to be binary; tool supports text only")
+	if allowBinary {
+		return data, nil
 	}
-	return data, nil
+	text, err := textContent(data)
+	if err != nil {
+		return nil, fmt.Errorf("file %w; tool supports text only", err)
+	}
+	return text, nil
 }
 
 func ensureFileWithinLimit(path string) error {
@@ -3004,7 +4155,7 @@ func ensureFileWithinLimit(path string) error {
 	}
 	limits := getLimits()
 	if limits.MaxFileSizeBytes > 0 && info.Size() > limits.MaxFileSizeBytes {
-		return fmt.Errorf("file exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+		return fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 	}
 	return nil
 }
@@ -3101,6 +4252,22 @@ func buildTranslationMap(from, to string) map[rune]rune {
 	return mapping
 }
 
+func validateSearchArgs(args map[string]interface{}) error {
+	pattern, err := extractStringArg(args, "pattern")
+	if err != nil {
+		return err
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	if name, ok := getStringLike(args["name"]); ok && name != "" {
+		if _, err := filepath.Match(name, ""); err != nil {
+			return fmt.Errorf("invalid name pattern: %w", err)
+		}
+	}
+	return nil
+}
+
 func validateGrepArgs(args map[string]interface{}) error {
 	if _, err := extractStringArg(args, "pattern"); err != nil {
 		return err
@@ -3112,10 +4279,10 @@ func validateGrepArgs(args map[string]interface{}) error {
 }
 
 func validateTranslateArgs(args map[string]interface{}) error {
-	if _, err := extractStringArg(args, "from"); err != nil {
+	if _, err := translateSet(args); err != nil {
 		return err
 	}
-	if _, err := extractStringArg(args, "to"); err != nil {
+	if _, err := translateTarget(args); err != nil {
 		return err
 	}
 	if _, ok := getStringLike(args["path"]); ok {
@@ -3168,17 +4335,14 @@ func validateSeqArgs(args map[string]interface{}) error {
 	} else if !ok {
 		return fmt.Errorf("missing or invalid 'end' parameter")
 	}
-	if _, ok, err := getOptionalIntArg(args, "start"); err != nil {
+	if _, _, err := getOptionalIntArg(args, "start"); err != nil {
 		return err
-	} else if ok {
-		return nil
 	}
-	if _, ok, err := getOptionalIntArg(args, "step"); err != nil {
+	if _, _, err := getOptionalIntArg(args, "step"); err != nil {
 		return err
-	} else if ok {
-		return nil
 	}
-	return nil
+	_, _, err := seqFormatArgs(args)
+	return err
 }
 
 func validateChmodArgs(args map[string]interface{}) error {
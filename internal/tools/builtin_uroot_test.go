@@ -22,13 +22,16 @@ import (
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -54,6 +57,48 @@ func TestURootFileOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("cat numbered range", func(t *testing.T) {
+		registry := NewRegistry()
+		dir := makeTempDir(t)
+		path := writeTestFile(t, dir, "lines.txt", "one\ntwo\nthree\nfour\n")
+		rel := relPath(t, path)
+
+		result := executeTool(t, registry, "cat", map[string]interface{}{"path": rel, "number": true})
+		if result.Error != nil {
+			t.Fatalf("expected numbered cat success, got %v", result.Error)
+		}
+		if result.Result != "     1\tone\n     2\ttwo\n     3\tthree\n     4\tfour\n" {
+			t.Fatalf("unexpected numbered output: %q", result.Result)
+		}
+
+		result = executeTool(t, registry, "cat", map[string]interface{}{"path": rel, "number": true, "start": 2.0, "end": 3.0})
+		if result.Error != nil {
+			t.Fatalf("expected ranged cat success, got %v", result.Error)
+		}
+		if result.Result != "     2\ttwo\n     3\tthree\n" {
+			t.Fatalf("unexpected ranged output: %q", result.Result)
+		}
+
+		result = executeTool(t, registry, "cat", map[string]interface{}{"path": rel, "start": 3.0, "end": 99.0})
+		if result.Error != nil {
+			t.Fatalf("expected clamped range success, got %v", result.Error)
+		}
+		if result.Result != "three\nfour\n" {
+			t.Fatalf("unexpected unnumbered range output: %q", result.Result)
+		}
+
+		for name, args := range map[string]map[string]interface{}{
+			"start past end":   {"path": rel, "start": 9.0},
+			"end before start": {"path": rel, "start": 3.0, "end": 2.0},
+			"zero start":       {"path": rel, "start": 0.0},
+			"several paths":    {"paths": []string{rel, rel}, "start": 1.0},
+		} {
+			if result := executeTool(t, registry, "cat", args); result.Error == nil {
+				t.Fatalf("%s: expected an error", name)
+			}
+		}
+	})
+
 	t.Run("cp and mv", func(t *testing.T) {
 		registry := NewRegistry()
 		dir := makeTempDir(t)
@@ -125,6 +170,112 @@ func TestURootFileOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("touch reference", func(t *testing.T) {
+		registry := NewRegistry()
+		dir := makeTempDir(t)
+		reference := writeTestFile(t, dir, "reference.txt", "ref")
+		target := writeTestFile(t, dir, "target.txt", "target")
+		refTime := time.Date(2020, 5, 17, 8, 30, 0, 123456789, time.UTC)
+		if err := os.Chtimes(reference, refTime, refTime); err != nil {
+			t.Fatalf("failed to set reference time: %v", err)
+		}
+
+		result := executeTool(t, registry, "touch", map[string]interface{}{
+			"path":      relPath(t, target),
+			"reference": relPath(t, reference),
+			"datetime":  "2001-01-01T00:00:00Z",
+		})
+		if result.Error != nil {
+			t.Fatalf("expected touch success, got %v", result.Error)
+		}
+		refInfo, err := os.Stat(reference)
+		if err != nil {
+			t.Fatalf("stat reference: %v", err)
+		}
+		info, err := os.Stat(target)
+		if err != nil {
+			t.Fatalf("stat target: %v", err)
+		}
+		if !info.ModTime().Equal(refInfo.ModTime()) {
+			t.Fatalf("expected mtime %v, got %v", refInfo.ModTime(), info.ModTime())
+		}
+
+		missing := executeTool(t, registry, "touch", map[string]interface{}{
+			"path":      relPath(t, target),
+			"reference": relPath(t, filepath.Join(dir, "missing.txt")),
+		})
+		if missing.Error == nil || !strings.Contains(missing.Error.Error(), "does not exist") {
+			t.Fatalf("expected missing reference error, got %v", missing.Error)
+		}
+		if err := validateTouchArgs(context.Background(), map[string]interface{}{"path": "x", "reference": relPath(t, filepath.Join(dir, "missing.txt"))}); err == nil {
+			t.Fatal("expected validation to reject a missing reference")
+		}
+	})
+
+	t.Run("ls formats", func(t *testing.T) {
+		registry := NewRegistry()
+		dir := makeTempDir(t)
+		writeTestFile(t, dir, "file.txt", "hello")
+		writeTestFile(t, dir, ".hidden", "secret")
+		if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+
+		result := executeTool(t, registry, "ls", map[string]interface{}{
+			"path":   relPath(t, dir),
+			"format": "json",
+		})
+		if result.Error != nil {
+			t.Fatalf("expected ls success, got %v", result.Error)
+		}
+		var entries []map[string]interface{}
+		if err := json.Unmarshal([]byte(result.Result), &entries); err != nil {
+			t.Fatalf("expected JSON output, got %q: %v", result.Result, err)
+		}
+		if len(entries) != 2 {
+			t.Fatalf("expected 2 entries without hidden files, got %v", entries)
+		}
+		byName := map[string]map[string]interface{}{}
+		for _, entry := range entries {
+			for _, key := range []string{"name", "size", "mode", "mtime", "is_dir"} {
+				if _, ok := entry[key]; !ok {
+					t.Fatalf("entry %v missing %q", entry, key)
+				}
+			}
+			if _, err := time.Parse(time.RFC3339, entry["mtime"].(string)); err != nil {
+				t.Fatalf("expected RFC3339 mtime, got %v", entry["mtime"])
+			}
+			byName[entry["name"].(string)] = entry
+		}
+		file, ok := byName["file.txt"]
+		if !ok || file["is_dir"] != false || file["size"] != float64(5) || !strings.HasPrefix(file["mode"].(string), "-") {
+			t.Fatalf("unexpected file entry: %v", file)
+		}
+		sub, ok := byName["sub"]
+		if !ok || sub["is_dir"] != true || !strings.HasPrefix(sub["mode"].(string), "d") {
+			t.Fatalf("unexpected directory entry: %v", sub)
+		}
+
+		long := executeTool(t, registry, "ls", map[string]interface{}{
+			"path":   relPath(t, dir),
+			"format": "long",
+		})
+		if long.Error != nil {
+			t.Fatalf("expected ls long success, got %v", long.Error)
+		}
+		if !strings.Contains(long.Result, "file.txt") || strings.Contains(long.Result, ".hidden") {
+			t.Fatalf("unexpected long output: %q", long.Result)
+		}
+
+		invalid := executeTool(t, registry, "ls", map[string]interface{}{
+			"path":   relPath(t, dir),
+			"format": "xml",
+		})
+		if invalid.Error == nil || !strings.Contains(invalid.Error.Error(), "unsupported format") {
+			t.Fatalf("expected unsupported format error, got %v", invalid.Error)
+		}
+	})
+
 	t.Run("touch and truncate", func(t *testing.T) {
 		registry := NewRegistry()
 		dir := makeTempDir(t)
@@ -210,6 +361,61 @@ func TestURootFileOperationsValidation(t *testing.T) {
 	}
 }
 
+func TestURootConfirmSummary(t *testing.T) {
+	registry := NewRegistry()
+	dir := makeTempDir(t)
+	tree := filepath.Join(dir, "tree")
+	if err := os.MkdirAll(filepath.Join(tree, "sub"), 0o755); err != nil {
+		t.Fatalf("failed to create tree: %v", err)
+	}
+	writeTestFile(t, tree, "a.txt", "a")
+	writeTestFile(t, filepath.Join(tree, "sub"), "b.txt", "b")
+	single := writeTestFile(t, dir, "single.txt", "12345")
+	absTree, absSingle := mustAbs(t, tree), mustAbs(t, single)
+
+	summarize := func(name string, args map[string]interface{}) string {
+		t.Helper()
+		data, err := json.Marshal(args)
+		if err != nil {
+			t.Fatalf("failed to marshal args: %v", err)
+		}
+		return registry.ConfirmSummary(name, string(data))
+	}
+
+	rm := summarize("rm", map[string]interface{}{
+		"paths":     []string{relPath(t, tree), relPath(t, single)},
+		"recursive": true,
+	})
+	if !strings.Contains(rm, "rm will delete 3 files from 2 target(s):") {
+		t.Fatalf("unexpected rm summary: %q", rm)
+	}
+	if !strings.Contains(rm, absTree+" (directory, 2 files)") || !strings.Contains(rm, "\n  "+absSingle) {
+		t.Fatalf("expected resolved rm targets, got %q", rm)
+	}
+
+	mv := summarize("mv", map[string]interface{}{
+		"sources":     []string{relPath(t, single)},
+		"destination": relPath(t, tree),
+	})
+	if !strings.Contains(mv, absSingle+" -> "+filepath.Join(absTree, "single.txt")) {
+		t.Fatalf("unexpected mv summary: %q", mv)
+	}
+
+	chmod := summarize("chmod", map[string]interface{}{"path": relPath(t, single), "mode": "600"})
+	if !strings.Contains(chmod, "chmod will change "+absSingle) || !strings.HasSuffix(chmod, "to 600") {
+		t.Fatalf("unexpected chmod summary: %q", chmod)
+	}
+
+	truncate := summarize("truncate", map[string]interface{}{"path": relPath(t, single), "size": 0})
+	if !strings.Contains(truncate, "truncate will resize "+absSingle+" from 5 B to 0 B") {
+		t.Fatalf("unexpected truncate summary: %q", truncate)
+	}
+
+	if summary := summarize("cat", map[string]interface{}{"path": relPath(t, single)}); summary != "" {
+		t.Fatalf("expected no summary for non-destructive tool, got %q", summary)
+	}
+}
+
 func TestURootFileOperationsSizeLimit(t *testing.T) {
 	ConfigureLimits(Limits{
 		MaxFileSizeBytes:    4,
@@ -312,6 +518,134 @@ func TestURootDirectoryOperations(t *testing.T) {
 		}
 	})
 
+	t.Run("cd stays within work root", func(t *testing.T) {
+		dir := makeTempDir(t)
+		root, err := filepath.EvalSymlinks(mustAbs(t, dir))
+		if err != nil {
+			t.Fatalf("failed to resolve temp dir: %v", err)
+		}
+		sub := filepath.Join(root, "project")
+		if err := os.Mkdir(sub, 0o755); err != nil {
+			t.Fatalf("failed to create subdir: %v", err)
+		}
+		writeTestFile(t, root, "file.txt", "x")
+
+		t.Chdir(root)
+		previousRoot := workRoot
+		if err := ConfigureWorkRoot(""); err != nil {
+			t.Fatalf("failed to configure work root: %v", err)
+		}
+		t.Cleanup(func() {
+			workRootMu.Lock()
+			workRoot = previousRoot
+			workRootMu.Unlock()
+		})
+
+		t.Cleanup(func() { registry.SetWorkingDirectory("") })
+
+		result := executeTool(t, registry, "cd", map[string]interface{}{"path": "project"})
+		if result.Error != nil {
+			t.Fatalf("expected cd success, got %v", result.Error)
+		}
+		if result.Result != sub {
+			t.Fatalf("expected new directory %q, got %q", sub, result.Result)
+		}
+		if pwd := executeTool(t, registry, "pwd", map[string]interface{}{}); pwd.Result != sub {
+			t.Fatalf("expected subsequent tools to use %q, got %q", sub, pwd.Result)
+		}
+		if cwd, _ := os.Getwd(); cwd != root {
+			t.Fatalf("expected cd to leave the process working directory at %q, got %q", root, cwd)
+		}
+		if other := NewRegistry(); executeTool(t, other, "pwd", map[string]interface{}{}).Result != root {
+			t.Fatal("expected cd to move only the registry it ran in")
+		}
+
+		if result := executeTool(t, registry, "cd", map[string]interface{}{"path": ".."}); result.Error != nil || result.Result != root {
+			t.Fatalf("expected cd .. back to root, got %q, %v", result.Result, result.Error)
+		}
+		if result := executeTool(t, registry, "cd", map[string]interface{}{"path": ".."}); result.Error == nil {
+			t.Fatal("expected cd above the work root to fail")
+		}
+		if result := executeTool(t, registry, "cd", map[string]interface{}{"path": "file.txt"}); result.Error == nil {
+			t.Fatal("expected cd into a file to fail")
+		}
+
+		_, _ = registry.ChangeWorkingDirectory("project")
+		if result := executeTool(t, registry, "cd", map[string]interface{}{}); result.Error != nil || result.Result != root {
+			t.Fatalf("expected cd without path to return to root, got %q, %v", result.Result, result.Error)
+		}
+	})
+
+	t.Run("base dir scopes a call", func(t *testing.T) {
+		dir := makeTempDir(t)
+		root, err := filepath.EvalSymlinks(mustAbs(t, dir))
+		if err != nil {
+			t.Fatalf("failed to resolve temp dir: %v", err)
+		}
+		subs := []string{filepath.Join(root, "a"), filepath.Join(root, "b")}
+		for _, sub := range subs {
+			if err := os.Mkdir(sub, 0o755); err != nil {
+				t.Fatalf("failed to create subdir: %v", err)
+			}
+			writeTestFile(t, sub, "name.txt", filepath.Base(sub))
+		}
+		writeTestFile(t, root, "file.txt", "x")
+
+		t.Chdir(root)
+		previousRoot := workRoot
+		if err := ConfigureWorkRoot(""); err != nil {
+			t.Fatalf("failed to configure work root: %v", err)
+		}
+		t.Cleanup(func() {
+			workRootMu.Lock()
+			workRoot = previousRoot
+			workRootMu.Unlock()
+		})
+
+		scoped := func(name, base string, args map[string]interface{}) *ToolResult {
+			return registry.ExecuteWithOptions(name, args, ExecuteOptions{Force: true, BaseDir: base})
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan string, 2*len(subs))
+		for _, sub := range subs {
+			wg.Add(1)
+			go func(base string) {
+				defer wg.Done()
+				name := filepath.Base(base)
+				if got := scoped("pwd", name, map[string]interface{}{}); got.Error != nil || got.Result != base {
+					errs <- fmt.Sprintf("pwd in %s: %q, %v", name, got.Result, got.Error)
+				}
+				if got := scoped("cat", name, map[string]interface{}{"path": "name.txt"}); got.Error != nil || strings.TrimSpace(got.Result) != name {
+					errs <- fmt.Sprintf("cat in %s: %q, %v", name, got.Result, got.Error)
+				}
+			}(sub)
+		}
+		wg.Wait()
+		close(errs)
+		for msg := range errs {
+			t.Error(msg)
+		}
+
+		if result := scoped("create_file", "a", map[string]interface{}{"path": "new.txt", "content": "scoped\n"}); result.Error != nil {
+			t.Fatalf("expected scoped create_file success, got %v", result.Error)
+		}
+		assertFileContent(t, filepath.Join(subs[0], "new.txt"), "scoped\n")
+		if cwd, _ := os.Getwd(); cwd != root {
+			t.Fatalf("expected working directory to stay %q, got %q", root, cwd)
+		}
+
+		if result := scoped("cat", "a", map[string]interface{}{"path": "../file.txt"}); !errors.Is(result.Error, ErrPathEscapesWorkdir) {
+			t.Fatalf("expected a path above the base dir to be rejected, got %q, %v", result.Result, result.Error)
+		}
+		if result := scoped("pwd", "..", map[string]interface{}{}); result.Error == nil {
+			t.Fatal("expected a base dir above the work root to be rejected")
+		}
+		if result := scoped("pwd", "file.txt", map[string]interface{}{}); result.Error == nil {
+			t.Fatal("expected a file as base dir to be rejected")
+		}
+	})
+
 	t.Run("dirname and basename", func(t *testing.T) {
 		path := filepath.Join("a", "b", "c.txt")
 		dirResult := executeTool(t, registry, "dirname", map[string]interface{}{"path": path})
@@ -500,6 +834,27 @@ func TestURootTextProcessing(t *testing.T) {
 			t.Fatalf("unexpected wc output: %q", wcResult.Result)
 		}
 
+		wcDir := filepath.Join(dir, "wc")
+		if err := os.MkdirAll(filepath.Join(wcDir, "sub"), 0o755); err != nil {
+			t.Fatalf("failed to create wc dir: %v", err)
+		}
+		writeTestFile(t, wcDir, "a.txt", "one two\nthree\n")
+		writeTestFile(t, filepath.Join(wcDir, "sub"), "b.txt", "four\n")
+		writeTestFile(t, wcDir, "blob.bin", "\x00\x01\x02binary")
+		rel := relPath(t, wcDir)
+		wcResult = executeTool(t, registry, "wc", map[string]interface{}{"path": rel, "recursive": true})
+		if wcResult.Error != nil {
+			t.Fatalf("expected wc over a directory to succeed, got %v", wcResult.Error)
+		}
+		want := "2 3 14 " + filepath.Join(rel, "a.txt") + "\n1 1 5 " + filepath.Join(rel, "sub", "b.txt") + "\n3 4 19 total"
+		if wcResult.Result != want {
+			t.Fatalf("unexpected wc directory output:\n%s\nwant:\n%s", wcResult.Result, want)
+		}
+		wcResult = executeTool(t, registry, "wc", map[string]interface{}{"path": rel})
+		if wcResult.Error != nil || wcResult.Result != "2 3 14 "+filepath.Join(rel, "a.txt") {
+			t.Fatalf("expected only the top-level text file without recursive, got %q (%v)", wcResult.Result, wcResult.Error)
+		}
+
 		trResult := executeTool(t, registry, "tr", map[string]interface{}{
 			"from":  "a",
 			"to":    "o",
@@ -513,6 +868,33 @@ func TestURootTextProcessing(t *testing.T) {
 		}
 	})
 
+	t.Run("tr delete and squeeze", func(t *testing.T) {
+		cases := []struct {
+			name string
+			args map[string]interface{}
+			want string
+		}{
+			{"delete", map[string]interface{}{"from": "aeiou", "delete": true, "input": "education"}, "dctn"},
+			{"squeeze from", map[string]interface{}{"from": " ", "squeeze": true, "input": "a   b  c"}, "a b c"},
+			{"squeeze mapped", map[string]interface{}{"from": "ab", "to": "xx", "squeeze": true, "input": "aabbc"}, "xc"},
+			{"delete and squeeze", map[string]interface{}{"from": "-", "to": "o", "delete": true, "squeeze": true, "input": "fo-o-o-d"}, "fod"},
+		}
+		for _, tc := range cases {
+			result := executeTool(t, registry, "tr", tc.args)
+			if result.Error != nil {
+				t.Fatalf("%s: expected tr success, got %v", tc.name, result.Error)
+			}
+			if result.Result != tc.want {
+				t.Fatalf("%s: expected %q, got %q", tc.name, tc.want, result.Result)
+			}
+		}
+
+		missing := executeTool(t, registry, "tr", map[string]interface{}{"from": "a", "input": "abc"})
+		if missing.Error == nil {
+			t.Fatalf("expected tr without to, delete or squeeze to fail")
+		}
+	})
+
 	t.Run("tee", func(t *testing.T) {
 		teeTarget := filepath.Join(dir, "tee.txt")
 		result := executeTool(t, registry, "tee", map[string]interface{}{
@@ -539,6 +921,26 @@ func TestURootTextProcessing(t *testing.T) {
 		if strings.TrimSpace(commResult.Result) == "" {
 			t.Fatalf("unexpected comm output: %q", commResult.Result)
 		}
+
+		common := executeTool(t, registry, "comm", map[string]interface{}{
+			"path1":     relPath(t, textPath),
+			"path2":     relPath(t, otherPath),
+			"suppress1": true,
+			"suppress2": true,
+		})
+		if common.Error != nil || common.Result != "beta" {
+			t.Fatalf("expected only the common line, got %q (%v)", common.Result, common.Error)
+		}
+
+		unique := executeTool(t, registry, "comm", map[string]interface{}{
+			"path1":     relPath(t, textPath),
+			"path2":     relPath(t, otherPath),
+			"suppress2": true,
+			"suppress3": true,
+		})
+		if unique.Error != nil || unique.Result != "alpha\nbeta\ngamma" {
+			t.Fatalf("expected only lines unique to path1, got %q (%v)", unique.Result, unique.Error)
+		}
 	})
 
 	t.Run("strings", func(t *testing.T) {
@@ -568,6 +970,38 @@ func TestURootTextProcessing(t *testing.T) {
 	})
 }
 
+func TestURootTextEncoding(t *testing.T) {
+	registry := NewRegistry()
+	dir := makeTempDir(t)
+	bomPath := writeTestFile(t, dir, "bom.txt", "\ufefffirst\nsecond\n")
+	latin1Path := writeTestFile(t, dir, "latin1.txt", "caf\xe9\n")
+	t.Cleanup(func() { ConfigureLatin1Fallback(false) })
+
+	head := executeTool(t, registry, "head", map[string]interface{}{"path": relPath(t, bomPath), "lines": 1})
+	if head.Error != nil || head.Result != "first" {
+		t.Fatalf("expected BOM to be stripped, got %q (%v)", head.Result, head.Error)
+	}
+	grep := executeTool(t, registry, "grep", map[string]interface{}{"pattern": "^first$", "paths": []interface{}{relPath(t, bomPath)}})
+	if grep.Error != nil || grep.Result != "first" {
+		t.Fatalf("expected grep to match after the BOM, got %q (%v)", grep.Result, grep.Error)
+	}
+
+	invalid := executeTool(t, registry, "read_file", map[string]interface{}{"path": relPath(t, latin1Path)})
+	if !errors.Is(invalid.Error, ErrInvalidUTF8) {
+		t.Fatalf("expected invalid UTF-8 error, got %v", invalid.Error)
+	}
+
+	ConfigureLatin1Fallback(true)
+	decoded := executeTool(t, registry, "read_file", map[string]interface{}{"path": relPath(t, latin1Path)})
+	if decoded.Error != nil || !strings.Contains(decoded.Result, "café") {
+		t.Fatalf("expected latin-1 fallback, got %q (%v)", decoded.Result, decoded.Error)
+	}
+	binary := writeTestFile(t, dir, "data.bin", "\x00\xff\x01")
+	if result := executeTool(t, registry, "read_file", map[string]interface{}{"path": relPath(t, binary)}); !errors.Is(result.Error, ErrBinaryContent) {
+		t.Fatalf("expected binary file to stay rejected, got %v", result.Error)
+	}
+}
+
 func TestURootFileViewingAnalysis(t *testing.T) {
 	registry := NewRegistry()
 	dir := makeTempDir(t)
@@ -577,6 +1011,73 @@ func TestURootFileViewingAnalysis(t *testing.T) {
 	diffPath := writeTestFile(t, dir, "diff.txt", "hash you")
 	base64Path := writeTestFile(t, dir, "encoded.txt", base64.StdEncoding.EncodeToString([]byte("data")))
 
+	t.Run("view_code", func(t *testing.T) {
+		var source strings.Builder
+		for i := 1; i <= 12; i++ {
+			source.WriteString("line " + strconv.Itoa(i) + "\n")
+		}
+		path := writeTestFile(t, dir, "view.go", source.String())
+
+		full := executeTool(t, registry, "view_code", map[string]interface{}{"path": relPath(t, path)})
+		if full.Error != nil {
+			t.Fatalf("expected view_code success, got %v", full.Error)
+		}
+		lines := strings.Split(full.Result, "\n")
+		if len(lines) != 13 || !strings.HasSuffix(lines[0], "(go): lines 1-12 of 12") {
+			t.Fatalf("unexpected view_code header or length: %q", full.Result)
+		}
+		if lines[1] != " 1 | line 1" || lines[12] != "12 | line 12" {
+			t.Fatalf("unexpected view_code numbering: %q", full.Result)
+		}
+
+		ranged := executeTool(t, registry, "view_code", map[string]interface{}{"path": relPath(t, path), "start": 3, "end": 4})
+		if ranged.Error != nil {
+			t.Fatalf("expected view_code range success, got %v", ranged.Error)
+		}
+		if !strings.HasSuffix(ranged.Result, ": lines 3-4 of 12\n3 | line 3\n4 | line 4") {
+			t.Fatalf("unexpected view_code range output: %q", ranged.Result)
+		}
+
+		past := executeTool(t, registry, "view_code", map[string]interface{}{"path": relPath(t, path), "start": 20})
+		if past.Error == nil {
+			t.Fatalf("expected start past the end to fail")
+		}
+		binary := writeTestFile(t, dir, "view.bin", "\x00\x01\x02")
+		if result := executeTool(t, registry, "view_code", map[string]interface{}{"path": relPath(t, binary)}); !errors.Is(result.Error, ErrBinaryContent) {
+			t.Fatalf("expected binary file to be rejected, got %v", result.Error)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		path := writeTestFile(t, dir, "data.csv", "name,city\nada,\"London, UK\"\nlinus,Helsinki\nrob,Sydney\n")
+		result := executeTool(t, registry, "table", map[string]interface{}{"path": relPath(t, path), "max_rows": 2})
+		if result.Error != nil {
+			t.Fatalf("expected table success, got %v", result.Error)
+		}
+		want := "+-------+------------+\n" +
+			"| name  | city       |\n" +
+			"+-------+------------+\n" +
+			"| ada   | London, UK |\n" +
+			"| linus | Helsinki   |\n" +
+			"+-------+------------+\n" +
+			"(showing 2 of 3 rows)"
+		if result.Result != want {
+			t.Fatalf("unexpected table output:\n%s", result.Result)
+		}
+
+		inline := executeTool(t, registry, "table", map[string]interface{}{"content": "a,b\n1,2\n"})
+		if inline.Error != nil || !strings.Contains(inline.Result, "| 1 | 2 |") {
+			t.Fatalf("unexpected inline table output: %q (%v)", inline.Result, inline.Error)
+		}
+		malformed := executeTool(t, registry, "table", map[string]interface{}{"content": "a,\"b\n1,2\n"})
+		if malformed.Error == nil || !strings.Contains(malformed.Error.Error(), "malformed CSV") {
+			t.Fatalf("expected malformed CSV error, got %v", malformed.Error)
+		}
+		if both := executeTool(t, registry, "table", map[string]interface{}{"path": relPath(t, path), "content": "a\n"}); both.Error == nil {
+			t.Fatalf("expected path and content together to fail")
+		}
+	})
+
 	t.Run("hexdump", func(t *testing.T) {
 		result := executeTool(t, registry, "hexdump", map[string]interface{}{
 			"path":      relPath(t, filePath),
@@ -737,6 +1238,45 @@ func TestURootSystemInformation(t *testing.T) {
 		}
 	})
 
+	t.Run("du per_file lists largest first", func(t *testing.T) {
+		root := makeTempDir(t)
+		writeTestFile(t, root, "big.bin", strings.Repeat("b", 3000))
+		writeTestFile(t, root, "small.txt", "tiny")
+		if err := os.Mkdir(filepath.Join(root, "sub"), 0o755); err != nil {
+			t.Fatalf("failed to create subdir: %v", err)
+		}
+		writeTestFile(t, filepath.Join(root, "sub"), "mid.txt", strings.Repeat("m", 500))
+
+		result := executeTool(t, registry, "du", map[string]interface{}{
+			"path":     relPath(t, root),
+			"per_file": true,
+		})
+		if result.Error != nil {
+			t.Fatalf("expected du per_file success, got %v", result.Error)
+		}
+		lines := strings.Split(result.Result, "\n")
+		if len(lines) != 6 {
+			t.Fatalf("expected header plus 4 entries, got %q", result.Result)
+		}
+		if !strings.HasSuffix(lines[2], "big.bin") {
+			t.Fatalf("expected largest file first, got %q", lines[2])
+		}
+		if !strings.HasSuffix(lines[3], "sub"+string(filepath.Separator)) || !strings.HasSuffix(lines[5], "small.txt") {
+			t.Fatalf("unexpected ordering: %q", result.Result)
+		}
+
+		topResult := executeTool(t, registry, "du", map[string]interface{}{
+			"path": relPath(t, root),
+			"top":  1,
+		})
+		if topResult.Error != nil {
+			t.Fatalf("expected du top success, got %v", topResult.Error)
+		}
+		if !strings.Contains(topResult.Result, "Largest 1 of 4 entries:") || !strings.HasSuffix(topResult.Result, "big.bin") {
+			t.Fatalf("unexpected du top output: %q", topResult.Result)
+		}
+	})
+
 	t.Run("ps and pidof", func(t *testing.T) {
 		psResult := executeTool(t, registry, "ps", map[string]interface{}{
 			"limit": 5,
@@ -800,6 +1340,51 @@ func TestURootMiscellaneousSafe(t *testing.T) {
 		if strings.TrimSpace(seqResult.Result) != "1\n2\n3" {
 			t.Fatalf("unexpected seq output: %q", seqResult.Result)
 		}
+
+		padded := executeTool(t, registry, "seq", map[string]interface{}{
+			"start":     8,
+			"end":       11,
+			"format":    "%03d",
+			"separator": ", ",
+		})
+		if padded.Error != nil || padded.Result != "008, 009, 010, 011" {
+			t.Fatalf("unexpected padded seq output: %q (%v)", padded.Result, padded.Error)
+		}
+		for _, format := range []string{"%s", "%d-%d", "%f"} {
+			if result := executeTool(t, registry, "seq", map[string]interface{}{"end": 3, "format": format}); result.Error == nil {
+				t.Fatalf("expected format %q to be rejected", format)
+			}
+		}
+		if result := executeTool(t, registry, "seq", map[string]interface{}{"end": 3, "separator": strings.Repeat("-", 17)}); result.Error == nil {
+			t.Fatal("expected a long separator to be rejected")
+		}
+	})
+
+	t.Run("printf", func(t *testing.T) {
+		result := executeTool(t, registry, "printf", map[string]interface{}{
+			"format": "%-6s|%05d|%.2f|%x|%v|%q|100%%",
+			"args":   []interface{}{"name", 42, 3.14159, 255, true, "quoted"},
+		})
+		if result.Error != nil {
+			t.Fatalf("expected printf success, got %v", result.Error)
+		}
+		if result.Result != `name  |00042|3.14|ff|true|"quoted"|100%` {
+			t.Fatalf("unexpected printf output: %q", result.Result)
+		}
+
+		for name, args := range map[string]map[string]interface{}{
+			"count mismatch":   {"format": "%s and %s", "args": []interface{}{"one"}},
+			"unsupported verb": {"format": "%T", "args": []interface{}{"x"}},
+			"arg index":        {"format": "%[1]s", "args": []interface{}{"x"}},
+			"star width":       {"format": "%*d", "args": []interface{}{3, 4}},
+			"huge width":       {"format": "%99999s", "args": []interface{}{"x"}},
+			"float for %d":     {"format": "%d", "args": []interface{}{1.5}},
+			"string for %f":    {"format": "%f", "args": []interface{}{"x"}},
+		} {
+			if result := executeTool(t, registry, "printf", args); result.Error == nil {
+				t.Errorf("%s: expected printf error, got %q", name, result.Result)
+			}
+		}
 	})
 
 	t.Run("printenv and tty", func(t *testing.T) {
@@ -866,6 +1451,87 @@ func TestURootMiscellaneousSafe(t *testing.T) {
 		}
 	})
 
+	t.Run("search combines find and grep", func(t *testing.T) {
+		root := filepath.Join(dir, "search")
+		if err := os.MkdirAll(filepath.Join(root, "sub", "deep"), 0o755); err != nil {
+			t.Fatalf("failed to create tree: %v", err)
+		}
+		top := writeTestFile(t, root, "top.go", "package top\n// TODO: top\n")
+		nested := writeTestFile(t, filepath.Join(root, "sub"), "nested.go", "package sub\nfunc x() {}\n// TODO: nested\n")
+		deep := writeTestFile(t, filepath.Join(root, "sub", "deep"), "deep.go", "// TODO: deep\n")
+		notes := writeTestFile(t, root, "notes.txt", "TODO: not a go file\n")
+		binary := writeTestFile(t, root, "blob.go", "TODO\x00\x01\x02")
+
+		result := executeTool(t, registry, "search", map[string]interface{}{
+			"path":    relPath(t, root),
+			"name":    "*.go",
+			"pattern": "TODO",
+		})
+		if result.Error != nil {
+			t.Fatalf("expected search success, got %v", result.Error)
+		}
+		for _, want := range []string{top + ":2:// TODO: top", nested + ":3:// TODO: nested", deep + ":1:// TODO: deep"} {
+			if !strings.Contains(result.Result, want) {
+				t.Fatalf("expected %q in search output, got %q", want, result.Result)
+			}
+		}
+		if strings.Contains(result.Result, notes) || strings.Contains(result.Result, binary) {
+			t.Fatalf("expected non-matching names and binaries to be skipped, got %q", result.Result)
+		}
+
+		shallow := executeTool(t, registry, "search", map[string]interface{}{
+			"path":        relPath(t, root),
+			"name":        "*.go",
+			"pattern":     "todo",
+			"max_depth":   1,
+			"ignore_case": true,
+		})
+		if shallow.Error != nil {
+			t.Fatalf("expected search success, got %v", shallow.Error)
+		}
+		if !strings.Contains(shallow.Result, top) || strings.Contains(shallow.Result, nested) {
+			t.Fatalf("expected max_depth to limit traversal, got %q", shallow.Result)
+		}
+
+		invalid := executeTool(t, registry, "search", map[string]interface{}{
+			"path":    relPath(t, root),
+			"pattern": "(",
+		})
+		if invalid.Error == nil {
+			t.Fatalf("expected invalid pattern to fail")
+		}
+	})
+
+	t.Run("search skips unreadable files", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can read files without permission")
+		}
+		root := filepath.Join(dir, "search_unreadable")
+		if err := os.MkdirAll(root, 0o755); err != nil {
+			t.Fatalf("failed to create tree: %v", err)
+		}
+		locked := writeTestFile(t, root, "a_locked.txt", "TODO: hidden\n")
+		open := writeTestFile(t, root, "b_open.txt", "TODO: visible\n")
+		if err := os.Chmod(locked, 0); err != nil {
+			t.Fatalf("failed to lock file: %v", err)
+		}
+		t.Cleanup(func() { _ = os.Chmod(locked, 0o644) })
+
+		result := executeTool(t, registry, "search", map[string]interface{}{
+			"path":    relPath(t, root),
+			"pattern": "TODO",
+		})
+		if result.Error != nil {
+			t.Fatalf("expected search to skip the unreadable file, got %v", result.Error)
+		}
+		if !strings.Contains(result.Result, open+":1:TODO: visible") {
+			t.Fatalf("expected the readable file to be searched, got %q", result.Result)
+		}
+		if !strings.Contains(result.Result, "skipped 1 unreadable files: ") || !strings.Contains(result.Result, locked+")") {
+			t.Fatalf("expected the unreadable file to be reported, got %q", result.Result)
+		}
+	})
+
 	t.Run("find and chmod", func(t *testing.T) {
 		target := writeTestFile(t, dir, "find.txt", "find me")
 		findResult := executeTool(t, registry, "find", map[string]interface{}{
@@ -895,6 +1561,64 @@ func TestURootMiscellaneousSafe(t *testing.T) {
 		}
 	})
 
+	t.Run("find age and size filters", func(t *testing.T) {
+		findDir := filepath.Join(dir, "find-filters")
+		if err := os.Mkdir(findDir, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		oldFile := writeTestFile(t, findDir, "old.txt", "old")
+		writeTestFile(t, findDir, "new.txt", strings.Repeat("n", 2048))
+		past := time.Now().Add(-72 * time.Hour)
+		if err := os.Chtimes(oldFile, past, past); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+
+		find := func(args map[string]interface{}) string {
+			t.Helper()
+			args["path"] = relPath(t, findDir)
+			result := executeTool(t, registry, "find", args)
+			if result.Error != nil {
+				t.Fatalf("expected find success for %v, got %v", args, result.Error)
+			}
+			return result.Result
+		}
+
+		recent := find(map[string]interface{}{"newer_than": "24h", "type": "file"})
+		if !strings.Contains(recent, "new.txt") || strings.Contains(recent, "old.txt") {
+			t.Fatalf("unexpected newer_than output: %q", recent)
+		}
+		stale := find(map[string]interface{}{"older_than": "2d"})
+		if !strings.Contains(stale, "old.txt") || strings.Contains(stale, "new.txt") {
+			t.Fatalf("unexpected older_than output: %q", stale)
+		}
+		since := find(map[string]interface{}{"newer_than": past.Add(-time.Hour).UTC().Format(time.RFC3339), "type": "file"})
+		if !strings.Contains(since, "old.txt") || !strings.Contains(since, "new.txt") {
+			t.Fatalf("unexpected RFC3339 newer_than output: %q", since)
+		}
+
+		large := find(map[string]interface{}{"min_size": 1024})
+		if !strings.Contains(large, "new.txt") || strings.Contains(large, "old.txt") || strings.Contains(large, "find-filters\n") {
+			t.Fatalf("unexpected min_size output: %q", large)
+		}
+		small := find(map[string]interface{}{"max_size": 1024})
+		if !strings.Contains(small, "old.txt") || strings.Contains(small, "new.txt") {
+			t.Fatalf("unexpected max_size output: %q", small)
+		}
+
+		for _, args := range []map[string]interface{}{
+			{"newer_than": "yesterday"},
+			{"older_than": "-5h"},
+			{"min_size": -1},
+			{"min_size": 10, "max_size": 5},
+			{"newer_than": "1h", "older_than": "2h"},
+		} {
+			args["path"] = relPath(t, findDir)
+			if result := executeTool(t, registry, "find", args); result.Error == nil {
+				t.Fatalf("expected find to reject %v", args)
+			}
+		}
+	})
+
 	t.Run("date", func(t *testing.T) {
 		result := executeTool(t, registry, "date", map[string]interface{}{
 			"format": "unix",
@@ -906,6 +1630,37 @@ func TestURootMiscellaneousSafe(t *testing.T) {
 			t.Fatalf("unexpected date output: %q", result.Result)
 		}
 	})
+
+	t.Run("date arithmetic", func(t *testing.T) {
+		for _, tc := range []struct {
+			args map[string]interface{}
+			want string
+		}{
+			{map[string]interface{}{"input": "2025-03-01T10:00:00Z", "add": "48h", "timezone": "UTC"}, "2025-03-03T10:00:00Z"},
+			{map[string]interface{}{"input": "2025-03-01T10:00:00Z", "subtract": "7d", "timezone": "UTC"}, "2025-02-22T10:00:00Z"},
+			{map[string]interface{}{"input": "2025-01-15T12:00:00Z", "timezone": "Asia/Tokyo"}, "2025-01-15T21:00:00+09:00"},
+			{map[string]interface{}{"input": "2025-07-01", "timezone": "Europe/Amsterdam"}, "2025-07-01T00:00:00+02:00"},
+			{map[string]interface{}{"input": "86400", "timezone": "UTC", "format": "2006-01-02"}, "1970-01-02"},
+			{map[string]interface{}{"input": "2025-03-01T10:00:00+01:00", "add": "90m", "format": "unix"}, "1740825000"},
+		} {
+			result := executeTool(t, registry, "date", tc.args)
+			if result.Error != nil {
+				t.Fatalf("date %v failed: %v", tc.args, result.Error)
+			}
+			if result.Result != tc.want {
+				t.Errorf("date %v = %q, want %q", tc.args, result.Result, tc.want)
+			}
+		}
+		for _, args := range []map[string]interface{}{
+			{"input": "yesterday"},
+			{"add": "two days"},
+			{"timezone": "Mars/Olympus"},
+		} {
+			if result := executeTool(t, registry, "date", args); result.Error == nil {
+				t.Errorf("expected date to reject %v", args)
+			}
+		}
+	})
 }
 
 func makeTempDir(t *testing.T) string {
@@ -920,6 +1675,15 @@ func makeTempDir(t *testing.T) string {
 	return dir
 }
 
+func mustAbs(t *testing.T, path string) string {
+	t.Helper()
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		t.Fatalf("failed to resolve absolute path: %v", err)
+	}
+	return abs
+}
+
 func writeTestFile(t *testing.T, dir, name, content string) string {
 	t.Helper()
 	path := filepath.Join(dir, name)
@@ -0,0 +1,200 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// confirmSummaryMaxTargets caps how many targets a summary lists.
+	confirmSummaryMaxTargets = 20
+	// confirmSummaryWalkLimit caps how many files are counted under a directory.
+	confirmSummaryWalkLimit = 10000
+)
+
+var errSummaryWalkLimit = errors.New("summary walk limit reached")
+
+// summarizeRemove lists the resolved rm targets and how many files they hold.
+func summarizeRemove(ctx context.Context, args map[string]interface{}) string {
+	paths, err := extractPaths(args, "paths", "path")
+	if err != nil {
+		return ""
+	}
+	resolved, err := resolveToolPaths(ctx, paths)
+	if err != nil {
+		return ""
+	}
+	recursive := getBoolArg(args, "recursive")
+
+	lines := make([]string, 0, len(resolved))
+	total := 0
+	capped := false
+	for _, path := range resolved {
+		info, err := os.Lstat(path)
+		switch {
+		case err != nil:
+			lines = append(lines, fmt.Sprintf("%s (missing)", path))
+		case info.IsDir() && recursive:
+			count, hitLimit := countFiles(path, confirmSummaryWalkLimit-total)
+			total += count
+			capped = capped || hitLimit
+			lines = append(lines, fmt.Sprintf("%s (directory, %s)", path, pluralFiles(count, hitLimit)))
+		case info.IsDir():
+			lines = append(lines, fmt.Sprintf("%s (directory, not recursive)", path))
+		default:
+			total++
+			lines = append(lines, path)
+		}
+	}
+	header := fmt.Sprintf("rm will delete %s from %d target(s):", pluralFiles(total, capped), len(resolved))
+	return formatSummary(header, lines)
+}
+
+// summarizeMove lists each resolved source and where it ends up.
+func summarizeMove(ctx context.Context, args map[string]interface{}) string {
+	sources, err := extractStringSliceArg(args, "sources")
+	if err != nil {
+		return ""
+	}
+	dest, err := extractStringArg(args, "destination")
+	if err != nil {
+		return ""
+	}
+	resolvedSources, err := resolveToolPaths(ctx, sources)
+	if err != nil {
+		return ""
+	}
+	resolvedDest, err := resolveToolPath(ctx, dest)
+	if err != nil {
+		return ""
+	}
+	destIsDir := false
+	if info, err := os.Stat(resolvedDest); err == nil && info.IsDir() {
+		destIsDir = true
+	}
+
+	lines := make([]string, 0, len(resolvedSources))
+	for _, src := range resolvedSources {
+		target := resolvedDest
+		if destIsDir {
+			target = filepath.Join(resolvedDest, filepath.Base(src))
+		}
+		line := fmt.Sprintf("%s -> %s", src, target)
+		if _, err := os.Lstat(target); err == nil {
+			line += " (overwrites existing)"
+		}
+		lines = append(lines, line)
+	}
+	header := fmt.Sprintf("mv will move %d source(s):", len(resolvedSources))
+	return formatSummary(header, lines)
+}
+
+// summarizeChmod shows the resolved target with its current and new mode.
+func summarizeChmod(ctx context.Context, args map[string]interface{}) string {
+	path, err := extractPathArg(args)
+	if err != nil {
+		return ""
+	}
+	mode, err := extractStringArg(args, "mode")
+	if err != nil {
+		return ""
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return ""
+	}
+	current := "missing"
+	if info, err := os.Stat(resolved); err == nil {
+		current = fmt.Sprintf("%04o", info.Mode().Perm())
+	}
+	return fmt.Sprintf("chmod will change %s from %s to %s", resolved, current, mode)
+}
+
+// summarizeTruncate shows the resolved target with its current and new size.
+func summarizeTruncate(ctx context.Context, args map[string]interface{}) string {
+	path, err := extractPathArg(args)
+	if err != nil {
+		return ""
+	}
+	size, err := extractIntArg(args, "size", -1)
+	if err != nil || size < 0 {
+		return ""
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		if getBoolArg(args, "no_create") {
+			return fmt.Sprintf("truncate will skip missing %s", resolved)
+		}
+		return fmt.Sprintf("truncate will create %s with %s", resolved, formatBytes(int64(size)))
+	}
+	return fmt.Sprintf("truncate will resize %s from %s to %s", resolved, formatBytes(info.Size()), formatBytes(int64(size)))
+}
+
+// countFiles counts non-directory entries under root, stopping at limit.
+func countFiles(root string, limit int) (int, bool) {
+	count := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if count >= limit {
+			return errSummaryWalkLimit
+		}
+		count++
+		return nil
+	})
+	return count, errors.Is(err, errSummaryWalkLimit)
+}
+
+func pluralFiles(count int, capped bool) string {
+	suffix := ""
+	if capped {
+		suffix = "+"
+	}
+	if count == 1 && !capped {
+		return "1 file"
+	}
+	return fmt.Sprintf("%d%s files", count, suffix)
+}
+
+func formatSummary(header string, lines []string) string {
+	var b strings.Builder
+	b.WriteString(header)
+	for i, line := range lines {
+		if i == confirmSummaryMaxTargets {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(lines)-i)
+			break
+		}
+		b.WriteString("\n  ")
+		b.WriteString(line)
+	}
+	return b.String()
+}
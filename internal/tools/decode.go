@@ -0,0 +1,137 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+)
+
+type decodeArgs struct {
+	Format string `json:"format" jsonschema:"description=Format to decode: url / html / jwt or json,enum=url,enum=html,enum=jwt,enum=json"`
+	Input  string `json:"input,omitempty" jsonschema:"description=Inline text to decode (if path not provided)"`
+	Path   string `json:"path,omitempty" jsonschema:"description=File holding the text to decode"`
+}
+
+// decoders maps each decode format to its implementation.
+var decoders = map[string]func(string) (string, error){
+	"url":  url.QueryUnescape,
+	"html": decodeHTML,
+	"jwt":  decodeJWT,
+	"json": indentJSON,
+}
+
+// decodeTool unescapes or pretty-prints text given inline or read from a file,
+// so the model does not have to decode it by hand.
+func decodeTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	if err := validateDecodeArgs(args); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[decodeArgs](args)
+	if err != nil {
+		return "", err
+	}
+
+	input := parsed.Input
+	if parsed.Path != "" {
+		resolved, err := resolveToolPath(ctx, parsed.Path)
+		if err != nil {
+			return "", err
+		}
+		data, err := readFileLimited(resolved, false)
+		if err != nil {
+			return "", err
+		}
+		input = string(data)
+	}
+
+	output, err := decoders[parsed.Format](strings.TrimSpace(input))
+	if err != nil {
+		return "", fmt.Errorf("invalid %s input: %w", parsed.Format, err)
+	}
+	if !isTextContent([]byte(output)) {
+		return "", fmt.Errorf("decoded output %w", ErrBinaryContent)
+	}
+	return output, nil
+}
+
+func validateDecodeArgs(args map[string]interface{}) error {
+	format, err := extractStringArg(args, "format")
+	if err != nil {
+		return err
+	}
+	if _, ok := decoders[format]; !ok {
+		return fmt.Errorf("unsupported format %q, expected url, html, jwt or json", format)
+	}
+	_, hasPath := getStringLike(args["path"])
+	input, hasInput := getStringLike(args["input"])
+	if hasPath == hasInput {
+		return fmt.Errorf("provide exactly one of 'path' or 'input'")
+	}
+	if limit := getLimits().MaxFileSizeBytes; limit > 0 && int64(len(input)) > limit {
+		return fmt.Errorf("input %w of %d bytes", ErrFileTooLarge, limit)
+	}
+	return nil
+}
+
+func decodeHTML(s string) (string, error) {
+	return html.UnescapeString(s), nil
+}
+
+func indentJSON(s string) (string, error) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(s), "", "  "); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// decodeJWT shows the header and payload of a JSON Web Token. The signature
+// is not verified.
+func decodeJWT(token string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(token, "Bearer "), ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("expected 3 dot-separated parts, got %d", len(parts))
+	}
+	var b strings.Builder
+	for i, name := range []string{"header", "payload"} {
+		raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[i], "="))
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		pretty, err := indentJSON(string(raw))
+		if err != nil {
+			return "", fmt.Errorf("%s is not JSON: %w", name, err)
+		}
+		fmt.Fprintf(&b, "%s:\n%s\n", name, pretty)
+	}
+	if parts[2] == "" {
+		b.WriteString("signature: none")
+	} else {
+		b.WriteString("signature: present (not verified)")
+	}
+	return b.String(), nil
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	apperrors "promptline/internal/errors"
+	"promptline/internal/paths"
 )
 
 // Common tool errors
@@ -34,6 +35,9 @@ var (
 	// ErrToolDeniedByUser indicates the user denied executing a tool.
 	ErrToolDeniedByUser = errors.New("tool execution denied by user")
 
+	// ErrToolApprovalTimeout indicates nobody answered an approval prompt in time.
+	ErrToolApprovalTimeout = errors.New("timed out awaiting approval")
+
 	// ErrToolNotFound indicates the requested tool doesn't exist in the registry.
 	ErrToolNotFound = errors.New("tool not found")
 
@@ -45,8 +49,50 @@ var (
 
 	// ErrToolInCooldown indicates a tool is in a cooldown window.
 	ErrToolInCooldown = errors.New("tool is in cooldown")
+
+	// ErrPathEscapesWorkdir indicates a path resolves outside the working directory.
+	ErrPathEscapesWorkdir = paths.ErrPathEscapesBase
+
+	// ErrPathRestricted indicates a path is blocked by the security rules or the path whitelist.
+	ErrPathRestricted = errors.New("restricted for security")
+
+	// ErrFileTooLarge indicates a file or content is over the configured size limit.
+	ErrFileTooLarge = errors.New("exceeds maximum size")
+
+	// ErrBinaryContent indicates a text-only tool was given binary data.
+	ErrBinaryContent = errors.New("appears to be binary")
+
+	// ErrInvalidUTF8 indicates a text-only tool was given text in another encoding.
+	ErrInvalidUTF8 = errors.New("is not valid UTF-8")
+
+	// ErrFileExists indicates a write would replace a file without overwrite set.
+	ErrFileExists = errors.New("file already exists")
 )
 
+// errorHints maps tool failure sentinels to short hints that help the model
+// choose a different approach.
+var errorHints = []struct {
+	err  error
+	hint string
+}{
+	{ErrBinaryContent, "the file is binary; use hexdump, strings or md5sum instead"},
+	{ErrInvalidUTF8, "the file uses another text encoding; use hexdump or strings to inspect it"},
+	{ErrFileTooLarge, "the file is too large to read at once; page through it with read_file offset and limit, or use head, tail or grep"},
+	{ErrPathEscapesWorkdir, "use a path inside the working directory without '..' segments that leave it"},
+	{ErrPathRestricted, "this path is off limits; work with files inside the project directory"},
+}
+
+// ErrorHint returns a short suggestion for a known tool failure, or an empty
+// string when err matches none of the sentinels.
+func ErrorHint(err error) string {
+	for _, entry := range errorHints {
+		if errors.Is(err, entry.err) {
+			return entry.hint
+		}
+	}
+	return ""
+}
+
 // NewToolExecutionError wraps a tool execution error with a shared error code.
 func NewToolExecutionError(toolName, operation string, err error) *apperrors.Error {
 	if operation != "" {
@@ -18,7 +18,13 @@ package tools
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/sashabaranov/go-openai"
 )
 
 func TestToolExecutionError(t *testing.T) {
@@ -99,3 +105,66 @@ func TestErrorConstants(t *testing.T) {
 		})
 	}
 }
+
+func TestToolErrorSentinels(t *testing.T) {
+	registry := NewRegistry()
+	dir := makeTempDir(t)
+	binary := writeTestFile(t, dir, "blob.bin", "\x00\x01\x02\x03")
+
+	ConfigureLimits(Limits{
+		MaxFileSizeBytes:    8,
+		MaxDirectoryDepth:   defaultMaxDirectoryDepth,
+		MaxDirectoryEntries: defaultMaxDirectoryEntries,
+	})
+	t.Cleanup(func() {
+		ConfigureLimits(DefaultLimits())
+	})
+	large := writeTestFile(t, dir, "large.txt", "0123456789")
+	allowed := filepath.Join(dir, "allowed")
+	if err := os.Mkdir(allowed, 0o755); err != nil {
+		t.Fatalf("failed to create whitelist dir: %v", err)
+	}
+	outside := writeTestFile(t, dir, "outside.txt", "x")
+
+	tests := []struct {
+		name     string
+		tool     string
+		args     map[string]interface{}
+		sentinel error
+	}{
+		{"binary", "grep", map[string]interface{}{"pattern": "x", "path": relPath(t, binary)}, ErrBinaryContent},
+		{"too large", "cat", map[string]interface{}{"path": relPath(t, large)}, ErrFileTooLarge},
+		{"escapes workdir", "cat", map[string]interface{}{"path": "../outside.txt"}, ErrPathEscapesWorkdir},
+		{"outside whitelist", "cat", map[string]interface{}{"path": relPath(t, outside)}, ErrPathRestricted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.sentinel == ErrPathRestricted {
+				ConfigurePathWhitelist([]string{relPath(t, allowed)})
+				t.Cleanup(func() {
+					ConfigurePathWhitelist(nil)
+				})
+			}
+			result := executeTool(t, registry, tt.tool, tt.args)
+			if !errors.Is(result.Error, tt.sentinel) {
+				t.Fatalf("expected %v, got %v", tt.sentinel, result.Error)
+			}
+			if ErrorHint(result.Error) == "" {
+				t.Fatalf("expected a hint for %v", result.Error)
+			}
+		})
+	}
+}
+
+func TestFormatToolResultIncludesHint(t *testing.T) {
+	call := openai.ToolCall{Function: openai.FunctionCall{Name: "cat", Arguments: `{"path":"blob.bin"}`}}
+	result := &ToolResult{Function: "cat", Error: fmt.Errorf("file %w; tool supports text only", ErrBinaryContent)}
+
+	output := FormatToolResult(call, result, false)
+	if !strings.Contains(output, "Hint: the file is binary; use hexdump") {
+		t.Fatalf("expected binary hint, got %q", output)
+	}
+	if hint := ErrorHint(errors.New("something else")); hint != "" {
+		t.Fatalf("expected no hint for unknown errors, got %q", hint)
+	}
+}
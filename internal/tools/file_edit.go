@@ -168,15 +168,16 @@ func createFile(ctx context.Context, args map[string]interface{}) (string, error
 	}
 	overwrite := parsed.Overwrite
 
+	content = normalizeLineEndings(content)
 	limits := getLimits()
 	if int64(len(content)) > limits.MaxFileSizeBytes {
-		return "", fmt.Errorf("content exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+		return "", fmt.Errorf("content %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 	}
 	if !isTextContent([]byte(content)) {
-		return "", fmt.Errorf("content appears to be binary; create_file supports text only")
+		return "", fmt.Errorf("content %w; create_file supports text only", ErrBinaryContent)
 	}
 
-	workdir, err := os.Getwd()
+	workdir, err := toolWorkdir(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to determine working directory: %v", err)
 	}
@@ -192,7 +193,7 @@ func createFile(ctx context.Context, args map[string]interface{}) (string, error
 			return "", fmt.Errorf("path '%s' is a directory", resolved)
 		}
 		if !overwrite {
-			return "", fmt.Errorf("file already exists; set overwrite to true to replace it")
+			return "", fmt.Errorf("%w; set overwrite to true to replace it", ErrFileExists)
 		}
 		mode = info.Mode().Perm()
 	} else if !os.IsNotExist(err) {
@@ -215,6 +216,18 @@ func createFile(ctx context.Context, args map[string]interface{}) (string, error
 	return fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), resolved), nil
 }
 
+// WriteTextFile writes content to path with the same path, size and content
+// checks as the create_file tool. A relative path is resolved against the
+// registry working directory, as for tool calls. Existing files are replaced
+// only when overwrite is set; otherwise the error wraps ErrFileExists.
+func (r *Registry) WriteTextFile(path, content string, overwrite bool) (string, error) {
+	return createFile(r.toolContext(), map[string]interface{}{
+		"path":      path,
+		"content":   content,
+		"overwrite": overwrite,
+	})
+}
+
 func editFile(ctx context.Context, args map[string]interface{}) (string, error) {
 	if err := ensureContext(ctx); err != nil {
 		return "", err
@@ -238,7 +251,7 @@ func editFile(ctx context.Context, args map[string]interface{}) (string, error)
 		return "", err
 	}
 
-	workdir, err := os.Getwd()
+	workdir, err := toolWorkdir(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to determine working directory: %v", err)
 	}
@@ -258,7 +271,7 @@ func editFile(ctx context.Context, args map[string]interface{}) (string, error)
 
 	limits := getLimits()
 	if info.Size() > limits.MaxFileSizeBytes {
-		return "", fmt.Errorf("file exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+		return "", fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 	}
 
 	if err := ensureContext(ctx); err != nil {
@@ -270,7 +283,7 @@ func editFile(ctx context.Context, args map[string]interface{}) (string, error)
 		return "", fmt.Errorf("failed to read file: %v", err)
 	}
 	if !isTextContent(originalBytes) {
-		return "", fmt.Errorf("file appears to be binary; edit_file supports text only")
+		return "", fmt.Errorf("file %w; edit_file supports text only", ErrBinaryContent)
 	}
 
 	edits, err := parseSearchReplaceEdits(editsRaw)
@@ -298,12 +311,13 @@ func editFile(ctx context.Context, args map[string]interface{}) (string, error)
 		return "", err
 	}
 
+	updated = normalizeLineEndings(updated)
 	if updated == string(originalBytes) {
 		return fmt.Sprintf("No changes applied to %s", resolved), nil
 	}
 
 	if int64(len(updated)) > limits.MaxFileSizeBytes {
-		return "", fmt.Errorf("updated file exceeds maximum size of %d bytes", limits.MaxFileSizeBytes)
+		return "", fmt.Errorf("updated file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
 	}
 
 	if err := os.WriteFile(resolved, []byte(updated), info.Mode().Perm()); err != nil {
@@ -0,0 +1,167 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// findDeleteMaxFiles caps how many files one find_delete call removes. A call
+// matching more fails without deleting anything, so criteria that are too
+// broad are noticed before they do damage.
+const findDeleteMaxFiles = 100
+
+type findDeleteArgs struct {
+	Path       string  `json:"path,omitempty" jsonschema:"description=Root path to search (default: current directory)"`
+	Name       string  `json:"name,omitempty" jsonschema:"description=Glob pattern to match file names (e.g. *.tmp)"`
+	MaxDepth   float64 `json:"max_depth,omitempty" jsonschema:"description=Maximum depth to traverse"`
+	ShowHidden bool    `json:"show_hidden,omitempty" jsonschema:"description=Include hidden entries"`
+	NewerThan  string  `json:"newer_than,omitempty" jsonschema:"description=Only files modified within this duration (e.g. 24h or 7d) or after this RFC3339 time"`
+	OlderThan  string  `json:"older_than,omitempty" jsonschema:"description=Only files modified before this duration ago (e.g. 30d) or before this RFC3339 time"`
+	MinSize    float64 `json:"min_size,omitempty" jsonschema:"description=Only files of at least this many bytes"`
+	MaxSize    float64 `json:"max_size,omitempty" jsonschema:"description=Only files of at most this many bytes"`
+}
+
+// findDeleteMatch is a regular file selected for deletion.
+type findDeleteMatch struct {
+	resolved string // absolute path, as listed in the confirmation
+	display  string // path under the requested root, as returned to the model
+}
+
+// findDeleteMatches walks the requested root with the find filters and
+// returns the regular files they select. It is shared by the confirmation
+// summary and the deletion, so both see the same list.
+func findDeleteMatches(ctx context.Context, args map[string]interface{}) ([]findDeleteMatch, error) {
+	path := getPathArg(args)
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := findFilters(args, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	opts.pattern, _ = getStringLike(args["name"])
+	if opts.pattern == "" && !opts.hasTimeFilter() && !opts.hasSizeFilter() {
+		return nil, fmt.Errorf("find_delete needs at least one of name, older_than, newer_than, min_size or max_size")
+	}
+	if _, err := filepath.Match(opts.pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid name pattern: %w", err)
+	}
+
+	limits := getLimits()
+	opts.maxDepth = limits.MaxDirectoryDepth
+	if depth, err := extractIntArg(args, "max_depth", 0); err != nil {
+		return nil, err
+	} else if depth > 0 && depth < opts.maxDepth {
+		opts.maxDepth = depth
+	}
+	opts.maxDepth = max(opts.maxDepth, 1)
+	opts.maxEntries = limits.MaxDirectoryEntries
+	if opts.maxEntries <= 0 {
+		opts.maxEntries = 2000
+	}
+	opts.showHidden = getBoolArg(args, "show_hidden")
+	opts.regularOnly = true
+
+	entries, err := walkDirEntries(ctx, resolved, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > findDeleteMaxFiles {
+		return nil, fmt.Errorf("%d files match, more than the %d find_delete removes in one call; narrow the criteria", len(entries), findDeleteMaxFiles)
+	}
+	matches := make([]findDeleteMatch, 0, len(entries))
+	for _, entry := range entries {
+		matches = append(matches, findDeleteMatch{
+			resolved: entry.Path,
+			display:  filepath.Join(path, entry.Rel),
+		})
+	}
+	return matches, nil
+}
+
+func validateFindDeleteArgs(args map[string]interface{}) error {
+	opts, err := findFilters(args, time.Now())
+	if err != nil {
+		return err
+	}
+	name, _ := getStringLike(args["name"])
+	if name == "" && !opts.hasTimeFilter() && !opts.hasSizeFilter() {
+		return fmt.Errorf("find_delete needs at least one of name, older_than, newer_than, min_size or max_size")
+	}
+	return nil
+}
+
+// findDelete removes the files selected by findDeleteMatches. Each one goes
+// through the same path checks and remover as rm, so nothing outside the
+// working directory or under a restricted path is touched.
+func findDelete(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	matches, err := findDeleteMatches(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) == 0 {
+		return "no files matched; nothing deleted", nil
+	}
+	deleted := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if err := ensureContext(ctx); err != nil {
+			return "", deleteFailure(deleted, match, err)
+		}
+		cmdArgs, err := buildRemoveArgs(ctx, map[string]interface{}{"paths": []string{match.resolved}})
+		if err != nil {
+			return "", deleteFailure(deleted, match, err)
+		}
+		if _, err := runRemove(ctx, cmdArgs); err != nil {
+			return "", deleteFailure(deleted, match, err)
+		}
+		deleted = append(deleted, match.display)
+	}
+	return fmt.Sprintf("deleted %s:\n%s", pluralFiles(len(deleted), false), strings.Join(deleted, "\n")), nil
+}
+
+// deleteFailure reports which files were already gone when a deletion failed.
+func deleteFailure(deleted []string, match findDeleteMatch, err error) error {
+	if len(deleted) == 0 {
+		return fmt.Errorf("failed to delete %s: %w", match.display, err)
+	}
+	return fmt.Errorf("deleted %s (%s) before failing on %s: %w", pluralFiles(len(deleted), false), strings.Join(deleted, ", "), match.display, err)
+}
+
+// summarizeFindDelete lists exactly the files find_delete is about to remove.
+func summarizeFindDelete(ctx context.Context, args map[string]interface{}) string {
+	matches, err := findDeleteMatches(ctx, args)
+	if err != nil {
+		return ""
+	}
+	if len(matches) == 0 {
+		return "find_delete matches no files"
+	}
+	lines := make([]string, 0, len(matches))
+	for _, match := range matches {
+		lines = append(lines, match.resolved)
+	}
+	return formatSummary(fmt.Sprintf("find_delete will delete %s:", pluralFiles(len(matches), false)), lines)
+}
@@ -0,0 +1,109 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFindDelete(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{Allow: map[string]bool{"find_delete": true}})
+	absDir, relDir := tempDirInCwd(t)
+	if err := os.MkdirAll(filepath.Join(absDir, "sub"), 0o755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	for _, name := range []string{"a.tmp", "keep.txt", "sub/b.tmp", "sub/new.tmp"} {
+		if err := os.WriteFile(filepath.Join(absDir, filepath.FromSlash(name)), []byte(name), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"a.tmp", "keep.txt", "sub/b.tmp"} {
+		if err := os.Chtimes(filepath.Join(absDir, filepath.FromSlash(name)), old, old); err != nil {
+			t.Fatalf("failed to age file: %v", err)
+		}
+	}
+	args := map[string]interface{}{"path": relDir, "name": "*.tmp", "older_than": "24h"}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		t.Fatalf("failed to marshal args: %v", err)
+	}
+	summary := registry.ConfirmSummary("find_delete", string(data))
+	if !strings.HasPrefix(summary, "find_delete will delete 2 files:") ||
+		!strings.Contains(summary, "\n  "+filepath.Join(absDir, "a.tmp")) ||
+		!strings.Contains(summary, "\n  "+filepath.Join(absDir, "sub", "b.tmp")) ||
+		strings.Contains(summary, "new.tmp") {
+		t.Fatalf("unexpected confirmation summary: %q", summary)
+	}
+
+	result := registry.Execute("find_delete", args)
+	if result.Error != nil {
+		t.Fatalf("expected find_delete success, got %v", result.Error)
+	}
+	want := "deleted 2 files:\n" + filepath.Join(relDir, "a.tmp") + "\n" + filepath.Join(relDir, "sub", "b.tmp")
+	if result.Result != want {
+		t.Fatalf("unexpected result:\n%s\nwant:\n%s", result.Result, want)
+	}
+	for name, exists := range map[string]bool{"a.tmp": false, "sub/b.tmp": false, "sub/new.tmp": true, "keep.txt": true} {
+		_, err := os.Stat(filepath.Join(absDir, filepath.FromSlash(name)))
+		if exists != (err == nil) {
+			t.Errorf("expected %s to exist: %v, stat error: %v", name, exists, err)
+		}
+	}
+
+	result = registry.Execute("find_delete", map[string]interface{}{"path": relDir})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "needs at least one of") {
+		t.Fatalf("expected find_delete without criteria to fail, got %q (%v)", result.Result, result.Error)
+	}
+}
+
+func TestFindDeleteRejectsEscape(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{Allow: map[string]bool{"find_delete": true}})
+	outside := t.TempDir()
+	victim := filepath.Join(outside, "victim.tmp")
+	if err := os.WriteFile(victim, []byte("x"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get working directory: %v", err)
+	}
+	rel, err := filepath.Rel(cwd, outside)
+	if err != nil {
+		t.Fatalf("failed to relativize path: %v", err)
+	}
+
+	for _, path := range []string{outside, rel, "/etc"} {
+		result := registry.Execute("find_delete", map[string]interface{}{"path": path, "name": "*"})
+		if result.Error == nil {
+			t.Fatalf("expected find_delete to reject %s, got %q", path, result.Result)
+		}
+		if !errors.Is(result.Error, ErrPathRestricted) && !errors.Is(result.Error, ErrPathEscapesWorkdir) {
+			t.Errorf("expected a path restriction error for %s, got %v", path, result.Error)
+		}
+	}
+	if _, err := os.Stat(victim); err != nil {
+		t.Fatalf("expected the file outside the working directory to survive: %v", err)
+	}
+}
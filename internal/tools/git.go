@@ -0,0 +1,183 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// maxGitOutputBytes caps the output git_status and git_diff return.
+const maxGitOutputBytes = 64 << 10
+
+// gitTimeout stops git_status and git_diff when git hangs, for example on a
+// network file system, unless tool_timeouts sets another limit.
+const gitTimeout = 30 * time.Second
+
+// ErrGitNotInstalled indicates the git tools were called without a git binary.
+var ErrGitNotInstalled = errors.New("git is not installed")
+
+type gitStatusArgs struct {
+	Path string `json:"path,omitempty" jsonschema:"description=Limit the status to this file or directory"`
+}
+
+type gitDiffArgs struct {
+	Path   string `json:"path,omitempty" jsonschema:"description=Limit the diff to this file or directory"`
+	Staged bool   `json:"staged,omitempty" jsonschema:"description=Show staged changes instead of unstaged ones"`
+	Ref    string `json:"ref,omitempty" jsonschema:"description=Compare the working tree against this commit/branch or tag"`
+}
+
+// gitStatus reports the branch and changed files in porcelain format.
+func gitStatus(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[gitStatusArgs](args)
+	if err != nil {
+		return "", err
+	}
+	gitArgs := []string{"status", "--porcelain=v1", "--branch", "--untracked-files=normal"}
+	pathspec, err := gitPathspec(ctx, parsed.Path)
+	if err != nil {
+		return "", err
+	}
+	output, err := runGit(ctx, append(gitArgs, pathspec...)...)
+	if err != nil {
+		return "", err
+	}
+	if strings.Count(output, "\n") <= 1 && strings.HasPrefix(output, "##") {
+		output += "(working tree clean)\n"
+	}
+	return capGitOutput(output), nil
+}
+
+// gitDiff returns the unified diff of unstaged, staged or ref changes.
+func gitDiff(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[gitDiffArgs](args)
+	if err != nil {
+		return "", err
+	}
+	if err := validateGitDiffArgs(args); err != nil {
+		return "", err
+	}
+	gitArgs := []string{"diff", "--no-color", "--no-ext-diff", "--no-textconv"}
+	if parsed.Staged {
+		gitArgs = append(gitArgs, "--cached")
+	}
+	if parsed.Ref != "" {
+		gitArgs = append(gitArgs, parsed.Ref)
+	}
+	pathspec, err := gitPathspec(ctx, parsed.Path)
+	if err != nil {
+		return "", err
+	}
+	output, err := runGit(ctx, append(gitArgs, pathspec...)...)
+	if err != nil {
+		return "", err
+	}
+	if output == "" {
+		return "No changes", nil
+	}
+	return capGitOutput(output), nil
+}
+
+func validateGitDiffArgs(args map[string]interface{}) error {
+	ref, _ := getStringLike(args["ref"])
+	// A ref is passed to git as an argument, so it must not read as an option.
+	if strings.HasPrefix(strings.TrimSpace(ref), "-") {
+		return fmt.Errorf("invalid ref %q", ref)
+	}
+	return nil
+}
+
+// gitPathspec resolves an optional path within the working directory into
+// the trailing "-- <path>" arguments of a git command.
+func gitPathspec(ctx context.Context, path string) ([]string, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, nil
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"--", resolved}, nil
+}
+
+// runGit runs git in the tool working directory after checking that it is
+// inside a work tree. Repository config that could run other programs, such
+// as fsmonitor hooks, is switched off, and git takes no optional locks so a
+// status never writes to the index.
+func runGit(ctx context.Context, args ...string) (string, error) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return "", ErrGitNotInstalled
+	}
+	workdir, err := toolWorkdir(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine working directory: %v", err)
+	}
+
+	run := func(args ...string) (string, error) {
+		full := append([]string{"--no-pager", "-c", "core.fsmonitor=false", "-c", "core.quotePath=false"}, args...)
+		cmd := exec.CommandContext(ctx, gitPath, full...)
+		cmd.Dir = workdir
+		cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0", "GIT_TERMINAL_PROMPT=0", "LC_ALL=C")
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return "", ctxErr
+			}
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return "", fmt.Errorf("git %s: %s", args[0], msg)
+			}
+			return "", fmt.Errorf("git %s: %v", args[0], err)
+		}
+		return stdout.String(), nil
+	}
+
+	inside, err := run("rev-parse", "--is-inside-work-tree")
+	if err != nil || strings.TrimSpace(inside) != "true" {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", fmt.Errorf("%s is not inside a git work tree", workdir)
+	}
+	return run(args...)
+}
+
+// capGitOutput cuts output at a line boundary once it passes maxGitOutputBytes.
+func capGitOutput(output string) string {
+	if len(output) <= maxGitOutputBytes {
+		return output
+	}
+	cut := output[:maxGitOutputBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i+1]
+	}
+	return fmt.Sprintf("%s[output truncated at %d of %d bytes; pass path to narrow it]\n", cut, len(cut), len(output))
+}
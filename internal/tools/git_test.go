@@ -0,0 +1,119 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+}
+
+func runTestGit(t *testing.T, args ...string) {
+	t.Helper()
+	full := append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)
+	if out, err := exec.Command("git", full...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestGitTools(t *testing.T) {
+	requireGit(t)
+	t.Chdir(t.TempDir())
+	registry := NewRegistry()
+
+	for _, name := range []string{"git_status", "git_diff"} {
+		if level := registry.GetPermission(name).Level; level != PermissionAllow {
+			t.Fatalf("expected %s to be allowed by default, got %s", name, level)
+		}
+	}
+	if result := registry.Execute("git_status", map[string]interface{}{}); result.Error == nil ||
+		!strings.Contains(result.Error.Error(), "not inside a git work tree") {
+		t.Fatalf("expected an error outside a repository, got %q, %v", result.Result, result.Error)
+	}
+
+	runTestGit(t, "init", "-q")
+	writeTestFile(t, ".", "a.txt", "old\n")
+	runTestGit(t, "add", "a.txt")
+	runTestGit(t, "commit", "-q", "-m", "first")
+
+	status := registry.Execute("git_status", map[string]interface{}{})
+	if status.Error != nil || !strings.Contains(status.Result, "(working tree clean)") {
+		t.Fatalf("expected a clean tree, got %q, %v", status.Result, status.Error)
+	}
+	if diff := registry.Execute("git_diff", map[string]interface{}{}); diff.Error != nil || diff.Result != "No changes" {
+		t.Fatalf("expected no changes, got %q, %v", diff.Result, diff.Error)
+	}
+
+	writeTestFile(t, ".", "a.txt", "new\n")
+	writeTestFile(t, ".", "b.txt", "untracked\n")
+	status = registry.Execute("git_status", map[string]interface{}{})
+	if status.Error != nil || !strings.Contains(status.Result, " M a.txt") || !strings.Contains(status.Result, "?? b.txt") {
+		t.Fatalf("unexpected status: %q, %v", status.Result, status.Error)
+	}
+	status = registry.Execute("git_status", map[string]interface{}{"path": "b.txt"})
+	if status.Error != nil || strings.Contains(status.Result, "a.txt") {
+		t.Fatalf("expected the status limited to b.txt, got %q, %v", status.Result, status.Error)
+	}
+
+	diff := registry.Execute("git_diff", map[string]interface{}{})
+	if diff.Error != nil || !strings.Contains(diff.Result, "-old") || !strings.Contains(diff.Result, "+new") {
+		t.Fatalf("unexpected diff: %q, %v", diff.Result, diff.Error)
+	}
+	runTestGit(t, "add", "a.txt")
+	if diff := registry.Execute("git_diff", map[string]interface{}{}); diff.Result != "No changes" {
+		t.Fatalf("expected no unstaged changes, got %q, %v", diff.Result, diff.Error)
+	}
+	if diff := registry.Execute("git_diff", map[string]interface{}{"staged": true}); !strings.Contains(diff.Result, "+new") {
+		t.Fatalf("expected the staged diff, got %q, %v", diff.Result, diff.Error)
+	}
+	if diff := registry.Execute("git_diff", map[string]interface{}{"ref": "HEAD", "path": "a.txt"}); !strings.Contains(diff.Result, "+new") {
+		t.Fatalf("expected the diff against HEAD, got %q, %v", diff.Result, diff.Error)
+	}
+	if diff := registry.Execute("git_diff", map[string]interface{}{"ref": "--output=x"}); diff.Error == nil {
+		t.Fatal("expected a ref that looks like an option to be rejected")
+	}
+	if _, err := os.Stat("x"); err == nil {
+		t.Fatal("git must not have been run with the option ref")
+	}
+	if diff := registry.Execute("git_diff", map[string]interface{}{"path": "../outside"}); !errors.Is(diff.Error, ErrPathEscapesWorkdir) {
+		t.Fatalf("expected a path outside the working directory to be rejected, got %v", diff.Error)
+	}
+}
+
+func TestCapGitOutput(t *testing.T) {
+	line := strings.Repeat("x", 99) + "\n"
+	output := strings.Repeat(line, maxGitOutputBytes/len(line)+10)
+	capped := capGitOutput(output)
+	if !strings.Contains(capped, "[output truncated at") || len(capped) > maxGitOutputBytes+200 {
+		t.Fatalf("expected a capped output, got %d bytes", len(capped))
+	}
+	if body, _, _ := strings.Cut(capped, "[output"); !strings.HasSuffix(body, "x\n") {
+		t.Fatal("expected the output to be cut at a line boundary")
+	}
+	if capGitOutput("short\n") != "short\n" {
+		t.Fatal("expected short output to be returned unchanged")
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxHashTreeBytes caps the total size of the files hashed by one hash_tree call.
+const maxHashTreeBytes = 512 << 20
+
+type hashTreeArgs struct {
+	Path       string `json:"path,omitempty" jsonschema:"description=Directory to hash (defaults to current directory)"`
+	Algorithm  int    `json:"algorithm,omitempty" jsonschema:"description=SHA algorithm: 1/256 or 512 (default 256)"`
+	Name       string `json:"name,omitempty" jsonschema:"description=Only hash files whose name matches this glob (e.g. *.go)"`
+	ShowHidden bool   `json:"show_hidden,omitempty" jsonschema:"description=Include hidden files"`
+}
+
+// newSHAHash returns the hash for a shasum algorithm number.
+func newSHAHash(algorithm int) (hash.Hash, error) {
+	switch algorithm {
+	case 1:
+		return sha1.New(), nil
+	case 256:
+		return sha256.New(), nil
+	case 512:
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("invalid algorithm, only 1, 256, or 512 are valid")
+	}
+}
+
+// hashTree walks a directory within the tool limits and returns a sorted
+// manifest of "relative/path  digest" lines for every regular file, so two
+// calls can be compared to tell whether the tree changed.
+func hashTree(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[hashTreeArgs](args)
+	if err != nil {
+		return "", err
+	}
+	algorithm := parsed.Algorithm
+	if algorithm == 0 {
+		algorithm = 256
+	}
+	if _, err := newSHAHash(algorithm); err != nil {
+		return "", err
+	}
+	path := parsed.Path
+	if path == "" {
+		path = "."
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	if parsed.Name != "" {
+		if _, err := filepath.Match(parsed.Name, ""); err != nil {
+			return "", fmt.Errorf("invalid name pattern: %w", err)
+		}
+	}
+
+	limits := getLimits()
+	opts := walkOptions{
+		maxDepth:    max(limits.MaxDirectoryDepth, 1),
+		maxEntries:  limits.MaxDirectoryEntries,
+		showHidden:  parsed.ShowHidden,
+		pattern:     parsed.Name,
+		regularOnly: true,
+	}
+	if opts.maxEntries <= 0 {
+		opts.maxEntries = 2000
+	}
+	entries, err := walkDirEntries(ctx, resolved, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("No files to hash in %s", path), nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Rel < entries[j].Rel })
+
+	var total int64
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if err := ensureContext(ctx); err != nil {
+			return "", err
+		}
+		rel := filepath.ToSlash(entry.Rel)
+		size, sum, err := hashTreeFile(entry.Path, algorithm, limits)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", rel, err)
+		}
+		total += size
+		if total > maxHashTreeBytes {
+			return "", fmt.Errorf("files under %s %w of %d bytes in total", path, ErrFileTooLarge, maxHashTreeBytes)
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s", rel, sum))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// hashTreeFile returns the size and hex digest of one file.
+func hashTreeFile(path string, algorithm int, limits Limits) (int64, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, "", err
+	}
+	if limits.MaxFileSizeBytes > 0 && info.Size() > limits.MaxFileSizeBytes {
+		return 0, "", fmt.Errorf("file %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+	h, err := newSHAHash(algorithm)
+	if err != nil {
+		return 0, "", err
+	}
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
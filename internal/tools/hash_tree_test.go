@@ -0,0 +1,81 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashTree(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"hash_tree": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	files := map[string]string{
+		"b.txt":         "bravo\n",
+		"a.txt":         "alpha\n",
+		"sub/c.go":      "package sub\n",
+		".hidden/d.txt": "secret\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(absDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	sha := func(content string) string {
+		sum := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(sum[:])
+	}
+
+	result := registry.Execute("hash_tree", map[string]interface{}{"path": relDir})
+	if result.Error != nil {
+		t.Fatalf("expected hash_tree success, got %v", result.Error)
+	}
+	want := strings.Join([]string{
+		"a.txt  " + sha("alpha\n"),
+		"b.txt  " + sha("bravo\n"),
+		"sub/c.go  " + sha("package sub\n"),
+	}, "\n")
+	if result.Result != want {
+		t.Fatalf("unexpected manifest:\n%s\nwant:\n%s", result.Result, want)
+	}
+
+	result = registry.Execute("hash_tree", map[string]interface{}{"path": relDir, "algorithm": 512, "name": "*.go"})
+	sum := sha512.Sum512([]byte("package sub\n"))
+	if result.Error != nil || result.Result != "sub/c.go  "+hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected sha512 manifest %q (%v)", result.Result, result.Error)
+	}
+
+	if result := registry.Execute("hash_tree", map[string]interface{}{"path": relDir, "algorithm": 384}); result.Error == nil {
+		t.Fatal("expected unsupported algorithm to fail")
+	}
+	if result := registry.Execute("hash_tree", map[string]interface{}{"path": filepath.Join(relDir, "a.txt")}); result.Error == nil {
+		t.Fatal("expected a file path to fail")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRules holds the ignore and use_gitignore arguments of a listing tool.
+type ignoreRules struct {
+	patterns     []ignorePattern
+	useGitignore bool
+}
+
+// ignorePattern is one gitignore-style glob. A pattern without a slash matches
+// a name at any depth; one with a slash matches the path from the walk root.
+// A trailing slash limits it to directories.
+type ignorePattern struct {
+	glob     string
+	anchored bool
+	dirOnly  bool
+}
+
+// ignoreRulesFromArgs reads the ignore patterns and the use_gitignore flag.
+func ignoreRulesFromArgs(args map[string]interface{}) (ignoreRules, error) {
+	var rules ignoreRules
+	if args == nil {
+		return rules, nil
+	}
+	rules.useGitignore = getBoolArg(args, "use_gitignore")
+	if _, ok := args["ignore"]; !ok {
+		return rules, nil
+	}
+	patterns, err := extractStringSliceArg(args, "ignore")
+	if err != nil {
+		return rules, err
+	}
+	for _, pattern := range patterns {
+		parsed, ok, err := parseIgnorePattern(pattern)
+		if err != nil {
+			return rules, err
+		}
+		if ok {
+			rules.patterns = append(rules.patterns, parsed)
+		}
+	}
+	return rules, nil
+}
+
+func parseIgnorePattern(pattern string) (ignorePattern, bool, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return ignorePattern{}, false, nil
+	}
+	var parsed ignorePattern
+	if strings.HasSuffix(pattern, "/") {
+		parsed.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if strings.HasPrefix(pattern, "/") {
+		parsed.anchored = true
+		pattern = strings.TrimLeft(pattern, "/")
+	}
+	if strings.Contains(pattern, "/") {
+		parsed.anchored = true
+	}
+	if pattern == "" {
+		return ignorePattern{}, false, nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return ignorePattern{}, false, fmt.Errorf("invalid ignore pattern %q: %v", pattern, err)
+	}
+	parsed.glob = pattern
+	return parsed, true, nil
+}
+
+// matcher returns the matcher for a walk rooted at root, adding the root's
+// .gitignore when requested. It returns nil when nothing is ignored.
+func (r ignoreRules) matcher(root string) (*ignoreMatcher, error) {
+	patterns := r.patterns
+	if r.useGitignore {
+		extra, err := readGitignore(filepath.Join(root, ".gitignore"))
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(append([]ignorePattern{}, patterns...), extra...)
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return &ignoreMatcher{patterns: patterns}, nil
+}
+
+// readGitignore parses a .gitignore file. Negated patterns ("!keep") are not
+// supported and skipped, as are invalid globs; a missing file ignores nothing.
+func readGitignore(name string) ([]ignorePattern, error) {
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "!") {
+			continue
+		}
+		if parsed, ok, err := parseIgnorePattern(line); err == nil && ok {
+			patterns = append(patterns, parsed)
+		}
+	}
+	return patterns, scanner.Err()
+}
+
+// ignoreMatcher tests paths relative to a walk root against ignore patterns.
+type ignoreMatcher struct {
+	patterns []ignorePattern
+}
+
+// match reports whether rel, a slash-separated path relative to the walk root,
+// is ignored. A nil matcher ignores nothing.
+func (m *ignoreMatcher) match(rel string, isDir bool) bool {
+	if m == nil || rel == "." || rel == "" {
+		return false
+	}
+	name := path.Base(rel)
+	for _, pattern := range m.patterns {
+		if pattern.dirOnly && !isDir {
+			continue
+		}
+		target := name
+		if pattern.anchored {
+			target = rel
+		}
+		if ok, _ := path.Match(pattern.glob, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAny reports whether rel or one of its parent directories is ignored,
+// for listings that include the contents of ignored directories.
+func (m *ignoreMatcher) matchAny(rel string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if m.match(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.match(rel, isDir)
+}
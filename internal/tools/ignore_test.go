@@ -0,0 +1,136 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnorePatternsSkipDirectories(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "node_modules", "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "node_modules", "pkg"), "index.js", "needle\n")
+	writeTestFile(t, filepath.Join(dir, "src"), "main.js", "needle\n")
+	writeTestFile(t, filepath.Join(dir, "src"), "util.js", "needle\n")
+	writeTestFile(t, dir, "build.log", "needle\n")
+
+	registry := NewRegistryWithPolicy(Policy{Allow: map[string]bool{"ls": true, "find": true, "grep": true, "search": true}})
+	ignore := []interface{}{"node_modules/", "*.log"}
+
+	cases := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"find", map[string]interface{}{"path": ".", "ignore": ignore}},
+		{"grep", map[string]interface{}{"pattern": "needle", "path": ".", "recursive": true, "ignore": ignore}},
+		{"search", map[string]interface{}{"pattern": "needle", "path": ".", "ignore": ignore}},
+		{"ls", map[string]interface{}{"path": ".", "recursive": true, "ignore": ignore}},
+		{"ls", map[string]interface{}{"path": ".", "recursive": true, "format": "json", "ignore": ignore}},
+	}
+	for _, tc := range cases {
+		result := executeTool(t, registry, tc.name, tc.args)
+		if result.Error != nil {
+			t.Fatalf("%s failed: %v", tc.name, result.Error)
+		}
+		if strings.Contains(result.Result, "node_modules") || strings.Contains(result.Result, "build.log") {
+			t.Errorf("%s %v: expected ignored entries to be skipped, got:\n%s", tc.name, tc.args["format"], result.Result)
+		}
+		if !strings.Contains(result.Result, "main.js") {
+			t.Errorf("%s %v: expected src/main.js to be listed, got:\n%s", tc.name, tc.args["format"], result.Result)
+		}
+	}
+
+	result := executeTool(t, registry, "ls", map[string]interface{}{"path": ".", "ignore": ignore})
+	if result.Error != nil || strings.Contains(result.Result, "node_modules") || !strings.Contains(result.Result, "src") {
+		t.Errorf("unexpected ls result %q (%v)", result.Result, result.Error)
+	}
+}
+
+func TestIgnoreUseGitignore(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "dist"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(dir, "dist"), "bundle.js", "x")
+	writeTestFile(t, dir, "app.js", "x")
+	writeTestFile(t, dir, ".gitignore", "# build output\n/dist/\n!dist/keep.js\n")
+
+	registry := NewRegistryWithPolicy(Policy{Allow: map[string]bool{"find": true}})
+	result := executeTool(t, registry, "find", map[string]interface{}{"path": ".", "use_gitignore": true})
+	if result.Error != nil {
+		t.Fatalf("find failed: %v", result.Error)
+	}
+	if strings.Contains(result.Result, "dist") || !strings.Contains(result.Result, "app.js") {
+		t.Errorf("expected dist to be skipped via .gitignore, got:\n%s", result.Result)
+	}
+
+	result = executeTool(t, registry, "find", map[string]interface{}{"path": "."})
+	if result.Error != nil || !strings.Contains(result.Result, "bundle.js") {
+		t.Errorf("expected dist without use_gitignore, got %q (%v)", result.Result, result.Error)
+	}
+
+	result = executeTool(t, registry, "find", map[string]interface{}{"path": ".", "ignore": []interface{}{"[bad"}})
+	if result.Error == nil {
+		t.Error("expected an invalid ignore pattern to fail")
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	rules, err := ignoreRulesFromArgs(map[string]interface{}{"ignore": []interface{}{"vendor", "/docs/*.md", "tmp/", "# comment"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	matcher, err := rules.matcher(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"vendor", true, true},
+		{"a/b/vendor", true, true},
+		{"docs/readme.md", false, true},
+		{"sub/docs/readme.md", false, false},
+		{"tmp", true, true},
+		{"tmp", false, false},
+		{"src/main.go", false, false},
+	}
+	for _, tc := range cases {
+		if got := matcher.match(tc.rel, tc.isDir); got != tc.want {
+			t.Errorf("match(%q, %v) = %v, want %v", tc.rel, tc.isDir, got, tc.want)
+		}
+	}
+	if !matcher.matchAny("vendor/lib/x.go", false) {
+		t.Error("expected matchAny to ignore files inside an ignored directory")
+	}
+	var nilMatcher *ignoreMatcher
+	if nilMatcher.match("vendor", true) {
+		t.Error("expected a nil matcher to ignore nothing")
+	}
+}
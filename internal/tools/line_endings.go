@@ -0,0 +1,78 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Line ending modes applied to text written by file tools.
+const (
+	LineEndingPreserve = "preserve"
+	LineEndingLF       = "lf"
+	LineEndingCRLF     = "crlf"
+)
+
+var (
+	lineEndingMu sync.RWMutex
+	lineEnding   = LineEndingPreserve
+)
+
+// ParseLineEnding validates a line ending mode; an empty name selects preserve.
+func ParseLineEnding(name string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(name)); mode {
+	case "", LineEndingPreserve:
+		return LineEndingPreserve, nil
+	case LineEndingLF, LineEndingCRLF:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown line ending %q (use preserve, lf or crlf)", name)
+	}
+}
+
+// ConfigureLineEnding sets how text file writes normalize line endings.
+// Unknown modes fall back to preserve.
+func ConfigureLineEnding(mode string) {
+	parsed, err := ParseLineEnding(mode)
+	if err != nil {
+		parsed = LineEndingPreserve
+	}
+	lineEndingMu.Lock()
+	defer lineEndingMu.Unlock()
+	lineEnding = parsed
+}
+
+func getLineEnding() string {
+	lineEndingMu.RLock()
+	defer lineEndingMu.RUnlock()
+	return lineEnding
+}
+
+// normalizeLineEndings rewrites text content to the configured line ending.
+// Callers must only pass text; binary writes are never normalized.
+func normalizeLineEndings(content string) string {
+	switch getLineEnding() {
+	case LineEndingLF:
+		return strings.ReplaceAll(content, "\r\n", "\n")
+	case LineEndingCRLF:
+		return strings.ReplaceAll(strings.ReplaceAll(content, "\r\n", "\n"), "\n", "\r\n")
+	default:
+		return content
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxMergeOutputBytes caps the merged output returned by one merge call.
+const maxMergeOutputBytes = 1 << 20
+
+type mergeArgs struct {
+	Paths   []string `json:"paths" jsonschema:"description=Files to merge; each must already be sorted the same way"`
+	Unique  bool     `json:"unique,omitempty" jsonschema:"description=Output only the first of a run of equal lines (like sort -mu)"`
+	Numeric bool     `json:"numeric,omitempty" jsonschema:"description=Compare lines by their leading number (like sort -mn)"`
+}
+
+// mergeCursor is the next unread line of one input file.
+type mergeCursor struct {
+	lines []string
+	pos   int
+	file  int
+}
+
+// mergeHeap orders cursors by their current line, then by input order so equal
+// lines keep the order of the files they came from.
+type mergeHeap struct {
+	cursors []*mergeCursor
+	compare func(a, b string) int
+}
+
+func (h *mergeHeap) Len() int { return len(h.cursors) }
+
+func (h *mergeHeap) Less(i, j int) bool {
+	a, b := h.cursors[i], h.cursors[j]
+	if c := h.compare(a.lines[a.pos], b.lines[b.pos]); c != 0 {
+		return c < 0
+	}
+	return a.file < b.file
+}
+
+func (h *mergeHeap) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+
+func (h *mergeHeap) Push(x any) { h.cursors = append(h.cursors, x.(*mergeCursor)) }
+
+func (h *mergeHeap) Pop() any {
+	last := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return last
+}
+
+// leadingNumber parses the number a line starts with, as sort -n does; lines
+// without one count as zero.
+func leadingNumber(line string) float64 {
+	line = strings.TrimLeft(line, " \t")
+	end := 0
+	for end < len(line) {
+		c := line[end]
+		if (c >= '0' && c <= '9') || c == '.' || (end == 0 && (c == '-' || c == '+')) {
+			end++
+			continue
+		}
+		break
+	}
+	for end > 0 {
+		if n, err := strconv.ParseFloat(line[:end], 64); err == nil {
+			return n
+		}
+		end--
+	}
+	return 0
+}
+
+func mergeCompare(numeric bool) func(a, b string) int {
+	if !numeric {
+		return strings.Compare
+	}
+	return func(a, b string) int {
+		na, nb := leadingNumber(a), leadingNumber(b)
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return strings.Compare(a, b)
+	}
+}
+
+// mergeFiles merges already sorted files into one sorted stream with a k-way
+// merge, so the inputs never have to be sorted again as a whole.
+func mergeFiles(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[mergeArgs](args)
+	if err != nil {
+		return "", err
+	}
+	if len(parsed.Paths) == 0 {
+		return "", fmt.Errorf("missing or invalid 'paths' parameter")
+	}
+	compare := mergeCompare(parsed.Numeric)
+
+	h := &mergeHeap{compare: compare}
+	for i, path := range parsed.Paths {
+		if err := ensureContext(ctx); err != nil {
+			return "", err
+		}
+		resolved, err := resolveToolPath(ctx, path)
+		if err != nil {
+			return "", err
+		}
+		lines, err := readTextLines(resolved)
+		if err != nil {
+			return "", err
+		}
+		if n := len(lines); n > 0 && lines[n-1] == "" {
+			lines = lines[:n-1]
+		}
+		for j := 1; j < len(lines); j++ {
+			if compare(lines[j-1], lines[j]) > 0 {
+				return "", fmt.Errorf("%s is not sorted: line %d sorts before line %d", path, j+1, j)
+			}
+		}
+		if len(lines) > 0 {
+			h.cursors = append(h.cursors, &mergeCursor{lines: lines, file: i})
+		}
+	}
+	heap.Init(h)
+
+	var out strings.Builder
+	var previous string
+	written := 0
+	for h.Len() > 0 {
+		cursor := h.cursors[0]
+		line := cursor.lines[cursor.pos]
+		if cursor.pos++; cursor.pos < len(cursor.lines) {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+		if parsed.Unique && written > 0 && compare(previous, line) == 0 {
+			continue
+		}
+		if written > 0 {
+			out.WriteByte('\n')
+		}
+		if out.Len()+len(line) > maxMergeOutputBytes {
+			fmt.Fprintf(&out, "[output truncated after %d lines at %d bytes]", written, maxMergeOutputBytes)
+			return out.String(), nil
+		}
+		out.WriteString(line)
+		previous = line
+		written++
+	}
+	return out.String(), nil
+}
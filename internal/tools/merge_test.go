@@ -0,0 +1,76 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeSortedFiles(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{Allow: map[string]bool{"merge": true}})
+	absDir, relDir := tempDirInCwd(t)
+	files := map[string]string{
+		"a.txt": "apple\ncherry\nmango\n",
+		"b.txt": "banana\ncherry\nzucchini\n",
+		"c.txt": "avocado\nkiwi\n",
+		"n1":    "2 two\n10 ten\n",
+		"n2":    "1 one\n3 three\n100 hundred\n",
+		"bad":   "pear\napple\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(absDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	paths := func(names ...string) []interface{} {
+		var out []interface{}
+		for _, name := range names {
+			out = append(out, filepath.Join(relDir, name))
+		}
+		return out
+	}
+
+	result := registry.Execute("merge", map[string]interface{}{"paths": paths("a.txt", "b.txt", "c.txt")})
+	if result.Error != nil {
+		t.Fatalf("expected merge success, got %v", result.Error)
+	}
+	want := "apple\navocado\nbanana\ncherry\ncherry\nkiwi\nmango\nzucchini"
+	if result.Result != want {
+		t.Fatalf("unexpected merge:\n%s\nwant:\n%s", result.Result, want)
+	}
+
+	result = registry.Execute("merge", map[string]interface{}{"paths": paths("a.txt", "b.txt", "c.txt"), "unique": true})
+	if result.Error != nil || strings.Count(result.Result, "cherry") != 1 {
+		t.Fatalf("expected unique to drop the repeated line, got %q (%v)", result.Result, result.Error)
+	}
+
+	result = registry.Execute("merge", map[string]interface{}{"paths": paths("n1", "n2"), "numeric": true})
+	if result.Error != nil {
+		t.Fatalf("expected numeric merge success, got %v", result.Error)
+	}
+	if want := "1 one\n2 two\n3 three\n10 ten\n100 hundred"; result.Result != want {
+		t.Fatalf("unexpected numeric merge:\n%s\nwant:\n%s", result.Result, want)
+	}
+
+	result = registry.Execute("merge", map[string]interface{}{"paths": paths("a.txt", "bad")})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "not sorted") {
+		t.Fatalf("expected an unsorted input to fail, got %q (%v)", result.Result, result.Error)
+	}
+}
@@ -27,9 +27,22 @@ type OutputFilterConfig struct {
 	MaxChars     int
 	StripANSI    bool
 	StripControl bool
+	// RedactPatterns are regular expressions whose matches are masked.
+	// A nil slice selects DefaultRedactPatterns; an empty slice disables redaction.
+	RedactPatterns []string
+	// TrimTrailingWhitespace strips trailing spaces and tabs from every line
+	// and trailing newlines from the whole output.
+	TrimTrailingWhitespace bool
+	// CollapseBlankLines squeezes runs of three or more blank lines into one.
+	CollapseBlankLines bool
+
+	redactors []*regexp.Regexp
 }
 
-const defaultMaxOutputChars = 4000
+const (
+	defaultMaxOutputChars = 4000
+	redactedPlaceholder   = "[REDACTED]"
+)
 
 var (
 	outputFiltersMu sync.RWMutex
@@ -37,13 +50,24 @@ var (
 	ansiPattern     = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]|\x1b\][^\x1b]*(?:\x07|\x1b\\)`)
 )
 
+// DefaultRedactPatterns returns patterns for common secret formats:
+// AWS access keys and secrets, bearer tokens and OpenAI-style "sk-" keys.
+func DefaultRedactPatterns() []string {
+	return []string{
+		`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`,
+		`(?i)aws_secret_access_key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`,
+		`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]{8,}=*`,
+		`\bsk-[A-Za-z0-9_-]{16,}`,
+	}
+}
+
 // DefaultOutputFilterConfig returns default output filtering settings.
 func DefaultOutputFilterConfig() OutputFilterConfig {
-	return OutputFilterConfig{
+	return normalizeOutputFilterConfig(OutputFilterConfig{
 		MaxChars:     defaultMaxOutputChars,
 		StripANSI:    true,
 		StripControl: true,
-	}
+	})
 }
 
 // ConfigureOutputFilters updates output sanitization settings.
@@ -63,21 +87,92 @@ func normalizeOutputFilterConfig(config OutputFilterConfig) OutputFilterConfig {
 	if config.MaxChars <= 0 {
 		config.MaxChars = defaultMaxOutputChars
 	}
+	if config.RedactPatterns == nil {
+		config.RedactPatterns = DefaultRedactPatterns()
+	}
+	config.redactors = make([]*regexp.Regexp, 0, len(config.RedactPatterns))
+	for _, pattern := range config.RedactPatterns {
+		// Patterns are validated when the config is loaded; skip anything that slipped through.
+		if re, err := regexp.Compile(pattern); err == nil {
+			config.redactors = append(config.redactors, re)
+		}
+	}
 	return config
 }
 
+// RedactSecrets masks substrings matching the configured redaction patterns.
+func RedactSecrets(input string) string {
+	return redactWith(getOutputFilters().redactors, input)
+}
+
+func redactWith(redactors []*regexp.Regexp, input string) string {
+	for _, re := range redactors {
+		input = re.ReplaceAllString(input, redactedPlaceholder)
+	}
+	return input
+}
+
 func sanitizeToolOutput(output string) (string, bool) {
 	config := getOutputFilters()
-	sanitized := output
+	// Redact first so truncation cannot leave a partial secret behind.
+	sanitized := redactWith(config.redactors, output)
 	if config.StripANSI {
 		sanitized = ansiPattern.ReplaceAllString(sanitized, "")
 	}
 	if config.StripControl {
 		sanitized = stripControlChars(sanitized)
 	}
+	sanitized = applyWhitespaceFilters(config, sanitized)
 	return truncateString(sanitized, config.MaxChars)
 }
 
+// normalizeToolWhitespace applies the configured whitespace policy to a raw
+// tool result before it is stored in the conversation.
+func normalizeToolWhitespace(output string) string {
+	return applyWhitespaceFilters(getOutputFilters(), output)
+}
+
+func applyWhitespaceFilters(config OutputFilterConfig, output string) string {
+	if !config.TrimTrailingWhitespace && !config.CollapseBlankLines {
+		return output
+	}
+	lines := strings.Split(output, "\n")
+	kept := lines[:0]
+	blankRun := 0
+	for _, line := range lines {
+		if config.TrimTrailingWhitespace {
+			line = strings.TrimRight(line, " \t\r")
+		}
+		if config.CollapseBlankLines {
+			if strings.TrimSpace(line) == "" {
+				blankRun++
+				continue
+			}
+			kept = appendBlankRun(kept, blankRun)
+			blankRun = 0
+		}
+		kept = append(kept, line)
+	}
+	kept = appendBlankRun(kept, blankRun)
+	result := strings.Join(kept, "\n")
+	if config.TrimTrailingWhitespace {
+		result = strings.TrimRight(result, "\n")
+	}
+	return result
+}
+
+// appendBlankRun restores a run of blank lines, keeping a single one when the
+// run is three lines or longer.
+func appendBlankRun(lines []string, run int) []string {
+	if run >= 3 {
+		run = 1
+	}
+	for i := 0; i < run; i++ {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
 func stripControlChars(input string) string {
 	var builder strings.Builder
 	builder.Grow(len(input))
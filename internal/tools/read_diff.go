@@ -0,0 +1,247 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxReadCacheEntries and maxReadCacheBytes bound the files remembered for
+	// read_file show_diff; the least recently read file is dropped first.
+	maxReadCacheEntries = 32
+	maxReadCacheBytes   = 4 << 20
+	// maxDiffCells caps the line comparison table. Larger changes are returned
+	// as full content instead of a diff.
+	maxDiffCells     = 4_000_000
+	diffContextLines = 3
+)
+
+// readCache remembers the last content read_file returned for each path.
+type readCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front is most recently read
+	bytes   int
+}
+
+type readCacheEntry struct {
+	path    string
+	content string
+}
+
+var lastReads = &readCache{entries: make(map[string]*list.Element), order: list.New()}
+
+// ResetReadCache forgets the file contents kept for read_file show_diff. It is
+// called when a session ends.
+func ResetReadCache() {
+	lastReads.mu.Lock()
+	defer lastReads.mu.Unlock()
+	lastReads.entries = make(map[string]*list.Element)
+	lastReads.order.Init()
+	lastReads.bytes = 0
+}
+
+// swap stores content for path and returns what was stored before.
+func (c *readCache) swap(path, content string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var previous string
+	var found bool
+	if elem, ok := c.entries[path]; ok {
+		entry := elem.Value.(*readCacheEntry)
+		previous, found = entry.content, true
+		c.bytes -= len(entry.content)
+		c.order.Remove(elem)
+		delete(c.entries, path)
+	}
+	if len(content) > maxReadCacheBytes {
+		return previous, found
+	}
+	c.entries[path] = c.order.PushFront(&readCacheEntry{path: path, content: content})
+	c.bytes += len(content)
+	for c.order.Len() > maxReadCacheEntries || c.bytes > maxReadCacheBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*readCacheEntry)
+		c.bytes -= len(entry.content)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.path)
+	}
+	return previous, found
+}
+
+// readFileDiff returns the change since the previous read of the file as a
+// unified diff. Without a previous read, or when the change is too large to
+// compare, it returns the full content.
+func readFileDiff(path, resolved, content string) string {
+	previous, found := lastReads.swap(resolved, content)
+	if !found {
+		return content
+	}
+	if previous == content {
+		return fmt.Sprintf("No changes since the last read of %s.", path)
+	}
+	diff, ok := unifiedDiff(path+" (last read)", path, splitDiffLines(previous), splitDiffLines(content))
+	if !ok {
+		return content
+	}
+	return diff
+}
+
+func splitDiffLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
+// diffLines compares two line lists with a longest common subsequence table.
+// It reports false when the lists are too large to compare.
+func diffLines(a, b []string) ([]diffOp, bool) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if (len(midA)+1)*(len(midB)+1) > maxDiffCells {
+		return nil, false
+	}
+
+	// lcs[i][j] is the common subsequence length of midA[i:] and midB[j:].
+	width := len(midB) + 1
+	lcs := make([]int, (len(midA)+1)*width)
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			ops = append(ops, diffOp{' ', midA[i]})
+			i++
+			j++
+		case i < len(midA) && (j == len(midB) || lcs[(i+1)*width+j] >= lcs[i*width+j+1]):
+			ops = append(ops, diffOp{'-', midA[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', midB[j]})
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops, true
+}
+
+// unifiedDiff renders the difference between a and b with three lines of
+// context around each change.
+func unifiedDiff(oldName, newName string, a, b []string) (string, bool) {
+	ops, ok := diffLines(a, b)
+	if !ok {
+		return "", false
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	oldLine, newLine := 1, 1 // line numbers at ops[pos]
+	pos := 0
+	for pos < len(ops) {
+		first := pos
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		start := max(pos, first-diffContextLines)
+		// Extend the hunk while the next change is close enough to share context.
+		end := first
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				end = min(end+diffContextLines, len(ops))
+				break
+			}
+			end = run
+		}
+
+		// Lines skipped before the hunk are unchanged in both files.
+		oldLine += start - pos
+		newLine += start - pos
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount))
+		for _, op := range ops[start:end] {
+			out.WriteByte(op.kind)
+			out.WriteString(op.line)
+			out.WriteByte('\n')
+		}
+		oldLine += oldCount
+		newLine += newCount
+		pos = end
+	}
+	return strings.TrimSuffix(out.String(), "\n"), true
+}
+
+func hunkRange(line, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+	return fmt.Sprintf("%d,%d", line, count)
+}
@@ -0,0 +1,218 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// readPage is one window of a file returned by read_file offset/limit.
+type readPage struct {
+	unit       string
+	first      int64 // 0-based index of the first line or byte shown
+	count      int64 // lines or bytes shown
+	total      int64 // lines or bytes in the file
+	content    []byte
+	sizeCapped bool // the page stopped early at max_file_size_bytes
+}
+
+// readPageArgs reports whether read_file was asked for a page and returns the
+// offset, the limit (0 for "as much as fits") and the unit.
+func readPageArgs(args map[string]interface{}) (offset, limit int, unit string, paged bool, err error) {
+	offset, hasOffset, err := getOptionalIntArg(args, "offset")
+	if err != nil {
+		return 0, 0, "", false, err
+	}
+	limit, hasLimit, err := getOptionalIntArg(args, "limit")
+	if err != nil {
+		return 0, 0, "", false, err
+	}
+	unit = "lines"
+	if raw, ok := args["unit"]; ok {
+		unit, _ = raw.(string)
+		if unit != "lines" && unit != "bytes" {
+			return 0, 0, "", false, fmt.Errorf("unit must be lines or bytes")
+		}
+	}
+	if offset < 0 {
+		return 0, 0, "", false, fmt.Errorf("offset must not be negative")
+	}
+	if hasLimit && limit < 1 {
+		return 0, 0, "", false, fmt.Errorf("limit must be at least 1")
+	}
+	paged = hasOffset || hasLimit
+	if paged && getBoolArg(args, "show_diff") {
+		return 0, 0, "", false, fmt.Errorf("show_diff cannot be combined with offset or limit")
+	}
+	return offset, limit, unit, paged, nil
+}
+
+func validateReadFileArgs(args map[string]interface{}) error {
+	if err := RequireNonEmptyArg("path", "missing or invalid 'path' parameter")(args); err != nil {
+		return err
+	}
+	_, _, _, _, err := readPageArgs(args)
+	return err
+}
+
+// readFilePage reads one page of a file of any size. Pages are capped at
+// max_file_size_bytes like whole reads.
+func readFilePage(ctx context.Context, path string, offset, limit int, unit string) (string, error) {
+	workdir, err := toolWorkdir(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine working directory: %v", err)
+	}
+	resolved, err := resolvePathWithinBase(path, workdir)
+	if err != nil {
+		return "", err
+	}
+	file, err := os.Open(resolved)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+
+	maxBytes := getLimits().MaxFileSizeBytes
+	var page readPage
+	if unit == "bytes" {
+		page, err = readBytePage(file, int64(offset), int64(limit), maxBytes)
+	} else {
+		page, err = readLinePage(ctx, file, int64(offset), int64(limit), maxBytes)
+	}
+	if err != nil {
+		return "", err
+	}
+	content, err := textContent(page.content)
+	if err != nil {
+		return "", fmt.Errorf("file %w; read_file supports text only", err)
+	}
+	return page.header() + "\n" + string(content), nil
+}
+
+// header describes the page, e.g. "showing lines 101-200 of 5000 (next offset: 200)".
+func (p readPage) header() string {
+	if p.count == 0 && p.first < p.total {
+		return fmt.Sprintf("showing no %s: no whole character fits at offset %d, use a larger limit", p.unit, p.first)
+	}
+	if p.count == 0 {
+		return fmt.Sprintf("showing no %s: offset %d is past the end of the file (%d %s)", p.unit, p.first, p.total, p.unit)
+	}
+	last := p.first + p.count
+	header := fmt.Sprintf("showing %s %d-%d of %d", p.unit, p.first+1, last, p.total)
+	if p.sizeCapped {
+		header += ", cut short at max_file_size_bytes"
+	}
+	if last < p.total {
+		header += fmt.Sprintf(" (next offset: %d)", last)
+	}
+	return header
+}
+
+// readLinePage collects lines [offset, offset+limit) and counts the rest.
+func readLinePage(ctx context.Context, file *os.File, offset, limit, maxBytes int64) (readPage, error) {
+	page := readPage{unit: "lines", first: offset}
+	reader := bufio.NewReader(file)
+	collecting := true
+	for {
+		if page.total%4096 == 0 {
+			if err := ensureContext(ctx); err != nil {
+				return readPage{}, err
+			}
+		}
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if collecting && page.total >= offset {
+				switch {
+				case limit > 0 && page.count >= limit:
+					collecting = false
+				case int64(len(page.content)+len(line)) > maxBytes:
+					collecting = false
+					page.sizeCapped = true
+				default:
+					page.content = append(page.content, line...)
+					page.count++
+				}
+			}
+			page.total++
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return readPage{}, fmt.Errorf("failed to read file: %v", err)
+		}
+	}
+	if page.count == 0 && page.total > offset {
+		// A single line longer than the size limit.
+		return readPage{}, fmt.Errorf("line %d %w of %d bytes; use unit bytes to read it", offset+1, ErrFileTooLarge, maxBytes)
+	}
+	return page, nil
+}
+
+// readBytePage reads limit bytes from offset, trimmed to whole UTF-8 characters.
+func readBytePage(file *os.File, offset, limit, maxBytes int64) (readPage, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return readPage{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	page := readPage{unit: "bytes", first: offset, total: info.Size()}
+	if offset >= page.total {
+		return page, nil
+	}
+	size := page.total - offset
+	if limit > 0 && limit < size {
+		size = limit
+	}
+	if size > maxBytes {
+		size = maxBytes
+		page.sizeCapped = true
+	}
+	page.content = make([]byte, size)
+	if _, err := file.ReadAt(page.content, offset); err != nil && !errors.Is(err, io.EOF) {
+		return readPage{}, fmt.Errorf("failed to read file: %v", err)
+	}
+
+	// Do not start or end in the middle of a multi-byte character.
+	for len(page.content) > 0 && !utf8.RuneStart(page.content[0]) {
+		page.content = page.content[1:]
+		page.first++
+	}
+	if offset+size < page.total {
+		page.content = trimPartialRune(page.content)
+	}
+	page.count = int64(len(page.content))
+	return page, nil
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence at the end of data.
+func trimPartialRune(data []byte) []byte {
+	for i := 1; i <= utf8.UTFMax && i <= len(data); i++ {
+		if utf8.RuneStart(data[len(data)-i]) {
+			if !utf8.FullRune(data[len(data)-i:]) {
+				return data[:len(data)-i]
+			}
+			break
+		}
+	}
+	return data
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxReplaceInTreeBytes caps the total size of the files read by one
+// replace_in_tree call.
+const maxReplaceInTreeBytes = 256 << 20
+
+type replaceInTreeArgs struct {
+	Pattern      string   `json:"pattern" jsonschema:"description=Regular expression to replace"`
+	Replacement  string   `json:"replacement" jsonschema:"description=Replacement text; $1 or ${name} insert capture groups"`
+	Path         string   `json:"path,omitempty" jsonschema:"description=Root directory (default: current directory)"`
+	Name         string   `json:"name,omitempty" jsonschema:"description=Only change files whose name matches this glob (e.g. *.go)"`
+	IgnoreCase   bool     `json:"ignore_case,omitempty" jsonschema:"description=Case-insensitive matching"`
+	ShowHidden   bool     `json:"show_hidden,omitempty" jsonschema:"description=Include hidden entries"`
+	Ignore       []string `json:"ignore,omitempty" jsonschema:"description=Gitignore-style globs of entries to skip (e.g. node_modules or build/)"`
+	UseGitignore bool     `json:"use_gitignore,omitempty" jsonschema:"description=Also skip entries matched by the .gitignore at the root of the walk"`
+	DryRun       bool     `json:"dry_run,omitempty" jsonschema:"description=Report the replacements without writing any file"`
+}
+
+// plannedReplace is one file that replace_in_tree changes.
+type plannedReplace struct {
+	Rel      string
+	Resolved string
+	Count    int
+	Content  string
+	Mode     os.FileMode
+}
+
+// replacePlan is the outcome of a replace_in_tree walk before anything is written.
+type replacePlan struct {
+	Files   []plannedReplace
+	Matches int
+	Skipped int
+}
+
+func parseReplaceInTreeArgs(args map[string]interface{}) (replaceInTreeArgs, *regexp.Regexp, error) {
+	parsed, err := unmarshalAndValidate[replaceInTreeArgs](args)
+	if err != nil {
+		return parsed, nil, err
+	}
+	if parsed.Pattern == "" {
+		return parsed, nil, fmt.Errorf("missing or invalid 'pattern' parameter")
+	}
+	pattern := parsed.Pattern
+	if parsed.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return parsed, nil, fmt.Errorf("invalid pattern: %w", err)
+	}
+	if parsed.Name != "" {
+		if _, err := filepath.Match(parsed.Name, ""); err != nil {
+			return parsed, nil, fmt.Errorf("invalid name pattern: %w", err)
+		}
+	}
+	return parsed, re, nil
+}
+
+func validateReplaceInTreeArgs(args map[string]interface{}) error {
+	_, _, err := parseReplaceInTreeArgs(args)
+	return err
+}
+
+// planReplaceInTree walks the tree within the directory limits and computes
+// the new content of every matching text file. Binary, non-UTF-8 and
+// oversized files are skipped; nothing is written.
+func planReplaceInTree(ctx context.Context, args map[string]interface{}) (replacePlan, error) {
+	var plan replacePlan
+	parsed, re, err := parseReplaceInTreeArgs(args)
+	if err != nil {
+		return plan, err
+	}
+	path := parsed.Path
+	if path == "" {
+		path = "."
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return plan, err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return plan, err
+	}
+	if !info.IsDir() {
+		return plan, fmt.Errorf("%s is not a directory", path)
+	}
+	ignore, err := ignoreRulesFromArgs(args)
+	if err != nil {
+		return plan, err
+	}
+	matcher, err := ignore.matcher(resolved)
+	if err != nil {
+		return plan, err
+	}
+
+	limits := getLimits()
+	opts := walkOptions{
+		maxDepth:    max(limits.MaxDirectoryDepth, 1),
+		maxEntries:  limits.MaxDirectoryEntries,
+		showHidden:  parsed.ShowHidden,
+		pattern:     parsed.Name,
+		regularOnly: true,
+		ignore:      matcher,
+	}
+	if opts.maxEntries <= 0 {
+		opts.maxEntries = 2000
+	}
+	entries, err := walkDirEntries(ctx, resolved, opts)
+	if err != nil {
+		return plan, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Rel < entries[j].Rel })
+
+	var total int64
+	for _, entry := range entries {
+		if err := ensureContext(ctx); err != nil {
+			return plan, err
+		}
+		info, err := os.Stat(entry.Path)
+		if err != nil {
+			return plan, err
+		}
+		if limits.MaxFileSizeBytes > 0 && info.Size() > limits.MaxFileSizeBytes {
+			plan.Skipped++
+			continue
+		}
+		total += info.Size()
+		if total > maxReplaceInTreeBytes {
+			return plan, fmt.Errorf("files under %s %w of %d bytes in total", path, ErrFileTooLarge, maxReplaceInTreeBytes)
+		}
+		data, err := os.ReadFile(entry.Path)
+		if err != nil {
+			return plan, err
+		}
+		if !isTextContent(data) {
+			plan.Skipped++
+			continue
+		}
+		rel := filepath.ToSlash(entry.Rel)
+		count := len(re.FindAllIndex(data, -1))
+		if count == 0 {
+			continue
+		}
+		updated := normalizeLineEndings(re.ReplaceAllString(string(data), parsed.Replacement))
+		if updated == string(data) {
+			continue
+		}
+		if limits.MaxFileSizeBytes > 0 && int64(len(updated)) > limits.MaxFileSizeBytes {
+			return plan, fmt.Errorf("%s: updated file %w of %d bytes", rel, ErrFileTooLarge, limits.MaxFileSizeBytes)
+		}
+		plan.Files = append(plan.Files, plannedReplace{
+			Rel:      rel,
+			Resolved: entry.Path,
+			Count:    count,
+			Content:  updated,
+			Mode:     info.Mode().Perm(),
+		})
+		plan.Matches += count
+	}
+	return plan, nil
+}
+
+// replaceInTree applies a regular expression replacement to every matching
+// text file under a directory and reports the count per file. All files are
+// planned before the first one is written.
+func replaceInTree(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	plan, err := planReplaceInTree(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	skipped := ""
+	if plan.Skipped > 0 {
+		skipped = fmt.Sprintf(" (skipped %d binary or oversized files)", plan.Skipped)
+	}
+	if len(plan.Files) == 0 {
+		return "No matches found" + skipped, nil
+	}
+
+	var b strings.Builder
+	for _, file := range plan.Files {
+		fmt.Fprintf(&b, "\n  %s: %d", file.Rel, file.Count)
+	}
+	if getBoolArg(args, "dry_run") {
+		return fmt.Sprintf("Dry run, nothing written: would replace %d matches in %d files%s:%s", plan.Matches, len(plan.Files), skipped, b.String()), nil
+	}
+	for i, file := range plan.Files {
+		if err := ensureContext(ctx); err != nil {
+			return "", fmt.Errorf("stopped after %d of %d files: %w", i, len(plan.Files), err)
+		}
+		if err := os.WriteFile(file.Resolved, []byte(file.Content), file.Mode); err != nil {
+			return "", fmt.Errorf("stopped after %d of %d files: failed to write %s: %v", i, len(plan.Files), file.Rel, err)
+		}
+	}
+	return fmt.Sprintf("Replaced %d matches in %d files%s:%s", plan.Matches, len(plan.Files), skipped, b.String()), nil
+}
+
+// summarizeReplaceInTree lists the files a replace_in_tree call would change.
+func summarizeReplaceInTree(ctx context.Context, args map[string]interface{}) string {
+	if getBoolArg(args, "dry_run") {
+		return ""
+	}
+	plan, err := planReplaceInTree(ctx, args)
+	if err != nil || len(plan.Files) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(plan.Files))
+	for _, file := range plan.Files {
+		lines = append(lines, fmt.Sprintf("%s (%d matches)", file.Rel, file.Count))
+	}
+	return formatSummary(fmt.Sprintf("replace_in_tree will change %d file(s):", len(plan.Files)), lines)
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeReplaceTree(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"main.go":      "package main\n\nfunc oldName() {}\n\nvar _ = oldName\n",
+		"sub/util.go":  "package sub\n\n// oldName is gone\n",
+		"notes.txt":    "oldName in prose\n",
+		"image.bin":    "oldName\x00\x01\x02",
+		".hidden/x.go": "oldName\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+}
+
+func TestReplaceInTree(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"replace_in_tree": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	writeReplaceTree(t, absDir)
+
+	result := registry.Execute("replace_in_tree", map[string]interface{}{
+		"path":        relDir,
+		"pattern":     `old(Name)`,
+		"replacement": "new$1",
+		"name":        "*.go",
+	})
+	if result.Error != nil {
+		t.Fatalf("replace_in_tree failed: %v", result.Error)
+	}
+	want := "Replaced 3 matches in 2 files:\n  main.go: 2\n  sub/util.go: 1"
+	if result.Result != want {
+		t.Fatalf("unexpected result:\n%s\nwant:\n%s", result.Result, want)
+	}
+	assertFileContent(t, filepath.Join(absDir, "main.go"), "package main\n\nfunc newName() {}\n\nvar _ = newName\n")
+	assertFileContent(t, filepath.Join(absDir, "sub", "util.go"), "package sub\n\n// newName is gone\n")
+	assertFileContent(t, filepath.Join(absDir, "notes.txt"), "oldName in prose\n")
+	assertFileContent(t, filepath.Join(absDir, ".hidden", "x.go"), "oldName\n")
+}
+
+func TestReplaceInTreeDryRun(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"replace_in_tree": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	writeReplaceTree(t, absDir)
+
+	result := registry.Execute("replace_in_tree", map[string]interface{}{
+		"path":        relDir,
+		"pattern":     "oldname",
+		"replacement": "newName",
+		"ignore_case": true,
+		"dry_run":     true,
+	})
+	if result.Error != nil {
+		t.Fatalf("replace_in_tree failed: %v", result.Error)
+	}
+	want := "Dry run, nothing written: would replace 4 matches in 3 files (skipped 1 binary or oversized files):\n  main.go: 2\n  notes.txt: 1\n  sub/util.go: 1"
+	if result.Result != want {
+		t.Fatalf("unexpected result:\n%s\nwant:\n%s", result.Result, want)
+	}
+	assertFileContent(t, filepath.Join(absDir, "main.go"), "package main\n\nfunc oldName() {}\n\nvar _ = oldName\n")
+}
+
+func TestReplaceInTreeSkipsOversizedFiles(t *testing.T) {
+	limits := DefaultLimits()
+	limits.MaxFileSizeBytes = 32
+	ConfigureLimits(limits)
+	t.Cleanup(func() {
+		ConfigureLimits(DefaultLimits())
+	})
+
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"replace_in_tree": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	writeTestFile(t, absDir, "small.txt", "foo\n")
+	writeTestFile(t, absDir, "large.txt", strings.Repeat("foo\n", 20))
+
+	result := registry.Execute("replace_in_tree", map[string]interface{}{
+		"path":        relDir,
+		"pattern":     "foo",
+		"replacement": "bar",
+	})
+	if result.Error != nil {
+		t.Fatalf("replace_in_tree failed: %v", result.Error)
+	}
+	if !strings.Contains(result.Result, "Replaced 1 matches in 1 files (skipped 1 binary or oversized files)") {
+		t.Fatalf("unexpected result: %q", result.Result)
+	}
+	assertFileContent(t, filepath.Join(absDir, "small.txt"), "bar\n")
+
+	// A replacement that grows a file past the limit writes nothing.
+	writeTestFile(t, absDir, "grow.txt", "foo foo\n")
+	result = registry.Execute("replace_in_tree", map[string]interface{}{
+		"path":        relDir,
+		"pattern":     "(foo|bar)",
+		"replacement": strings.Repeat("x", 20),
+	})
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "exceeds maximum size") {
+		t.Fatalf("expected a size error, got %v", result.Error)
+	}
+	assertFileContent(t, filepath.Join(absDir, "small.txt"), "bar\n")
+}
+
+func TestReplaceInTreeRejectsInvalidArgs(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"replace_in_tree": true,
+		},
+	})
+	_, relDir := tempDirInCwd(t)
+	tests := []struct {
+		name string
+		args map[string]interface{}
+	}{
+		{"bad pattern", map[string]interface{}{"path": relDir, "pattern": "(", "replacement": "x"}},
+		{"bad glob", map[string]interface{}{"path": relDir, "pattern": "a", "replacement": "x", "name": "["}},
+		{"outside work dir", map[string]interface{}{"path": "/etc", "pattern": "a", "replacement": "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := registry.Execute("replace_in_tree", tt.args); result.Error == nil {
+				t.Fatalf("expected an error, got %q", result.Result)
+			}
+		})
+	}
+}
+
+func TestReplaceInTreeAsksByDefault(t *testing.T) {
+	registry := NewRegistry()
+	if level := registry.GetPermission("replace_in_tree").Level; level != PermissionAsk {
+		t.Fatalf("expected replace_in_tree to ask for approval, got %q", level)
+	}
+	absDir, relDir := tempDirInCwd(t)
+	writeReplaceTree(t, absDir)
+	summary := summarizeReplaceInTree(context.Background(), map[string]interface{}{"path": relDir, "pattern": "oldName", "replacement": "x", "name": "*.go"})
+	if summary != "replace_in_tree will change 2 file(s):\n  main.go (2 matches)\n  sub/util.go (1 matches)" {
+		t.Fatalf("unexpected summary: %q", summary)
+	}
+}
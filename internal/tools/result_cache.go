@@ -0,0 +1,162 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// CacheConfig configures result caching for cacheable tools.
+type CacheConfig struct {
+	MaxEntries int // 0 disables the cache
+	TTL        time.Duration
+}
+
+// DefaultCacheConfig returns the default cache configuration: disabled, with a
+// 30 second TTL once enabled.
+func DefaultCacheConfig() CacheConfig {
+	return CacheConfig{TTL: 30 * time.Second}
+}
+
+// CacheableTool is implemented by read-only tools whose results can be reused
+// for an identical call until another tool changes something.
+type CacheableTool interface {
+	Cacheable() bool
+}
+
+// CacheableCallTool is implemented by cacheable tools that cannot serve every
+// call from the cache, such as read_file with show_diff.
+type CacheableCallTool interface {
+	CacheableCall(args map[string]interface{}) bool
+}
+
+func isCacheable(tool Tool) bool {
+	cacheable, ok := tool.(CacheableTool)
+	return ok && cacheable.Cacheable()
+}
+
+func isCacheableCall(tool Tool, args map[string]interface{}) bool {
+	if !isCacheable(tool) {
+		return false
+	}
+	call, ok := tool.(CacheableCallTool)
+	return !ok || call.CacheableCall(args)
+}
+
+// resultCache is a small LRU of tool results with a fixed TTL.
+type resultCache struct {
+	mu      sync.Mutex
+	config  CacheConfig
+	entries map[string]*list.Element
+	order   *list.List // front is most recently used
+	now     func() time.Time
+}
+
+type cacheEntry struct {
+	key     string
+	result  string
+	expires time.Time
+}
+
+func newResultCache(config CacheConfig) *resultCache {
+	return &resultCache{
+		config:  config,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+func (c *resultCache) enabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.MaxEntries > 0 && c.config.TTL > 0
+}
+
+func (c *resultCache) configure(config CacheConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config = config
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+func (c *resultCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(elem)
+	return entry.result, true
+}
+
+func (c *resultCache) put(key, result string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.config.MaxEntries <= 0 {
+		return
+	}
+	expires := c.now().Add(c.config.TTL)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.result, entry.expires = result, expires
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result, expires: expires})
+	for c.order.Len() > c.config.MaxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+func (c *resultCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) == 0 {
+		return
+	}
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// toolCacheKey identifies a call by tool name, arguments and working directory,
+// since relative paths resolve differently after cd.
+func toolCacheKey(ctx context.Context, name string, args map[string]interface{}) (string, bool) {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return "", false
+	}
+	workdir, err := toolWorkdir(ctx)
+	if err != nil {
+		return "", false
+	}
+	return name + "\x00" + workdir + "\x00" + string(encoded), true
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newCacheTestRegistry(t *testing.T, config CacheConfig) (*Registry, *int) {
+	t.Helper()
+	reads := 0
+	registry := NewRegistry()
+	for _, tool := range []*ToolDefinition{
+		{
+			NameValue:       "count_read",
+			ParametersValue: map[string]interface{}{"type": "object"},
+			ExecuteFunc: func(ctx context.Context, args map[string]interface{}) (string, error) {
+				reads++
+				return fmt.Sprintf("read %d", reads), nil
+			},
+			CacheableValue: true,
+			VersionValue:   builtinToolVersion,
+		},
+		{
+			NameValue:       "count_write",
+			ParametersValue: map[string]interface{}{"type": "object"},
+			ExecuteFunc: func(ctx context.Context, args map[string]interface{}) (string, error) {
+				return "written", nil
+			},
+			VersionValue: builtinToolVersion,
+		},
+	} {
+		if err := registry.RegisterTool(tool); err != nil {
+			t.Fatalf("failed to register %s: %v", tool.NameValue, err)
+		}
+		registry.AllowTool(tool.NameValue, false)
+	}
+	registry.ConfigureCache(config)
+	return registry, &reads
+}
+
+func TestResultCacheReusesReads(t *testing.T) {
+	registry, reads := newCacheTestRegistry(t, CacheConfig{MaxEntries: 8, TTL: time.Minute})
+
+	first := registry.Execute("count_read", map[string]interface{}{"path": "a"})
+	second := registry.Execute("count_read", map[string]interface{}{"path": "a"})
+	if first.Error != nil || second.Error != nil {
+		t.Fatalf("unexpected errors: %v, %v", first.Error, second.Error)
+	}
+	if *reads != 1 || second.Result != "read 1" {
+		t.Fatalf("expected cached result without re-executing, got %q after %d reads", second.Result, *reads)
+	}
+
+	registry.Execute("count_read", map[string]interface{}{"path": "b"})
+	if *reads != 2 {
+		t.Fatalf("expected different args to execute, got %d reads", *reads)
+	}
+
+	registry.Execute("count_write", map[string]interface{}{})
+	if result := registry.Execute("count_read", map[string]interface{}{"path": "a"}); result.Result != "read 3" {
+		t.Fatalf("expected a write to bust the cache, got %q", result.Result)
+	}
+}
+
+func TestResultCacheDisabledByDefault(t *testing.T) {
+	registry, reads := newCacheTestRegistry(t, DefaultCacheConfig())
+	registry.Execute("count_read", map[string]interface{}{})
+	registry.Execute("count_read", map[string]interface{}{})
+	if *reads != 2 {
+		t.Fatalf("expected no caching by default, got %d reads", *reads)
+	}
+}
+
+func TestResultCacheExpiryAndEviction(t *testing.T) {
+	now := time.Now()
+	cache := newResultCache(CacheConfig{MaxEntries: 2, TTL: time.Second})
+	cache.now = func() time.Time { return now }
+
+	cache.put("a", "1")
+	cache.put("b", "2")
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.put("c", "3")
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected least recently used entry b to be evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Error("expected recently used entry a to survive")
+	}
+
+	now = now.Add(2 * time.Second)
+	if _, ok := cache.get("c"); ok {
+		t.Error("expected c to expire after the TTL")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRunOutputBytes caps each of the stdout and stderr sections of run and time.
+	maxRunOutputBytes = 64 << 10
+	// defaultRunTimeout bounds a program when the call sets no timeout_seconds.
+	defaultRunTimeout = 2 * time.Minute
+	maxRunTimeout     = 30 * time.Minute
+)
+
+type runArgs struct {
+	Command        string   `json:"command" jsonschema:"description=Program to run: a name looked up in PATH or a path inside the working directory"`
+	Args           []string `json:"args,omitempty" jsonschema:"description=Arguments passed to the program as they are (no shell expansion)"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty" jsonschema:"description=Kill the program after this many seconds (default 120)"`
+}
+
+// runTool runs a program in the tool working directory without a shell and
+// returns its exit code with stdout and stderr in separate sections, so
+// diagnostics are not mixed into the output. A non-zero exit is a result,
+// not a tool failure.
+func runTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	result, err := runProgram(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%s)\n%s", result.status, result.elapsed.Round(time.Millisecond), result.sections()), nil
+}
+
+// timeTool runs a program like run and reports how long it took, like the
+// shell's time: wall-clock time from start to exit, and the CPU time the
+// program and its children spent in user and kernel mode.
+func timeTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	result, err := runProgram(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", result.status)
+	fmt.Fprintf(&b, "real %.3fs\n", result.elapsed.Seconds())
+	if result.state != nil {
+		fmt.Fprintf(&b, "user %.3fs\n", result.state.UserTime().Seconds())
+		fmt.Fprintf(&b, "sys  %.3fs\n", result.state.SystemTime().Seconds())
+	}
+	b.WriteString(result.sections())
+	return b.String(), nil
+}
+
+// runResult is the outcome of a program started by run or time.
+type runResult struct {
+	status  string
+	elapsed time.Duration
+	state   *os.ProcessState
+	stdout  *cappedBuffer
+	stderr  *cappedBuffer
+}
+
+// sections returns the labeled stdout and stderr of the program.
+func (r *runResult) sections() string {
+	return "--- stdout ---\n" + r.stdout.section() + "--- stderr ---\n" + r.stderr.section()
+}
+
+// runProgram runs the program described by args, a runArgs, in the tool
+// working directory. Only the time between starting the program and its
+// exit is measured, not the lookup and setup around it.
+func runProgram(ctx context.Context, args map[string]interface{}) (*runResult, error) {
+	if err := ensureContext(ctx); err != nil {
+		return nil, err
+	}
+	parsed, err := unmarshalAndValidate[runArgs](args)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRunArgs(args); err != nil {
+		return nil, err
+	}
+	program, err := resolveRunProgram(ctx, parsed.Command)
+	if err != nil {
+		return nil, err
+	}
+	workdir, err := toolWorkdir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine working directory: %v", err)
+	}
+
+	timeout := defaultRunTimeout
+	if parsed.TimeoutSeconds > 0 {
+		timeout = time.Duration(parsed.TimeoutSeconds) * time.Second
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, program, parsed.Args...)
+	cmd.Dir = workdir
+	// Children that keep the pipes open must not hold the tool forever.
+	cmd.WaitDelay = 2 * time.Second
+	result := &runResult{
+		status: "exit code: 0",
+		stdout: &cappedBuffer{limit: maxRunOutputBytes},
+		stderr: &cappedBuffer{limit: maxRunOutputBytes},
+	}
+	cmd.Stdout = result.stdout
+	cmd.Stderr = result.stderr
+
+	start := time.Now()
+	if err = cmd.Start(); err == nil {
+		err = cmd.Wait()
+	}
+	result.elapsed = time.Since(start)
+	result.state = cmd.ProcessState
+	if err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case runCtx.Err() != nil:
+			result.status = fmt.Sprintf("killed after %s timeout", timeout)
+		case errors.As(err, &exitErr):
+			result.status = fmt.Sprintf("exit code: %d", exitErr.ExitCode())
+		default:
+			return nil, fmt.Errorf("failed to run %s: %v", parsed.Command, err)
+		}
+	}
+	return result, nil
+}
+
+// validateRunArgs checks the arguments of run and time: a non-empty command of
+// path length without NUL or line breaks, arguments without NUL bytes, and a
+// timeout within maxRunTimeout. The program itself is resolved later by
+// resolveRunProgram, under the usual tool path rules.
+func validateRunArgs(args map[string]interface{}) error {
+	command, _ := getStringLike(args["command"])
+	if strings.TrimSpace(command) == "" {
+		return fmt.Errorf("%w: command is required", ErrInvalidArguments)
+	}
+	if len(command) > maxPathLength || strings.ContainsAny(command, "\x00\n\r") {
+		return fmt.Errorf("%w: invalid command %q", ErrInvalidArguments, command)
+	}
+	if list, ok := args["args"].([]interface{}); ok {
+		for _, arg := range list {
+			if s, ok := arg.(string); ok && strings.ContainsRune(s, 0) {
+				return fmt.Errorf("%w: arguments cannot contain NUL bytes", ErrInvalidArguments)
+			}
+		}
+	}
+	timeout, err := extractIntArg(args, "timeout_seconds", 0)
+	if err != nil {
+		return err
+	}
+	if timeout < 0 || time.Duration(timeout)*time.Second > maxRunTimeout {
+		return fmt.Errorf("%w: timeout_seconds must be between 0 and %d", ErrInvalidArguments, int(maxRunTimeout/time.Second))
+	}
+	return nil
+}
+
+// resolveRunProgram finds the program to run. A bare name is looked up in
+// PATH; anything with a slash is a path subject to the usual tool path rules.
+func resolveRunProgram(ctx context.Context, command string) (string, error) {
+	if !strings.ContainsRune(command, '/') && !strings.ContainsRune(command, os.PathSeparator) {
+		program, err := exec.LookPath(command)
+		if err != nil {
+			return "", fmt.Errorf("%s: command not found", command)
+		}
+		return program, nil
+	}
+	return resolveToolPath(ctx, command)
+}
+
+// cappedBuffer keeps the first limit bytes written to it and counts the rest.
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+	total int
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	c.total += len(p)
+	if room := c.limit - c.buf.Len(); room > 0 {
+		if len(p) > room {
+			c.buf.Write(p[:room])
+		} else {
+			c.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// section returns the captured text for a labeled section of run's result.
+func (c *cappedBuffer) section() string {
+	if c.total == 0 {
+		return "(empty)\n"
+	}
+	text := c.buf.String()
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	if c.total > c.buf.Len() {
+		text += fmt.Sprintf("[truncated at %d of %d bytes]\n", c.buf.Len(), c.total)
+	}
+	return text
+}
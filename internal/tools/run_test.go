@@ -0,0 +1,138 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+}
+
+func TestRunSeparatesStdoutAndStderr(t *testing.T) {
+	requireShell(t)
+	if level := NewRegistry().GetPermission("run").Level; level != PermissionAsk {
+		t.Fatalf("expected run to ask by default, got %s", level)
+	}
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"run": true,
+		},
+	})
+
+	result := registry.Execute("run", map[string]interface{}{
+		"command": "sh",
+		"args":    []interface{}{"-c", "echo out; echo problem >&2; echo more; exit 3"},
+	})
+	if result.Error != nil {
+		t.Fatalf("run failed: %v", result.Error)
+	}
+	if !strings.HasPrefix(result.Result, "exit code: 3 (") {
+		t.Fatalf("expected the exit code first, got %q", result.Result)
+	}
+	_, rest, _ := strings.Cut(result.Result, "--- stdout ---\n")
+	stdout, stderr, ok := strings.Cut(rest, "--- stderr ---\n")
+	if !ok || stdout != "out\nmore\n" || stderr != "problem\n" {
+		t.Fatalf("expected separate sections, got stdout %q and stderr %q", stdout, stderr)
+	}
+
+	result = registry.Execute("run", map[string]interface{}{"command": "sh", "args": []interface{}{"-c", "true"}})
+	if result.Error != nil || !strings.HasPrefix(result.Result, "exit code: 0") || strings.Count(result.Result, "(empty)") != 2 {
+		t.Fatalf("expected a clean exit with empty sections, got %q, %v", result.Result, result.Error)
+	}
+}
+
+func TestRunLimits(t *testing.T) {
+	requireShell(t)
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"run": true,
+		},
+	})
+
+	result := registry.Execute("run", map[string]interface{}{
+		"command":         "sh",
+		"args":            []interface{}{"-c", "echo started; sleep 5"},
+		"timeout_seconds": 1,
+	})
+	if result.Error != nil || !strings.HasPrefix(result.Result, "killed after 1s timeout") || !strings.Contains(result.Result, "started") {
+		t.Fatalf("expected the program killed with its output kept, got %q, %v", result.Result, result.Error)
+	}
+
+	result = registry.Execute("run", map[string]interface{}{
+		"command": "sh",
+		"args":    []interface{}{"-c", "head -c 70000 /dev/zero | tr '\\0' x"},
+	})
+	if result.Error != nil || !strings.Contains(result.Result, "[truncated at 65536 of 70000 bytes]") {
+		t.Fatalf("expected stdout to be capped, got %d bytes, %v", len(result.Result), result.Error)
+	}
+
+	if result := registry.Execute("run", map[string]interface{}{"command": "promptline-no-such-program"}); result.Error == nil ||
+		!strings.Contains(result.Error.Error(), "command not found") {
+		t.Fatalf("expected a missing program to fail, got %q, %v", result.Result, result.Error)
+	}
+	for _, args := range []map[string]interface{}{
+		{"command": "  "},
+		{"command": "sh\nrm"},
+		{"command": "sh", "timeout_seconds": -1},
+	} {
+		if result := registry.Execute("run", args); !errors.Is(result.Error, ErrInvalidArguments) {
+			t.Fatalf("expected %v to be rejected, got %q, %v", args, result.Result, result.Error)
+		}
+	}
+	if result := registry.Execute("run", map[string]interface{}{"command": "../outside/sh"}); result.Error == nil {
+		t.Fatalf("expected a path outside the working directory to be refused, got %q", result.Result)
+	}
+}
+
+func TestTimeReportsDuration(t *testing.T) {
+	requireShell(t)
+	if level := NewRegistry().GetPermission("time").Level; level != PermissionAsk {
+		t.Fatalf("expected time to ask by default, got %s", level)
+	}
+	registry := NewRegistryWithPolicy(Policy{Allow: map[string]bool{"time": true}})
+
+	result := registry.Execute("time", map[string]interface{}{
+		"command": "sh",
+		"args":    []interface{}{"-c", "sleep 0.2; echo done"},
+	})
+	if result.Error != nil {
+		t.Fatalf("time failed: %v", result.Error)
+	}
+	lines := strings.Split(result.Result, "\n")
+	if len(lines) < 4 || lines[0] != "exit code: 0" || !strings.HasPrefix(lines[2], "user ") || !strings.HasPrefix(lines[3], "sys  ") {
+		t.Fatalf("unexpected time report: %q", result.Result)
+	}
+	var real float64
+	if _, err := fmt.Sscanf(lines[1], "real %fs", &real); err != nil {
+		t.Fatalf("expected a real time line, got %q: %v", lines[1], err)
+	}
+	if real < 0.2 || real > 5 {
+		t.Fatalf("expected about 0.2s of wall-clock time, got %.3fs", real)
+	}
+	if !strings.Contains(result.Result, "--- stdout ---\ndone\n") {
+		t.Fatalf("expected the program output, got %q", result.Result)
+	}
+}
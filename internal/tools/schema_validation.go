@@ -0,0 +1,237 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// validateSchemaArgs checks args against a tool's JSON schema parameters:
+// required fields, property types, enum values and array item types. Keys
+// without a schema are accepted, as are schemas this validator does not
+// understand, so tools keep their own ValidateFunc for anything beyond types.
+//
+// A top-level "path" follows extractPathArg instead of its declared schema, so
+// the aliases and wrapped values models sometimes send keep working.
+func validateSchemaArgs(schema map[string]interface{}, args map[string]interface{}) error {
+	if value, ok := args["path"]; ok {
+		if _, isString := value.(string); !isString {
+			if path, ok := getStringLike(value); ok {
+				unwrapped := make(map[string]interface{}, len(args))
+				for key, value := range args {
+					unwrapped[key] = value
+				}
+				unwrapped["path"] = path
+				args = unwrapped
+			}
+		}
+	}
+	return validateSchemaObject(schema, args, "")
+}
+
+func validateSchemaObject(schema map[string]interface{}, args map[string]interface{}, prefix string) error {
+	for _, name := range schemaStrings(schema["required"]) {
+		if _, ok := args[name]; !ok {
+			if prefix == "" && name == "path" {
+				if _, err := extractPathArg(args); err == nil {
+					continue
+				}
+			}
+			return fmt.Errorf("missing or invalid '%s' parameter: field is required", prefix+name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := properties[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if err := validateSchemaValue(property, args[name], prefix+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSchemaValue(schema map[string]interface{}, value interface{}, name string) error {
+	if types := schemaStrings(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, typ := range types {
+			if schemaTypeMatches(typ, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("missing or invalid '%s' parameter: expected %s, got %s", name, strings.Join(types, " or "), schemaTypeName(value))
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		if !schemaEnumContains(enum, value) {
+			options := make([]string, len(enum))
+			for i, option := range enum {
+				options[i] = fmt.Sprint(option)
+			}
+			return fmt.Errorf("missing or invalid '%s' parameter: expected one of %s", name, strings.Join(options, ", "))
+		}
+	}
+
+	switch typed := value.(type) {
+	case []interface{}:
+		items, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for i, item := range typed {
+			if err := validateSchemaValue(items, item, fmt.Sprintf("%s[%d]", name, i)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		if _, ok := schema["properties"]; ok {
+			return validateSchemaObject(schema, typed, name+".")
+		}
+	}
+	return nil
+}
+
+// schemaTypeMatches reports whether value is a valid instance of a JSON schema
+// type. Go numeric types are accepted alongside float64 so that callers
+// building args in code validate the same as decoded JSON.
+func schemaTypeMatches(typ string, value interface{}) bool {
+	switch typ {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		number, ok := schemaNumber(value)
+		return ok && number == math.Trunc(number)
+	case "number":
+		_, ok := schemaNumber(value)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		if !ok && value != nil {
+			kind := reflect.TypeOf(value).Kind()
+			ok = kind == reflect.Slice || kind == reflect.Array
+		}
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+func schemaNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func schemaTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	}
+	if number, ok := schemaNumber(value); ok {
+		if number == math.Trunc(number) {
+			return "integer"
+		}
+		return "number"
+	}
+	if schemaTypeMatches("array", value) {
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func schemaEnumContains(enum []interface{}, value interface{}) bool {
+	number, isNumber := schemaNumber(value)
+	for _, option := range enum {
+		if optionNumber, ok := schemaNumber(option); ok && isNumber {
+			if optionNumber == number {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(option, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaStrings reads a schema keyword that holds a string or a list of strings.
+func schemaStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type schemaFixtureArgs struct {
+	Name  string   `json:"name" jsonschema:"required"`
+	Count int      `json:"count,omitempty"`
+	Mode  string   `json:"mode,omitempty" jsonschema:"enum=fast,enum=slow"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func newSchemaFixtureRegistry(t *testing.T) (*Registry, *int) {
+	t.Helper()
+	calls := 0
+	registry := NewRegistry()
+	if err := registry.RegisterTool(&ToolDefinition{
+		NameValue:        "schema_tool",
+		DescriptionValue: "schema fixture",
+		ParametersValue:  mustSchemaParametersFor[schemaFixtureArgs](),
+		ExecuteFunc: func(ctx context.Context, args map[string]interface{}) (string, error) {
+			calls++
+			return "ran", nil
+		},
+		VersionValue: builtinToolVersion,
+	}); err != nil {
+		t.Fatalf("failed to register schema tool: %v", err)
+	}
+	registry.AllowTool("schema_tool", false)
+	return registry, &calls
+}
+
+func TestSchemaValidationRejectsBadArgs(t *testing.T) {
+	registry, calls := newSchemaFixtureRegistry(t)
+
+	tests := []struct {
+		name     string
+		args     map[string]interface{}
+		argsJSON string
+		want     string
+	}{
+		{"missing required", map[string]interface{}{"count": 1}, `{"count":1}`, "'name' parameter: field is required"},
+		{"wrong type", map[string]interface{}{"name": "a", "count": "three"}, `{"name":"a","count":"three"}`, "'count' parameter: expected integer, got string"},
+		{"fractional integer", map[string]interface{}{"name": "a", "count": 1.5}, `{"name":"a","count":1.5}`, "'count' parameter: expected integer, got number"},
+		{"enum", map[string]interface{}{"name": "a", "mode": "medium"}, `{"name":"a","mode":"medium"}`, "'mode' parameter: expected one of fast, slow"},
+		{"array item", map[string]interface{}{"name": "a", "tags": []interface{}{"x", 2.0}}, `{"name":"a","tags":["x",2]}`, "'tags[1]' parameter: expected string, got integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := registry.Execute("schema_tool", tt.args)
+			if !errors.Is(result.Error, ErrInvalidArguments) {
+				t.Fatalf("expected ErrInvalidArguments, got %v", result.Error)
+			}
+			if !strings.Contains(result.Error.Error(), tt.want) {
+				t.Errorf("expected %q in %q", tt.want, result.Error.Error())
+			}
+
+			if invalid := registry.ValidateToolCall("schema_tool", tt.argsJSON); invalid == nil || !strings.Contains(invalid.Error.Error(), tt.want) {
+				t.Errorf("ValidateToolCall: expected %q, got %+v", tt.want, invalid)
+			}
+		})
+	}
+	if *calls != 0 {
+		t.Fatalf("tool should not run with invalid args, ran %d times", *calls)
+	}
+
+	result := registry.Execute("schema_tool", map[string]interface{}{"name": "a", "count": 3, "mode": "fast", "tags": []interface{}{"x"}, "extra": true})
+	if result.Error != nil || result.Result != "ran" {
+		t.Fatalf("expected valid args to run, got %v %q", result.Error, result.Result)
+	}
+}
+
+func TestSchemaValidationKeepsFlexiblePath(t *testing.T) {
+	schema := mustSchemaParametersFor[viewCodeArgs]()
+	for _, args := range []map[string]interface{}{
+		{"path": "a.go"},
+		{"path": []interface{}{"a.go"}},
+		{"path": map[string]interface{}{"path": "a.go"}},
+		{"file": "a.go"},
+	} {
+		if err := validateSchemaArgs(schema, args); err != nil {
+			t.Errorf("expected %v to pass, got %v", args, err)
+		}
+	}
+	if err := validateSchemaArgs(schema, map[string]interface{}{"path": 3.0}); err == nil {
+		t.Error("expected numeric path to be rejected")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultTableRows  = 50
+	maxTableRows      = 1000
+	maxTableColumns   = 20
+	maxTableCellWidth = 40
+)
+
+// tableTool renders CSV data from a file or inline content as an aligned,
+// bordered text table. The first record is used as the header.
+func tableTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	if err := validateTableArgs(args); err != nil {
+		return "", err
+	}
+	maxRows, err := extractIntArg(args, "max_rows", defaultTableRows)
+	if err != nil {
+		return "", err
+	}
+	data, err := tableInput(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	records, err := parseTableCSV(ctx, data, maxRows+1)
+	if err != nil {
+		return "", err
+	}
+	if records.total == 0 {
+		return "empty table", nil
+	}
+	return renderTable(records), nil
+}
+
+// tableInput returns the CSV text from the path or content argument.
+func tableInput(ctx context.Context, args map[string]interface{}) (string, error) {
+	if content, ok := args["content"].(string); ok && content != "" {
+		limits := getLimits()
+		if limits.MaxFileSizeBytes > 0 && int64(len(content)) > limits.MaxFileSizeBytes {
+			return "", fmt.Errorf("content %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
+		}
+		return content, nil
+	}
+	path, err := extractPathArg(args)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	data, err := readFileLimited(resolved, false)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// tableRecords holds the parsed records kept for display and the totals
+// needed to report what was left out.
+type tableRecords struct {
+	rows    [][]string
+	total   int
+	columns int
+}
+
+// parseTableCSV reads every record to count them but keeps at most keep rows.
+func parseTableCSV(ctx context.Context, data string, keep int) (tableRecords, error) {
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	var records tableRecords
+	for {
+		if err := ensureContext(ctx); err != nil {
+			return tableRecords{}, err
+		}
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return tableRecords{}, fmt.Errorf("malformed CSV: %v", err)
+		}
+		records.total++
+		records.columns = max(records.columns, len(record))
+		if len(records.rows) < keep {
+			records.rows = append(records.rows, record)
+		}
+	}
+}
+
+func renderTable(records tableRecords) string {
+	columns := min(records.columns, maxTableColumns)
+	widths := make([]int, columns)
+	cells := make([][]string, len(records.rows))
+	for i, record := range records.rows {
+		cells[i] = make([]string, columns)
+		for j := 0; j < columns && j < len(record); j++ {
+			cell := tableCell(record[j])
+			cells[i][j] = cell
+			widths[j] = max(widths[j], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var border strings.Builder
+	border.WriteByte('+')
+	for _, width := range widths {
+		border.WriteString(strings.Repeat("-", width+2))
+		border.WriteByte('+')
+	}
+
+	var b strings.Builder
+	b.WriteString(border.String())
+	for i, row := range cells {
+		b.WriteString("\n|")
+		for j, cell := range row {
+			fmt.Fprintf(&b, " %s%s |", cell, strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell)))
+		}
+		if i == 0 && len(cells) > 1 {
+			b.WriteString("\n" + border.String())
+		}
+	}
+	b.WriteString("\n" + border.String())
+
+	if shown := len(cells) - 1; records.total-1 > shown {
+		fmt.Fprintf(&b, "\n(showing %d of %d rows)", shown, records.total-1)
+	}
+	if omitted := records.columns - columns; omitted > 0 {
+		fmt.Fprintf(&b, "\n(%d columns omitted)", omitted)
+	}
+	return b.String()
+}
+
+// tableCell flattens a field onto one line and shortens long values.
+func tableCell(field string) string {
+	field = strings.Join(strings.Fields(field), " ")
+	if utf8.RuneCountInString(field) <= maxTableCellWidth {
+		return field
+	}
+	runes := []rune(field)
+	return string(runes[:maxTableCellWidth-1]) + "…"
+}
+
+func validateTableArgs(args map[string]interface{}) error {
+	content, _ := args["content"].(string)
+	path, _ := args["path"].(string)
+	if (content == "") == (strings.TrimSpace(path) == "") {
+		return fmt.Errorf("provide either 'path' or 'content'")
+	}
+	maxRows, err := extractIntArg(args, "max_rows", defaultTableRows)
+	if err != nil {
+		return err
+	}
+	if maxRows < 1 || maxRows > maxTableRows {
+		return fmt.Errorf("max_rows must be between 1 and %d", maxTableRows)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// tempDirName is the directory under the working directory where mktemp
+// creates files.
+const tempDirName = ".tmp"
+
+var (
+	createdTempsMu sync.Mutex
+	createdTemps   []string
+)
+
+func trackTempPath(path string) {
+	createdTempsMu.Lock()
+	defer createdTempsMu.Unlock()
+	createdTemps = append(createdTemps, path)
+}
+
+// RemoveCreatedTemps deletes the files and directories mktemp created since
+// the last call and returns how many were removed. Paths that are already
+// gone are skipped; only entries directly inside a .tmp directory are touched.
+func RemoveCreatedTemps() (int, error) {
+	createdTempsMu.Lock()
+	paths := createdTemps
+	createdTemps = nil
+	createdTempsMu.Unlock()
+
+	removed := 0
+	var errs []error
+	for _, path := range paths {
+		if filepath.Base(filepath.Dir(path)) != tempDirName {
+			continue
+		}
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// CleanTempDir empties the .tmp directory of the registry working directory
+// and returns how many entries were removed.
+func (r *Registry) CleanTempDir() (int, error) {
+	return cleanTempDir(r.toolContext())
+}
+
+func cleanTempDir(ctx context.Context) (int, error) {
+	baseResolved, err := resolveBaseDir(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tempRoot := filepath.Join(baseResolved, tempDirName)
+	info, err := os.Lstat(tempRoot)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !info.IsDir() {
+		return 0, fmt.Errorf("%s is not a directory", tempDirName)
+	}
+
+	entries, err := os.ReadDir(tempRoot)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, entry := range entries {
+		// RemoveAll deletes a symlink itself, never its target.
+		if err := os.RemoveAll(filepath.Join(tempRoot, entry.Name())); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func cleanTempTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	removed, err := cleanTempDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Removed %d entries from %s", removed, tempDirName), nil
+}
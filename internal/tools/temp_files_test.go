@@ -0,0 +1,63 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCleanTemp(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if _, err := RemoveCreatedTemps(); err != nil {
+		t.Fatalf("failed to reset temp tracking: %v", err)
+	}
+	registry := NewRegistryWithPolicy(Policy{Allow: map[string]bool{"mktemp": true, "clean_temp": true}})
+
+	file := executeTool(t, registry, "mktemp", map[string]interface{}{})
+	tempDir := executeTool(t, registry, "mktemp", map[string]interface{}{"dir": true})
+	if file.Error != nil || tempDir.Error != nil {
+		t.Fatalf("mktemp failed: %v %v", file.Error, tempDir.Error)
+	}
+	outside := filepath.Join(dir, "keep.txt")
+	if err := os.WriteFile(outside, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := executeTool(t, registry, "clean_temp", map[string]interface{}{})
+	if result.Error != nil {
+		t.Fatalf("clean_temp failed: %v", result.Error)
+	}
+	if !strings.Contains(result.Result, "Removed 2 entries") {
+		t.Errorf("unexpected clean_temp result %q", result.Result)
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, tempDirName))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected an empty %s, got %v (%v)", tempDirName, entries, err)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("expected files outside %s to stay: %v", tempDirName, err)
+	}
+
+	// The tracked paths are gone already, so nothing is left to remove.
+	if removed, err := RemoveCreatedTemps(); err != nil || removed != 0 {
+		t.Errorf("expected nothing to remove, got %d (%v)", removed, err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"bytes"
+	"sync"
+	"unicode/utf8"
+)
+
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+var (
+	textEncodingMu sync.RWMutex
+	latin1Fallback bool
+)
+
+// ConfigureLatin1Fallback sets whether text tools decode files that are not
+// valid UTF-8 as latin-1 instead of rejecting them.
+func ConfigureLatin1Fallback(enabled bool) {
+	textEncodingMu.Lock()
+	defer textEncodingMu.Unlock()
+	latin1Fallback = enabled
+}
+
+func latin1FallbackEnabled() bool {
+	textEncodingMu.RLock()
+	defer textEncodingMu.RUnlock()
+	return latin1Fallback
+}
+
+// textContent prepares file data for a text tool: a leading UTF-8 BOM is
+// stripped and the rest must be text. Data that is not valid UTF-8 but reads
+// as latin-1 text fails with ErrInvalidUTF8, or is converted to UTF-8 when the
+// latin-1 fallback is enabled. Anything else fails with ErrBinaryContent.
+func textContent(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if utf8.Valid(data) {
+		if !isTextContent(data) {
+			return nil, ErrBinaryContent
+		}
+		return data, nil
+	}
+	decoded := decodeLatin1(data)
+	if !isTextContent(decoded) {
+		return nil, ErrBinaryContent
+	}
+	if !latin1FallbackEnabled() {
+		return nil, ErrInvalidUTF8
+	}
+	return decoded, nil
+}
+
+// decodeLatin1 converts ISO-8859-1 bytes to UTF-8; every byte is one code point.
+func decodeLatin1(data []byte) []byte {
+	out := make([]byte, 0, len(data)+len(data)/4)
+	for _, b := range data {
+		out = utf8.AppendRune(out, rune(b))
+	}
+	return out
+}
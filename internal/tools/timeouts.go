@@ -33,10 +33,20 @@ func DefaultTimeoutConfig() TimeoutConfig {
 
 // TimeoutForTool returns the timeout for a tool, if configured.
 func (t TimeoutConfig) TimeoutForTool(name string) time.Duration {
+	return t.timeoutWithToolDefault(name, 0)
+}
+
+// timeoutWithToolDefault is TimeoutForTool for a tool that carries its own
+// default: a PerTool entry still wins, and a positive toolDefault takes the
+// place of Default.
+func (t TimeoutConfig) timeoutWithToolDefault(name string, toolDefault time.Duration) time.Duration {
 	if t.PerTool != nil {
 		if timeout, ok := t.PerTool[name]; ok {
 			return timeout
 		}
 	}
+	if toolDefault > 0 {
+		return toolDefault
+	}
 	return t.Default
 }
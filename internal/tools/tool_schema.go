@@ -56,16 +56,17 @@ func mustSchemaParametersFor[T any]() map[string]interface{} {
 }
 
 func schemaParametersForType(t reflect.Type) (map[string]interface{}, error) {
-	defName := t.Name()
-	schema := jsonschema.ReflectFromType(t)
-	def, ok := schema.Definitions[defName]
-	if !ok {
-		return nil, fmt.Errorf("schema definition %q not found", defName)
+	// Nested structs are inlined: tool parameters are sent without $defs, so a
+	// $ref would point nowhere.
+	reflector := &jsonschema.Reflector{ExpandedStruct: true, DoNotReference: true}
+	schema := reflector.ReflectFromType(t)
+	if schema.Properties == nil {
+		return nil, fmt.Errorf("schema for %q has no properties", t.Name())
 	}
 	params := &jsonschema.Schema{
 		Type:       "object",
-		Properties: def.Properties,
-		Required:   def.Required,
+		Properties: schema.Properties,
+		Required:   schema.Required,
 	}
 	return jsonSchemaToMap(params)
 }
@@ -16,7 +16,10 @@
 
 package tools
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // HostAPIVersion identifies the tool API version supported by this host.
 const HostAPIVersion = "v1"
@@ -39,15 +42,48 @@ type ToolPlugin interface {
 	Tools() []Tool
 }
 
+// ConfirmSummarizer is implemented by tools that can describe the concrete
+// effect of a call before it runs, for richer approval prompts. ctx carries
+// the working directory the call would run in.
+type ConfirmSummarizer interface {
+	ConfirmSummary(ctx context.Context, args map[string]interface{}) string
+}
+
+// ContextValidator is implemented by tools whose validation resolves paths,
+// so it needs the working directory the call would run in.
+type ContextValidator interface {
+	ValidateContext(ctx context.Context, args map[string]interface{}) error
+}
+
+// ReadOnlyTool is implemented by tools that can report whether they only read.
+// Read-only tools may be auto-approved with Policy.AutoApproveReadOnly. They
+// never change files, but may remember what they read, as read_file does for
+// show_diff.
+type ReadOnlyTool interface {
+	ReadOnly() bool
+}
+
+// DefaultTimeoutTool is implemented by tools that know how long a call may
+// reasonably take. The timeout applies unless tool_timeouts names the tool.
+type DefaultTimeoutTool interface {
+	DefaultTimeout() time.Duration
+}
+
 // ToolDefinition provides a default implementation of Tool.
 type ToolDefinition struct {
-	NameValue          string
-	DescriptionValue   string
-	ParametersValue    map[string]interface{}
-	ExecuteFunc        ExecutorFunc
-	ValidateFunc       func(args map[string]interface{}) error
-	VersionValue       string
-	CompatibleWithFunc func(hostVersion string) bool
+	NameValue           string
+	DescriptionValue    string
+	ParametersValue     map[string]interface{}
+	ExecuteFunc         ExecutorFunc
+	ValidateFunc        func(args map[string]interface{}) error
+	ValidateContextFunc func(ctx context.Context, args map[string]interface{}) error // replaces ValidateFunc when set
+	VersionValue        string
+	CompatibleWithFunc  func(hostVersion string) bool
+	ConfirmSummaryFunc  func(ctx context.Context, args map[string]interface{}) string
+	CacheableValue      bool                                   // read-only tool whose results may be cached
+	CacheableFunc       func(args map[string]interface{}) bool // narrows CacheableValue per call when set
+	ReadOnlyValue       bool                                   // never changes files; may remember what it read
+	DefaultTimeoutValue time.Duration                          // replaces tool_timeouts.default_seconds for this tool; 0 keeps it
 }
 
 func (t *ToolDefinition) Name() string {
@@ -70,6 +106,15 @@ func (t *ToolDefinition) Execute(ctx context.Context, args map[string]interface{
 }
 
 func (t *ToolDefinition) Validate(args map[string]interface{}) error {
+	return t.ValidateContext(context.Background(), args)
+}
+
+// ValidateContext validates args for a call running in the working directory
+// of ctx.
+func (t *ToolDefinition) ValidateContext(ctx context.Context, args map[string]interface{}) error {
+	if t.ValidateContextFunc != nil {
+		return t.ValidateContextFunc(ctx, args)
+	}
 	if t.ValidateFunc == nil {
 		return nil
 	}
@@ -80,6 +125,39 @@ func (t *ToolDefinition) Version() string {
 	return t.VersionValue
 }
 
+// ConfirmSummary describes what the call would change, or returns an empty
+// string when the tool has no summary for these arguments.
+func (t *ToolDefinition) ConfirmSummary(ctx context.Context, args map[string]interface{}) string {
+	if t.ConfirmSummaryFunc == nil {
+		return ""
+	}
+	return t.ConfirmSummaryFunc(ctx, args)
+}
+
+// Cacheable reports whether results of this tool may be served from the cache.
+func (t *ToolDefinition) Cacheable() bool {
+	return t.CacheableValue
+}
+
+// CacheableCall reports whether the result of a call with args may be served
+// from the cache.
+func (t *ToolDefinition) CacheableCall(args map[string]interface{}) bool {
+	if !t.CacheableValue {
+		return false
+	}
+	return t.CacheableFunc == nil || t.CacheableFunc(args)
+}
+
+// ReadOnly reports whether the tool never modifies files.
+func (t *ToolDefinition) ReadOnly() bool {
+	return t.ReadOnlyValue
+}
+
+// DefaultTimeout returns the tool's own timeout, or 0 for the configured default.
+func (t *ToolDefinition) DefaultTimeout() time.Duration {
+	return t.DefaultTimeoutValue
+}
+
 func (t *ToolDefinition) CompatibleWith(hostVersion string) bool {
 	if t.CompatibleWithFunc != nil {
 		return t.CompatibleWithFunc(hostVersion)
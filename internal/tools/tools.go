@@ -58,6 +58,21 @@ type Policy struct {
 	Allow map[string]bool
 	Ask   map[string]bool
 	Deny  map[string]bool
+	// AutoApproveReadOnly allows read-only tools that would otherwise ask.
+	// Tools listed in Ask or Deny keep that level.
+	AutoApproveReadOnly bool
+	// Aliases maps alternate tool names, such as "list_files", to registered
+	// tools. Registered names always win over an alias of the same name.
+	Aliases map[string]string
+	// Disabled lists tools that are removed right after registration, so
+	// they are neither offered to the model nor callable.
+	Disabled map[string]bool
+	// Advertised, when non-empty, limits the tools offered to the model to
+	// these names. The others stay registered and callable.
+	Advertised map[string]bool
+	// HideDenied leaves tools with the deny level out of the tools offered
+	// to the model until they are allowed again.
+	HideDenied bool
 }
 
 // ExecuteOptions controls how tool execution is handled.
@@ -66,6 +81,11 @@ type ExecuteOptions struct {
 	Force bool
 	// DryRun validates tool arguments but skips execution.
 	DryRun bool
+	// BaseDir scopes the call to a directory within the work root: relative
+	// paths resolve against it and commands run in it, without changing the
+	// process working directory. Relative BaseDir values resolve against the
+	// current directory.
+	BaseDir string
 }
 
 // Registry holds all available tools with their implementations.
@@ -82,6 +102,12 @@ type Registry struct {
 	rateLimits   RateLimitConfig
 	rateLimiters map[string]*toolRateLimiter
 	timeouts     TimeoutConfig
+	cache        *resultCache
+	aliases      map[string]string
+	descriptions map[string]string
+	advertised   map[string]bool
+	hideDenied   bool
+	workdir      workdirState // moved by cd; see toolContext
 }
 
 // NewRegistry creates a new tool registry and registers all built-in tools
@@ -97,11 +123,22 @@ func NewRegistryWithPolicy(policy Policy) *Registry {
 		rateLimits:   DefaultRateLimitConfig(),
 		rateLimiters: make(map[string]*toolRateLimiter),
 		timeouts:     DefaultTimeoutConfig(),
+		cache:        newResultCache(DefaultCacheConfig()),
+		aliases:      make(map[string]string),
+		descriptions: make(map[string]string),
+		advertised:   make(map[string]bool),
+		hideDenied:   policy.HideDenied,
+	}
+	for name, advertised := range policy.Advertised {
+		if advertised {
+			r.advertised[name] = true
+		}
 	}
 
 	// Register all built-in tools
 	registerBuiltInTools(r)
 	registerURootTools(r)
+	r.removeTools(policy.Disabled)
 	r.applyPolicy(DefaultPolicy())
 	r.applyPolicy(policy)
 
@@ -154,6 +191,18 @@ func (r *Registry) RegisterPlugin(plugin ToolPlugin) error {
 	return nil
 }
 
+// removeTools drops the named tools and their permissions from the registry.
+func (r *Registry) removeTools(names map[string]bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, disabled := range names {
+		if disabled {
+			delete(r.tools, name)
+			delete(r.permissions, name)
+		}
+	}
+}
+
 // applyPolicy merges the provided policy into the registry permissions.
 func (r *Registry) applyPolicy(policy Policy) {
 	r.mu.Lock()
@@ -163,14 +212,29 @@ func (r *Registry) applyPolicy(policy Policy) {
 		if !ok {
 			perm = Permission{Level: PermissionAsk}
 		}
+		if policy.AutoApproveReadOnly && perm.Level == PermissionAsk && isReadOnlyTool(r.tools[name]) {
+			perm.Level = PermissionAllow
+		}
 		perm.Level = applyPolicyLevel(perm.Level, name, policy)
 		r.permissions[name] = perm
 	}
+	for alias, target := range policy.Aliases {
+		r.aliases[alias] = target
+	}
+}
+
+func isReadOnlyTool(tool Tool) bool {
+	readOnly, ok := tool.(ReadOnlyTool)
+	return ok && readOnly.ReadOnly()
 }
 
+// defaultAllowedTools run without approval unless the policy says otherwise:
+// they only read repository state through a fixed set of git commands.
+var defaultAllowedTools = []string{"git_status", "git_diff"}
+
 // DefaultPolicy returns the default allow/ask/deny policy.
 func DefaultPolicy() Policy {
-	return PolicyFromLists(nil, nil, nil)
+	return PolicyFromLists(defaultAllowedTools, nil, nil)
 }
 
 // PolicyFromLists builds a policy from allow/ask/deny lists.
@@ -216,17 +280,25 @@ func (r *Registry) GetTools() []Tool {
 	return list
 }
 
-// OpenAITools returns the registry as OpenAI tool definitions.
+// OpenAITools returns the registry as OpenAI tool definitions, limited to the
+// advertised tools and, with HideDenied, to tools that are not denied.
 func (r *Registry) OpenAITools() []openai.Tool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	defs := make([]openai.Tool, 0, len(r.tools))
 	for _, tool := range r.tools {
+		if !r.isAdvertisedLocked(tool.Name()) {
+			continue
+		}
+		description := tool.Description()
+		if override, ok := r.descriptions[tool.Name()]; ok {
+			description = override
+		}
 		defs = append(defs, openai.Tool{
 			Type: openai.ToolTypeFunction,
 			Function: &openai.FunctionDefinition{
 				Name:        tool.Name(),
-				Description: tool.Description(),
+				Description: description,
 				Parameters:  tool.Parameters(),
 			},
 		})
@@ -234,6 +306,15 @@ func (r *Registry) OpenAITools() []openai.Tool {
 	return defs
 }
 
+// isAdvertisedLocked reports whether a tool is offered to the model. The
+// caller must hold r.mu.
+func (r *Registry) isAdvertisedLocked(name string) bool {
+	if len(r.advertised) > 0 && !r.advertised[name] {
+		return false
+	}
+	return !r.hideDenied || r.permissions[name].Level != PermissionDeny
+}
+
 // Execute runs the specified tool with given arguments.
 func (r *Registry) Execute(function string, args map[string]interface{}) *ToolResult {
 	return r.ExecuteWithOptions(function, args, ExecuteOptions{})
@@ -241,6 +322,7 @@ func (r *Registry) Execute(function string, args map[string]interface{}) *ToolRe
 
 // ExecuteWithOptions runs the tool using the provided options.
 func (r *Registry) ExecuteWithOptions(function string, args map[string]interface{}, opts ExecuteOptions) *ToolResult {
+	function, _ = r.ResolveAlias(function)
 	result := &ToolResult{
 		Function: function,
 	}
@@ -272,13 +354,41 @@ func (r *Registry) ExecuteWithOptions(function string, args map[string]interface
 		}
 	}
 
+	if err := validateSchemaArgs(tool.Parameters(), args); err != nil {
+		result.Error = fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+		result.Result = fmt.Sprintf("Error: %v", result.Error)
+		return result
+	}
+
+	ctx := r.toolContext()
+	if opts.BaseDir != "" {
+		baseDir, err := resolveDirWithinWorkRoot(ctx, opts.BaseDir)
+		if err != nil {
+			result.Error = fmt.Errorf("invalid base directory: %w", err)
+			result.Result = fmt.Sprintf("Error: %v", result.Error)
+			return result
+		}
+		ctx = withBaseDir(ctx, baseDir)
+	}
+
+	var cacheKey string
+	cacheable := !opts.DryRun && isCacheableCall(tool, args) && r.cache.enabled()
+	if cacheable {
+		if key, ok := toolCacheKey(ctx, function, args); ok {
+			cacheKey = key
+			if cached, hit := r.cache.get(cacheKey); hit {
+				result.Result = cached
+				return result
+			}
+		}
+	}
+
 	if err := r.checkRateLimit(function); err != nil {
 		result.Error = err
 		result.Result = fmt.Sprintf("Error: %v", err)
 		return result
 	}
 
-	ctx := context.Background()
 	timeout := r.getTimeout(function)
 	if timeout > 0 {
 		var cancel context.CancelFunc
@@ -287,7 +397,7 @@ func (r *Registry) ExecuteWithOptions(function string, args map[string]interface
 	}
 
 	if opts.DryRun {
-		if err := tool.Validate(args); err != nil {
+		if err := validateTool(ctx, tool, args); err != nil {
 			result.Error = fmt.Errorf("%w: %v", ErrInvalidArguments, err)
 			result.Result = fmt.Sprintf("Error: %v", result.Error)
 			return result
@@ -297,6 +407,16 @@ func (r *Registry) ExecuteWithOptions(function string, args map[string]interface
 	}
 
 	result.Result, result.Error = tool.Execute(ctx, args)
+	if result.Error == nil {
+		result.Result = normalizeToolWhitespace(result.Result)
+	}
+	switch {
+	case !isCacheable(tool):
+		// Anything that is not read-only may have changed what cached reads saw.
+		r.cache.clear()
+	case cacheKey != "" && result.Error == nil:
+		r.cache.put(cacheKey, result.Result)
+	}
 	return result
 }
 
@@ -369,9 +489,25 @@ func (r *Registry) SetRequireConfirmation(name string, require bool) {
 
 // GetPermission returns the current permission entry for a tool.
 func (r *Registry) GetPermission(name string) Permission {
+	name, _ = r.ResolveAlias(name)
 	return r.getPermission(name)
 }
 
+// ResolveAlias maps a configured alias to the registered tool name. It returns
+// the name unchanged and false when the name is registered or not an alias.
+func (r *Registry) ResolveAlias(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, ok := r.tools[name]; ok {
+		return name, false
+	}
+	target, ok := r.aliases[name]
+	if !ok {
+		return name, false
+	}
+	return target, true
+}
+
 // ConfigureRateLimits updates rate limiting configuration for the registry.
 func (r *Registry) ConfigureRateLimits(config RateLimitConfig) {
 	r.mu.Lock()
@@ -385,6 +521,29 @@ func (r *Registry) ConfigureRateLimits(config RateLimitConfig) {
 	r.rateLimiters = make(map[string]*toolRateLimiter)
 }
 
+// ConfigureDescriptions replaces the descriptions advertised to the model for
+// the named tools. Empty overrides are ignored; tool behavior is unchanged.
+func (r *Registry) ConfigureDescriptions(overrides map[string]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.descriptions = make(map[string]string, len(overrides))
+	for name, description := range overrides {
+		if strings.TrimSpace(description) != "" {
+			r.descriptions[name] = description
+		}
+	}
+}
+
+// ConfigureCache replaces the result cache settings and drops cached results.
+func (r *Registry) ConfigureCache(config CacheConfig) {
+	r.cache.configure(config)
+}
+
+// ClearCache drops all cached tool results.
+func (r *Registry) ClearCache() {
+	r.cache.clear()
+}
+
 // ConfigureTimeouts updates tool execution timeouts.
 func (r *Registry) ConfigureTimeouts(config TimeoutConfig) {
 	r.mu.Lock()
@@ -448,7 +607,11 @@ func (r *Registry) getRateLimiter(name string) *toolRateLimiter {
 func (r *Registry) getTimeout(name string) time.Duration {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.timeouts.TimeoutForTool(name)
+	var toolDefault time.Duration
+	if tool, ok := r.tools[name].(DefaultTimeoutTool); ok {
+		toolDefault = tool.DefaultTimeout()
+	}
+	return r.timeouts.timeoutWithToolDefault(name, toolDefault)
 }
 
 func applyPolicyLevel(current PermissionLevel, name string, policy Policy) PermissionLevel {
@@ -477,6 +640,7 @@ func FormatToolResult(toolCall openai.ToolCall, result *ToolResult, truncate boo
 		} else {
 			argsStr = toolCall.Function.Arguments
 		}
+		argsStr = RedactSecrets(argsStr)
 	}
 
 	sb := getBuilder()
@@ -489,6 +653,9 @@ func FormatToolResult(toolCall openai.ToolCall, result *ToolResult, truncate boo
 
 	if result.Error != nil {
 		sb.WriteString(fmt.Sprintf("❌ Error: %v\n", result.Error))
+		if hint := ErrorHint(result.Error); hint != "" {
+			sb.WriteString(fmt.Sprintf("💡 Hint: %s\n", hint))
+		}
 	} else {
 		displayResult, truncated := sanitizeToolOutput(result.Result)
 		if truncate {
@@ -45,17 +45,20 @@ type touchArgs struct {
 	Modification bool     `json:"modification,omitempty" jsonschema:"description=Change modification time only"`
 	NoCreate     bool     `json:"no_create,omitempty" jsonschema:"description=Do not create files if they do not exist"`
 	Datetime     string   `json:"datetime,omitempty" jsonschema:"description=RFC3339 timestamp to apply"`
+	Reference    string   `json:"reference,omitempty" jsonschema:"description=Use this file's modification time instead of datetime (like touch -r)"`
 }
 
 type grepArgs struct {
-	Pattern    string   `json:"pattern" jsonschema:"description=Pattern to search for (regular expression)"`
-	Paths      []string `json:"paths,omitempty" jsonschema:"description=File paths to search"`
-	Path       string   `json:"path,omitempty" jsonschema:"description=Single file path to search"`
-	IgnoreCase bool     `json:"ignore_case,omitempty" jsonschema:"description=Case-insensitive matching"`
-	Recursive  bool     `json:"recursive,omitempty" jsonschema:"description=Search directories recursively"`
-	ShowHidden bool     `json:"show_hidden,omitempty" jsonschema:"description=Include hidden files when searching directories"`
-	Invert     bool     `json:"invert,omitempty" jsonschema:"description=Select non-matching lines"`
-	MaxMatches float64  `json:"max_matches,omitempty" jsonschema:"description=Maximum number of matches to return"`
+	Pattern      string   `json:"pattern" jsonschema:"description=Pattern to search for (regular expression)"`
+	Paths        []string `json:"paths,omitempty" jsonschema:"description=File paths to search"`
+	Path         string   `json:"path,omitempty" jsonschema:"description=Single file path to search"`
+	IgnoreCase   bool     `json:"ignore_case,omitempty" jsonschema:"description=Case-insensitive matching"`
+	Recursive    bool     `json:"recursive,omitempty" jsonschema:"description=Search directories recursively"`
+	ShowHidden   bool     `json:"show_hidden,omitempty" jsonschema:"description=Include hidden files when searching directories"`
+	Invert       bool     `json:"invert,omitempty" jsonschema:"description=Select non-matching lines"`
+	MaxMatches   float64  `json:"max_matches,omitempty" jsonschema:"description=Maximum number of matches to return"`
+	Ignore       []string `json:"ignore,omitempty" jsonschema:"description=Gitignore-style globs of entries to skip (e.g. node_modules or build/)"`
+	UseGitignore bool     `json:"use_gitignore,omitempty" jsonschema:"description=Also skip entries matched by the .gitignore at the root of the walk"`
 }
 
 type teeArgs struct {
@@ -72,14 +75,33 @@ type mkdirArgs struct {
 }
 
 type lsArgs struct {
-	Path       string `json:"path,omitempty" jsonschema:"description=Directory path to list (default: current directory)"`
-	Recursive  bool   `json:"recursive,omitempty" jsonschema:"description=List directories recursively"`
-	ShowHidden bool   `json:"show_hidden,omitempty" jsonschema:"description=Include hidden files"`
+	Path         string   `json:"path,omitempty" jsonschema:"description=Directory path to list (default: current directory)"`
+	Recursive    bool     `json:"recursive,omitempty" jsonschema:"description=List directories recursively"`
+	ShowHidden   bool     `json:"show_hidden,omitempty" jsonschema:"description=Include hidden files"`
+	Format       string   `json:"format,omitempty" jsonschema:"description=Output format: short (default); long adds size/mode/mtime; json returns an array of entries"`
+	Ignore       []string `json:"ignore,omitempty" jsonschema:"description=Gitignore-style globs of entries to skip (e.g. node_modules or build/)"`
+	UseGitignore bool     `json:"use_gitignore,omitempty" jsonschema:"description=Also skip entries matched by the .gitignore at the root of the walk"`
 }
 
 type catArgs struct {
-	Paths []string `json:"paths,omitempty" jsonschema:"description=File paths to concatenate"`
-	Path  string   `json:"path,omitempty" jsonschema:"description=Single file path to concatenate"`
+	Paths    []string `json:"paths,omitempty" jsonschema:"description=File paths to concatenate"`
+	Path     string   `json:"path,omitempty" jsonschema:"description=Single file path to concatenate"`
+	Number   bool     `json:"number,omitempty" jsonschema:"description=Prefix each line with its line number like cat -n"`
+	Start    float64  `json:"start,omitempty" jsonschema:"description=First line to print (1-based; single path only)"`
+	End      float64  `json:"end,omitempty" jsonschema:"description=Last line to print (inclusive; single path only)"`
+	ShowDiff bool     `json:"show_diff,omitempty" jsonschema:"description=Return a unified diff against the last read of this file instead of the full content (single path only)"`
+}
+
+type viewCodeArgs struct {
+	Path  string  `json:"path" jsonschema:"description=File path to view"`
+	Start float64 `json:"start,omitempty" jsonschema:"description=First line to show (1-based, default: 1)"`
+	End   float64 `json:"end,omitempty" jsonschema:"description=Last line to show (inclusive, default: end of file)"`
+}
+
+type tableArgs struct {
+	Path    string  `json:"path,omitempty" jsonschema:"description=CSV file to render"`
+	Content string  `json:"content,omitempty" jsonschema:"description=Inline CSV data (instead of path)"`
+	MaxRows float64 `json:"max_rows,omitempty" jsonschema:"description=Maximum data rows to show (default: 50/max: 1000)"`
 }
 
 type headArgs struct {
@@ -95,8 +117,9 @@ type tailArgs struct {
 }
 
 type wcArgs struct {
-	Paths []string `json:"paths,omitempty" jsonschema:"description=File paths to count"`
-	Path  string   `json:"path,omitempty" jsonschema:"description=Single file path to count"`
+	Paths     []string `json:"paths,omitempty" jsonschema:"description=File or directory paths to count"`
+	Path      string   `json:"path,omitempty" jsonschema:"description=Single file or directory path to count"`
+	Recursive bool     `json:"recursive,omitempty" jsonschema:"description=Count text files in subdirectories too"`
 }
 
 type md5sumArgs struct {
@@ -115,6 +138,11 @@ type echoArgs struct {
 	Parts []string `json:"parts,omitempty" jsonschema:"description=Text parts to join with spaces"`
 }
 
+type printfArgs struct {
+	Format string        `json:"format" jsonschema:"description=Format string using the verbs %s %d %f %x %v %q with optional flags/width/precision; %% prints a percent sign,minLength=1" validate:"required,min=1"`
+	Args   []interface{} `json:"args,omitempty" jsonschema:"description=Values for the verbs in order: strings/numbers or booleans"`
+}
+
 type linkArgs struct {
 	Target   string `json:"target" jsonschema:"description=Existing target path"`
 	LinkPath string `json:"link_path" jsonschema:"description=New link path"`
@@ -123,10 +151,12 @@ type linkArgs struct {
 }
 
 type translateArgs struct {
-	From  string `json:"from" jsonschema:"description=Characters to replace"`
-	To    string `json:"to" jsonschema:"description=Replacement characters"`
-	Path  string `json:"path,omitempty" jsonschema:"description=File path to process"`
-	Input string `json:"input,omitempty" jsonschema:"description=Inline text to process (if path not provided)"`
+	From    string `json:"from" jsonschema:"description=Characters to replace (or delete)"`
+	To      string `json:"to,omitempty" jsonschema:"description=Replacement characters (optional with delete or squeeze)"`
+	Path    string `json:"path,omitempty" jsonschema:"description=File path to process"`
+	Input   string `json:"input,omitempty" jsonschema:"description=Inline text to process (if path not provided)"`
+	Delete  bool   `json:"delete,omitempty" jsonschema:"description=Delete characters in from instead of replacing them (like tr -d)"`
+	Squeeze bool   `json:"squeeze,omitempty" jsonschema:"description=Collapse runs of a repeated character from to (or from when to is empty) into one (like tr -s)"`
 }
 
 type truncateArgs struct {
@@ -146,8 +176,11 @@ type sortArgs struct {
 }
 
 type commArgs struct {
-	Path1 string `json:"path1" jsonschema:"description=First file path"`
-	Path2 string `json:"path2" jsonschema:"description=Second file path"`
+	Path1     string `json:"path1" jsonschema:"description=First file path"`
+	Path2     string `json:"path2" jsonschema:"description=Second file path"`
+	Suppress1 bool   `json:"suppress1,omitempty" jsonschema:"description=Hide lines only in the first file (like comm -1)"`
+	Suppress2 bool   `json:"suppress2,omitempty" jsonschema:"description=Hide lines only in the second file (like comm -2)"`
+	Suppress3 bool   `json:"suppress3,omitempty" jsonschema:"description=Hide lines in both files (like comm -3)"`
 }
 
 type stringsArgs struct {
@@ -176,9 +209,11 @@ type base64Args struct {
 }
 
 type seqArgs struct {
-	Start float64 `json:"start,omitempty" jsonschema:"description=Start value (default: 1 if end specified)"`
-	End   float64 `json:"end,omitempty" jsonschema:"description=End value"`
-	Step  float64 `json:"step,omitempty" jsonschema:"description=Step value (default: 1)"`
+	Start     float64 `json:"start,omitempty" jsonschema:"description=Start value (default: 1 if end specified)"`
+	End       float64 `json:"end,omitempty" jsonschema:"description=End value"`
+	Step      float64 `json:"step,omitempty" jsonschema:"description=Step value (default: 1)"`
+	Separator string  `json:"separator,omitempty" jsonschema:"description=Text between numbers (default: newline; at most 16 characters)"`
+	Format    string  `json:"format,omitempty" jsonschema:"description=printf-style format with one integer verb such as %03d or %x (default: %d)"`
 }
 
 type mkfifoArgs struct {
@@ -187,11 +222,29 @@ type mkfifoArgs struct {
 }
 
 type findArgs struct {
-	Path       string  `json:"path,omitempty" jsonschema:"description=Root path to search (default: current directory)"`
-	Name       string  `json:"name,omitempty" jsonschema:"description=Glob pattern to match file names"`
-	Type       string  `json:"type,omitempty" jsonschema:"description=Filter by type: file or dir"`
-	MaxDepth   float64 `json:"max_depth,omitempty" jsonschema:"description=Maximum depth to traverse"`
-	ShowHidden bool    `json:"show_hidden,omitempty" jsonschema:"description=Include hidden entries"`
+	Path         string   `json:"path,omitempty" jsonschema:"description=Root path to search (default: current directory)"`
+	Name         string   `json:"name,omitempty" jsonschema:"description=Glob pattern to match file names"`
+	Type         string   `json:"type,omitempty" jsonschema:"description=Filter by type: file or dir"`
+	MaxDepth     float64  `json:"max_depth,omitempty" jsonschema:"description=Maximum depth to traverse"`
+	ShowHidden   bool     `json:"show_hidden,omitempty" jsonschema:"description=Include hidden entries"`
+	NewerThan    string   `json:"newer_than,omitempty" jsonschema:"description=Only entries modified within this duration (e.g. 24h or 7d) or after this RFC3339 time"`
+	OlderThan    string   `json:"older_than,omitempty" jsonschema:"description=Only entries modified before this duration ago (e.g. 30d) or before this RFC3339 time"`
+	MinSize      float64  `json:"min_size,omitempty" jsonschema:"description=Only files of at least this many bytes"`
+	MaxSize      float64  `json:"max_size,omitempty" jsonschema:"description=Only files of at most this many bytes"`
+	Ignore       []string `json:"ignore,omitempty" jsonschema:"description=Gitignore-style globs of entries to skip (e.g. node_modules or build/)"`
+	UseGitignore bool     `json:"use_gitignore,omitempty" jsonschema:"description=Also skip entries matched by the .gitignore at the root of the walk"`
+}
+
+type searchArgs struct {
+	Path         string   `json:"path,omitempty" jsonschema:"description=Root path to search (default: current directory)"`
+	Name         string   `json:"name,omitempty" jsonschema:"description=Glob pattern to match file names"`
+	Pattern      string   `json:"pattern" jsonschema:"description=Pattern to search for in file contents (regular expression)"`
+	MaxDepth     float64  `json:"max_depth,omitempty" jsonschema:"description=Maximum depth to traverse"`
+	IgnoreCase   bool     `json:"ignore_case,omitempty" jsonschema:"description=Case-insensitive matching"`
+	ShowHidden   bool     `json:"show_hidden,omitempty" jsonschema:"description=Include hidden entries"`
+	MaxMatches   float64  `json:"max_matches,omitempty" jsonschema:"description=Maximum number of matching lines to return"`
+	Ignore       []string `json:"ignore,omitempty" jsonschema:"description=Gitignore-style globs of entries to skip (e.g. node_modules or build/)"`
+	UseGitignore bool     `json:"use_gitignore,omitempty" jsonschema:"description=Also skip entries matched by the .gitignore at the root of the walk"`
 }
 
 type chmodArgs struct {
@@ -209,6 +262,10 @@ type uniqArgs struct {
 	Path string `json:"path" jsonschema:"description=File path to process"`
 }
 
+type cdArgs struct {
+	Path string `json:"path,omitempty" jsonschema:"description=Directory to switch to (default: the startup directory)"`
+}
+
 type pathArg struct {
 	Path string `json:"path" jsonschema:"description=Path to evaluate"`
 }
@@ -220,6 +277,8 @@ type dfArgs struct {
 type duArgs struct {
 	Path     string  `json:"path,omitempty" jsonschema:"description=Path to inspect (default: current directory)"`
 	MaxDepth float64 `json:"max_depth,omitempty" jsonschema:"description=Maximum depth to traverse"`
+	PerFile  bool    `json:"per_file,omitempty" jsonschema:"description=List the largest files and directories sorted by size"`
+	Top      float64 `json:"top,omitempty" jsonschema:"description=Number of entries to list (implies per_file; default: 20, max: 100)"`
 }
 
 type psArgs struct {
@@ -249,5 +308,9 @@ type mktempArgs struct {
 }
 
 type dateArgs struct {
-	Format string `json:"format,omitempty" jsonschema:"description=Go time layout or 'unix' (default: RFC3339)"`
+	Format   string `json:"format,omitempty" jsonschema:"description=Go time layout or 'unix' (default: RFC3339)"`
+	Input    string `json:"input,omitempty" jsonschema:"description=Timestamp to use instead of now: RFC3339 / a date like 2025-01-02 / or unix seconds"`
+	Add      string `json:"add,omitempty" jsonschema:"description=Duration to add (e.g. 48h / 90m / 7d)"`
+	Subtract string `json:"subtract,omitempty" jsonschema:"description=Duration to subtract (e.g. 48h / 90m / 7d)"`
+	Timezone string `json:"timezone,omitempty" jsonschema:"description=IANA timezone to render in (e.g. UTC or Europe/Amsterdam; default: local)"`
 }
@@ -0,0 +1,155 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type validateFileArgs struct {
+	Path   string `json:"path" jsonschema:"description=File to validate"`
+	Format string `json:"format,omitempty" jsonschema:"description=json or yaml (default: from the file extension),enum=json,enum=yaml"`
+}
+
+// validateFileTool parses a JSON or YAML file and reports whether it is well
+// formed. A syntax error is a result rather than a tool failure, with the
+// line and column where it was found so the model can fix the file.
+func validateFileTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[validateFileArgs](args)
+	if err != nil {
+		return "", err
+	}
+	format, err := validateFileFormat(parsed.Path, parsed.Format)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolveToolPath(ctx, parsed.Path)
+	if err != nil {
+		return "", err
+	}
+	data, err := readFileLimited(resolved, false)
+	if err != nil {
+		return "", err
+	}
+
+	var problem string
+	if format == "json" {
+		problem = checkJSON(data)
+	} else {
+		problem = checkYAML(data)
+	}
+	if problem != "" {
+		return fmt.Sprintf("%s: invalid %s: %s", parsed.Path, format, problem), nil
+	}
+	return fmt.Sprintf("%s: valid %s", parsed.Path, format), nil
+}
+
+func validateValidateFileArgs(args map[string]interface{}) error {
+	path, err := extractPathArg(args)
+	if err != nil {
+		return err
+	}
+	format, _ := getStringLike(args["format"])
+	_, err = validateFileFormat(path, format)
+	return err
+}
+
+// validateFileFormat returns the format to check, inferred from the
+// extension when none is given.
+func validateFileFormat(path, format string) (string, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			format = "json"
+		case ".yaml", ".yml":
+			format = "yaml"
+		default:
+			return "", fmt.Errorf("cannot tell the format of %s, set format to json or yaml", path)
+		}
+	}
+	if format != "json" && format != "yaml" {
+		return "", fmt.Errorf("unsupported format %q, expected json or yaml", format)
+	}
+	return format, nil
+}
+
+// checkJSON returns a description of the first JSON error, or "".
+func checkJSON(data []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var value interface{}
+	err := dec.Decode(&value)
+	if err == nil {
+		// Anything but whitespace after the value is an error too.
+		rest := bytes.TrimLeft(data[dec.InputOffset():], " \t\r\n")
+		if len(rest) == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%s: unexpected data after the top-level value", lineColumn(data, int64(len(data)-len(rest))))
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// Offset counts the offending byte.
+		return fmt.Sprintf("%s: %v", lineColumn(data, syntaxErr.Offset-1), syntaxErr)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Sprintf("%s: unexpected end of input", lineColumn(data, int64(len(data))))
+	}
+	return err.Error()
+}
+
+// lineColumn returns the 1-based "line L, column C" of the byte at offset.
+func lineColumn(data []byte, offset int64) string {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte{'\n'}) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return fmt.Sprintf("line %d, column %d", line, column)
+}
+
+// checkYAML returns a description of the first YAML error in any of the
+// documents, or "". The parser reports lines but not columns.
+func checkYAML(data []byte) string {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		// Decoding into a value, not a yaml.Node, also catches duplicate keys.
+		var value interface{}
+		err := dec.Decode(&value)
+		if err == io.EOF {
+			return ""
+		}
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			return strings.Join(typeErr.Errors, "; ")
+		}
+		if err != nil {
+			return strings.TrimPrefix(err.Error(), "yaml: ")
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateFile(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"validate": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	files := map[string]string{
+		"good.json":      "{\n  \"name\": \"promptline\",\n  \"tags\": [1, 2]\n}\n",
+		"bad.json":       "{\n  \"name\": \"promptline\",\n  \"tags\": [1, 2,]\n}\n",
+		"truncated.json": "{\n  \"name\": \"promptline\"\n",
+		"trailing.json":  "{}\n{}\n",
+		"good.yaml":      "name: promptline\ntags:\n  - one\n  - two\n---\nsecond: doc\n",
+		"bad.yml":        "name: promptline\ntags:\n  - one\n bad: indent\n",
+		"dupe.yaml":      "name: a\nname: b\n",
+		"config.txt":     "{}",
+	}
+	for name, content := range files {
+		writeTestFile(t, absDir, name, content)
+	}
+
+	tests := []struct {
+		name   string
+		file   string
+		format string
+		want   string
+	}{
+		{"valid json", "good.json", "", "good.json: valid json"},
+		{"invalid json", "bad.json", "", "bad.json: invalid json: line 3, column 17: invalid character ']' looking for beginning of value"},
+		{"truncated json", "truncated.json", "", "truncated.json: invalid json: line 3, column 1: unexpected end of input"},
+		{"trailing json", "trailing.json", "", "trailing.json: invalid json: line 2, column 1: unexpected data after the top-level value"},
+		{"valid yaml", "good.yaml", "", "good.yaml: valid yaml"},
+		{"invalid yaml", "bad.yml", "", "bad.yml: invalid yaml: line 3: did not find expected key"},
+		{"duplicate yaml key", "dupe.yaml", "", `dupe.yaml: invalid yaml: line 2: mapping key "name" already defined at line 1`},
+		{"explicit format", "config.txt", "json", "config.txt: valid json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := map[string]interface{}{"path": filepath.Join(relDir, tt.file)}
+			if tt.format != "" {
+				args["format"] = tt.format
+			}
+			result := registry.Execute("validate", args)
+			if result.Error != nil {
+				t.Fatalf("validate failed: %v", result.Error)
+			}
+			want := filepath.Join(relDir, tt.want)
+			if result.Result != want {
+				t.Fatalf("unexpected result:\n%s\nwant:\n%s", result.Result, want)
+			}
+		})
+	}
+
+	if result := registry.Execute("validate", map[string]interface{}{"path": filepath.Join(relDir, "config.txt")}); result.Error == nil {
+		t.Fatal("expected an error when the format cannot be inferred")
+	}
+}
@@ -17,6 +17,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -27,6 +28,7 @@ type ValidationRule func(args map[string]interface{}) error
 
 // ValidateToolCall validates a tool call before execution.
 func (r *Registry) ValidateToolCall(name, argsJSON string) *ToolResult {
+	name, _ = r.ResolveAlias(name)
 	tool, ok := r.getTool(name)
 	if !ok {
 		return invalidToolResult(name, fmt.Errorf("%w: tool %q not found", ErrToolNotFound, name))
@@ -37,13 +39,36 @@ func (r *Registry) ValidateToolCall(name, argsJSON string) *ToolResult {
 		return invalidToolResult(name, fmt.Errorf("%w: %v", ErrInvalidArguments, err))
 	}
 
-	if err := tool.Validate(args); err != nil {
+	if err := validateSchemaArgs(tool.Parameters(), args); err != nil {
+		return invalidToolResult(name, fmt.Errorf("%w: %v", ErrInvalidArguments, err))
+	}
+
+	if err := validateTool(r.toolContext(), tool, args); err != nil {
 		return invalidToolResult(name, fmt.Errorf("%w: %v", ErrInvalidArguments, err))
 	}
 
 	return nil
 }
 
+// ConfirmSummary returns the approval summary for a tool call, or an empty
+// string when the tool does not provide one or the arguments cannot be parsed.
+func (r *Registry) ConfirmSummary(name, argsJSON string) string {
+	name, _ = r.ResolveAlias(name)
+	tool, ok := r.getTool(name)
+	if !ok {
+		return ""
+	}
+	summarizer, ok := tool.(ConfirmSummarizer)
+	if !ok {
+		return ""
+	}
+	args, err := parseToolArgs(argsJSON)
+	if err != nil {
+		return ""
+	}
+	return summarizer.ConfirmSummary(r.toolContext(), args)
+}
+
 func invalidToolResult(name string, err error) *ToolResult {
 	return &ToolResult{
 		Function: name,
@@ -117,3 +142,12 @@ func RequireNonEmptyArg(key, message string) ValidationRule {
 		return nil
 	}
 }
+
+// validateTool runs the tool's own validation, in the working directory of
+// ctx when the tool resolves paths.
+func validateTool(ctx context.Context, tool Tool, args map[string]interface{}) error {
+	if validator, ok := tool.(ContextValidator); ok {
+		return validator.ValidateContext(ctx, args)
+	}
+	return tool.Validate(args)
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// codeLanguages maps file extensions to the language label shown by view_code.
+var codeLanguages = map[string]string{
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".cs":    "csharp",
+	".css":   "css",
+	".go":    "go",
+	".html":  "html",
+	".java":  "java",
+	".js":    "javascript",
+	".json":  "json",
+	".kt":    "kotlin",
+	".lua":   "lua",
+	".md":    "markdown",
+	".php":   "php",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".sh":    "shell",
+	".sql":   "sql",
+	".swift": "swift",
+	".toml":  "toml",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".zig":   "zig",
+}
+
+// codeLanguage infers a language label from the file extension.
+func codeLanguage(path string) string {
+	if lang, ok := codeLanguages[strings.ToLower(filepath.Ext(path))]; ok {
+		return lang
+	}
+	if filepath.Base(path) == "Makefile" {
+		return "make"
+	}
+	return ""
+}
+
+// viewCodeTool returns a text file, or a range of it, with line numbers and a
+// language label so the model can reference exact lines.
+func viewCodeTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	path, err := extractPathArg(args)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	lines, err := readTextLines(resolved)
+	if err != nil {
+		return "", err
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	start, end, err := viewCodeRange(args, len(lines))
+	if err != nil {
+		return "", err
+	}
+
+	header := path
+	if lang := codeLanguage(resolved); lang != "" {
+		header += fmt.Sprintf(" (%s)", lang)
+	}
+	if len(lines) == 0 {
+		return header + ": empty file", nil
+	}
+	header += fmt.Sprintf(": lines %d-%d of %d", start, end, len(lines))
+
+	width := len(fmt.Sprint(end))
+	var b strings.Builder
+	b.WriteString(header)
+	for i := start; i <= end; i++ {
+		fmt.Fprintf(&b, "\n%*d | %s", width, i, lines[i-1])
+	}
+	return b.String(), nil
+}
+
+// viewCodeRange resolves the 1-based inclusive start/end arguments.
+func viewCodeRange(args map[string]interface{}, total int) (int, int, error) {
+	start, err := extractIntArg(args, "start", 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := extractIntArg(args, "end", total)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start < 1 {
+		return 0, 0, fmt.Errorf("start must be at least 1")
+	}
+	if total == 0 {
+		return 0, 0, nil
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("end must not be before start")
+	}
+	if start > total {
+		return 0, 0, fmt.Errorf("start %d is past the end of the file (%d lines)", start, total)
+	}
+	if end > total {
+		end = total
+	}
+	return start, end, nil
+}
+
+func validateViewCodeArgs(args map[string]interface{}) error {
+	if _, err := extractPathArg(args); err != nil {
+		return err
+	}
+	_, _, err := viewCodeRange(args, int(^uint(0)>>1))
+	return err
+}
@@ -0,0 +1,194 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	defaultWatchSeconds  = 10
+	maxWatchSeconds      = 300
+	defaultWatchInterval = 1000 * time.Millisecond
+	minWatchInterval     = 100 * time.Millisecond
+)
+
+type watchDirArgs struct {
+	Path            string `json:"path,omitempty" jsonschema:"description=Directory to watch (defaults to current directory)"`
+	DurationSeconds int    `json:"duration_seconds,omitempty" jsonschema:"description=How long to watch in seconds (default 10; max 300),minimum=1,maximum=300"`
+	IntervalMS      int    `json:"interval_ms,omitempty" jsonschema:"description=Polling interval in milliseconds (default 1000; min 100),minimum=100"`
+	Name            string `json:"name,omitempty" jsonschema:"description=Only watch files whose name matches this glob (e.g. *.go)"`
+	ShowHidden      bool   `json:"show_hidden,omitempty" jsonschema:"description=Include hidden files"`
+	StopOnChange    bool   `json:"stop_on_change,omitempty" jsonschema:"description=Return as soon as a change is seen instead of watching for the full duration"`
+}
+
+// watchFileState is what a snapshot remembers about a file.
+type watchFileState struct {
+	size  int64
+	mtime time.Time
+}
+
+type watchChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"` // created, modified or deleted
+	Size   int64  `json:"size,omitempty"`
+	MTime  string `json:"mtime,omitempty"`
+}
+
+type watchReport struct {
+	Path      string        `json:"path"`
+	WatchedMS int64         `json:"watched_ms"`
+	Polls     int           `json:"polls"`
+	Changes   []watchChange `json:"changes"`
+}
+
+// watchDir polls a directory tree and reports the files created, modified or
+// deleted since the call started. Changes are net: a file created and removed
+// again while watching is not reported. Watching ends early, with the changes
+// seen so far, when the tool timeout would otherwise cut it off.
+func watchDir(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	parsed, err := unmarshalAndValidate[watchDirArgs](args)
+	if err != nil {
+		return "", err
+	}
+	path := parsed.Path
+	if path == "" {
+		path = "."
+	}
+	resolved, err := resolveToolPath(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", path)
+	}
+	if parsed.Name != "" {
+		if _, err := filepath.Match(parsed.Name, ""); err != nil {
+			return "", fmt.Errorf("invalid name pattern: %w", err)
+		}
+	}
+
+	duration := time.Duration(parsed.DurationSeconds) * time.Second
+	if duration <= 0 {
+		duration = defaultWatchSeconds * time.Second
+	}
+	interval := time.Duration(parsed.IntervalMS) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	start := time.Now()
+	end := start.Add(duration)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Add(-interval).Before(end) {
+		// Leave one interval to build the report before the tool times out.
+		end = deadline.Add(-interval)
+	}
+
+	limits := getLimits()
+	opts := walkOptions{
+		maxDepth:    max(limits.MaxDirectoryDepth, 1),
+		maxEntries:  limits.MaxDirectoryEntries,
+		showHidden:  parsed.ShowHidden,
+		pattern:     parsed.Name,
+		regularOnly: true,
+	}
+	if opts.maxEntries <= 0 {
+		opts.maxEntries = 2000
+	}
+	baseline, err := watchSnapshot(ctx, resolved, opts)
+	if err != nil {
+		return "", err
+	}
+
+	report := watchReport{Path: path, Changes: []watchChange{}}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for time.Now().Before(end) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+		current, err := watchSnapshot(ctx, resolved, opts)
+		if err != nil {
+			return "", err
+		}
+		report.Polls++
+		report.Changes = diffWatchSnapshots(baseline, current)
+		if parsed.StopOnChange && len(report.Changes) > 0 {
+			break
+		}
+	}
+	report.WatchedMS = time.Since(start).Milliseconds()
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func watchSnapshot(ctx context.Context, root string, opts walkOptions) (map[string]watchFileState, error) {
+	entries, err := walkDirEntries(ctx, root, opts)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := make(map[string]watchFileState, len(entries))
+	for _, entry := range entries {
+		info, err := os.Lstat(entry.Path)
+		if err != nil {
+			continue // removed between the walk and the stat
+		}
+		snapshot[filepath.ToSlash(entry.Rel)] = watchFileState{size: info.Size(), mtime: info.ModTime()}
+	}
+	return snapshot, nil
+}
+
+// diffWatchSnapshots lists the changes from before to after, sorted by path.
+func diffWatchSnapshots(before, after map[string]watchFileState) []watchChange {
+	changes := []watchChange{}
+	for path, state := range after {
+		old, existed := before[path]
+		switch {
+		case !existed:
+			changes = append(changes, watchChange{Path: path, Change: "created", Size: state.size, MTime: state.mtime.UTC().Format(time.RFC3339)})
+		case old.size != state.size || !old.mtime.Equal(state.mtime):
+			changes = append(changes, watchChange{Path: path, Change: "modified", Size: state.size, MTime: state.mtime.UTC().Format(time.RFC3339)})
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changes = append(changes, watchChange{Path: path, Change: "deleted"})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
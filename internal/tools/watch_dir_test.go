@@ -0,0 +1,113 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func runWatchDir(t *testing.T, registry *Registry, args map[string]interface{}) watchReport {
+	t.Helper()
+	result := registry.Execute("watch_dir", args)
+	if result.Error != nil {
+		t.Fatalf("expected watch_dir success, got %v", result.Error)
+	}
+	var report watchReport
+	if err := json.Unmarshal([]byte(result.Result), &report); err != nil {
+		t.Fatalf("expected JSON report, got %q: %v", result.Result, err)
+	}
+	return report
+}
+
+func TestWatchDir(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"watch_dir": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	for name, content := range map[string]string{"keep.txt": "same", "edit.txt": "old", "gone.txt": "bye"} {
+		if err := os.WriteFile(filepath.Join(absDir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(absDir, "edit.txt"), []byte("new content"), 0o644)
+		_ = os.Remove(filepath.Join(absDir, "gone.txt"))
+		_ = os.MkdirAll(filepath.Join(absDir, "out"), 0o755)
+		_ = os.WriteFile(filepath.Join(absDir, "out", "build.log"), []byte("ok"), 0o644)
+		_ = os.WriteFile(filepath.Join(absDir, "temp.txt"), []byte("x"), 0o644)
+		_ = os.Remove(filepath.Join(absDir, "temp.txt"))
+	}()
+
+	report := runWatchDir(t, registry, map[string]interface{}{
+		"path":             relDir,
+		"duration_seconds": 1,
+		"interval_ms":      100,
+	})
+	want := []watchChange{
+		{Path: "edit.txt", Change: "modified", Size: 11},
+		{Path: "gone.txt", Change: "deleted"},
+		{Path: "out/build.log", Change: "created", Size: 2},
+	}
+	if len(report.Changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), report.Changes)
+	}
+	for i := range want {
+		got := report.Changes[i]
+		if got.Path != want[i].Path || got.Change != want[i].Change || got.Size != want[i].Size {
+			t.Errorf("change %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+	if report.Polls < 5 {
+		t.Errorf("expected the full duration to be watched, got %d polls", report.Polls)
+	}
+}
+
+func TestWatchDirStopOnChange(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"watch_dir": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		_ = os.WriteFile(filepath.Join(absDir, "ready"), []byte("1"), 0o644)
+	}()
+
+	report := runWatchDir(t, registry, map[string]interface{}{
+		"path":             relDir,
+		"duration_seconds": 30,
+		"interval_ms":      100,
+		"stop_on_change":   true,
+	})
+	if report.WatchedMS >= 30000 || len(report.Changes) != 1 || report.Changes[0].Path != "ready" {
+		t.Fatalf("expected to stop at the first change, got %+v", report)
+	}
+
+	if result := registry.Execute("watch_dir", map[string]interface{}{"path": "../"}); result.Error == nil {
+		t.Fatal("expected watch_dir to reject paths outside the working directory")
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	workRootMu sync.Mutex
+	workRoot   string
+)
+
+// ConfigureWorkRoot sets the directory that ChangeWorkingDirectory never leaves.
+// An empty root captures the current working directory.
+func ConfigureWorkRoot(root string) error {
+	if root == "" {
+		resolved, err := resolveBaseDir(context.Background())
+		if err != nil {
+			return err
+		}
+		root = resolved
+	} else {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return fmt.Errorf("invalid work root: %v", err)
+		}
+		resolved, err := filepath.EvalSymlinks(abs)
+		if err != nil {
+			return fmt.Errorf("failed to resolve work root: %v", err)
+		}
+		root = resolved
+	}
+	workRootMu.Lock()
+	defer workRootMu.Unlock()
+	workRoot = root
+	return nil
+}
+
+// WorkRoot returns the guard root, capturing the current directory on first use.
+func WorkRoot() (string, error) {
+	workRootMu.Lock()
+	defer workRootMu.Unlock()
+	if workRoot == "" {
+		resolved, err := resolveBaseDir(context.Background())
+		if err != nil {
+			return "", err
+		}
+		workRoot = resolved
+	}
+	return workRoot, nil
+}
+
+// workdirState is the working directory of one registry, moved by the cd
+// tool. It is empty until the first change, which means the process working
+// directory. The process directory itself is never changed, so concurrent
+// tool calls and relative paths elsewhere in the program are unaffected.
+type workdirState struct {
+	mu  sync.Mutex
+	dir string
+}
+
+func (w *workdirState) get() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dir
+}
+
+func (w *workdirState) set(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.dir = dir
+}
+
+type workdirKey struct{}
+
+// toolContext returns the context a tool call of this registry starts from:
+// relative paths resolve against the registry working directory, captured
+// once so a concurrent cd cannot move a call halfway through.
+func (r *Registry) toolContext() context.Context {
+	ctx := context.WithValue(context.Background(), workdirKey{}, &r.workdir)
+	if dir := r.workdir.get(); dir != "" {
+		ctx = withBaseDir(ctx, dir)
+	}
+	return ctx
+}
+
+// WorkingDirectory returns the directory tools of this registry resolve
+// relative paths against.
+func (r *Registry) WorkingDirectory() (string, error) {
+	return toolWorkdir(r.toolContext())
+}
+
+// SetWorkingDirectory points the tools of this registry at dir without
+// checking it against WorkRoot; an empty dir returns them to the process
+// working directory.
+func (r *Registry) SetWorkingDirectory(dir string) {
+	r.workdir.set(dir)
+}
+
+// ChangeWorkingDirectory moves the working directory of this registry to
+// path, resolved against the current one, and returns the new directory. The
+// target must stay within WorkRoot. The change applies to every later tool
+// call of the registry.
+func (r *Registry) ChangeWorkingDirectory(path string) (string, error) {
+	return changeWorkingDirectory(r.toolContext(), path)
+}
+
+func changeWorkingDirectory(ctx context.Context, path string) (string, error) {
+	state, ok := ctx.Value(workdirKey{}).(*workdirState)
+	if !ok {
+		return "", fmt.Errorf("cannot change directory: no working directory to change")
+	}
+	resolved, err := resolveDirWithinWorkRoot(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("cannot change directory: %w", err)
+	}
+	state.set(resolved)
+	return resolved, nil
+}
+
+// resolveDirWithinWorkRoot resolves path against the working directory of
+// ctx (an empty path is WorkRoot itself) and checks that it is a directory
+// within WorkRoot.
+func resolveDirWithinWorkRoot(ctx context.Context, path string) (string, error) {
+	root, err := WorkRoot()
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		path = root
+	}
+	if !filepath.IsAbs(path) {
+		current, err := resolveBaseDir(ctx)
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(current, path)
+	}
+	resolved, err := ensureResolvedPathWithinBase(path, root)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("not a directory: %s", path)
+	}
+	return resolved, nil
+}
+
+func changeDirectoryTool(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	path, _ := getStringLike(args["path"])
+	return changeWorkingDirectory(ctx, path)
+}
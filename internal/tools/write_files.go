@@ -0,0 +1,175 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxWriteFiles caps how many files a single write_files call may create.
+const maxWriteFiles = 64
+
+type writeFilesArgs struct {
+	Files     []writeFileEntry `json:"files" jsonschema:"description=Files to write as objects with path and content,minItems=1" validate:"required,min=1,dive"`
+	Overwrite bool             `json:"overwrite,omitempty" jsonschema:"description=Replace files that already exist"`
+}
+
+type writeFileEntry struct {
+	Path    string `json:"path" jsonschema:"description=Path of the file to write,minLength=1" validate:"required,min=1"`
+	Content string `json:"content" jsonschema:"description=Text content to write (may be empty)"`
+}
+
+// plannedWrite is a validated write_files entry.
+type plannedWrite struct {
+	Path     string
+	Resolved string
+	Content  string
+	Mode     os.FileMode
+	Exists   bool
+}
+
+func validateWriteFilesArgs(ctx context.Context, args map[string]interface{}) error {
+	_, err := planWriteFiles(ctx, args)
+	return err
+}
+
+// planWriteFiles validates every entry before anything is written. All
+// offending paths are reported together so the model can fix them in one go.
+func planWriteFiles(ctx context.Context, args map[string]interface{}) ([]plannedWrite, error) {
+	parsed, err := unmarshalAndValidate[writeFilesArgs](args)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.Files) > maxWriteFiles {
+		return nil, fmt.Errorf("too many files: %d (maximum %d per call)", len(parsed.Files), maxWriteFiles)
+	}
+
+	workdir, err := toolWorkdir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine working directory: %v", err)
+	}
+	limits := getLimits()
+
+	var total int64
+	var problems []string
+	seen := make(map[string]string, len(parsed.Files))
+	plan := make([]plannedWrite, 0, len(parsed.Files))
+	for _, entry := range parsed.Files {
+		write, err := planWriteFile(entry, workdir, parsed.Overwrite, limits)
+		if err == nil {
+			if previous, dup := seen[write.Resolved]; dup {
+				err = fmt.Errorf("same file as '%s'", previous)
+			} else {
+				seen[write.Resolved] = entry.Path
+			}
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", entry.Path, err))
+			continue
+		}
+		total += int64(len(write.Content))
+		plan = append(plan, write)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("no files written, invalid paths:\n  %s", strings.Join(problems, "\n  "))
+	}
+	if total > limits.MaxFileSizeBytes {
+		return nil, fmt.Errorf("no files written, total content %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
+	}
+	return plan, nil
+}
+
+func planWriteFile(entry writeFileEntry, workdir string, overwrite bool, limits Limits) (plannedWrite, error) {
+	path, err := extractPathArg(map[string]interface{}{"path": strings.TrimSpace(entry.Path)})
+	if err != nil {
+		return plannedWrite{}, err
+	}
+	entry.Content = normalizeLineEndings(entry.Content)
+	if int64(len(entry.Content)) > limits.MaxFileSizeBytes {
+		return plannedWrite{}, fmt.Errorf("content %w of %d bytes", ErrFileTooLarge, limits.MaxFileSizeBytes)
+	}
+	if !isTextContent([]byte(entry.Content)) {
+		return plannedWrite{}, fmt.Errorf("content %w; write_files supports text only", ErrBinaryContent)
+	}
+	resolved, err := resolvePathWithinBaseAllowMissing(path, workdir)
+	if err != nil {
+		return plannedWrite{}, err
+	}
+
+	write := plannedWrite{Path: path, Resolved: resolved, Content: entry.Content, Mode: 0o644}
+	if info, err := os.Stat(resolved); err == nil {
+		if info.IsDir() {
+			return plannedWrite{}, fmt.Errorf("path is a directory")
+		}
+		if !overwrite {
+			return plannedWrite{}, fmt.Errorf("file already exists; set overwrite to true to replace it")
+		}
+		write.Mode = info.Mode().Perm()
+		write.Exists = true
+	} else if !os.IsNotExist(err) {
+		return plannedWrite{}, fmt.Errorf("failed to stat file: %v", err)
+	}
+	return write, nil
+}
+
+func writeFiles(ctx context.Context, args map[string]interface{}) (string, error) {
+	if err := ensureContext(ctx); err != nil {
+		return "", err
+	}
+	plan, err := planWriteFiles(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	total := 0
+	for i, write := range plan {
+		if err := ensureContext(ctx); err != nil {
+			return "", fmt.Errorf("stopped after %d of %d files: %w", i, len(plan), err)
+		}
+		if err := os.MkdirAll(filepath.Dir(write.Resolved), 0o755); err != nil {
+			return "", fmt.Errorf("stopped after %d of %d files: failed to create parent directories for %s: %v", i, len(plan), write.Path, err)
+		}
+		if err := os.WriteFile(write.Resolved, []byte(write.Content), write.Mode); err != nil {
+			return "", fmt.Errorf("stopped after %d of %d files: failed to write %s: %v", i, len(plan), write.Path, err)
+		}
+		total += len(write.Content)
+		fmt.Fprintf(&b, "\n  %s (%d bytes)", write.Path, len(write.Content))
+	}
+	return fmt.Sprintf("Successfully wrote %d files (%d bytes):%s", len(plan), total, b.String()), nil
+}
+
+// summarizeWriteFiles lists each target with its size and whether it is replaced.
+func summarizeWriteFiles(ctx context.Context, args map[string]interface{}) string {
+	plan, err := planWriteFiles(ctx, args)
+	if err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(plan))
+	for _, write := range plan {
+		line := fmt.Sprintf("%s (%s)", write.Resolved, formatBytes(int64(len(write.Content))))
+		if write.Exists {
+			line += " (overwrites existing)"
+		}
+		lines = append(lines, line)
+	}
+	return formatSummary(fmt.Sprintf("write_files will write %d file(s):", len(plan)), lines)
+}
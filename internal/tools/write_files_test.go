@@ -0,0 +1,179 @@
+// Copyright (C) 2025 Dyne.org foundation
+// designed, written and maintained by Denis Roio <jaromil@dyne.org>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as
+// published by the Free Software Foundation, either version 3 of the
+// License, or (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFiles(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"write_files": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+
+	result := registry.Execute("write_files", map[string]interface{}{
+		"files": []interface{}{
+			map[string]interface{}{"path": filepath.Join(relDir, "main.go"), "content": "package main\n"},
+			map[string]interface{}{"path": filepath.Join(relDir, "pkg", "doc.go"), "content": "package pkg\n"},
+			map[string]interface{}{"path": filepath.Join(relDir, "pkg", "empty.txt"), "content": ""},
+		},
+	})
+	if result.Error != nil {
+		t.Fatalf("expected write_files success, got: %v", result.Error)
+	}
+	if !strings.Contains(result.Result, "Successfully wrote 3 files (25 bytes)") ||
+		!strings.Contains(result.Result, filepath.Join(relDir, "pkg", "doc.go")+" (12 bytes)") {
+		t.Fatalf("unexpected summary: %q", result.Result)
+	}
+
+	data, err := os.ReadFile(filepath.Join(absDir, "pkg", "doc.go"))
+	if err != nil || string(data) != "package pkg\n" {
+		t.Fatalf("unexpected nested file content %q: %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(absDir, "pkg", "empty.txt")); err != nil || info.Size() != 0 {
+		t.Fatalf("expected empty file to be created: %v", err)
+	}
+}
+
+func TestWriteFilesValidatesBeforeWriting(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"write_files": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	existing := filepath.Join(absDir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("keep"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	fresh := filepath.Join(relDir, "fresh.txt")
+	result := registry.Execute("write_files", map[string]interface{}{
+		"files": []interface{}{
+			map[string]interface{}{"path": fresh, "content": "new"},
+			map[string]interface{}{"path": filepath.Join(relDir, "existing.txt"), "content": "replaced"},
+			map[string]interface{}{"path": "../outside.txt", "content": "escape"},
+		},
+	})
+	if result.Error == nil {
+		t.Fatal("expected write_files to fail")
+	}
+	message := result.Error.Error()
+	if !strings.Contains(message, "no files written") ||
+		!strings.Contains(message, filepath.Join(relDir, "existing.txt")+": file already exists") ||
+		!strings.Contains(message, "../outside.txt") || strings.Contains(message, fresh+":") {
+		t.Fatalf("expected error listing the offending paths, got: %v", message)
+	}
+	if _, err := os.Stat(filepath.Join(absDir, "fresh.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected no file to be written, stat err: %v", err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "keep" {
+		t.Fatalf("existing file was modified: %q", data)
+	}
+
+	duplicate := registry.Execute("write_files", map[string]interface{}{
+		"files": []interface{}{
+			map[string]interface{}{"path": fresh, "content": "a"},
+			map[string]interface{}{"path": "./" + fresh, "content": "b"},
+		},
+	})
+	if duplicate.Error == nil || !strings.Contains(duplicate.Error.Error(), "same file as") {
+		t.Fatalf("expected duplicate path error, got: %v", duplicate.Error)
+	}
+
+	overwrite := registry.Execute("write_files", map[string]interface{}{
+		"overwrite": true,
+		"files": []interface{}{
+			map[string]interface{}{"path": filepath.Join(relDir, "existing.txt"), "content": "replaced"},
+		},
+	})
+	if overwrite.Error != nil {
+		t.Fatalf("expected overwrite to succeed, got: %v", overwrite.Error)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "replaced" {
+		t.Fatalf("expected existing file to be replaced, got %q", data)
+	}
+}
+
+func TestWriteFilesTotalSizeLimit(t *testing.T) {
+	ConfigureLimits(Limits{
+		MaxFileSizeBytes:    6,
+		MaxDirectoryDepth:   defaultMaxDirectoryDepth,
+		MaxDirectoryEntries: defaultMaxDirectoryEntries,
+	})
+	t.Cleanup(func() {
+		ConfigureLimits(DefaultLimits())
+	})
+
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"write_files": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	result := registry.Execute("write_files", map[string]interface{}{
+		"files": []interface{}{
+			map[string]interface{}{"path": filepath.Join(relDir, "a.txt"), "content": "1234"},
+			map[string]interface{}{"path": filepath.Join(relDir, "b.txt"), "content": "5678"},
+		},
+	})
+	if !errors.Is(result.Error, ErrFileTooLarge) {
+		t.Fatalf("expected total size error, got: %v", result.Error)
+	}
+	if _, err := os.Stat(filepath.Join(absDir, "a.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected no file to be written, stat err: %v", err)
+	}
+}
+
+func TestWriteFilesNormalizesLineEndings(t *testing.T) {
+	registry := NewRegistryWithPolicy(Policy{
+		Allow: map[string]bool{
+			"write_files": true,
+		},
+	})
+	absDir, relDir := tempDirInCwd(t)
+	t.Cleanup(func() { ConfigureLineEnding(LineEndingPreserve) })
+
+	const mixed = "one\r\ntwo\nthree\r\n"
+	cases := map[string]string{
+		LineEndingPreserve: mixed,
+		LineEndingLF:       "one\ntwo\nthree\n",
+		LineEndingCRLF:     "one\r\ntwo\r\nthree\r\n",
+	}
+	for mode, want := range cases {
+		ConfigureLineEnding(mode)
+		result := registry.Execute("write_files", map[string]interface{}{
+			"files": []interface{}{
+				map[string]interface{}{"path": filepath.Join(relDir, mode+".txt"), "content": mixed},
+			},
+		})
+		if result.Error != nil {
+			t.Fatalf("%s: expected write_files success, got: %v", mode, result.Error)
+		}
+		data, err := os.ReadFile(filepath.Join(absDir, mode+".txt"))
+		if err != nil || string(data) != want {
+			t.Fatalf("%s: unexpected content %q: %v", mode, data, err)
+		}
+	}
+}